@@ -69,6 +69,21 @@ Build DATA_SOURCES JSON from dataSources values with computed mount paths.
 {{- if $ds.platform -}}
 {{- $source = set $source "platform" $ds.platform -}}
 {{- end -}}
+{{- if $ds.context -}}
+{{- $source = set $source "context" $ds.context -}}
+{{- end -}}
+{{- if $ds.execEnv -}}
+{{- $source = set $source "execEnv" $ds.execEnv -}}
+{{- end -}}
+{{- if $ds.primary -}}
+{{- $source = set $source "primary" true -}}
+{{- end -}}
+{{- if $ds.profiles -}}
+{{- $source = set $source "profiles" $ds.profiles -}}
+{{- end -}}
+{{- if $ds.interval -}}
+{{- $source = set $source "interval" $ds.interval -}}
+{{- end -}}
 {{- $sources = append $sources $source -}}
 {{- end -}}
 {{- $sources | toJson -}}
@@ -4,44 +4,345 @@ import (
 	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/parser"
 	"github.com/fredericrous/cluster-vision/internal/server"
 )
 
 func main() {
-	var cfg server.Config
-	flag.IntVar(&cfg.Port, "port", 8080, "HTTP server port")
-	flag.StringVar(&cfg.Kubeconfig, "kubeconfig", "", "path to kubeconfig (empty for in-cluster)")
-	flag.DurationVar(&cfg.RefreshInterval, "refresh", 5*time.Minute, "data refresh interval")
+	var flags server.Config
+	var configPath string
+	flag.IntVar(&flags.Port, "port", 8080, "HTTP server port")
+	flag.StringVar(&flags.Kubeconfig, "kubeconfig", "", "path to kubeconfig (empty for in-cluster)")
+	flag.DurationVar(&flags.RefreshInterval, "refresh", 5*time.Minute, "data refresh interval")
+	flag.DurationVar(&flags.ChartCheckInterval, "chart-check-interval", 15*time.Minute, "minimum time between Helm chart version checks (0 checks every refresh)")
+	flag.IntVar(&flags.TagCacheSize, "tag-cache-size", 1000, "maximum image repos and OCI charts whose tag listings are cached (0 disables the cache)")
+	flag.DurationVar(&flags.TagCacheTTL, "tag-cache-ttl", 0, "how long a tag listing is reused before the repo is listed again (0 uses the shorter checker interval)")
+	flag.DurationVar(&flags.RefreshIfStale, "refresh-if-stale", 0, "refresh in the background when diagrams older than this are read (0 disables)")
+	flag.StringVar(&flags.StaticDir, "static-dir", "", "serve the web UI from this directory (SPA fallback to index.html)")
+	flag.StringVar(&flags.ExportDir, "export-dir", "", "write diagrams to this directory after each refresh")
+	flag.StringVar(&flags.SnapshotDB, "snapshot-db", "", "write a SQLite snapshot of the cluster data to this file after each refresh")
+	flag.StringVar(&configPath, "config", "", "JSON config file (data sources, filters); re-read on SIGHUP")
 	flag.Parse()
 
+	load := func() (server.Config, error) { return loadConfig(flags, configPath) }
+	cfg, err := load()
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("cluster-vision starting",
+		"port", cfg.Port,
+		"kubeconfig", cfg.Kubeconfig,
+		"dataSources", len(cfg.DataSources),
+		"refresh", cfg.RefreshInterval,
+		"eam", cfg.DatabaseURL != "",
+	)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		slog.Error("failed to create server", "error", err)
+		os.Exit(1)
+	}
+
+	// SIGHUP re-reads the environment and config file.
+	go srv.WatchReload(ctx, load)
+	// DATA_SOURCES_FROM is watched in the cluster.
+	go srv.WatchDataSources(ctx)
+
+	if err := srv.Start(ctx); err != nil {
+		slog.Error("server error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig builds the server config from flags, the environment and, when
+// configPath is set, the config file (whose values win over the environment).
+func loadConfig(flags server.Config, configPath string) (server.Config, error) {
+	cfg := flags
+
 	// Allow env var overrides
 	if v := os.Getenv("KUBECONFIG"); v != "" && cfg.Kubeconfig == "" {
 		cfg.Kubeconfig = v
 	}
+	if v := os.Getenv("STATIC_DIR"); v != "" && cfg.StaticDir == "" {
+		cfg.StaticDir = v
+	}
+	if v := os.Getenv("EXPORT_DIR"); v != "" && cfg.ExportDir == "" {
+		cfg.ExportDir = v
+	}
+	if v := os.Getenv("SNAPSHOT_DB"); v != "" && cfg.SnapshotDB == "" {
+		cfg.SnapshotDB = v
+	}
+	if v := os.Getenv("REFRESH_IF_STALE"); v != "" && cfg.RefreshIfStale == 0 {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing REFRESH_IF_STALE: %w", err)
+		}
+		cfg.RefreshIfStale = d
+	}
+	// Longest wait between refreshes while the API server is unreachable, e.g. "30m"
+	if v := os.Getenv("REFRESH_BACKOFF_MAX"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing REFRESH_BACKOFF_MAX: %w", err)
+		}
+		cfg.RefreshBackoffMax = d
+	}
+	// Warnings below this severity are hidden: info, warn (default) or error
+	cfg.WarningMinSeverity = os.Getenv("WARNING_MIN_SEVERITY")
+	// Age after which served data is flagged stale, e.g. "15m"
+	if v := os.Getenv("STALE_AFTER"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing STALE_AFTER: %w", err)
+		}
+		cfg.StaleAfter = d
+	}
+	if v := os.Getenv("CHART_CHECK_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing CHART_CHECK_INTERVAL: %w", err)
+		}
+		cfg.ChartCheckInterval = d
+	}
+	// How long a new upstream version must be the latest before it flags
+	// deployments as outdated, e.g. "72h"
+	if v := os.Getenv("OUTDATED_GRACE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing OUTDATED_GRACE: %w", err)
+		}
+		cfg.OutdatedGrace = d
+	}
 	if v := os.Getenv("CLUSTER_NAME"); v != "" {
 		cfg.ClusterName = v
 	}
+	// Node label naming the cluster when CLUSTER_NAME is unset, e.g. cluster.x-k8s.io/cluster-name
+	cfg.ClusterNameLabel = os.Getenv("CLUSTER_NAME_LABEL")
+	// Where Istio east-west gateways live, for installs outside istio-system
+	cfg.EastWestNamespace = os.Getenv("EASTWEST_NAMESPACE")
+	cfg.EastWestLabel = os.Getenv("EASTWEST_LABEL")
+	// Extra node role rules, e.g. "node.kubernetes.io/control-plane:control-plane,tier=db:etcd"
+	if v := os.Getenv("NODE_ROLE_RULES"); v != "" {
+		rules, err := parser.ParseRoleRules(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing NODE_ROLE_RULES: %w", err)
+		}
+		cfg.NodeRoleRules = rules
+	}
 	if v := os.Getenv("REGISTRY_PROXY"); v != "" {
 		cfg.RegistryProxy = v
 	}
+	if v := os.Getenv("LOCAL_REGISTRY"); v != "" {
+		cfg.LocalRegistry = v
+	}
+	// Minimum chart/image versions, e.g. {"images": {"nginx": ">=1.25"}}
+	cfg.PolicyFile = os.Getenv("POLICY_FILE")
+	// Image inventory scope, e.g. "media,team-*"
+	if v := os.Getenv("IMAGE_NAMESPACES"); v != "" {
+		for _, ns := range strings.Split(v, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				cfg.ImageNamespaces = append(cfg.ImageNamespaces, ns)
+			}
+		}
+	}
+	// Diagram generators to run first, and to skip, e.g. "nodes,images" and "labels"
+	cfg.DiagramOrder = splitList(os.Getenv("DIAGRAM_ORDER"))
+	cfg.DisabledDiagrams = splitList(os.Getenv("DISABLED_DIAGRAMS"))
+	// What pinned image tags are compared against: latest, major or minor
+	cfg.ImageTagCompare = os.Getenv("IMAGE_TAG_COMPARE")
+	// Cap on tags listed per image, for repos with tens of thousands of tags
+	if v := os.Getenv("MAX_TAGS_PER_IMAGE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing MAX_TAGS_PER_IMAGE: %w", err)
+		}
+		cfg.MaxTagsPerImage = n
+	}
+	// Tag listing cache shared by the chart and image checkers
+	if v := os.Getenv("TAG_CACHE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing TAG_CACHE_SIZE: %w", err)
+		}
+		cfg.TagCacheSize = n
+	}
+	if v := os.Getenv("TAG_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing TAG_CACHE_TTL: %w", err)
+		}
+		cfg.TagCacheTTL = d
+	}
+
+	if v := os.Getenv("INCLUDE_TERMINATED_PODS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing INCLUDE_TERMINATED_PODS: %w", err)
+		}
+		cfg.IncludeTerminatedPods = b
+	}
+
+	// Label or annotation naming the owning team, e.g. app.kubernetes.io/part-of
+	cfg.TeamLabel = os.Getenv("TEAM_LABEL")
+
+	// Header for per-request IDs in access logs (default X-Request-Id)
+	cfg.RequestIDHeader = os.Getenv("REQUEST_ID_HEADER")
+
+	// Concurrent /api requests before 503 (0 = unlimited)
+	if v := os.Getenv("MAX_CONCURRENT_REQUESTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing MAX_CONCURRENT_REQUESTS: %w", err)
+		}
+		cfg.MaxConcurrentRequests = n
+	}
+
+	// Other origins whose pages may open the WebSocket (same-origin only by default)
+	cfg.WSAllowedOrigins = splitList(os.Getenv("WS_ALLOWED_ORIGINS"))
+
+	// Optional image vulnerability scanner (Trivy server wrapper and/or report dir)
+	cfg.TrivyServerURL = os.Getenv("TRIVY_SERVER_URL")
+	cfg.VulnReportDir = os.Getenv("VULN_REPORT_DIR")
+
+	// Kubernetes client rate limits (defaults in parser.DefaultQPS/DefaultBurst)
+	if v := os.Getenv("KUBE_QPS"); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing KUBE_QPS: %w", err)
+		}
+		cfg.KubeQPS = float32(f)
+	}
+	if v := os.Getenv("KUBE_BURST"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing KUBE_BURST: %w", err)
+		}
+		cfg.KubeBurst = n
+	}
+
+	if v := os.Getenv("MAX_LABEL_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing MAX_LABEL_LENGTH: %w", err)
+		}
+		cfg.MaxLabelLength = n
+	}
+
+	if v := os.Getenv("MERGE_MESH_SERVICE_ENTRIES"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing MERGE_MESH_SERVICE_ENTRIES: %w", err)
+		}
+		cfg.MergeMeshServiceEntries = b
+	}
+
+	// Extra security matrix columns, e.g. "Compliance=compliance:pci,Cost center=cost-center"
+	if v := os.Getenv("NAMESPACE_COLUMNS"); v != "" {
+		cols, err := parseLabelColumns(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing NAMESPACE_COLUMNS: %w", err)
+		}
+		cfg.NamespaceColumns = cols
+	}
+
+	// Age past which a namespace's last Velero backup counts as stale, e.g. "24h"
+	if v := os.Getenv("BACKUP_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing BACKUP_MAX_AGE: %w", err)
+		}
+		cfg.BackupMaxAge = d
+	}
+
+	// System namespaces shown anyway, per diagram, e.g. "security:default,security:kube-system"
+	if v := os.Getenv("INCLUDE_SYSTEM_NAMESPACES"); v != "" {
+		inc, err := parseNamespaceIncludes(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing INCLUDE_SYSTEM_NAMESPACES: %w", err)
+		}
+		cfg.IncludeSystemNamespaces = inc
+	}
+
+	// Registry names shown in the images table, e.g. "docker.io=Docker Hub,*.dkr.ecr.*.amazonaws.com=ECR"
+	if v := os.Getenv("REGISTRY_ALIASES"); v != "" {
+		aliases, err := parseRegistryAliases(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing REGISTRY_ALIASES: %w", err)
+		}
+		cfg.RegistryAliases = aliases
+	}
+
+	// Workloads listed under each node in the topology (0 = off)
+	if v := os.Getenv("TOPOLOGY_PLACEMENT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing TOPOLOGY_PLACEMENT: %w", err)
+		}
+		cfg.PlacementLimit = n
+	}
+
+	// Node label grouping the topology into zones/racks, e.g. "topology.kubernetes.io/zone"
+	cfg.TopologyGroupLabel = os.Getenv("TOPOLOGY_GROUP_LABEL")
+
+	// Node OS distros resolved via endoflife.date, e.g. "ubuntu,rhel=redhat"
+	cfg.EOLWarnDays = 90
+	if v := os.Getenv("EOL_DISTROS"); v != "" {
+		cfg.EOLProducts = make(map[string]string)
+		for _, entry := range strings.Split(v, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			distro, product, ok := strings.Cut(entry, "=")
+			if !ok {
+				product = distro
+			}
+			cfg.EOLProducts[strings.ToLower(distro)] = product
+		}
+	}
+	// Compare node containerd/CRI-O versions against their latest release
+	if v := os.Getenv("CHECK_CONTAINER_RUNTIMES"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing CHECK_CONTAINER_RUNTIMES: %w", err)
+		}
+		cfg.CheckRuntimes = b
+	}
+	if v := os.Getenv("EOL_WARN_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing EOL_WARN_DAYS: %w", err)
+		}
+		cfg.EOLWarnDays = n
+	}
 
 	// Data sources from env
 	if v := os.Getenv("DATA_SOURCES"); v != "" {
 		var sources []model.DataSource
 		if err := json.Unmarshal([]byte(v), &sources); err != nil {
-			slog.Error("failed to parse DATA_SOURCES", "error", err)
-			os.Exit(1)
+			return cfg, fmt.Errorf("parsing DATA_SOURCES: %w", err)
 		}
 		cfg.DataSources = sources
 	}
 
+	// Data sources from a ConfigMap/Secret, e.g. "configmap/cluster-vision/data-sources"
+	cfg.DataSourcesFrom = os.Getenv("DATA_SOURCES_FROM")
+
 	// Backward compat: TFSTATE_PATH creates a single tfstate source
 	if v := os.Getenv("TFSTATE_PATH"); v != "" && len(cfg.DataSources) == 0 {
 		cfg.DataSources = []model.DataSource{{
@@ -65,25 +366,68 @@ func main() {
 		cfg.LiteLLMModel = v
 	}
 
-	slog.Info("cluster-vision starting",
-		"port", cfg.Port,
-		"kubeconfig", cfg.Kubeconfig,
-		"dataSources", len(cfg.DataSources),
-		"refresh", cfg.RefreshInterval,
-		"eam", cfg.DatabaseURL != "",
-	)
+	if configPath != "" {
+		return server.ApplyConfigFile(cfg, configPath)
+	}
+	return cfg, nil
+}
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
+// parseLabelColumns parses "Name=key[:value],..." into security matrix
+// columns. A column without a value matches any namespace carrying the key.
+func parseLabelColumns(s string) ([]model.LabelColumn, error) {
+	var cols []model.LabelColumn
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, label, ok := strings.Cut(part, "=")
+		if !ok || name == "" || label == "" {
+			return nil, fmt.Errorf("invalid column %q, want Name=key[:value]", part)
+		}
+		key, value, _ := strings.Cut(label, ":")
+		cols = append(cols, model.LabelColumn{Name: strings.TrimSpace(name), Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return cols, nil
+}
 
-	srv, err := server.New(cfg)
-	if err != nil {
-		slog.Error("failed to create server", "error", err)
-		os.Exit(1)
+// parseNamespaceIncludes parses "diagram:namespace" pairs, e.g.
+// "security:default,security:kube-system", into namespaces per diagram ID.
+func parseNamespaceIncludes(s string) (map[string][]string, error) {
+	inc := make(map[string][]string)
+	for _, part := range splitList(s) {
+		id, ns, ok := strings.Cut(part, ":")
+		id, ns = strings.TrimSpace(id), strings.TrimSpace(ns)
+		if !ok || id == "" || ns == "" {
+			return nil, fmt.Errorf("invalid entry %q, want diagram:namespace", part)
+		}
+		inc[id] = append(inc[id], ns)
 	}
+	return inc, nil
+}
 
-	if err := srv.Start(ctx); err != nil {
-		slog.Error("server error", "error", err)
-		os.Exit(1)
+// parseRegistryAliases parses "host=Name" pairs, e.g. "ghcr.io=GHCR", into
+// display names per registry host or host pattern.
+func parseRegistryAliases(s string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, part := range splitList(s) {
+		host, name, ok := strings.Cut(part, "=")
+		host, name = strings.TrimSpace(host), strings.TrimSpace(name)
+		if !ok || host == "" || name == "" {
+			return nil, fmt.Errorf("invalid entry %q, want host=Name", part)
+		}
+		aliases[host] = name
+	}
+	return aliases, nil
+}
+
+// splitList splits a comma-separated list, dropping blank entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
 	}
+	return out
 }
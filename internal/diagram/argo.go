@@ -0,0 +1,134 @@
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// ArgoAppRow represents a single row in the Argo CD Applications table.
+type ArgoAppRow struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Cluster     string `json:"cluster"`
+	Project     string `json:"project"`
+	Destination string `json:"destination"` // "cluster/namespace"
+	Source      string `json:"source"`      // "repoURL/path@revision"
+	SyncWave    int    `json:"syncWave"`
+	SyncStatus  string `json:"syncStatus"`
+	Health      string `json:"health"`
+}
+
+// GenerateArgo produces the Argo CD sync-wave flow ("argo") and the
+// Applications status table ("argo-apps").
+func GenerateArgo(data *model.ClusterData) []model.DiagramResult {
+	if len(data.ArgoApps) == 0 {
+		empty, _ := json.Marshal(FlowData{Nodes: []FlowNode{}, Edges: []FlowEdge{}})
+		return []model.DiagramResult{{
+			ID:      "argo",
+			Title:   "Argo CD Sync Waves",
+			Type:    "flow",
+			Content: string(empty),
+			Empty:   true,
+		}, {
+			ID:      "argo-apps",
+			Title:   "Argo CD Applications",
+			Type:    "markdown",
+			Content: "*No Argo CD Application data available.*",
+			Empty:   true,
+		}}
+	}
+
+	apps := make([]model.ArgoApplication, len(data.ArgoApps))
+	copy(apps, data.ArgoApps)
+	sort.Slice(apps, func(i, j int) bool {
+		a, b := apps[i], apps[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.SyncWave != b.SyncWave {
+			return a.SyncWave < b.SyncWave
+		}
+		return a.Name < b.Name
+	})
+
+	return []model.DiagramResult{argoWaveFlow(apps), argoAppsTable(apps)}
+}
+
+// argoWaveFlow lays the apps of each Argo CD instance (cluster/namespace) out
+// by sync wave: every app depends on the apps of the previous wave.
+func argoWaveFlow(apps []model.ArgoApplication) model.DiagramResult {
+	flow := FlowData{Nodes: []FlowNode{}, Edges: []FlowEdge{}}
+	var prev, cur []string // node IDs of the previous and current wave
+	for i, a := range apps {
+		id := a.Cluster + "/" + a.Namespace + "/" + a.Name
+		if i > 0 {
+			last := apps[i-1]
+			switch {
+			case last.Cluster != a.Cluster || last.Namespace != a.Namespace:
+				prev, cur = nil, nil
+			case last.SyncWave != a.SyncWave:
+				prev, cur = cur, nil
+			}
+		}
+		flow.Nodes = append(flow.Nodes, FlowNode{
+			ID:      id,
+			Label:   a.Name,
+			Cluster: a.Cluster,
+			Layer:   fmt.Sprintf("wave %d", a.SyncWave),
+		})
+		for _, dep := range prev {
+			flow.Edges = append(flow.Edges, FlowEdge{
+				ID:     dep + "->" + id,
+				Source: dep,
+				Target: id,
+			})
+		}
+		cur = append(cur, id)
+	}
+
+	content, _ := json.Marshal(flow)
+	return model.DiagramResult{
+		ID:      "argo",
+		Title:   "Argo CD Sync Waves",
+		Type:    "flow",
+		Content: string(content),
+	}
+}
+
+func argoAppsTable(apps []model.ArgoApplication) model.DiagramResult {
+	rows := make([]ArgoAppRow, 0, len(apps))
+	for _, a := range apps {
+		source := a.RepoURL
+		if a.Path != "" {
+			source += "/" + a.Path
+		}
+		if a.TargetRevision != "" {
+			source += "@" + a.TargetRevision
+		}
+		rows = append(rows, ArgoAppRow{
+			Name:        a.Name,
+			Namespace:   a.Namespace,
+			Cluster:     a.Cluster,
+			Project:     a.Project,
+			Destination: a.DestCluster + "/" + a.DestNamespace,
+			Source:      source,
+			SyncWave:    a.SyncWave,
+			SyncStatus:  a.SyncStatus,
+			Health:      a.Health,
+		})
+	}
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "argo-apps",
+		Title:   "Argo CD Applications",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
@@ -0,0 +1,41 @@
+package diagram
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestGenerateArgoSyncWaves(t *testing.T) {
+	app := func(name string, wave int) model.ArgoApplication {
+		return model.ArgoApplication{Name: name, Namespace: "argocd", Cluster: "Homelab", SyncWave: wave, SyncStatus: "Synced"}
+	}
+	data := &model.ClusterData{ArgoApps: []model.ArgoApplication{
+		app("apps", 5), app("cert-manager", 0), app("cilium", -1), app("ingress", 0),
+	}}
+
+	results := GenerateArgo(data)
+	if len(results) != 2 || results[0].ID != "argo" || results[1].ID != "argo-apps" {
+		t.Fatalf("got %d results, want argo and argo-apps", len(results))
+	}
+
+	var flow FlowData
+	if err := json.Unmarshal([]byte(results[0].Content), &flow); err != nil {
+		t.Fatalf("decoding flow: %v", err)
+	}
+	var edges []string
+	for _, e := range flow.Edges {
+		edges = append(edges, e.ID)
+	}
+	want := []string{
+		"Homelab/argocd/cilium->Homelab/argocd/cert-manager",
+		"Homelab/argocd/cilium->Homelab/argocd/ingress",
+		"Homelab/argocd/cert-manager->Homelab/argocd/apps",
+		"Homelab/argocd/ingress->Homelab/argocd/apps",
+	}
+	if !slices.Equal(edges, want) {
+		t.Errorf("edges = %v, want %v", edges, want)
+	}
+}
@@ -0,0 +1,58 @@
+package diagram
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// CapabilityRow represents a single row in the integrations table.
+type CapabilityRow struct {
+	Cluster     string `json:"cluster"`
+	Integration string `json:"integration"`
+	Installed   bool   `json:"installed"`
+	Found       string `json:"found"`    // comma-separated served group/versions
+	Missing     string `json:"missing"`  // comma-separated groups not served
+	Diagrams    string `json:"diagrams"` // comma-separated diagram IDs it feeds
+}
+
+// GenerateCapabilities produces a table of the optional integrations each
+// cluster has installed, explaining why the diagrams they feed may be empty.
+func GenerateCapabilities(data *model.ClusterData) model.DiagramResult {
+	if len(data.Capabilities) == 0 {
+		return model.DiagramResult{
+			ID:      "capabilities",
+			Title:   "Integrations",
+			Type:    "markdown",
+			Content: "*No API discovery data available.*",
+			Empty:   true,
+		}
+	}
+
+	caps := make([]model.Capability, len(data.Capabilities))
+	copy(caps, data.Capabilities)
+	// Stable: each cluster keeps the parser's integration order.
+	sort.SliceStable(caps, func(i, j int) bool { return caps[i].Cluster < caps[j].Cluster })
+
+	rows := make([]CapabilityRow, 0, len(caps))
+	for _, c := range caps {
+		rows = append(rows, CapabilityRow{
+			Cluster:     c.Cluster,
+			Integration: c.Name,
+			Installed:   c.Installed,
+			Found:       strings.Join(c.Found, ", "),
+			Missing:     strings.Join(c.Missing, ", "),
+			Diagrams:    strings.Join(c.Diagrams, ", "),
+		})
+	}
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "capabilities",
+		Title:   "Integrations",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
@@ -0,0 +1,124 @@
+package diagram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// GenerateCAPI renders the Cluster API management plane: each management
+// cluster linked to the workload clusters it manages, with their machines
+// split into control plane and workers. It complements the tfstate-based
+// topology for clusters provisioned through Cluster API.
+func GenerateCAPI(data *model.ClusterData, opts Options) model.DiagramResult {
+	if len(data.CAPIClusters) == 0 {
+		return model.DiagramResult{
+			ID:      "capi",
+			Title:   "Cluster API",
+			Type:    "markdown",
+			Content: "*No Cluster API data available.*",
+			Empty:   true,
+		}
+	}
+
+	clusters := make([]model.CAPICluster, len(data.CAPIClusters))
+	copy(clusters, data.CAPIClusters)
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	var b strings.Builder
+	var notRunning []string
+	b.WriteString("graph TD\n")
+	for i, c := range clusters {
+		mgmtID := "mgmt_" + sanitizeID(c.Cluster)
+		if i == 0 || clusters[i-1].Cluster != c.Cluster {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", mgmtID, opts.nodeLabel(c.Cluster, "management cluster"))
+		}
+
+		id := "capi_" + sanitizeID(c.Cluster+"_"+c.Namespace+"_"+c.Name)
+		var details []string
+		if c.InfrastructureProvider != "" {
+			details = append(details, c.InfrastructureProvider)
+		}
+		if c.Phase != "" {
+			details = append(details, c.Phase)
+		}
+		fmt.Fprintf(&b, "  subgraph %s[\"%s\"]\n", id, opts.nodeLabel(c.Name, details...))
+
+		var controlPlane, workers []model.CAPIMachine
+		for _, m := range c.Machines {
+			if m.Role == "control-plane" {
+				controlPlane = append(controlPlane, m)
+			} else {
+				workers = append(workers, m)
+			}
+		}
+		cpTitle := "Control plane"
+		if c.ControlPlaneProvider != "" {
+			cpTitle += " (" + c.ControlPlaneProvider + ")"
+		}
+		for _, grp := range []struct {
+			suffix, title string
+			machines      []model.CAPIMachine
+		}{
+			{"cp", cpTitle, controlPlane},
+			{"workers", "Workers", workers},
+		} {
+			if len(grp.machines) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "    subgraph %s_%s[\"%s\"]\n", id, grp.suffix, escapeLabel(grp.title))
+			for _, m := range grp.machines {
+				mid := id + "_" + sanitizeID(m.Name)
+				fmt.Fprintf(&b, "      %s[\"%s\"]\n", mid, opts.nodeLabel(m.Name, machineDetails(m)...))
+				if m.Phase != "" && m.Phase != "Running" {
+					notRunning = append(notRunning, mid)
+				}
+			}
+			b.WriteString("    end\n")
+		}
+		b.WriteString("  end\n")
+		fmt.Fprintf(&b, "  %s --> %s\n", mgmtID, id)
+	}
+
+	if len(notRunning) > 0 {
+		b.WriteString("  classDef notRunning stroke:#dc2626,stroke-width:2px\n")
+		fmt.Fprintf(&b, "  class %s notRunning\n", strings.Join(notRunning, ","))
+	}
+
+	return model.DiagramResult{
+		ID:      "capi",
+		Title:   "Cluster API",
+		Type:    "mermaid",
+		Content: b.String(),
+	}
+}
+
+// machineDetails lists a machine's provider, version and phase on one line,
+// then the node it backs.
+func machineDetails(m model.CAPIMachine) []string {
+	var parts []string
+	for _, v := range []string{m.Provider, m.Version, m.Phase} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	var details []string
+	if len(parts) > 0 {
+		details = append(details, strings.Join(parts, " · "))
+	}
+	if m.NodeName != "" {
+		details = append(details, "node: "+m.NodeName)
+	}
+	return details
+}
@@ -0,0 +1,40 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestGenerateCAPI(t *testing.T) {
+	if r := GenerateCAPI(&model.ClusterData{}, DefaultOptions()); !r.Empty {
+		t.Errorf("without Cluster API data: %+v, want empty", r)
+	}
+
+	data := &model.ClusterData{CAPIClusters: []model.CAPICluster{{
+		Name: "prod", Namespace: "clusters", Cluster: "Management", Phase: "Provisioned",
+		InfrastructureProvider: "ProxmoxCluster", ControlPlaneProvider: "KubeadmControlPlane",
+		Machines: []model.CAPIMachine{
+			{Name: "prod-cp-1", Role: "control-plane", Provider: "ProxmoxMachine", Version: "v1.30.2", Phase: "Running", NodeName: "prod-cp-1"},
+			{Name: "prod-md-1", Role: "worker", Provider: "ProxmoxMachine", Version: "v1.30.2", Phase: "Failed"},
+		},
+	}}}
+	r := GenerateCAPI(data, DefaultOptions())
+	if r.Type != "mermaid" || r.Empty {
+		t.Fatalf("result = %+v, want a non-empty mermaid diagram", r)
+	}
+	for _, want := range []string{
+		`mgmt_Management["Management<br/>management cluster"]`,
+		`subgraph capi_Management_clusters_prod["prod<br/>ProxmoxCluster<br/>Provisioned"]`,
+		`subgraph capi_Management_clusters_prod_cp["Control plane (KubeadmControlPlane)"]`,
+		`capi_Management_clusters_prod_prod_cp_1["prod-cp-1<br/>ProxmoxMachine · v1.30.2 · Running<br/>node: prod-cp-1"]`,
+		`subgraph capi_Management_clusters_prod_workers["Workers"]`,
+		"mgmt_Management --> capi_Management_clusters_prod\n",
+		"class capi_Management_clusters_prod_prod_md_1 notRunning\n",
+	} {
+		if !strings.Contains(r.Content, want) {
+			t.Errorf("missing %s in:\n%s", want, r.Content)
+		}
+	}
+}
@@ -31,6 +31,7 @@ func GenerateCertificates(data *model.ClusterData) model.DiagramResult {
 			Title:   "Certificates",
 			Type:    "markdown",
 			Content: "*No certificate data available.*",
+			Empty:   true,
 		}
 	}
 
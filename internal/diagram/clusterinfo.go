@@ -0,0 +1,139 @@
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+// maxKubeletLag is how many minor versions a kubelet may trail the API
+// server under the Kubernetes version skew policy (since 1.28).
+const maxKubeletLag = 3
+
+// ClusterInfoRow represents a single row in the cluster info table.
+type ClusterInfoRow struct {
+	Cluster           string `json:"cluster"`
+	APIServer         string `json:"apiServer"` // API server git version; "" when unknown
+	LatestAPIServer   string `json:"latestAPIServer"`
+	APIServerOutdated bool   `json:"apiServerOutdated"`
+	Platform          string `json:"platform"`
+	Nodes             int    `json:"nodes"`
+	Kubelets          string `json:"kubelets"` // distinct kubelet versions, oldest first
+	// Skew lists kubelets outside the version skew policy: newer than the
+	// API server, or more than maxKubeletLag minors behind it.
+	Skew string `json:"skew,omitempty"`
+}
+
+// GenerateClusterInfo produces a per-cluster summary of the API server
+// version, compared with the latest patch of its minor like the kubelets in
+// the nodes table, and of the kubelet versions running against it.
+func GenerateClusterInfo(data *model.ClusterData, checker *versions.NodeChecker) model.DiagramResult {
+	if len(data.APIServers) == 0 && len(data.Nodes) == 0 {
+		return model.DiagramResult{
+			ID:      "cluster-info",
+			Title:   "Cluster Info",
+			Type:    "markdown",
+			Content: "*No cluster data available.*",
+			Empty:   true,
+		}
+	}
+
+	rowsByCluster := make(map[string]*ClusterInfoRow)
+	row := func(cluster string) *ClusterInfoRow {
+		r, ok := rowsByCluster[cluster]
+		if !ok {
+			r = &ClusterInfoRow{Cluster: cluster}
+			rowsByCluster[cluster] = r
+		}
+		return r
+	}
+	for _, a := range data.APIServers {
+		r := row(a.Cluster)
+		r.APIServer, r.Platform = a.GitVersion, a.Platform
+		if checker != nil && a.GitVersion != "" {
+			if v := checker.GetLatestKubelet(a.GitVersion); v != "" {
+				r.LatestAPIServer = v
+				r.APIServerOutdated = versions.IsOutdated(a.GitVersion, v)
+			}
+		}
+	}
+	kubelets := make(map[string]map[string]bool)
+	for _, n := range data.Nodes {
+		row(n.Cluster).Nodes++
+		if n.KubeletVersion == "" {
+			continue
+		}
+		if kubelets[n.Cluster] == nil {
+			kubelets[n.Cluster] = make(map[string]bool)
+		}
+		kubelets[n.Cluster][n.KubeletVersion] = true
+	}
+
+	var rows []ClusterInfoRow
+	for cluster, r := range rowsByCluster {
+		vs := sortedKeys(kubelets[cluster])
+		sort.SliceStable(vs, func(i, j int) bool { return versions.IsOutdated(vs[i], vs[j]) })
+		r.Kubelets = strings.Join(vs, ", ")
+
+		var skew []string
+		for _, v := range vs {
+			if s := kubeletSkew(r.APIServer, v); s != "" {
+				skew = append(skew, s)
+			}
+		}
+		r.Skew = strings.Join(skew, "; ")
+		rows = append(rows, *r)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Cluster < rows[j].Cluster })
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "cluster-info",
+		Title:   "Cluster Info",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
+
+// kubeletSkew describes how a kubelet version breaks the version skew policy
+// against apiServer, or returns "" when it doesn't or either is unknown.
+func kubeletSkew(apiServer, kubelet string) string {
+	apiMajor, apiMinor, ok := kubeMinor(apiServer)
+	if !ok {
+		return ""
+	}
+	major, minor, ok := kubeMinor(kubelet)
+	if !ok || major != apiMajor {
+		return ""
+	}
+	switch {
+	case minor > apiMinor:
+		return fmt.Sprintf("kubelet %s is newer than the API server", kubelet)
+	case apiMinor-minor > maxKubeletLag:
+		return fmt.Sprintf("kubelet %s is %d minor versions behind the API server", kubelet, apiMinor-minor)
+	}
+	return ""
+}
+
+// kubeMinor returns the major and minor numbers of a Kubernetes version such
+// as "v1.31.4+k3s1".
+func kubeMinor(v string) (major, minor int, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
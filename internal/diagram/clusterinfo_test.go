@@ -0,0 +1,45 @@
+package diagram
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestGenerateClusterInfo(t *testing.T) {
+	data := &model.ClusterData{
+		APIServers: []model.APIServerInfo{
+			{Cluster: "Homelab", GitVersion: "v1.31.2", Platform: "linux/amd64"},
+			{Cluster: "NAS", GitVersion: "v1.33.1+k3s1", Platform: "linux/arm64"},
+		},
+		Nodes: []model.NodeInfo{
+			{Name: "cp-1", Cluster: "Homelab", KubeletVersion: "v1.31.2"},
+			{Name: "worker-1", Cluster: "Homelab", KubeletVersion: "v1.32.0"},
+			{Name: "nas-1", Cluster: "NAS", KubeletVersion: "v1.29.5+k3s1"},
+			{Name: "nas-2", Cluster: "NAS", KubeletVersion: "v1.33.1+k3s1"},
+			{Name: "edge-1", Cluster: "Edge", KubeletVersion: "v1.30.0"},
+		},
+	}
+
+	var rows []ClusterInfoRow
+	if err := json.Unmarshal([]byte(GenerateClusterInfo(data, nil).Content), &rows); err != nil {
+		t.Fatalf("decoding cluster info: %v", err)
+	}
+	want := []ClusterInfoRow{
+		// Edge's API server couldn't be read: no skew to report.
+		{Cluster: "Edge", Nodes: 1, Kubelets: "v1.30.0"},
+		{Cluster: "Homelab", APIServer: "v1.31.2", Platform: "linux/amd64", Nodes: 2, Kubelets: "v1.31.2, v1.32.0",
+			Skew: "kubelet v1.32.0 is newer than the API server"},
+		{Cluster: "NAS", APIServer: "v1.33.1+k3s1", Platform: "linux/arm64", Nodes: 2, Kubelets: "v1.29.5+k3s1, v1.33.1+k3s1",
+			Skew: "kubelet v1.29.5+k3s1 is 4 minor versions behind the API server"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
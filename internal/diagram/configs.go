@@ -27,6 +27,7 @@ func GenerateConfigs(data *model.ClusterData) model.DiagramResult {
 			Title:   "ConfigMaps & Secrets",
 			Type:    "markdown",
 			Content: "*No configmap or secret data available.*",
+			Empty:   true,
 		}
 	}
 
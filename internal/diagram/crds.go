@@ -25,6 +25,7 @@ func GenerateCRDs(data *model.ClusterData) model.DiagramResult {
 			Title:   "Custom Resource Definitions",
 			Type:    "markdown",
 			Content: "*No CRD data available.*",
+			Empty:   true,
 		}
 	}
 
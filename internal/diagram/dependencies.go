@@ -351,9 +351,98 @@ func GenerateDependencies(data *model.ClusterData) model.DiagramResult {
 			Title:   "Flux Dependencies",
 			Type:    "flow",
 			Content: string(content),
+			Empty:   true,
 		}
 	}
 
+	flowData := dependencyGraph(data)
+	content, _ := json.Marshal(flowData)
+
+	return model.DiagramResult{
+		ID:      "dependencies",
+		Title:   "Flux Dependencies",
+		Type:    "flow",
+		Content: string(content),
+	}
+}
+
+// GenerateDependenciesMermaid renders the same graph as GenerateDependencies
+// as a Mermaid flowchart, for Markdown that can't embed the flow JSON: one
+// subgraph per cluster holding one per layer, with cross-cluster edges
+// dashed.
+func GenerateDependenciesMermaid(data *model.ClusterData) model.DiagramResult {
+	if len(data.Flux) == 0 {
+		return model.DiagramResult{
+			ID:      "dependencies-mermaid",
+			Title:   "Flux Dependencies (Mermaid)",
+			Type:    "mermaid",
+			Content: "graph TD\n  empty[\"No Flux Kustomizations found\"]\n",
+			Empty:   true,
+		}
+	}
+
+	graph := dependencyGraph(data)
+
+	// Group nodes by cluster, then layer; nodes are already sorted by ID.
+	byCluster := make(map[string]map[string][]FlowNode)
+	var clusters []string
+	for _, n := range graph.Nodes {
+		if byCluster[n.Cluster] == nil {
+			byCluster[n.Cluster] = make(map[string][]FlowNode)
+			clusters = append(clusters, n.Cluster)
+		}
+		byCluster[n.Cluster][n.Layer] = append(byCluster[n.Cluster][n.Layer], n)
+	}
+	sort.Strings(clusters)
+
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+	for _, cluster := range clusters {
+		clusterID := "c_" + sanitizeID(cluster)
+		fmt.Fprintf(&b, "  subgraph %s[\"%s\"]\n", clusterID, escapeLabel(cluster))
+		var layers []string
+		for layer := range byCluster[cluster] {
+			layers = append(layers, layer)
+		}
+		sort.Strings(layers)
+		for _, layer := range layers {
+			fmt.Fprintf(&b, "    subgraph %s_%s[\"%s\"]\n", clusterID, sanitizeID(layer), escapeLabel(layer))
+			for _, n := range byCluster[cluster][layer] {
+				fmt.Fprintf(&b, "      %s[\"%s\"]\n", dependencyNodeID(n.ID), escapeLabel(n.Label))
+			}
+			b.WriteString("    end\n")
+		}
+		b.WriteString("  end\n")
+	}
+
+	for _, e := range graph.Edges {
+		arrow := "-->"
+		if e.CrossCluster {
+			arrow = "-.->"
+		}
+		if e.Label != "" {
+			arrow += fmt.Sprintf("|\"%s\"|", escapeLabel(e.Label))
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", dependencyNodeID(e.Source), arrow, dependencyNodeID(e.Target))
+	}
+
+	return model.DiagramResult{
+		ID:      "dependencies-mermaid",
+		Title:   "Flux Dependencies (Mermaid)",
+		Type:    "mermaid",
+		Content: b.String(),
+	}
+}
+
+// dependencyNodeID is the Mermaid ID of a "{Cluster}/{Name}" graph node.
+func dependencyNodeID(id string) string {
+	return "k_" + sanitizeID(id)
+}
+
+// dependencyGraph builds the Kustomization dependency graph shared by the
+// flow and Mermaid renderings: nodes sorted by ID, transitively reduced
+// Flux edges, then the cross-cluster ServiceEntry and Cilium mesh edges.
+func dependencyGraph(data *model.ClusterData) FlowData {
 	// Build node ID set. IDs use {Cluster}/{Name} to disambiguate cross-cluster.
 	idSet := make(map[string]bool)
 	for _, k := range data.Flux {
@@ -428,13 +517,5 @@ func GenerateDependencies(data *model.ClusterData) model.DiagramResult {
 	ciliumEdges := discoverCiliumMeshEdges(data, idSet)
 	edges = append(edges, ciliumEdges...)
 
-	flowData := FlowData{Nodes: nodes, Edges: edges}
-	content, _ := json.Marshal(flowData)
-
-	return model.DiagramResult{
-		ID:      "dependencies",
-		Title:   "Flux Dependencies",
-		Type:    "flow",
-		Content: string(content),
-	}
+	return FlowData{Nodes: nodes, Edges: edges}
 }
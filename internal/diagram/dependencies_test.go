@@ -0,0 +1,67 @@
+package diagram
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestGenerateDependenciesMermaid(t *testing.T) {
+	data := &model.ClusterData{
+		Flux: []model.FluxKustomization{
+			{Name: "crds", Cluster: "Homelab", Path: "./kubernetes/homelab/crds"},
+			{Name: "controllers", Cluster: "Homelab", Path: "./kubernetes/homelab/infrastructure", DependsOn: []string{"crds"}},
+			// apps → crds is implied by apps → controllers → crds.
+			{Name: "vault", Cluster: "Homelab", Path: "./kubernetes/homelab/apps", DependsOn: []string{"controllers", "crds"}},
+			{Name: "platform", Cluster: "NAS", Path: "./kubernetes/nas/infrastructure"},
+			{Name: "vault-client", Cluster: "NAS", Path: "./kubernetes/nas/apps", DependsOn: []string{"platform"}},
+		},
+		ServiceEntries: []model.ServiceEntryInfo{{
+			Name: "homelab-vault", Cluster: "NAS", Location: "MESH_EXTERNAL",
+			Network: "homelab-network", Hosts: []string{"vault.homelab.mesh"},
+		}},
+	}
+
+	var flow FlowData
+	if err := json.Unmarshal([]byte(GenerateDependencies(data).Content), &flow); err != nil {
+		t.Fatalf("decoding flow graph: %v", err)
+	}
+	result := GenerateDependenciesMermaid(data)
+	if result.Type != "mermaid" || result.Empty {
+		t.Fatalf("result = %+v, want a non-empty mermaid diagram", result)
+	}
+	content := result.Content
+	if !strings.HasPrefix(content, "graph TD\n") {
+		t.Errorf("content does not start with graph TD:\n%s", content)
+	}
+
+	var cross int
+	for _, e := range flow.Edges {
+		src, dst := dependencyNodeID(e.Source), dependencyNodeID(e.Target)
+		want := src + " --> " + dst
+		if e.CrossCluster {
+			cross++
+			want = src + ` -.->|"` + e.Label + `"| ` + dst
+		}
+		if !strings.Contains(content, want+"\n") {
+			t.Errorf("edge %s missing, want %q in:\n%s", e.ID, want, content)
+		}
+	}
+	if cross != 1 {
+		t.Errorf("cross-cluster edges = %d, want 1", cross)
+	}
+	if got := strings.Count(content, "-->") + strings.Count(content, "-.->"); got != len(flow.Edges) {
+		t.Errorf("mermaid has %d edges, flow graph %d", got, len(flow.Edges))
+	}
+	if strings.Contains(content, dependencyNodeID("Homelab/crds")+" --> "+dependencyNodeID("Homelab/vault")) {
+		t.Error("transitively implied crds --> vault edge was not reduced")
+	}
+
+	for _, sub := range []string{`subgraph c_Homelab["Homelab"]`, `subgraph c_Homelab_apps["apps"]`, `subgraph c_NAS_infrastructure["infrastructure"]`} {
+		if !strings.Contains(content, sub) {
+			t.Errorf("missing %s in:\n%s", sub, content)
+		}
+	}
+}
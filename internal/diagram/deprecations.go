@@ -0,0 +1,93 @@
+package diagram
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+// DeprecatedAPIRow represents an object on a deprecated API version.
+type DeprecatedAPIRow struct {
+	Cluster        string `json:"cluster"`
+	ClusterVersion string `json:"clusterVersion"`
+	Namespace      string `json:"namespace"`
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	APIVersion     string `json:"apiVersion"`
+	Replacement    string `json:"replacement"`
+	DeprecatedIn   string `json:"deprecatedIn"`
+	RemovedIn      string `json:"removedIn"`
+}
+
+// GenerateDeprecatedAPIs produces a table of objects using an API version
+// deprecated as of their cluster's Kubernetes version: the API server's, or
+// else the newest kubelet's. Clusters without either list every deprecated
+// API found.
+func GenerateDeprecatedAPIs(data *model.ClusterData) model.DiagramResult {
+	clusterVersion := make(map[string]string)
+	for _, n := range data.Nodes {
+		if cur := clusterVersion[n.Cluster]; cur == "" || versions.IsOutdated(cur, n.KubeletVersion) {
+			clusterVersion[n.Cluster] = n.KubeletVersion
+		}
+	}
+	for _, a := range data.APIServers {
+		if a.GitVersion != "" {
+			clusterVersion[a.Cluster] = a.GitVersion
+		}
+	}
+
+	var rows []DeprecatedAPIRow
+	for _, u := range data.DeprecatedAPIs {
+		cv := clusterVersion[u.Cluster]
+		if cv != "" && versions.IsOutdated(cv, u.DeprecatedIn) {
+			continue // not deprecated yet on this cluster
+		}
+		rows = append(rows, DeprecatedAPIRow{
+			Cluster:        u.Cluster,
+			ClusterVersion: cv,
+			Namespace:      u.Namespace,
+			Kind:           u.Kind,
+			Name:           u.Name,
+			APIVersion:     u.APIVersion,
+			Replacement:    u.Replacement,
+			DeprecatedIn:   u.DeprecatedIn,
+			RemovedIn:      u.RemovedIn,
+		})
+	}
+
+	if len(rows) == 0 {
+		return model.DiagramResult{
+			ID:      "deprecated-apis",
+			Title:   "Deprecated APIs",
+			Type:    "markdown",
+			Content: "*No objects use a deprecated API version.*",
+			Empty:   true,
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Cluster != rows[j].Cluster {
+			return rows[i].Cluster < rows[j].Cluster
+		}
+		if rows[i].RemovedIn != rows[j].RemovedIn {
+			return versions.IsOutdated(rows[i].RemovedIn, rows[j].RemovedIn)
+		}
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		if rows[i].Kind != rows[j].Kind {
+			return rows[i].Kind < rows[j].Kind
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "deprecated-apis",
+		Title:   "Deprecated APIs",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
@@ -0,0 +1,74 @@
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// DefaultEventsWindow is how far back the events feed reaches unless a
+// request asks for another window.
+const DefaultEventsWindow = time.Hour
+
+// EventRow represents a single row in the Warning events table.
+type EventRow struct {
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Object    string    `json:"object"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// GenerateEvents produces a table of the Warning events seen within since of
+// now, newest first.
+func GenerateEvents(data *model.ClusterData, since time.Duration, now time.Time) model.DiagramResult {
+	cutoff := now.Add(-since)
+	var rows []EventRow
+	for _, e := range data.Events {
+		if e.LastSeen.Before(cutoff) {
+			continue
+		}
+		rows = append(rows, EventRow{
+			Cluster:   e.Cluster,
+			Namespace: e.Namespace,
+			Object:    e.Object,
+			Reason:    e.Reason,
+			Message:   e.Message,
+			Count:     e.Count,
+			LastSeen:  e.LastSeen,
+		})
+	}
+
+	if len(rows) == 0 {
+		return model.DiagramResult{
+			ID:      "events",
+			Title:   "Warning Events",
+			Type:    "markdown",
+			Content: fmt.Sprintf("*No Warning events in the last %s.*", since),
+			Empty:   true,
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].LastSeen.Equal(rows[j].LastSeen) {
+			return rows[i].LastSeen.After(rows[j].LastSeen)
+		}
+		if rows[i].Cluster != rows[j].Cluster {
+			return rows[i].Cluster < rows[j].Cluster
+		}
+		return rows[i].Object < rows[j].Object
+	})
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "events",
+		Title:   "Warning Events",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
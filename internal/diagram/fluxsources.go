@@ -0,0 +1,99 @@
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// FluxSourceRow represents a single row in the Flux sources table.
+type FluxSourceRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	Kind      string `json:"kind"`
+	URL       string `json:"url"`
+	Ref       string `json:"ref"`
+	Revision  string `json:"revision"`
+	Ready     string `json:"ready"`
+	Message   string `json:"message"`
+	LastSync  string `json:"lastSync"`
+	SyncAge   string `json:"syncAge"` // e.g. "5m", "3h", "2d"; "-" if unknown
+}
+
+// GenerateFluxSources produces a table of Flux sources and their sync status.
+func GenerateFluxSources(data *model.ClusterData) model.DiagramResult {
+	if len(data.FluxSources) == 0 {
+		return model.DiagramResult{
+			ID:      "flux-sources",
+			Title:   "Flux Sources",
+			Type:    "markdown",
+			Content: "*No Flux source data available.*",
+			Empty:   true,
+		}
+	}
+
+	now := time.Now()
+	var rows []FluxSourceRow
+	for _, src := range data.FluxSources {
+		age := "-"
+		if t, err := time.Parse(time.RFC3339, src.LastSync); err == nil {
+			age = formatAge(now.Sub(t))
+		}
+
+		rows = append(rows, FluxSourceRow{
+			Name:      src.Name,
+			Namespace: src.Namespace,
+			Cluster:   src.Cluster,
+			Kind:      src.Kind,
+			URL:       src.URL,
+			Ref:       src.Ref,
+			Revision:  src.Revision,
+			Ready:     boolIcon(src.Ready),
+			Message:   src.Message,
+			LastSync:  src.LastSync,
+			SyncAge:   age,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Cluster != rows[j].Cluster {
+			return rows[i].Cluster < rows[j].Cluster
+		}
+		if rows[i].Kind != rows[j].Kind {
+			return rows[i].Kind < rows[j].Kind
+		}
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "flux-sources",
+		Title:   "Flux Sources",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
+
+// formatAge renders a duration in the coarse kubectl style: "45s", "5m", "3h", "2d".
+func formatAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
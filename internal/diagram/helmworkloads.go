@@ -27,6 +27,7 @@ func GenerateHelmWorkloads(data *model.ClusterData) model.DiagramResult {
 			Title:   "Helm to Workloads",
 			Type:    "markdown",
 			Content: "*No Helm release or workload data available for correlation.*",
+			Empty:   true,
 		}
 	}
 
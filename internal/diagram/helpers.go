@@ -1,6 +1,9 @@
 package diagram
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+)
 
 var nonAlphaNum = regexp.MustCompile(`[^a-zA-Z0-9]`)
 
@@ -8,3 +11,34 @@ var nonAlphaNum = regexp.MustCompile(`[^a-zA-Z0-9]`)
 func sanitizeID(name string) string {
 	return nonAlphaNum.ReplaceAllString(name, "_")
 }
+
+// nodeLabel builds a Mermaid node label: the name on the first line, then one
+// line per detail. The name is kept whole; details are cut to
+// o.MaxLabelLength.
+func (o Options) nodeLabel(name string, details ...string) string {
+	lines := make([]string, 0, 1+len(details))
+	lines = append(lines, escapeLabel(name))
+	for _, d := range details {
+		lines = append(lines, escapeLabel(truncateMiddle(d, o.MaxLabelLength)))
+	}
+	return strings.Join(lines, "<br/>")
+}
+
+// truncateMiddle shortens s to max runes by replacing its middle with "…".
+func truncateMiddle(s string, max int) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+	if max == 1 {
+		return "…"
+	}
+	head := max / 2
+	tail := max - 1 - head
+	return string(r[:head]) + "…" + string(r[len(r)-tail:])
+}
+
+// escapeLabel makes s safe inside a quoted Mermaid label.
+func escapeLabel(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
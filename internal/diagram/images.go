@@ -2,9 +2,11 @@ package diagram
 
 import (
 	"encoding/json"
+	"path"
 	"sort"
 	"strings"
 
+	"github.com/fredericrous/cluster-vision/internal/imageref"
 	"github.com/fredericrous/cluster-vision/internal/model"
 	"github.com/fredericrous/cluster-vision/internal/versions"
 )
@@ -13,10 +15,15 @@ import (
 type ImageRow struct {
 	Image      string `json:"image"`      // registry/repo (without tag)
 	Tag        string `json:"tag"`        // tag or digest
-	Type       string `json:"type"`       // "app" | "init"
+	Type       string `json:"type"`       // "app" | "init" | "ephemeral"
 	Namespaces string `json:"namespaces"` // comma-separated unique namespaces
+	Owners     string `json:"owners"`     // comma-separated top-level workloads ("Kind/name"); bare pods omitted
 	Pods       int    `json:"pods"`       // count of pods using this image:tag
 	Registry   string `json:"registry"`   // extracted registry hostname
+	// RegistryName is Registry as shown: its Options.RegistryAliases entry, or the
+	// host with registry-1.docker.io folded back to docker.io.
+	RegistryName string `json:"registryName"`
+	State      string `json:"state"`      // comma-separated unique pod phases
 	Latest       string `json:"latest"`        // latest tag with same variant pattern
 	Outdated     bool   `json:"outdated"`      // true if latest != current tag
 	SecurityRisk string `json:"securityRisk"`  // "critical" | "warning" | "none" | ""
@@ -26,29 +33,88 @@ type ImageRow struct {
 	ExploitRisk    string `json:"exploitRisk"`    // "kev" | "high-epss" | "low-epss" | "none" | ""
 	ExploitSummary string `json:"exploitSummary"` // e.g. "1 KEV (CVE-2024-12345)" or "EPSS 0.87 (CVE-…)"
 	KEVCVEs        string `json:"kevCVEs"`        // comma-separated for tooltip
+	// Scanner counts from the optional Trivy/Grype integration; nil, and
+	// omitted, when no scanner is configured or the image has not been
+	// scanned.
+	CriticalVulns *int `json:"criticalVulns,omitempty"`
+	HighVulns     *int `json:"highVulns,omitempty"`
+	// Inconsistent marks a mutable tag that moved mid-rollout: pods on this
+	// image:tag in one namespace run different digests. Digests counts the
+	// distinct digests across all namespaces, which may differ without
+	// being inconsistent when each was rolled out at a different time.
+	Inconsistent bool `json:"inconsistent"`
+	Digests      int  `json:"digests"`
+	// PulledVia lists the registry proxies pods actually pulled this image
+	// through; Image and Registry name the upstream.
+	PulledVia string `json:"pulledVia,omitempty"`
+	// Version is the cluster-vision.io/version pod annotation, shown and
+	// compared with Latest instead of an uninformative Tag.
+	Version string `json:"version,omitempty"`
+	// LatestStatus qualifies Latest: "unsupported" explains a "-" when the
+	// registry doesn't list tags; "no-tags" and "only-tag" mark a Latest
+	// equal to Tag because the repo has nothing else to offer.
+	LatestStatus string `json:"latestStatus,omitempty"`
+	// PolicyMinimum is the minimum version Options.VersionPolicy requires for the
+	// image; PolicyViolation marks a Version, or else Tag, below it.
+	PolicyMinimum   string `json:"policyMinimum,omitempty"`
+	PolicyViolation bool   `json:"policyViolation,omitempty"`
+}
+
+// registryDisplayName returns the name shown for a registry host: its alias,
+// else the host itself with Docker Hub's API host folded back to docker.io.
+// Exact aliases win over patterns, and patterns are tried in sorted order.
+func registryDisplayName(host string, aliases map[string]string) string {
+	if host == "registry-1.docker.io" || host == "index.docker.io" {
+		host = "docker.io"
+	}
+	if name, ok := aliases[host]; ok {
+		return name
+	}
+	patterns := make([]string, 0, len(aliases))
+	for p := range aliases {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, host); ok {
+			return aliases[p]
+		}
+	}
+	return host
 }
 
 // imageKey uniquely identifies an image ref + container type.
 type imageKey struct {
 	image    string // registry/repo (no tag)
 	tag      string
-	initContainer bool
+	typ      string // model.PodImageInfo.ContainerType
 }
 
 type imageAgg struct {
 	namespaces map[string]bool
-	pods       map[string]bool // namespace/podName for dedup
+	owners     map[string]bool            // model.PodImageInfo.Owner
+	pods       map[string]bool            // namespace/podName for dedup
+	states     map[string]bool            // pod phases
+	digests    map[string]map[string]bool // "cluster/namespace" → resolved image digests
+	refs       map[string]bool            // registry/repo as pulled, for checker/scanner lookups
+	via        map[string]bool            // proxy hosts pulled through
+	versions   map[string]bool            // annotated versions (model.PodImageInfo.Version)
 	registry   string
 }
 
-// GenerateImages produces a table of container images running across the cluster.
-func GenerateImages(data *model.ClusterData, checker *versions.ImageChecker) model.DiagramResult {
+// GenerateImages produces a table of container images running across the
+// cluster, limited to the checker's namespace scope. When scanner is
+// non-nil, rows carry its vulnerability counts and the worst images are
+// listed first. Images pulled through registryProxy are listed under their
+// upstream registry.
+func GenerateImages(data *model.ClusterData, checker *versions.ImageChecker, scanner *versions.VulnScanner, registryProxy string, opts Options) model.DiagramResult {
 	if len(data.Pods) == 0 {
 		return model.DiagramResult{
 			ID:      "images",
 			Title:   "Container Images",
 			Type:    "markdown",
 			Content: "*No pod data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -61,40 +127,100 @@ func GenerateImages(data *model.ClusterData, checker *versions.ImageChecker) mod
 	agg := make(map[imageKey]*imageAgg)
 
 	for _, p := range data.Pods {
-		registry, repo, tag := parseImageRef(p.Image)
+		if !checker.InScope(p.Namespace) {
+			continue
+		}
+		pulledRegistry, pulledRepo, tag := imageref.Parse(p.Image)
+		pulled := pulledRegistry + "/" + pulledRepo
+		registry, repo := versions.ResolveUpstream(registryProxy, pulled)
 		image := registry + "/" + repo
 
-		key := imageKey{image: image, tag: tag, initContainer: p.InitContainer}
+		key := imageKey{image: image, tag: tag, typ: p.ContainerType()}
 
 		a, ok := agg[key]
 		if !ok {
 			a = &imageAgg{
 				namespaces: make(map[string]bool),
+				owners:     make(map[string]bool),
 				pods:       make(map[string]bool),
+				states:     make(map[string]bool),
+				digests:    make(map[string]map[string]bool),
+				refs:       make(map[string]bool),
+				via:        make(map[string]bool),
+				versions:   make(map[string]bool),
 				registry:   registry,
 			}
 			agg[key] = a
 		}
+		a.refs[pulled] = true
+		if pulledRegistry != registry {
+			a.via[pulledRegistry] = true
+		}
+		if p.Version != "" {
+			a.versions[p.Version] = true
+		}
 		a.namespaces[p.Namespace] = true
+		if p.Owner != "" {
+			a.owners[p.Owner] = true
+		}
 		a.pods[p.Namespace+"/"+p.PodName] = true
+		if p.State != "" {
+			a.states[p.State] = true
+		}
+		if d := imageref.Digest(p.ImageID); d != "" {
+			scope := p.Cluster + "/" + p.Namespace
+			if a.digests[scope] == nil {
+				a.digests[scope] = make(map[string]bool)
+			}
+			a.digests[scope][d] = true
+		}
 	}
 
 	var rows []ImageRow
 	for key, a := range agg {
 		ns := sortedKeys(a.namespaces)
 
-		typ := "app"
-		if key.initContainer {
-			typ = "init"
+		// A tag resolving to several digests within one namespace moved
+		// mid-rollout; across namespaces it just wasn't pulled at once.
+		inconsistent := false
+		digests := make(map[string]bool)
+		for _, ds := range a.digests {
+			inconsistent = inconsistent || len(ds) > 1
+			for d := range ds {
+				digests[d] = true
+			}
 		}
 
+		// Checker and scanner results are keyed by the image as pulled.
+		refs := sortedKeys(a.refs)
+
+		// An annotated version stands in for the tag, unless pods on this
+		// tag disagree about it.
+		version := ""
+		if len(a.versions) == 1 {
+			version = sortedKeys(a.versions)[0]
+		}
+		current := key.tag
+		if version != "" {
+			current = version
+		}
+
+		policyMin := opts.VersionPolicy.ImageMinimum(key.image)
+
 		latest := "-"
 		outdated := false
+		latestStatus := ""
 		if checker != nil {
-			if v := checker.GetLatest(key.image, key.tag); v != "" {
-				latest = v
-				if latest != "-" && latest != key.tag {
-					outdated = true
+			for _, ref := range refs {
+				if v := checker.GetLatest(ref, current); v != "" {
+					latest = v
+					outdated = latest != "-" && versions.IsOutdated(current, latest) && checker.Settled(ref, current)
+					if latest == "-" && checker.ListingUnsupported(ref) {
+						latestStatus = versions.TagListUnsupported
+					} else if st := checker.TagListStatus(ref); latest == current && (st == versions.TagListEmpty || st == versions.TagListOnlyTag) {
+						latestStatus = st
+					}
+					break
 				}
 			}
 		}
@@ -105,31 +231,63 @@ func GenerateImages(data *model.ClusterData, checker *versions.ImageChecker) mod
 		exploitRisk := ""
 		exploitSum := ""
 		kevList := ""
-		imageRef := key.image + ":" + key.tag
-		if v, ok := vulnByImage[imageRef]; ok {
-			secRisk, vulnSum = vulnRisk(v)
-			exploitRisk, exploitSum = vulnExploitRisk(v)
-			kevList = strings.Join(v.KEVCVEs, ",")
+		for _, ref := range refs {
+			if v, ok := vulnByImage[ref+":"+key.tag]; ok {
+				secRisk, vulnSum = vulnRisk(v)
+				exploitRisk, exploitSum = vulnExploitRisk(v)
+				kevList = strings.Join(v.KEVCVEs, ",")
+				break
+			}
+		}
+
+		var critical, high *int
+		if scanner != nil {
+			for _, ref := range refs {
+				if c, ok := scanner.Get(ref + ":" + key.tag); ok {
+					critical, high = &c.Critical, &c.High
+					break
+				}
+			}
 		}
 
 		rows = append(rows, ImageRow{
 			Image:          key.image,
 			Tag:            key.tag,
-			Type:           typ,
+			Type:           key.typ,
 			Namespaces:     strings.Join(ns, ", "),
+			Owners:         strings.Join(sortedKeys(a.owners), ", "),
 			Pods:           len(a.pods),
 			Registry:       a.registry,
+			RegistryName:   registryDisplayName(a.registry, opts.RegistryAliases),
+			State:          strings.Join(sortedKeys(a.states), ", "),
 			Latest:         latest,
+			LatestStatus:   latestStatus,
 			Outdated:       outdated,
 			SecurityRisk:   secRisk,
 			VulnSummary:    vulnSum,
 			ExploitRisk:    exploitRisk,
 			ExploitSummary: exploitSum,
 			KEVCVEs:        kevList,
+			CriticalVulns:  critical,
+			HighVulns:      high,
+			Inconsistent:   inconsistent,
+			Digests:        len(digests),
+			PulledVia:      strings.Join(sortedKeys(a.via), ", "),
+			Version:        version,
+			PolicyMinimum:   policyMin,
+			PolicyViolation: versions.BelowMinimum(current, policyMin),
 		})
 	}
 
 	sort.Slice(rows, func(i, j int) bool {
+		if scanner != nil {
+			if ci, cj := vulnCount(rows[i].CriticalVulns), vulnCount(rows[j].CriticalVulns); ci != cj {
+				return ci > cj
+			}
+			if hi, hj := vulnCount(rows[i].HighVulns), vulnCount(rows[j].HighVulns); hi != hj {
+				return hi > hj
+			}
+		}
 		if rows[i].Registry != rows[j].Registry {
 			return rows[i].Registry < rows[j].Registry
 		}
@@ -152,52 +310,6 @@ func GenerateImages(data *model.ClusterData, checker *versions.ImageChecker) mod
 	}
 }
 
-// parseImageRef splits a container image reference into registry, repo, and tag.
-// Examples:
-//
-//	"ghcr.io/foo/bar:v1.2" → "ghcr.io", "foo/bar", "v1.2"
-//	"nginx:latest"         → "docker.io", "library/nginx", "latest"
-//	"nginx"                → "docker.io", "library/nginx", "latest"
-//	"myregistry:5000/app:v1" → "myregistry:5000", "app", "v1"
-func parseImageRef(ref string) (registry, repo, tag string) {
-	// Handle @sha256: digest references
-	if idx := strings.Index(ref, "@"); idx != -1 {
-		tag = ref[idx+1:]
-		ref = ref[:idx]
-	}
-
-	// Split off tag
-	if tag == "" {
-		if idx := strings.LastIndex(ref, ":"); idx != -1 {
-			// Make sure the colon is after the last slash (not a port in registry)
-			slashIdx := strings.LastIndex(ref, "/")
-			if idx > slashIdx {
-				tag = ref[idx+1:]
-				ref = ref[:idx]
-			}
-		}
-		if tag == "" {
-			tag = "latest"
-		}
-	}
-
-	// Determine registry vs repo
-	parts := strings.SplitN(ref, "/", 2)
-	if len(parts) == 1 {
-		// No slash — Docker Hub official image
-		return "docker.io", "library/" + parts[0], tag
-	}
-
-	// Check if first part looks like a registry (has dot or colon, or is "localhost")
-	first := parts[0]
-	if strings.Contains(first, ".") || strings.Contains(first, ":") || first == "localhost" {
-		return first, parts[1], tag
-	}
-
-	// No registry indicator — Docker Hub user image
-	return "docker.io", ref, tag
-}
-
 func sortedKeys(m map[string]bool) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -206,3 +318,12 @@ func sortedKeys(m map[string]bool) []string {
 	sort.Strings(keys)
 	return keys
 }
+
+// vulnCount returns a scanner count for sorting, with unscanned images
+// (nil) after every scanned one.
+func vulnCount(n *int) int {
+	if n == nil {
+		return -1
+	}
+	return *n
+}
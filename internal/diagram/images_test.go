@@ -0,0 +1,323 @@
+package diagram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestGenerateImagesVulnCounts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Image string `json:"image"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/scan" || req.Image != "ghcr.io/acme/api@sha256:bbb" {
+			_, _ = w.Write([]byte(`{"ArtifactName":"` + req.Image + `","Results":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"ArtifactName": "ghcr.io/acme/api:1.4.0",
+			"Results": [
+				{"Vulnerabilities": [
+					{"VulnerabilityID": "CVE-2024-0001", "Severity": "CRITICAL"},
+					{"VulnerabilityID": "CVE-2024-0002", "Severity": "HIGH"},
+					{"VulnerabilityID": "CVE-2024-0003", "Severity": "HIGH"},
+					{"VulnerabilityID": "CVE-2024-0004", "Severity": "LOW"}
+				]},
+				{"Vulnerabilities": [
+					{"VulnerabilityID": "CVE-2024-0002", "Severity": "HIGH"}
+				]}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Namespace: "apps", PodName: "web-1", Image: "nginx:1.27", ImageID: "docker.io/library/nginx@sha256:aaa"},
+		{Namespace: "apps", PodName: "api-1", Image: "ghcr.io/acme/api:1.4.0", ImageID: "ghcr.io/acme/api@sha256:bbb"},
+		{Namespace: "apps", PodName: "api-2", Image: "ghcr.io/acme/api:1.4.0", ImageID: "ghcr.io/acme/api@sha256:bbb"},
+	}}
+
+	scanner := versions.NewVulnScanner(srv.URL, "")
+	scanner.Check(data.Pods)
+
+	got := GenerateImages(data, nil, scanner, "", DefaultOptions())
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(got.Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	// Worst image first.
+	if rows[0].Image != "ghcr.io/acme/api" || vulnCount(rows[0].CriticalVulns) != 1 || vulnCount(rows[0].HighVulns) != 2 {
+		t.Errorf("rows[0] = {%s critical:%d high:%d}, want {ghcr.io/acme/api critical:1 high:2}",
+			rows[0].Image, vulnCount(rows[0].CriticalVulns), vulnCount(rows[0].HighVulns))
+	}
+	if vulnCount(rows[1].CriticalVulns) != 0 || vulnCount(rows[1].HighVulns) != 0 {
+		t.Errorf("nginx counts = %d/%d, want 0/0", vulnCount(rows[1].CriticalVulns), vulnCount(rows[1].HighVulns))
+	}
+}
+
+func TestGenerateImagesScannerUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // nothing listening
+
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Namespace: "apps", PodName: "web-1", Image: "nginx:1.27"},
+	}}
+	scanner := versions.NewVulnScanner(srv.URL, "")
+	scanner.Check(data.Pods)
+
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(GenerateImages(data, nil, scanner, "", DefaultOptions()).Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+	if len(rows) != 1 || rows[0].CriticalVulns != nil || rows[0].HighVulns != nil {
+		t.Errorf("rows = %+v, want one unscanned row (no counts)", rows)
+	}
+}
+
+func TestGenerateImagesNoScannerOmitsCounts(t *testing.T) {
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Namespace: "apps", PodName: "web-1", Image: "nginx:1.27"},
+	}}
+	got := GenerateImages(data, nil, nil, "", DefaultOptions())
+	if strings.Contains(got.Content, "Vulns") {
+		t.Errorf("content = %s, want no vulnerability counts without a scanner", got.Content)
+	}
+}
+
+func TestGenerateImagesNamespaceScope(t *testing.T) {
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Cluster: "Homelab", Namespace: "media", PodName: "jellyfin-0", Image: "jellyfin/jellyfin:10.9.0"},
+		{Cluster: "Homelab", Namespace: "team-a", PodName: "api-0", Image: "ghcr.io/acme/api:1.0.0"},
+		{Cluster: "Homelab", Namespace: "kube-system", PodName: "coredns-0", Image: "coredns/coredns:1.11.1"},
+	}}
+
+	result := GenerateImages(data, versions.NewImageChecker("", []string{"media", "team-*"}), nil, "", DefaultOptions())
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(result.Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, r := range rows {
+		got[r.Namespaces] = true
+	}
+	if len(rows) != 2 || !got["media"] || !got["team-a"] {
+		t.Errorf("rows cover namespaces %v, want only media and team-a", got)
+	}
+}
+
+func TestGenerateImagesEphemeralType(t *testing.T) {
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Namespace: "apps", PodName: "web-1", Container: "web", Image: "busybox:1.36"},
+		{Namespace: "apps", PodName: "web-1", Container: "debugger-x7k2", Image: "busybox:1.36", Ephemeral: true},
+	}}
+
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(GenerateImages(data, nil, nil, "", DefaultOptions()).Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+	types := make(map[string]bool)
+	for _, r := range rows {
+		types[r.Type] = true
+	}
+	if len(rows) != 2 || !types["app"] || !types["ephemeral"] {
+		t.Errorf("rows = %+v, want busybox once as app and once as ephemeral", rows)
+	}
+}
+
+func TestGenerateImagesDigestInconsistency(t *testing.T) {
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Namespace: "apps", PodName: "app-1", Image: "ghcr.io/acme/app:v1", ImageID: "ghcr.io/acme/app@sha256:aaa"},
+		{Namespace: "apps", PodName: "app-2", Image: "ghcr.io/acme/app:v1", ImageID: "ghcr.io/acme/app@sha256:bbb"},
+		{Namespace: "apps", PodName: "app-3", Image: "ghcr.io/acme/app:v1", ImageID: "ghcr.io/acme/app@sha256:aaa"},
+		{Namespace: "apps", PodName: "web-1", Image: "nginx:1.27", ImageID: "docker.io/library/nginx@sha256:ccc"},
+		{Namespace: "apps", PodName: "web-2", Image: "nginx:1.27", ImageID: "docker-pullable://nginx@sha256:ccc"},
+		{Namespace: "apps", PodName: "web-3", Image: "nginx:1.27"}, // still pulling: no ImageID yet
+		// One digest per namespace: each pulled the tag at a different time,
+		// but neither is mid-rollout.
+		{Namespace: "staging", PodName: "api-1", Image: "ghcr.io/acme/api:main", ImageID: "ghcr.io/acme/api@sha256:ddd"},
+		{Namespace: "staging", PodName: "api-2", Image: "ghcr.io/acme/api:main", ImageID: "ghcr.io/acme/api@sha256:ddd"},
+		{Namespace: "prod", PodName: "api-1", Image: "ghcr.io/acme/api:main", ImageID: "ghcr.io/acme/api@sha256:eee"},
+	}}
+
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(GenerateImages(data, nil, nil, "", DefaultOptions()).Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+
+	want := map[string]struct {
+		inconsistent bool
+		digests      int
+	}{
+		"ghcr.io/acme/app":        {true, 2},
+		"docker.io/library/nginx": {false, 1},
+		"ghcr.io/acme/api":        {false, 2},
+	}
+	for _, r := range rows {
+		w, ok := want[r.Image]
+		if !ok {
+			t.Errorf("unexpected row %s:%s", r.Image, r.Tag)
+			continue
+		}
+		if r.Inconsistent != w.inconsistent || r.Digests != w.digests {
+			t.Errorf("%s:%s inconsistent = %v, digests = %d, want %v, %d", r.Image, r.Tag, r.Inconsistent, r.Digests, w.inconsistent, w.digests)
+		}
+	}
+}
+
+func TestGenerateImagesRegistryProxy(t *testing.T) {
+	const proxy = "192.168.1.43:5000"
+	data := &model.ClusterData{
+		Pods: []model.PodImageInfo{
+			{Cluster: "Homelab", Namespace: "apps", PodName: "app-1", Image: proxy + "/ghcr.io/acme/app:v1"},
+			{Cluster: "Cloud", Namespace: "apps", PodName: "app-2", Image: "ghcr.io/acme/app:v1"},
+		},
+		ImageVulns: []model.ImageVuln{{Image: proxy + "/ghcr.io/acme/app:v1", Critical: 1}},
+	}
+
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(GenerateImages(data, nil, nil, proxy, DefaultOptions()).Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want the proxied and direct pulls grouped into 1: %+v", len(rows), rows)
+	}
+	r := rows[0]
+	if r.Image != "ghcr.io/acme/app" || r.Registry != "ghcr.io" {
+		t.Errorf("image = %q, registry = %q, want ghcr.io/acme/app under ghcr.io", r.Image, r.Registry)
+	}
+	if r.Pods != 2 {
+		t.Errorf("pods = %d, want 2", r.Pods)
+	}
+	if r.PulledVia != proxy {
+		t.Errorf("pulledVia = %q, want %q", r.PulledVia, proxy)
+	}
+	if r.SecurityRisk != "critical" {
+		t.Errorf("securityRisk = %q, want the proxied ref's report (critical)", r.SecurityRisk)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestGenerateImagesVersionAnnotation(t *testing.T) {
+	var paths []string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`{"tag_name":"v2024.4.0"}`))
+	}))
+	defer github.Close()
+
+	// The checker's client uses the default transport; send GitHub API
+	// calls to the test server.
+	orig := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "api.github.com" {
+			r = r.Clone(r.Context())
+			r.URL.Scheme, r.URL.Host = "http", strings.TrimPrefix(github.URL, "http://")
+		}
+		return orig.RoundTrip(r)
+	})
+	defer func() { http.DefaultTransport = orig }()
+
+	data := &model.ClusterData{Pods: []model.PodImageInfo{{
+		Cluster: "Homelab", Namespace: "apps", PodName: "api-1",
+		Image:         "registry.acme.dev/monorepo/api:3f2c1e9a7b",
+		Version:       "2024.3.1",
+		VersionSource: "github.com/acme/monorepo",
+	}}}
+	checker := versions.NewImageChecker("", nil)
+	checker.Check(data.Pods)
+
+	result := GenerateImages(data, checker, nil, "", DefaultOptions())
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(result.Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	r := rows[0]
+	if r.Tag != "3f2c1e9a7b" || r.Version != "2024.3.1" {
+		t.Errorf("tag, version = %q, %q; want the SHA tag and the annotated version", r.Tag, r.Version)
+	}
+	if r.Latest != "v2024.4.0" || !r.Outdated {
+		t.Errorf("latest, outdated = %q, %v; want v2024.4.0 from the release source, outdated", r.Latest, r.Outdated)
+	}
+	if len(paths) != 1 || paths[0] != "/repos/acme/monorepo/releases/latest" {
+		t.Errorf("GitHub requests = %v, want the monorepo's latest release", paths)
+	}
+}
+
+func TestGenerateImagesPolicyViolation(t *testing.T) {
+	opts := DefaultOptions()
+	opts.VersionPolicy = &versions.Policy{Images: map[string]string{"nginx": "1.25"}}
+
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Cluster: "Homelab", Namespace: "apps", PodName: "old", Image: "nginx:1.24"},
+		{Cluster: "Homelab", Namespace: "apps", PodName: "new", Image: "nginx:1.26-alpine"},
+	}}
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(GenerateImages(data, nil, nil, "", opts).Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+	byTag := make(map[string]ImageRow)
+	for _, r := range rows {
+		byTag[r.Tag] = r
+	}
+	if r := byTag["1.24"]; !r.PolicyViolation || r.PolicyMinimum != "1.25" {
+		t.Errorf("nginx:1.24 against >=1.25: violation=%v minimum=%q, want a violation", r.PolicyViolation, r.PolicyMinimum)
+	}
+	if r := byTag["1.26-alpine"]; r.PolicyViolation {
+		t.Error("nginx:1.26-alpine against >=1.25 flagged as a violation")
+	}
+}
+
+func TestGenerateImagesRegistryAliases(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RegistryAliases = map[string]string{"docker.io": "Docker Hub", "*.dkr.ecr.*.amazonaws.com": "ECR"}
+
+	data := &model.ClusterData{Pods: []model.PodImageInfo{
+		{Namespace: "apps", PodName: "web", Image: "registry-1.docker.io/library/nginx:1.27"},
+		{Namespace: "apps", PodName: "api", Image: "123456.dkr.ecr.us-east-1.amazonaws.com/api:v2"},
+		{Namespace: "apps", PodName: "worker", Image: "123456.dkr.ecr.us-east-1.amazonaws.com/worker:v2"},
+		{Namespace: "apps", PodName: "cli", Image: "ghcr.io/acme/cli:v1"},
+	}}
+	var rows []ImageRow
+	if err := json.Unmarshal([]byte(GenerateImages(data, nil, nil, "", opts).Content), &rows); err != nil {
+		t.Fatalf("decoding images table: %v", err)
+	}
+
+	want := map[string][2]string{ // image → registry, registry name
+		"registry-1.docker.io/library/nginx":            {"registry-1.docker.io", "Docker Hub"},
+		"123456.dkr.ecr.us-east-1.amazonaws.com/api":    {"123456.dkr.ecr.us-east-1.amazonaws.com", "ECR"},
+		"123456.dkr.ecr.us-east-1.amazonaws.com/worker": {"123456.dkr.ecr.us-east-1.amazonaws.com", "ECR"},
+		"ghcr.io/acme/cli":                              {"ghcr.io", "ghcr.io"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i, r := range rows {
+		w, ok := want[r.Image]
+		if !ok || r.Registry != w[0] || r.RegistryName != w[1] {
+			t.Errorf("%s: registry=%q name=%q, want %q shown as %q", r.Image, r.Registry, r.RegistryName, w[0], w[1])
+		}
+		// Rows stay sorted by the real host, not the alias.
+		if i > 0 && rows[i-1].Registry > r.Registry {
+			t.Errorf("rows not grouped by registry host: %q before %q", rows[i-1].Registry, r.Registry)
+		}
+	}
+}
@@ -58,6 +58,7 @@ func GenerateLabels(data *model.ClusterData) model.DiagramResult {
 			Title:   "Labels & Annotations",
 			Type:    "markdown",
 			Content: "*No label data available.*",
+			Empty:   true,
 		}
 	}
 
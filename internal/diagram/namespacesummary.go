@@ -76,6 +76,7 @@ func GenerateNamespaceSummary(data *model.ClusterData) model.DiagramResult {
 			Title:   "Namespace Summary",
 			Type:    "markdown",
 			Content: "*No namespace data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -2,6 +2,7 @@ package diagram
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
@@ -11,18 +12,23 @@ import (
 func GenerateNetwork(data *model.ClusterData) model.DiagramResult {
 	var b strings.Builder
 
-	if len(data.Gateways) == 0 && len(data.HTTPRoutes) == 0 {
+	if len(data.Gateways) == 0 && len(data.HTTPRoutes) == 0 && len(data.IngressRoutes) == 0 && len(data.Ingresses) == 0 {
 		return model.DiagramResult{
 			ID:      "network",
 			Title:   "Network & Ingress",
 			Type:    "mermaid",
-			Content: "graph LR\n  empty[\"No Gateway or HTTPRoute resources found\"]\n",
+			Content: "graph LR\n  empty[\"No Gateway, HTTPRoute, IngressRoute or Ingress resources found\"]\n",
+			Empty:   true,
 		}
 	}
 
 	fmt.Fprint(&b, "graph LR\n")
 	fmt.Fprint(&b, "  internet((\"Internet\"))\n")
 
+	// Route node IDs drawn so far, so cross-namespace edges only start at
+	// declared nodes.
+	drawnRoutes := make(map[string]bool)
+
 	// One subgraph per gateway (skip mesh-internal waypoints)
 	for gi, gw := range data.Gateways {
 		if gw.GatewayClassName == "istio-waypoint" {
@@ -36,25 +42,14 @@ func GenerateNetwork(data *model.ClusterData) model.DiagramResult {
 		fmt.Fprintf(&b, "  %s{\"%s<br/>%s<br/>%s\"}\n", gwID, gw.Name, gw.Namespace, clusterLabel)
 		fmt.Fprintf(&b, "  internet -->|HTTPS| %s\n\n", gwID)
 
-		// Build hostname → listener mapping
-		hostToListener := make(map[string]string)
-		for _, l := range gw.Listeners {
-			if l.Hostname != "" {
-				hostToListener[l.Hostname] = l.Name
-			}
-		}
-
 		// Collect routes that match this gateway's listeners in the same cluster.
 		var matched []model.HTTPRouteInfo
 		for _, r := range data.HTTPRoutes {
 			if r.Cluster != "" && gw.Cluster != "" && r.Cluster != gw.Cluster {
 				continue
 			}
-			for _, h := range r.Hostnames {
-				if _, ok := hostToListener[h]; ok {
-					matched = append(matched, r)
-					break
-				}
+			if _, ok := matchListener(gw.Listeners, r.Hostnames); ok {
+				matched = append(matched, r)
 			}
 		}
 
@@ -84,6 +79,7 @@ func GenerateNetwork(data *model.ClusterData) model.DiagramResult {
 			}
 
 			fmt.Fprintf(&b, "  %s[\"%s\"]\n", routeID, label)
+			drawnRoutes[routeID] = true
 
 			edgeLabel := hostname
 			if edgeLabel == "" {
@@ -103,9 +99,14 @@ func GenerateNetwork(data *model.ClusterData) model.DiagramResult {
 			}
 			label := fmt.Sprintf("%s<br/><small>%s</small>", r.Name, hostname)
 			fmt.Fprintf(&b, "  %s[\"%s\"]\n", routeID, label)
+			drawnRoutes[routeID] = true
 		}
 	}
 
+	writeCrossNamespaceRefs(&b, data.HTTPRoutes, drawnRoutes)
+	writeIngressRoutes(&b, data)
+	writeIngresses(&b, data)
+
 	return model.DiagramResult{
 		ID:      "network",
 		Title:   "Network & Ingress",
@@ -113,3 +114,217 @@ func GenerateNetwork(data *model.ClusterData) model.DiagramResult {
 		Content: b.String(),
 	}
 }
+
+// writeIngressRoutes draws Traefik IngressRoutes: one Traefik node per
+// cluster fed from the internet, with an edge per distinct host/path to each
+// route.
+func writeIngressRoutes(b *strings.Builder, data *model.ClusterData) {
+	controllers := make(map[string]bool)
+	for _, ir := range data.IngressRoutes {
+		cluster := ir.Cluster
+		if cluster == "" {
+			cluster = data.PrimaryCluster
+		}
+		ctrlID := "traefik_" + sanitizeID(cluster)
+		if !controllers[ctrlID] {
+			controllers[ctrlID] = true
+			fmt.Fprintf(b, "  %s{\"Traefik<br/>%s\"}\n", ctrlID, cluster)
+			fmt.Fprintf(b, "  internet -->|HTTPS| %s\n", ctrlID)
+		}
+
+		routeID := "ir_" + sanitizeID(cluster+"_"+ir.Namespace+"_"+ir.Name)
+		label := fmt.Sprintf("%s<br/><small>%s</small>", ir.Name, ir.Namespace)
+		if len(ir.EntryPoints) > 0 {
+			label += fmt.Sprintf("<br/><small>%s</small>", strings.Join(ir.EntryPoints, ", "))
+		}
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", routeID, label)
+
+		seen := make(map[string]bool)
+		for _, rule := range ir.Routes {
+			for _, edge := range ruleEdgeLabels(rule) {
+				if edge == "" {
+					edge = ir.Name
+				}
+				if seen[edge] {
+					continue
+				}
+				seen[edge] = true
+				fmt.Fprintf(b, "  %s -->|\"%s\"| %s\n", ctrlID, escapeLabel(edge), routeID)
+			}
+		}
+		if len(seen) == 0 {
+			fmt.Fprintf(b, "  %s --> %s\n", ctrlID, routeID)
+		}
+	}
+}
+
+// ruleEdgeLabels returns "host/path" labels for a rule: one per host, each
+// with the first path; just the paths if no host is matched.
+func ruleEdgeLabels(rule model.IngressRouteRule) []string {
+	path := ""
+	if len(rule.Paths) > 0 && rule.Paths[0] != "/" {
+		path = rule.Paths[0]
+	}
+	if len(rule.Hosts) == 0 {
+		return []string{path}
+	}
+	labels := make([]string, 0, len(rule.Hosts))
+	for _, h := range rule.Hosts {
+		labels = append(labels, h+path)
+	}
+	return labels
+}
+
+// writeIngresses draws classic Ingresses: one controller node per cluster and
+// ingress class, an edge per host/path to each Ingress, and the services it
+// forwards to. The internet edge says HTTPS once any Ingress of the class
+// terminates TLS.
+func writeIngresses(b *strings.Builder, data *model.ClusterData) {
+	type controller struct{ cluster, class string }
+	ctrlOf := func(ing model.IngressInfo) controller {
+		c := controller{ing.Cluster, ing.ClassName}
+		if c.cluster == "" {
+			c.cluster = data.PrimaryCluster
+		}
+		if c.class == "" {
+			c.class = "default"
+		}
+		return c
+	}
+	tls := make(map[controller]bool)
+	for _, ing := range data.Ingresses {
+		if len(ing.TLSHosts) > 0 {
+			tls[ctrlOf(ing)] = true
+		}
+	}
+
+	drawn := make(map[string]bool)
+	for _, ing := range data.Ingresses {
+		ctrl := ctrlOf(ing)
+		ctrlID := "ingress_" + sanitizeID(ctrl.cluster+"_"+ctrl.class)
+		if !drawn[ctrlID] {
+			drawn[ctrlID] = true
+			proto := "HTTP"
+			if tls[ctrl] {
+				proto = "HTTPS"
+			}
+			fmt.Fprintf(b, "  %s{\"Ingress<br/>%s<br/>%s\"}\n", ctrlID, ctrl.class, ctrl.cluster)
+			fmt.Fprintf(b, "  internet -->|%s| %s\n", proto, ctrlID)
+		}
+
+		ingID := "ing_" + sanitizeID(ctrl.cluster+"_"+ing.Namespace+"_"+ing.Name)
+		label := fmt.Sprintf("%s<br/><small>%s</small>", ing.Name, ing.Namespace)
+		if len(ing.TLSHosts) > 0 {
+			label += "<br/><small>TLS</small>"
+		}
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", ingID, label)
+
+		edges := make(map[string]bool)
+		for _, r := range ing.Rules {
+			edge := r.Host
+			if r.Path != "" && r.Path != "/" {
+				edge += r.Path
+			}
+			if edge == "" {
+				edge = "*"
+			}
+			if !edges[edge] {
+				edges[edge] = true
+				fmt.Fprintf(b, "  %s -->|\"%s\"| %s\n", ctrlID, escapeLabel(edge), ingID)
+			}
+
+			if r.Backend.Name == "" {
+				continue
+			}
+			svcID := "svc_" + sanitizeID(ctrl.cluster+"_"+ing.Namespace+"_"+r.Backend.Name)
+			if !drawn[svcID] {
+				drawn[svcID] = true
+				svcLabel := r.Backend.Name
+				if r.Backend.Port != 0 {
+					svcLabel += fmt.Sprintf(":%d", r.Backend.Port)
+				}
+				fmt.Fprintf(b, "  %s([\"%s\"])\n", svcID, svcLabel)
+			}
+			if !drawn[ingID+"->"+svcID] {
+				drawn[ingID+"->"+svcID] = true
+				fmt.Fprintf(b, "  %s --> %s\n", ingID, svcID)
+			}
+		}
+	}
+}
+
+// writeCrossNamespaceRefs draws an edge from each drawn route to its
+// backends in other namespaces. Refs without a ReferenceGrant get a red
+// dashed edge: the Gateway won't route them. Routes that matched no gateway
+// listener have no node, so their refs are left out rather than drawn from
+// an undeclared ID.
+func writeCrossNamespaceRefs(b *strings.Builder, routes []model.HTTPRouteInfo, drawnRoutes map[string]bool) {
+	seen := make(map[string]bool)
+	ungranted := false
+	for _, r := range routes {
+		routeID := sanitizeID(r.Namespace + "_" + r.Name)
+		if !drawnRoutes[routeID] {
+			continue
+		}
+		for _, ref := range r.Backends {
+			if ref.Namespace == "" || ref.Namespace == r.Namespace {
+				continue
+			}
+			backendID := "xns_" + sanitizeID(ref.Namespace+"_"+ref.Name)
+			if !seen[backendID] {
+				seen[backendID] = true
+				fmt.Fprintf(b, "  %s[\"%s<br/><small>%s</small>\"]\n", backendID, ref.Name, ref.Namespace)
+			}
+			if ref.Ungranted {
+				ungranted = true
+				fmt.Fprintf(b, "  %s -.->|\"no ReferenceGrant\"| %s\n", routeID, backendID)
+				fmt.Fprintf(b, "  class %s ungranted\n", backendID)
+			} else {
+				fmt.Fprintf(b, "  %s --> %s\n", routeID, backendID)
+			}
+		}
+	}
+	if ungranted {
+		b.WriteString("  classDef ungranted stroke:#dc2626,stroke-width:2px,stroke-dasharray:4\n")
+	}
+}
+
+// matchListener returns the listener a route with the given hostnames
+// attaches to, following Gateway API hostname matching: an exact hostname
+// wins over a wildcard ("*.example.com" covers any subdomain, not the apex),
+// a longer wildcard over a shorter one, and a listener without a hostname
+// accepts every route. Routes without hostnames only attach to such a
+// listener, since routes here aren't matched by parentRef.
+func matchListener(listeners []model.ListenerInfo, hostnames []string) (model.ListenerInfo, bool) {
+	var best model.ListenerInfo
+	bestScore := 0 // 0 = none, 1 = any host, 2+ = wildcard by length, max = exact
+	for _, l := range listeners {
+		score := 0
+		if l.Hostname == "" {
+			score = 1
+		}
+		for _, h := range hostnames {
+			switch {
+			case h == l.Hostname:
+				score = math.MaxInt
+			case hostnameMatches(l.Hostname, h) || hostnameMatches(h, l.Hostname):
+				score = max(score, 1+len(l.Hostname))
+			}
+		}
+		if score > bestScore {
+			best, bestScore = l, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// hostnameMatches reports whether the wildcard pattern ("*.example.com")
+// covers host, itself possibly a narrower wildcard. Non-wildcard patterns
+// never match here; exact equality is checked by the caller.
+func hostnameMatches(pattern, host string) bool {
+	suffix, ok := strings.CutPrefix(pattern, "*")
+	if !ok || !strings.HasPrefix(suffix, ".") {
+		return false
+	}
+	return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+}
@@ -0,0 +1,148 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestMatchListener(t *testing.T) {
+	wildcard := model.ListenerInfo{Name: "wildcard", Hostname: "*.example.com"}
+	deeper := model.ListenerInfo{Name: "deeper", Hostname: "*.apps.example.com"}
+	exact := model.ListenerInfo{Name: "exact", Hostname: "app.example.com"}
+	anyHost := model.ListenerInfo{Name: "any"}
+
+	tests := []struct {
+		name      string
+		listeners []model.ListenerInfo
+		hostnames []string
+		want      string // listener name, "" for no match
+	}{
+		{"wildcard matches subdomain", []model.ListenerInfo{wildcard}, []string{"app.example.com"}, "wildcard"},
+		{"wildcard matches nested subdomain", []model.ListenerInfo{wildcard}, []string{"a.b.example.com"}, "wildcard"},
+		{"wildcard does not match apex", []model.ListenerInfo{wildcard}, []string{"example.com"}, ""},
+		{"wildcard does not match other domain", []model.ListenerInfo{wildcard}, []string{"app.example.org"}, ""},
+		{"suffix must be a label boundary", []model.ListenerInfo{wildcard}, []string{"appexample.com"}, ""},
+		{"exact wins over wildcard", []model.ListenerInfo{wildcard, exact}, []string{"app.example.com"}, "exact"},
+		{"longer wildcard wins", []model.ListenerInfo{wildcard, deeper}, []string{"x.apps.example.com"}, "deeper"},
+		{"wildcard wins over any-host listener", []model.ListenerInfo{anyHost, wildcard}, []string{"app.example.com"}, "wildcard"},
+		{"route wildcard inside listener wildcard", []model.ListenerInfo{wildcard}, []string{"*.apps.example.com"}, "wildcard"},
+		{"no-hostname listener matches any route", []model.ListenerInfo{anyHost}, []string{"whatever.internal"}, "any"},
+		{"no-hostname listener matches hostless route", []model.ListenerInfo{anyHost}, nil, "any"},
+		{"hostless route needs a no-hostname listener", []model.ListenerInfo{exact}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, ok := matchListener(tt.listeners, tt.hostnames)
+			got := ""
+			if ok {
+				got = l.Name
+			}
+			if got != tt.want {
+				t.Errorf("matchListener(%v) = %q, want %q", tt.hostnames, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateNetworkWildcardListener(t *testing.T) {
+	data := &model.ClusterData{
+		PrimaryCluster: "Homelab",
+		Gateways: []model.GatewayInfo{{
+			Name: "public", Namespace: "gateway", Cluster: "Homelab",
+			Listeners: []model.ListenerInfo{{Name: "https", Hostname: "*.example.com", Protocol: "HTTPS", Port: 443}},
+		}},
+		HTTPRoutes: []model.HTTPRouteInfo{
+			{Name: "app", Namespace: "apps", Cluster: "Homelab", Hostnames: []string{"app.example.com"}},
+			{Name: "other", Namespace: "apps", Cluster: "Homelab", Hostnames: []string{"other.example.org"}},
+		},
+	}
+
+	content := GenerateNetwork(data).Content
+	if !strings.Contains(content, "apps_app") {
+		t.Errorf("route under the wildcard listener is missing:\n%s", content)
+	}
+	if strings.Contains(content, "apps_other") {
+		t.Errorf("route for another domain was attached:\n%s", content)
+	}
+}
+
+func TestGenerateNetworkCrossNamespaceRefsOnlyFromDrawnRoutes(t *testing.T) {
+	data := &model.ClusterData{
+		PrimaryCluster: "Homelab",
+		Gateways: []model.GatewayInfo{{
+			Name: "public", Namespace: "gateway", Cluster: "Homelab",
+			Listeners: []model.ListenerInfo{{Name: "https", Hostname: "*.example.com", Protocol: "HTTPS", Port: 443}},
+		}},
+		HTTPRoutes: []model.HTTPRouteInfo{
+			{Name: "app", Namespace: "apps", Cluster: "Homelab", Hostnames: []string{"app.example.com"},
+				Backends: []model.BackendRef{{Name: "oauth", Namespace: "auth", Ungranted: true}}},
+			// Matches no listener, so it has no node to draw an edge from.
+			{Name: "other", Namespace: "apps", Cluster: "Homelab", Hostnames: []string{"other.example.org"},
+				Backends: []model.BackendRef{{Name: "pg", Namespace: "db"}}},
+		},
+	}
+
+	content := GenerateNetwork(data).Content
+	if !strings.Contains(content, `apps_app -.->|"no ReferenceGrant"| xns_auth_oauth`) {
+		t.Errorf("ungranted edge from the drawn route is missing:\n%s", content)
+	}
+	for _, unwanted := range []string{"apps_other", "xns_db_pg"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("network diagram references undrawn route %q:\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestGenerateNetworkIngressRoutes(t *testing.T) {
+	data := &model.ClusterData{
+		PrimaryCluster: "Homelab",
+		IngressRoutes: []model.IngressRouteInfo{{
+			Name: "app", Namespace: "apps", Cluster: "Homelab", EntryPoints: []string{"websecure"},
+			Routes: []model.IngressRouteRule{{
+				Hosts:    []string{"app.example.com"},
+				Paths:    []string{"/api"},
+				Services: []model.BackendRef{{Name: "app-api", Port: 8080}},
+			}},
+		}},
+	}
+
+	content := GenerateNetwork(data).Content
+	for _, want := range []string{
+		"internet -->|HTTPS| traefik_Homelab",
+		`traefik_Homelab -->|"app.example.com/api"| ir_Homelab_apps_app`,
+		"websecure",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("network diagram missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestGenerateNetworkIngresses(t *testing.T) {
+	data := &model.ClusterData{
+		PrimaryCluster: "Homelab",
+		Ingresses: []model.IngressInfo{{
+			Name: "shop", Namespace: "apps", Cluster: "Homelab", ClassName: "nginx",
+			Rules: []model.IngressRule{
+				{Host: "shop.example.com", Path: "/", Backend: model.BackendRef{Name: "shop-web", Port: 80}},
+				{Host: "api.example.com", Path: "/v1", Backend: model.BackendRef{Name: "shop-api", Port: 8080}},
+			},
+			TLSHosts: []string{"shop.example.com"},
+		}},
+	}
+
+	content := GenerateNetwork(data).Content
+	for _, want := range []string{
+		"internet -->|HTTPS| ingress_Homelab_nginx",
+		`ingress_Homelab_nginx -->|"shop.example.com"| ing_Homelab_apps_shop`,
+		`ingress_Homelab_nginx -->|"api.example.com/v1"| ing_Homelab_apps_shop`,
+		"ing_Homelab_apps_shop --> svc_Homelab_apps_shop_api",
+		`"shop-api:8080"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("network diagram missing %q:\n%s", want, content)
+		}
+	}
+}
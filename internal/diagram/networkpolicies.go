@@ -27,6 +27,7 @@ func GenerateNetworkPolicies(data *model.ClusterData) model.DiagramResult {
 			Title:   "Network Policies",
 			Type:    "markdown",
 			Content: "*No network policy data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 	"github.com/fredericrous/cluster-vision/internal/versions"
@@ -16,6 +17,10 @@ type NodeRow struct {
 	Name             string `json:"name"`
 	Cluster          string `json:"cluster"`
 	Type             string `json:"type"` // "node" | "load-balancer"
+	Status           string `json:"status"`     // kubectl-style, e.g. "Ready", "NotReady,SchedulingDisabled"
+	NotReady         bool   `json:"notReady"`   // Ready condition is not True
+	Cordoned         bool   `json:"cordoned"`   // spec.unschedulable
+	Conditions       string `json:"conditions"` // active problem conditions, e.g. "MemoryPressure, DiskPressure"
 	Roles            string `json:"roles"`
 	IP               string `json:"ip"`
 	OS               string `json:"os"`
@@ -25,12 +30,21 @@ type NodeRow struct {
 	Kubelet          string `json:"kubelet"`
 	LatestKubelet    string `json:"latestKubelet"`
 	KubeletOutdated  bool   `json:"kubeletOutdated"`
+	EOL              bool   `json:"eol"`     // running OS cycle is past end-of-life
+	EOLSoon          bool   `json:"eolSoon"` // running OS cycle reaches end-of-life within the warning window
+	EOLDate          string `json:"eolDate"` // YYYY-MM-DD, from endoflife.date
 	ContainerRuntime string `json:"containerRuntime"`
+	LatestRuntime    string `json:"latestRuntime"`
+	RuntimeOutdated  bool   `json:"runtimeOutdated"`
 	Kernel           string `json:"kernel"`
 	CPU              string `json:"cpu"`
 	Memory           string `json:"memory"`
+	Pods             string `json:"pods"`        // scheduled/capacity, e.g. "104/110"; "" if unknown
+	PodPressure      bool   `json:"podPressure"` // scheduled pods at or above podPressureRatio of capacity
+	Taints           string `json:"taints"`      // comma-separated, e.g. "nvidia.com/gpu:NoSchedule"
 	Arch             string `json:"arch"`
 	Provider         string `json:"provider"` // e.g. "proxmox"
+	Host             string `json:"host"`     // physical host of the VM, from tfstate
 	Distro           string `json:"distro"`        // K8s distribution, e.g. "Talos", "K3s"
 	GPU              string `json:"gpu"`
 	OSDisk           string `json:"osDisk"`        // e.g. "32 GB"
@@ -39,6 +53,50 @@ type NodeRow struct {
 	VulnSummary      string `json:"vulnSummary"`   // human-readable tooltip
 }
 
+// podPressureRatio is the share of a node's pod capacity at which it is
+// flagged as near its limit.
+const podPressureRatio = 0.9
+
+// podsPerNode counts the distinct non-terminal pods scheduled on each node,
+// keyed by cluster/node.
+func podsPerNode(pods []model.PodImageInfo) map[string]int {
+	seen := make(map[string]bool)
+	counts := make(map[string]int)
+	for _, p := range pods {
+		if p.NodeName == "" || p.State == "Succeeded" || p.State == "Failed" {
+			continue
+		}
+		podKey := p.Cluster + "/" + p.Namespace + "/" + p.PodName
+		if seen[podKey] {
+			continue
+		}
+		seen[podKey] = true
+		counts[p.Cluster+"/"+p.NodeName]++
+	}
+	return counts
+}
+
+// nodeStatus renders a node's status the way kubectl get nodes does.
+func nodeStatus(n model.NodeInfo) string {
+	status := "Ready"
+	if !n.Ready {
+		status = "NotReady"
+	}
+	if !n.Schedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// formatTaints joins node taints in kubectl notation.
+func formatTaints(taints []model.NodeTaint) string {
+	parts := make([]string, len(taints))
+	for i, t := range taints {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
 // formatDiskGB formats a disk size in GB for display, omitting zero values.
 func formatDiskGB(gb int) string {
 	if gb == 0 {
@@ -56,6 +114,7 @@ func GenerateNodes(data *model.ClusterData, checker *versions.NodeChecker, secCh
 			Title:   "Cluster Nodes",
 			Type:    "markdown",
 			Content: "*No node data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -67,6 +126,8 @@ func GenerateNodes(data *model.ClusterData, checker *versions.NodeChecker, secCh
 		}
 	}
 
+	podCounts := podsPerNode(data.Pods)
+
 	var rows []NodeRow
 	for _, n := range data.Nodes {
 		distro, osVer := versions.ParseOSImage(n.OSImage)
@@ -84,6 +145,11 @@ func GenerateNodes(data *model.ClusterData, checker *versions.NodeChecker, secCh
 			}
 		}
 
+		var eol versions.EOLStatus
+		if checker != nil {
+			eol = checker.GetEOLStatus(n.OSImage, time.Now())
+		}
+
 		latestKubelet := ""
 		kubeletOutdated := false
 		if checker != nil {
@@ -95,6 +161,16 @@ func GenerateNodes(data *model.ClusterData, checker *versions.NodeChecker, secCh
 			}
 		}
 
+		latestRuntime := ""
+		runtimeOutdated := false
+		if checker != nil {
+			if v := checker.GetLatestRuntime(n.ContainerRuntime); v != "" {
+				latestRuntime = v
+				_, current := versions.ParseContainerRuntime(n.ContainerRuntime)
+				runtimeOutdated = versions.IsOutdated(current, latestRuntime)
+			}
+		}
+
 		osName := distro
 		if osName == "" {
 			osName = n.OSImage
@@ -113,7 +189,12 @@ func GenerateNodes(data *model.ClusterData, checker *versions.NodeChecker, secCh
 			Kubelet:          n.KubeletVersion,
 			LatestKubelet:    latestKubelet,
 			KubeletOutdated:  kubeletOutdated,
+			EOL:              eol.EOL,
+			EOLSoon:          eol.EOLSoon,
+			EOLDate:          eol.Date,
 			ContainerRuntime: n.ContainerRuntime,
+			LatestRuntime:    latestRuntime,
+			RuntimeOutdated:  runtimeOutdated,
 			Kernel:           n.KernelVersion,
 			CPU:              n.CPU,
 			Memory:           n.Memory,
@@ -121,9 +202,22 @@ func GenerateNodes(data *model.ClusterData, checker *versions.NodeChecker, secCh
 			Distro:           capitalizeFirst(distro),
 		}
 
+		row.Taints = formatTaints(n.Taints)
+		row.Status = nodeStatus(n)
+		row.NotReady = !n.Ready
+		row.Cordoned = !n.Schedulable
+		row.Conditions = strings.Join(n.Conditions, ", ")
+
+		if n.PodCapacity > 0 {
+			used := podCounts[n.Cluster+"/"+n.Name]
+			row.Pods = fmt.Sprintf("%d/%d", used, n.PodCapacity)
+			row.PodPressure = float64(used) >= podPressureRatio*float64(n.PodCapacity)
+		}
+
 		// Enrich with Terraform data.
 		if tfn, ok := tfByName[n.Name]; ok {
 			row.Provider = tfn.Provider
+			row.Host = tfn.Host
 			row.GPU = tfn.GPU
 			row.OSDisk = formatDiskGB(tfn.OSDiskGB)
 			row.DataDisk = formatDiskGB(tfn.DataDiskGB)
@@ -0,0 +1,132 @@
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func decodeNodeRows(t *testing.T, d model.DiagramResult) map[string]NodeRow {
+	t.Helper()
+	var rows []NodeRow
+	if err := json.Unmarshal([]byte(d.Content), &rows); err != nil {
+		t.Fatalf("decoding nodes table: %v", err)
+	}
+	byName := make(map[string]NodeRow)
+	for _, r := range rows {
+		byName[r.Name] = r
+	}
+	return byName
+}
+
+func TestGenerateNodesPodPressure(t *testing.T) {
+	var pods []model.PodImageInfo
+	for i := 0; i < 100; i++ {
+		// Two containers per pod must count once.
+		for _, c := range []string{"app", "sidecar"} {
+			pods = append(pods, model.PodImageInfo{
+				Cluster: "Homelab", Namespace: "apps", PodName: fmt.Sprintf("busy-%d", i),
+				Container: c, NodeName: "busy", State: "Running",
+			})
+		}
+	}
+	pods = append(pods,
+		model.PodImageInfo{Cluster: "Homelab", Namespace: "apps", PodName: "quiet-1", NodeName: "quiet", State: "Running"},
+		model.PodImageInfo{Cluster: "Homelab", Namespace: "apps", PodName: "done-1", NodeName: "quiet", State: "Succeeded"},
+		model.PodImageInfo{Cluster: "Homelab", Namespace: "apps", PodName: "pending-1", State: "Pending"},
+	)
+
+	data := &model.ClusterData{
+		Nodes: []model.NodeInfo{
+			{Name: "busy", Cluster: "Homelab", PodCapacity: 110},
+			{Name: "quiet", Cluster: "Homelab", PodCapacity: 110},
+			{Name: "unknown", Cluster: "Homelab"},
+		},
+		Pods: pods,
+	}
+
+	rows := decodeNodeRows(t, GenerateNodes(data, nil, nil))
+
+	tests := []struct {
+		node         string
+		wantPods     string
+		wantPressure bool
+	}{
+		{"busy", "100/110", true},
+		{"quiet", "1/110", false},
+		{"unknown", "", false},
+	}
+	for _, tt := range tests {
+		r := rows[tt.node]
+		if r.Pods != tt.wantPods || r.PodPressure != tt.wantPressure {
+			t.Errorf("node %s = {pods:%q pressure:%v}, want {%q %v}", tt.node, r.Pods, r.PodPressure, tt.wantPods, tt.wantPressure)
+		}
+	}
+}
+
+func TestGenerateNodesTaints(t *testing.T) {
+	data := &model.ClusterData{Nodes: []model.NodeInfo{
+		{Name: "gpu-1", Cluster: "Homelab", Taints: []model.NodeTaint{
+			{Key: "nvidia.com/gpu", Effect: "NoSchedule"},
+			{Key: "dedicated", Value: "ml", Effect: "NoExecute"},
+		}},
+		{Name: "worker-1", Cluster: "Homelab"},
+	}}
+
+	rows := decodeNodeRows(t, GenerateNodes(data, nil, nil))
+	if got, want := rows["gpu-1"].Taints, "nvidia.com/gpu:NoSchedule, dedicated=ml:NoExecute"; got != want {
+		t.Errorf("gpu-1 taints = %q, want %q", got, want)
+	}
+	if got := rows["worker-1"].Taints; got != "" {
+		t.Errorf("worker-1 taints = %q, want empty", got)
+	}
+
+	topo := generateK8sOnlyTopology(data, DefaultOptions()).Content
+	if !strings.Contains(topo, "Taint: nvidia.com/gpu:NoSchedule<br/>Taint: dedicated=ml:NoExecute") {
+		t.Errorf("topology lacks taint line:\n%s", topo)
+	}
+}
+
+func TestGenerateNodesReadiness(t *testing.T) {
+	data := &model.ClusterData{Nodes: []model.NodeInfo{
+		{Name: "broken-1", Cluster: "Homelab", Ready: false, Schedulable: false, Conditions: []string{"MemoryPressure", "DiskPressure"}},
+		{Name: "drained-1", Cluster: "Homelab", Ready: true, Schedulable: false},
+		{Name: "worker-1", Cluster: "Homelab", Ready: true, Schedulable: true},
+	}}
+
+	rows := decodeNodeRows(t, GenerateNodes(data, nil, nil))
+	tests := []struct {
+		name               string
+		status             string
+		notReady, cordoned bool
+		conditions         string
+	}{
+		{"broken-1", "NotReady,SchedulingDisabled", true, true, "MemoryPressure, DiskPressure"},
+		{"drained-1", "Ready,SchedulingDisabled", false, true, ""},
+		{"worker-1", "Ready", false, false, ""},
+	}
+	for _, tt := range tests {
+		r := rows[tt.name]
+		if r.Status != tt.status || r.NotReady != tt.notReady || r.Cordoned != tt.cordoned || r.Conditions != tt.conditions {
+			t.Errorf("%s = {status:%q notReady:%v cordoned:%v conditions:%q}, want {%q %v %v %q}",
+				tt.name, r.Status, r.NotReady, r.Cordoned, r.Conditions, tt.status, tt.notReady, tt.cordoned, tt.conditions)
+		}
+	}
+
+	topo := generateK8sOnlyTopology(data, DefaultOptions()).Content
+	for _, want := range []string{
+		"NotReady,SchedulingDisabled<br/>Condition: MemoryPressure<br/>Condition: DiskPressure",
+		"class n0 notReady",
+		"class n1 cordoned",
+	} {
+		if !strings.Contains(topo, want) {
+			t.Errorf("topology missing %q:\n%s", want, topo)
+		}
+	}
+	if strings.Contains(topo, "class n2 ") {
+		t.Errorf("healthy node was styled:\n%s", topo)
+	}
+}
@@ -0,0 +1,60 @@
+package diagram
+
+import (
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+// Options configures the generators that take them. Callers start from
+// DefaultOptions and pass the same value to every generator of a refresh;
+// generators only read it, so concurrent refreshes never share mutable
+// state.
+type Options struct {
+	// MaxLabelLength caps each detail line of a Mermaid node label, in
+	// runes. Longer lines are shortened in the middle so both ends stay
+	// readable, e.g. an image keeps its registry and tag. Zero or negative
+	// disables truncation.
+	MaxLabelLength int
+	// PlacementLimit is how many workloads the topology lists under each
+	// node, largest first; the rest are summarized in one "+N more" entry.
+	// Zero disables the placement overlay.
+	PlacementLimit int
+	// TopologyGroupLabel is the node label, e.g. topology.kubernetes.io/zone
+	// or a custom rack label, whose values group nodes into subgraphs of
+	// the Kubernetes topology. Empty keeps a single flat cluster subgraph.
+	TopologyGroupLabel string
+	// MergeMeshServiceEntries collapses reciprocal cross-cluster
+	// ServiceEntries (one per cluster for the same hosts) into a single
+	// bidirectional node in the mesh topology.
+	MergeMeshServiceEntries bool
+	// NamespaceLabelColumns are extra boolean columns of the security
+	// matrix, each computed from a namespace label, after the built-in ones.
+	NamespaceLabelColumns []model.LabelColumn
+	// IncludeSystemNamespaces lists, per diagram ID, the system namespaces
+	// (model.NamespaceInfo.System) that diagram shows anyway, e.g.
+	// {"security": {"default"}} to keep test apps deployed in default in the
+	// security matrix. "*" includes every system namespace.
+	IncludeSystemNamespaces map[string][]string
+	// BackupMaxAge is how old a namespace's last completed Velero backup may
+	// be for the security matrix to count it as backed up.
+	BackupMaxAge time.Duration
+	// RegistryAliases maps registry hosts to the names the images table
+	// shows for them, e.g. "ghcr.io" → "GHCR". A key may be a path.Match
+	// pattern such as "*.dkr.ecr.*.amazonaws.com". Rows are still grouped
+	// and sorted by the real host.
+	RegistryAliases map[string]string
+	// VersionPolicy holds the minimum chart and image versions the charts
+	// and images tables flag violations of; nil flags none.
+	VersionPolicy *versions.Policy
+}
+
+// DefaultOptions returns the options generators run with when nothing is
+// configured.
+func DefaultOptions() Options {
+	return Options{
+		MaxLabelLength: 48,
+		BackupMaxAge:   48 * time.Hour,
+	}
+}
@@ -25,6 +25,7 @@ func GenerateQuotas(data *model.ClusterData) model.DiagramResult {
 			Title:   "Resource Quotas & Limits",
 			Type:    "markdown",
 			Content: "*No quota or limit range data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -3,6 +3,7 @@ package diagram
 import (
 	"encoding/json"
 	"sort"
+	"strings"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 )
@@ -25,6 +26,7 @@ func GenerateRBAC(data *model.ClusterData) model.DiagramResult {
 			Title:   "RBAC Inventory",
 			Type:    "markdown",
 			Content: "*No RBAC binding data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -61,3 +63,80 @@ func GenerateRBAC(data *model.ClusterData) model.DiagramResult {
 		Content: string(tableJSON),
 	}
 }
+
+// PrivilegedRow is a subject bound to cluster-admin or a wildcard role.
+type PrivilegedRow struct {
+	Subject     string `json:"subject"`
+	SubjectKind string `json:"subjectKind"`
+	Role        string `json:"role"`
+	Reason      string `json:"reason"` // "cluster-admin" or "wildcard"
+	Scope       string `json:"scope"`  // "cluster", or the namespace a RoleBinding grants in
+	Binding     string `json:"binding"`
+	Namespace   string `json:"namespace"`
+	Cluster     string `json:"cluster"`
+}
+
+// GeneratePrivilegedRBAC produces a table of subjects bound to cluster-admin
+// or to roles with wildcard rules, grouped by namespace. Kubernetes' own
+// bootstrap bindings and "system:" users and groups are left out; service
+// accounts in system namespaces stay, since add-ons installed there are
+// exactly the ones worth reviewing.
+func GeneratePrivilegedRBAC(data *model.ClusterData) model.DiagramResult {
+	var rows []PrivilegedRow
+	for _, b := range data.RBACBindings {
+		if b.Broad == "" || !notableBinding(b) {
+			continue
+		}
+		scope := "cluster"
+		if !b.ClusterWide {
+			scope = b.BindingNamespace
+		}
+		rows = append(rows, PrivilegedRow{
+			Subject:     b.SubjectName,
+			SubjectKind: b.SubjectKind,
+			Role:        b.RoleName,
+			Reason:      b.Broad,
+			Scope:       scope,
+			Binding:     b.Binding,
+			Namespace:   b.Namespace,
+			Cluster:     b.Cluster,
+		})
+	}
+
+	if len(rows) == 0 {
+		return model.DiagramResult{
+			ID:      "rbac-privileged",
+			Title:   "Privileged RBAC Subjects",
+			Type:    "markdown",
+			Content: "*No subjects bound to cluster-admin or wildcard roles.*",
+			Empty:   true,
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Cluster != rows[j].Cluster {
+			return rows[i].Cluster < rows[j].Cluster
+		}
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		if rows[i].Subject != rows[j].Subject {
+			return rows[i].Subject < rows[j].Subject
+		}
+		return rows[i].Role < rows[j].Role
+	})
+
+	tableJSON, _ := json.Marshal(rows)
+	return model.DiagramResult{
+		ID:      "rbac-privileged",
+		Title:   "Privileged RBAC Subjects",
+		Type:    "table",
+		Content: string(tableJSON),
+	}
+}
+
+// notableBinding drops the bindings every cluster ships with: those named
+// "system:…" and subjects such as the system:masters group.
+func notableBinding(b model.RBACBindingInfo) bool {
+	return !strings.HasPrefix(b.Binding, "system:") && !strings.HasPrefix(b.SubjectName, "system:")
+}
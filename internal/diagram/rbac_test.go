@@ -0,0 +1,41 @@
+package diagram
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestGeneratePrivilegedRBACScope(t *testing.T) {
+	data := &model.ClusterData{
+		RBACBindings: []model.RBACBindingInfo{
+			{SubjectName: "deployer", SubjectKind: "ServiceAccount", RoleName: "cluster-admin", Namespace: "ci",
+				Cluster: "Homelab", Binding: "deployer-admin", ClusterWide: true, Broad: "cluster-admin"},
+			// A ServiceAccount from "tools" bound in "ci": the grant is scoped
+			// to the binding's namespace, not the subject's.
+			{SubjectName: "builder", SubjectKind: "ServiceAccount", RoleName: "everything", Namespace: "tools",
+				Cluster: "Homelab", Binding: "builder-everything", BindingNamespace: "ci", Broad: "wildcard"},
+		},
+	}
+
+	var rows []PrivilegedRow
+	if err := json.Unmarshal([]byte(GeneratePrivilegedRBAC(data).Content), &rows); err != nil {
+		t.Fatalf("decoding privileged table: %v", err)
+	}
+	scopes := make(map[string]string)
+	namespaces := make(map[string]string)
+	for _, r := range rows {
+		scopes[r.Subject] = r.Scope
+		namespaces[r.Subject] = r.Namespace
+	}
+	if got := scopes["deployer"]; got != "cluster" {
+		t.Errorf("deployer scope = %q, want cluster", got)
+	}
+	if got := scopes["builder"]; got != "ci" {
+		t.Errorf("builder scope = %q, want ci, the binding's namespace", got)
+	}
+	if got := namespaces["builder"]; got != "tools" {
+		t.Errorf("builder namespace = %q, want tools, the subject's namespace", got)
+	}
+}
@@ -0,0 +1,68 @@
+package diagram
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/metrics"
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// Safe runs a single-diagram generator, recovering a panic into a markdown
+// DiagramResult that keeps the diagram's ID and carries the error. One bad
+// generator (a nil map, unexpected CRD shape) then degrades to an error
+// card instead of taking the whole refresh down with it. Structured results
+// get their Data filled (see model.DiagramResult.WithData). Every result
+// carries the generator's duration in GenMillis.
+func Safe(id, title string, gen func() model.DiagramResult) (result model.DiagramResult) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result = failedDiagram(id, title, r)
+		}
+		result.GenMillis = recordGenTime(id, start)
+	}()
+	return gen().WithData()
+}
+
+// SafeAll is Safe for generators that return several diagrams. On panic
+// the whole group collapses to a single error result under id.
+func SafeAll(id, title string, gen func() []model.DiagramResult) (results []model.DiagramResult) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			results = []model.DiagramResult{failedDiagram(id, title, r)}
+		}
+		ms := recordGenTime(id, start)
+		for i := range results {
+			results[i].GenMillis = ms
+		}
+	}()
+	results = gen()
+	for i := range results {
+		results[i] = results[i].WithData()
+	}
+	return results
+}
+
+// recordGenTime exports the time since start for generator id and returns
+// it in milliseconds.
+func recordGenTime(id string, start time.Time) int64 {
+	d := time.Since(start)
+	metrics.DiagramGenSeconds.WithLabelValues(id).Set(d.Seconds())
+	return d.Milliseconds()
+}
+
+func failedDiagram(id, title string, r interface{}) model.DiagramResult {
+	err := fmt.Sprint(r)
+	slog.Error("diagram generator panicked", "id", id, "error", err, "stack", string(debug.Stack()))
+	return model.DiagramResult{
+		ID:      id,
+		Title:   title,
+		Type:    "markdown",
+		Content: fmt.Sprintf("*Failed to generate diagram: %s*", err),
+		Error:   err,
+	}
+}
@@ -0,0 +1,127 @@
+package diagram
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestSafeRecoversPanickingGenerator(t *testing.T) {
+	data := &model.ClusterData{
+		Workloads: []model.WorkloadInfo{{Name: "api", Namespace: "apps", Cluster: "Homelab", Kind: "Deployment", Replicas: 1}},
+	}
+
+	var nilMap map[string]*model.WorkloadInfo
+	diagrams := []model.DiagramResult{
+		Safe("workloads", "Workloads", func() model.DiagramResult { return GenerateWorkloads(data) }),
+		Safe("broken", "Broken", func() model.DiagramResult {
+			nilMap["x"].Name = "boom"
+			return model.DiagramResult{ID: "broken"}
+		}),
+	}
+	diagrams = append(diagrams, SafeAll("security", "Security Matrix", func() []model.DiagramResult {
+		return GenerateSecurity(data, DefaultOptions())
+	})...)
+
+	// Security without namespaces still returns its table and chart.
+	if len(diagrams) != 4 {
+		t.Fatalf("got %d diagrams, want 4", len(diagrams))
+	}
+
+	if d := diagrams[0]; d.Type != "table" || d.Error != "" {
+		t.Errorf("workloads diagram = {Type:%q Error:%q}, want table with no error", d.Type, d.Error)
+	}
+
+	broken := diagrams[1]
+	if broken.ID != "broken" || broken.Title != "Broken" {
+		t.Errorf("broken diagram = {ID:%q Title:%q}, want ID and title preserved", broken.ID, broken.Title)
+	}
+	if broken.Type != "markdown" {
+		t.Errorf("broken diagram type = %q, want markdown", broken.Type)
+	}
+	if !strings.Contains(broken.Error, "nil pointer") {
+		t.Errorf("broken diagram error = %q, want nil pointer dereference", broken.Error)
+	}
+	if !strings.Contains(broken.Content, broken.Error) {
+		t.Errorf("broken diagram content = %q, want it to mention the error", broken.Content)
+	}
+
+	if d := diagrams[2]; d.ID != "security" || d.Error != "" {
+		t.Errorf("security diagram = {ID:%q Error:%q}, want rendered without error", d.ID, d.Error)
+	}
+}
+
+func TestSafeAllCollapsesToSingleError(t *testing.T) {
+	got := SafeAll("topology", "Physical Topology", func() []model.DiagramResult {
+		panic("bad tfstate")
+	})
+	if len(got) != 1 {
+		t.Fatalf("got %d results, want 1", len(got))
+	}
+	if got[0].ID != "topology" || got[0].Error != "bad tfstate" {
+		t.Errorf("SafeAll() = {ID:%q Error:%q}, want {topology, bad tfstate}", got[0].ID, got[0].Error)
+	}
+}
+
+func TestSafeEmbedsStructuredData(t *testing.T) {
+	data := &model.ClusterData{
+		Namespaces: []model.NamespaceInfo{{Name: "apps", Cluster: "Homelab"}},
+		Workloads:  []model.WorkloadInfo{{Name: "api", Namespace: "apps", Cluster: "Homelab", Kind: "Deployment", Replicas: 1}},
+	}
+	table := Safe("workloads", "Workloads", func() model.DiagramResult { return GenerateWorkloads(data) })
+	chart := SafeAll("security", "Security Matrix", func() []model.DiagramResult { return GenerateSecurity(data, DefaultOptions()) })[1]
+
+	body, err := json.Marshal([]model.DiagramResult{table, chart})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []struct {
+		Type    string           `json:"type"`
+		Content string           `json:"content"`
+		Data    []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("table data should decode without a nested string decode: %v", err)
+	}
+
+	if len(got[0].Data) != 1 || got[0].Data[0]["name"] != "api" {
+		t.Errorf("table data = %v, want the api workload row", got[0].Data)
+	}
+	if got[0].Content == "" {
+		t.Error("table content should stay populated for older clients")
+	}
+	if got[1].Type != "mermaid" || got[1].Data != nil {
+		t.Errorf("mermaid diagram should carry no data, got %v", got[1].Data)
+	}
+}
+
+func TestSafeRecordsGenerationTime(t *testing.T) {
+	slow := Safe("slow", "Slow", func() model.DiagramResult {
+		time.Sleep(50 * time.Millisecond)
+		return model.DiagramResult{ID: "slow", Type: "markdown", Content: "done"}
+	})
+	if slow.GenMillis < 50 || slow.GenMillis > 1000 {
+		t.Errorf("slow GenMillis = %d, want about 50", slow.GenMillis)
+	}
+
+	group := SafeAll("pair", "Pair", func() []model.DiagramResult {
+		time.Sleep(20 * time.Millisecond)
+		return []model.DiagramResult{{ID: "a"}, {ID: "b"}}
+	})
+	for _, d := range group {
+		if d.GenMillis < 20 || d.GenMillis > 1000 {
+			t.Errorf("%s GenMillis = %d, want about 20 (the group's time)", d.ID, d.GenMillis)
+		}
+	}
+
+	failed := Safe("panics", "Panics", func() model.DiagramResult {
+		time.Sleep(10 * time.Millisecond)
+		panic("boom")
+	})
+	if failed.Error == "" || failed.GenMillis < 10 {
+		t.Errorf("failed = {Error:%q GenMillis:%d}, want the error and its time", failed.Error, failed.GenMillis)
+	}
+}
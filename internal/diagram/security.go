@@ -3,33 +3,66 @@ package diagram
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 )
 
 // SecurityRow represents a single row in the security table.
 type SecurityRow struct {
-	Cluster     string `json:"cluster"`
-	Namespace   string `json:"namespace"`
-	Ingress     string `json:"ingress"`
-	Ambient     string `json:"ambient"`
-	MTLS        string `json:"mtls"`
-	MTLSClient  string `json:"mtlsClient"`
-	ExtAuth     string `json:"extAuth"`
-	Backup      string `json:"backup"`
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace"`
+	Ingress    string `json:"ingress"`
+	Ambient    string `json:"ambient"`
+	MTLS       string `json:"mtls"`
+	MTLSClient string `json:"mtlsClient"`
+	ExtAuth    string `json:"extAuth"`
+	// Backup is "yes" when a completed Velero Backup covered the namespace
+	// within Options.BackupMaxAge, "stale" when the last one is older, else "no".
+	// Without Velero Backups to go by it follows the backup: velero label.
+	Backup string `json:"backup"`
+	// LastBackup is the age of the last completed backup, e.g. "5h".
+	LastBackup  string `json:"lastBackup,omitempty"`
 	PodSecurity string `json:"podSecurity"`
+	// Limited says whether the namespace has a LimitRange or ResourceQuota;
+	// without either a runaway pod can starve the cluster.
+	Limited string `json:"limited"`
+	// Columns holds the configured NamespaceLabelColumns, header → "yes"/"no".
+	Columns map[string]string `json:"columns,omitempty"`
+}
+
+// visibleNamespaces returns the namespaces diagramID shows: app namespaces,
+// plus the system ones opts.IncludeSystemNamespaces includes for it.
+func visibleNamespaces(diagramID string, namespaces []model.NamespaceInfo, opts Options) []model.NamespaceInfo {
+	include := opts.IncludeSystemNamespaces[diagramID]
+	var out []model.NamespaceInfo
+	for _, ns := range namespaces {
+		if !ns.System || slices.Contains(include, ns.Name) || slices.Contains(include, "*") {
+			out = append(out, ns)
+		}
+	}
+	return out
 }
 
 // GenerateSecurity produces a table diagram and a coverage pie chart.
-func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
-	if len(data.Namespaces) == 0 {
+func GenerateSecurity(data *model.ClusterData, opts Options) []model.DiagramResult {
+	namespaces := visibleNamespaces("security", data.Namespaces, opts)
+	if len(namespaces) == 0 {
 		return []model.DiagramResult{{
 			ID:      "security",
 			Title:   "Security Matrix",
 			Type:    "markdown",
 			Content: "*No namespace data available.*",
+			Empty:   true,
+		}, {
+			ID:      "security-chart",
+			Title:   "Security Coverage",
+			Type:    "markdown",
+			Content: "*No namespace data available.*",
+			Empty:   true,
 		}}
 	}
 
@@ -70,8 +103,13 @@ func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
 		}
 	}
 
-	sorted := make([]model.NamespaceInfo, len(data.Namespaces))
-	copy(sorted, data.Namespaces)
+	// Namespaces with a LimitRange or ResourceQuota (cluster/namespace)
+	limitedNS := make(map[string]bool)
+	for _, q := range data.Quotas {
+		limitedNS[q.Cluster+"/"+q.Namespace] = true
+	}
+
+	sorted := namespaces
 	sort.Slice(sorted, func(i, j int) bool {
 		if sorted[i].Cluster != sorted[j].Cluster {
 			return sorted[i].Cluster < sorted[j].Cluster
@@ -79,8 +117,10 @@ func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
 		return sorted[i].Name < sorted[j].Name
 	})
 
+	now := time.Now()
 	var rows []SecurityRow
-	var ingressCount, ambientCount, mtlsCount, clientMTLSCount, authCount, backupCount int
+	var ingressCount, ambientCount, mtlsCount, clientMTLSCount, authCount, backupCount, limitedCount int
+	columnCounts := make([]int, len(opts.NamespaceLabelColumns))
 
 	for _, ns := range sorted {
 		nsKey := ns.Cluster + "/" + ns.Name
@@ -109,11 +149,15 @@ func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
 		if extAuthNS[nsKey] {
 			authCount++
 		}
-		if ns.Backup {
+		backup, lastBackup := namespaceBackup(ns, now, opts.BackupMaxAge)
+		if backup == "yes" {
 			backupCount++
 		}
+		if limitedNS[nsKey] {
+			limitedCount++
+		}
 
-		rows = append(rows, SecurityRow{
+		row := SecurityRow{
 			Cluster:     ns.Cluster,
 			Namespace:   ns.Name,
 			Ingress:     boolIcon(ingressNS[nsKey]),
@@ -121,9 +165,22 @@ func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
 			MTLS:        boolIcon(ns.MTLS),
 			MTLSClient:  cmtls,
 			ExtAuth:     boolIcon(extAuthNS[nsKey]),
-			Backup:      boolIcon(ns.Backup),
+			Backup:      backup,
+			LastBackup:  lastBackup,
 			PodSecurity: podSec,
-		})
+			Limited:     boolIcon(limitedNS[nsKey]),
+		}
+		if len(opts.NamespaceLabelColumns) > 0 {
+			row.Columns = make(map[string]string, len(opts.NamespaceLabelColumns))
+			for i, c := range opts.NamespaceLabelColumns {
+				match := c.Matches(ns.Labels)
+				if match {
+					columnCounts[i]++
+				}
+				row.Columns[c.Name] = boolIcon(match)
+			}
+		}
+		rows = append(rows, row)
 	}
 
 	tableJSON, _ := json.Marshal(rows)
@@ -137,6 +194,10 @@ func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
 	fmt.Fprintf(&b, "  \"Ext Auth\" : %d\n", authCount)
 	fmt.Fprintf(&b, "  \"mTLS Mesh\" : %d\n", mtlsCount)
 	fmt.Fprintf(&b, "  \"mTLS Client\" : %d\n", clientMTLSCount)
+	fmt.Fprintf(&b, "  \"Resource Limits\" : %d\n", limitedCount)
+	for i, c := range opts.NamespaceLabelColumns {
+		fmt.Fprintf(&b, "  %q : %d\n", c.Name, columnCounts[i])
+	}
 
 	return []model.DiagramResult{
 		{
@@ -154,6 +215,23 @@ func GenerateSecurity(data *model.ClusterData) []model.DiagramResult {
 	}
 }
 
+// namespaceBackup returns a namespace's Backup cell and the age of its last
+// completed backup. With Velero Backups listed, what they cover is what
+// counts; otherwise the backup: velero label is taken at its word.
+func namespaceBackup(ns model.NamespaceInfo, now time.Time, maxAge time.Duration) (backup, age string) {
+	if !ns.BackupsListed {
+		return boolIcon(ns.Backup), ""
+	}
+	if ns.LastBackup.IsZero() {
+		return "no", ""
+	}
+	d := now.Sub(ns.LastBackup)
+	if d > maxAge {
+		return "stale", formatAge(d)
+	}
+	return "yes", formatAge(d)
+}
+
 func boolIcon(v bool) string {
 	if v {
 		return "yes"
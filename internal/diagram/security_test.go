@@ -0,0 +1,157 @@
+package diagram
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestGenerateSecurityLabelColumns(t *testing.T) {
+	opts := DefaultOptions()
+	opts.NamespaceLabelColumns = []model.LabelColumn{
+		{Name: "PCI", Key: "compliance", Value: "pci"},
+		{Name: "Cost center", Key: "cost-center"},
+	}
+
+	data := &model.ClusterData{
+		Namespaces: []model.NamespaceInfo{
+			{Name: "payments", Cluster: "Homelab", Backup: true, Labels: map[string]string{"compliance": "pci", "cost-center": "42"}},
+			{Name: "blog", Cluster: "Homelab", Labels: map[string]string{"compliance": "none"}},
+			{Name: "scratch", Cluster: "Homelab"},
+		},
+	}
+
+	results := GenerateSecurity(data, opts)
+	var rows []SecurityRow
+	if err := json.Unmarshal([]byte(results[0].Content), &rows); err != nil {
+		t.Fatalf("decoding security table: %v", err)
+	}
+	byNS := make(map[string]SecurityRow)
+	for _, r := range rows {
+		byNS[r.Namespace] = r
+	}
+
+	tests := []struct {
+		ns, column, want string
+	}{
+		{"payments", "PCI", "yes"},
+		{"payments", "Cost center", "yes"},
+		{"blog", "PCI", "no"},
+		{"blog", "Cost center", "no"},
+		{"scratch", "PCI", "no"},
+	}
+	for _, tt := range tests {
+		if got := byNS[tt.ns].Columns[tt.column]; got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.ns, tt.column, got, tt.want)
+		}
+	}
+	if got := byNS["payments"].Backup; got != "yes" {
+		t.Errorf("built-in Backup column = %q, want yes", got)
+	}
+	if !strings.Contains(results[1].Content, `"PCI" : 1`) {
+		t.Errorf("coverage chart missing PCI slice:\n%s", results[1].Content)
+	}
+}
+
+func TestGenerateSecurityLimited(t *testing.T) {
+	data := &model.ClusterData{
+		Namespaces: []model.NamespaceInfo{
+			{Name: "payments", Cluster: "Homelab"},
+			{Name: "scratch", Cluster: "Homelab"},
+			{Name: "payments", Cluster: "NAS"},
+		},
+		Quotas: []model.QuotaInfo{
+			{Name: "defaults", Namespace: "payments", Cluster: "Homelab", Kind: "LimitRange"},
+		},
+	}
+
+	results := GenerateSecurity(data, DefaultOptions())
+	var rows []SecurityRow
+	if err := json.Unmarshal([]byte(results[0].Content), &rows); err != nil {
+		t.Fatalf("decoding security table: %v", err)
+	}
+	got := make(map[string]string)
+	for _, r := range rows {
+		got[r.Cluster+"/"+r.Namespace] = r.Limited
+	}
+	want := map[string]string{"Homelab/payments": "yes", "Homelab/scratch": "no", "NAS/payments": "no"}
+	for ns, w := range want {
+		if got[ns] != w {
+			t.Errorf("%s limited = %q, want %q", ns, got[ns], w)
+		}
+	}
+	if !strings.Contains(results[1].Content, `"Resource Limits" : 1`) {
+		t.Errorf("coverage chart missing Resource Limits slice:\n%s", results[1].Content)
+	}
+}
+
+func TestGenerateSecurityVeleroBackups(t *testing.T) {
+	now := time.Now()
+	data := &model.ClusterData{
+		Namespaces: []model.NamespaceInfo{
+			{Name: "fresh", Cluster: "Homelab", BackupsListed: true, LastBackup: now.Add(-5 * time.Hour)},
+			{Name: "old", Cluster: "Homelab", Backup: true, BackupsListed: true, LastBackup: now.Add(-72 * time.Hour)},
+			{Name: "labelled", Cluster: "Homelab", Backup: true, BackupsListed: true},
+			{Name: "labelled", Cluster: "NAS", Backup: true}, // no Velero: the label is all there is
+		},
+	}
+
+	results := GenerateSecurity(data, DefaultOptions())
+	var rows []SecurityRow
+	if err := json.Unmarshal([]byte(results[0].Content), &rows); err != nil {
+		t.Fatalf("decoding security table: %v", err)
+	}
+	got := make(map[string][2]string)
+	for _, r := range rows {
+		got[r.Cluster+"/"+r.Namespace] = [2]string{r.Backup, r.LastBackup}
+	}
+	want := map[string][2]string{
+		"Homelab/fresh":    {"yes", "5h"},
+		"Homelab/old":      {"stale", "3d"},
+		"Homelab/labelled": {"no", ""},
+		"NAS/labelled":     {"yes", ""},
+	}
+	for ns, w := range want {
+		if got[ns] != w {
+			t.Errorf("%s backup, last backup = %q, want %q", ns, got[ns], w)
+		}
+	}
+	if !strings.Contains(results[1].Content, `"Velero Backup" : 2`) {
+		t.Errorf("coverage chart should count the fresh and label-only namespaces:\n%s", results[1].Content)
+	}
+}
+
+func TestGenerateSecuritySystemNamespaces(t *testing.T) {
+	opts := DefaultOptions()
+	data := &model.ClusterData{
+		Namespaces: []model.NamespaceInfo{
+			{Name: "blog", Cluster: "Homelab"},
+			{Name: "default", Cluster: "Homelab", System: true},
+			{Name: "kube-system", Cluster: "Homelab", System: true},
+		},
+	}
+	namespaces := func() []string {
+		t.Helper()
+		var rows []SecurityRow
+		if err := json.Unmarshal([]byte(GenerateSecurity(data, opts)[0].Content), &rows); err != nil {
+			t.Fatalf("decoding security table: %v", err)
+		}
+		var names []string
+		for _, r := range rows {
+			names = append(names, r.Namespace)
+		}
+		return names
+	}
+
+	if got := strings.Join(namespaces(), ","); got != "blog" {
+		t.Errorf("default namespaces = %s, want only blog", got)
+	}
+
+	opts.IncludeSystemNamespaces = map[string][]string{"security": {"default"}, "topology": {"kube-system"}}
+	if got := strings.Join(namespaces(), ","); got != "blog,default" {
+		t.Errorf("namespaces with default included = %s, want blog,default", got)
+	}
+}
@@ -26,6 +26,7 @@ func GenerateServiceMap(data *model.ClusterData) model.DiagramResult {
 			Title:   "Service Mapping",
 			Type:    "markdown",
 			Content: "*No service data available.*",
+			Empty:   true,
 		}
 	}
 
@@ -69,6 +70,7 @@ func GenerateServiceMap(data *model.ClusterData) model.DiagramResult {
 			Title:   "Service Mapping",
 			Type:    "markdown",
 			Content: "*No services with selectors found.*",
+			Empty:   true,
 		}
 	}
 
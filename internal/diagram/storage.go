@@ -29,6 +29,7 @@ func GenerateStorage(data *model.ClusterData) model.DiagramResult {
 			Title:   "Storage",
 			Type:    "markdown",
 			Content: "*No storage data available.*",
+			Empty:   true,
 		}
 	}
 
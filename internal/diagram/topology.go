@@ -2,6 +2,8 @@ package diagram
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"golang.org/x/text/cases"
@@ -14,37 +16,42 @@ var titleCaser = cases.Title(language.English)
 
 // GenerateTopologySections produces one DiagramResult per InfraSource,
 // falling back to a single K8s-only diagram if no sources are configured.
-func GenerateTopologySections(data *model.ClusterData) []model.DiagramResult {
+func GenerateTopologySections(data *model.ClusterData, opts Options) []model.DiagramResult {
 	if len(data.InfraSources) == 0 {
-		return []model.DiagramResult{generateK8sOnlyTopology(data)}
+		return []model.DiagramResult{generateK8sOnlyTopology(data, opts), generateMeshTopology(data, opts)}
 	}
 
-	var results []model.DiagramResult
-
 	// Mesh topology first (east-west gateways + cross-cluster services)
-	if mesh := generateMeshTopology(data); mesh != nil {
-		results = append(results, *mesh)
-	}
+	results := []model.DiagramResult{generateMeshTopology(data, opts)}
 
 	for _, src := range data.InfraSources {
 		id := "topology-" + sanitizeID(src.Name)
 		switch src.Type {
 		case "tfstate":
-			results = append(results, generateTFSourceDiagram(id, src, data))
+			results = append(results, generateTFSourceDiagram(id, src, data, opts))
 		case "docker-compose":
-			results = append(results, generateDockerComposeDiagram(id, src))
+			results = append(results, generateDockerComposeDiagram(id, src, opts))
 		}
 	}
 
 	// Append K8s nodes not covered by any tfstate source
-	if extra := extraK8sNodes(data); len(extra) > 0 {
+	extra := extraK8sNodes(data)
+	if len(extra) == 0 {
+		results = append(results, model.DiagramResult{
+			ID:      "topology-other",
+			Title:   "Other Nodes",
+			Type:    "markdown",
+			Content: "*Every Kubernetes node is covered by a data source.*",
+			Empty:   true,
+		})
+	} else {
 		var b strings.Builder
 		b.WriteString("graph TB\n")
 		b.WriteString("  subgraph other[\"Other Kubernetes Nodes\"]\n")
 		b.WriteString("    direction TB\n")
 		for i, n := range extra {
 			id := fmt.Sprintf("ex%d", i)
-			label := fmt.Sprintf("%s<br/>%s / %s<br/>%s", n.Name, n.CPU, n.Memory, n.IP)
+			label := opts.nodeLabel(n.Name, n.CPU+" / "+n.Memory, n.IP)
 			fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, label)
 		}
 		b.WriteString("  end\n")
@@ -59,13 +66,26 @@ func GenerateTopologySections(data *model.ClusterData) []model.DiagramResult {
 	return results
 }
 
-func generateTFSourceDiagram(id string, src model.InfraSource, data *model.ClusterData) model.DiagramResult {
+func generateTFSourceDiagram(id string, src model.InfraSource, data *model.ClusterData, opts Options) model.DiagramResult {
 	var b strings.Builder
 	b.WriteString("graph TB\n")
 	fmt.Fprintf(&b, "  subgraph cluster[\"%s\"]\n", src.Name)
 	b.WriteString("    direction TB\n")
 
+	// VMs are grouped by the physical host they run on, when known, so
+	// the failure domains show; VMs without a host come last, ungrouped.
+	byHost := make(map[string][]int)
+	var hosts []string
 	for i, node := range src.TerraformNodes {
+		if _, ok := byHost[node.Host]; !ok && node.Host != "" {
+			hosts = append(hosts, node.Host)
+		}
+		byHost[node.Host] = append(byHost[node.Host], i)
+	}
+	sort.Strings(hosts)
+
+	writeNode := func(i int, indent string) {
+		node := src.TerraformNodes[i]
 		nodeID := fmt.Sprintf("tf%d", i)
 		memGB := float64(node.MemoryMB) / 1024.0
 
@@ -91,16 +111,32 @@ func generateTFSourceDiagram(id string, src model.InfraSource, data *model.Clust
 			role = "worker"
 		}
 
-		label := fmt.Sprintf("%s<br/>%s<br/>%s",
-			node.Name,
-			titleCaser.String(role),
-			strings.Join(details, " / "),
-		)
+		lines := []string{titleCaser.String(role), strings.Join(details, " / ")}
 		if node.IP != "" {
-			label += "<br/>" + node.IP
+			lines = append(lines, node.IP)
+		}
+		label := opts.nodeLabel(node.Name, lines...)
+
+		fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, nodeID, label)
+	}
+	for h, host := range hosts {
+		fmt.Fprintf(&b, "    subgraph host%d[\"%s\"]\n", h, escapeLabel(host))
+		for _, i := range byHost[host] {
+			writeNode(i, "      ")
 		}
+		b.WriteString("    end\n")
+	}
+	for _, i := range byHost[""] {
+		writeNode(i, "    ")
+	}
 
-		fmt.Fprintf(&b, "    %s[\"%s\"]\n", nodeID, label)
+	// Outputs (cluster VIP, API endpoint, ...) as a caption node.
+	if len(src.TerraformOutputs) > 0 {
+		lines := make([]string, 0, len(src.TerraformOutputs))
+		for _, o := range src.TerraformOutputs {
+			lines = append(lines, o.Name+": "+o.Value)
+		}
+		fmt.Fprintf(&b, "    tfoutputs[/\"%s\"/]\n", opts.nodeLabel("Outputs", lines...))
 	}
 
 	b.WriteString("  end\n")
@@ -113,13 +149,14 @@ func generateTFSourceDiagram(id string, src model.InfraSource, data *model.Clust
 	}
 }
 
-func generateDockerComposeDiagram(id string, src model.InfraSource) model.DiagramResult {
+func generateDockerComposeDiagram(id string, src model.InfraSource, opts Options) model.DiagramResult {
 	dc := src.DockerCompose
 	var b strings.Builder
 	b.WriteString("graph TB\n")
 	fmt.Fprintf(&b, "  subgraph host[\"%s\"]\n", src.Name)
 	b.WriteString("    direction TB\n")
 
+	var risky []string
 	for i, svc := range dc.Services {
 		svcID := fmt.Sprintf("svc%d", i)
 
@@ -142,19 +179,31 @@ func generateDockerComposeDiagram(id string, src model.InfraSource) model.Diagra
 			hostname = svc.Name
 		}
 
-		label := hostname
-		if len(details) > 0 {
-			label += "<br/>" + strings.Join(details, "<br/>")
-		}
 		if len(svc.Volumes) > 0 {
 			// Show volume count to avoid overly long labels
-			label += fmt.Sprintf("<br/>%d volume(s)", len(svc.Volumes))
+			details = append(details, fmt.Sprintf("%d volume(s)", len(svc.Volumes)))
 		}
+		// Host paths that hand over the host are listed one by one.
+		sensitive := false
+		for _, m := range svc.Mounts {
+			if m.Sensitive {
+				sensitive = true
+				details = append(details, "⚠ host "+m.Source+mountMode(m))
+			}
+		}
+		label := opts.nodeLabel(hostname, details...)
 
 		fmt.Fprintf(&b, "    %s[\"%s\"]\n", svcID, label)
+		if svc.Privileged || sensitive {
+			risky = append(risky, svcID)
+		}
 	}
 
 	b.WriteString("  end\n")
+	if len(risky) > 0 {
+		b.WriteString("  classDef risky stroke:#dc2626,stroke-width:2px\n")
+		fmt.Fprintf(&b, "  class %s risky\n", strings.Join(risky, ","))
+	}
 
 	return model.DiagramResult{
 		ID:      id,
@@ -164,17 +213,29 @@ func generateDockerComposeDiagram(id string, src model.InfraSource) model.Diagra
 	}
 }
 
-func generateK8sOnlyTopology(data *model.ClusterData) model.DiagramResult {
+// mountMode returns " (ro)" for a read-only mount, "" otherwise.
+func mountMode(m model.DockerMount) string {
+	if m.ReadOnly {
+		return " (ro)"
+	}
+	return ""
+}
+
+func generateK8sOnlyTopology(data *model.ClusterData, opts Options) model.DiagramResult {
 	var b strings.Builder
 	b.WriteString("graph TB\n")
 
-	if len(data.Nodes) == 0 {
+	empty := len(data.Nodes) == 0
+	if empty {
 		b.WriteString("  empty[\"No node information available\"]\n")
 	} else {
 		b.WriteString("  subgraph cluster[\"Kubernetes Cluster\"]\n")
 		b.WriteString("    direction TB\n")
 
-		for i, node := range data.Nodes {
+		placement := podPlacement(data, opts.PlacementLimit)
+		var notReady, cordoned bool
+		writeNode := func(i int, indent string) {
+			node := data.Nodes[i]
 			id := fmt.Sprintf("n%d", i)
 			role := "Worker"
 			for _, r := range node.Roles {
@@ -184,19 +245,60 @@ func generateK8sOnlyTopology(data *model.ClusterData) model.DiagramResult {
 				}
 			}
 
-			label := fmt.Sprintf("%s<br/>%s<br/>CPU: %s / Mem: %s<br/>%s",
-				node.Name, role, node.CPU, node.Memory, node.IP)
-
+			lines := []string{role, fmt.Sprintf("CPU: %s / Mem: %s", node.CPU, node.Memory), node.IP}
+			if !node.Ready || !node.Schedulable {
+				lines = append(lines, nodeStatus(node))
+			}
+			for _, c := range node.Conditions {
+				lines = append(lines, "Condition: "+c)
+			}
 			for k, v := range node.Labels {
 				if strings.Contains(strings.ToLower(k), "gpu") {
-					label += fmt.Sprintf("<br/>GPU: %s", v)
+					lines = append(lines, "GPU: "+v)
 				}
 			}
+			for _, t := range node.Taints {
+				lines = append(lines, "Taint: "+t.String())
+			}
+			label := opts.nodeLabel(node.Name, lines...)
 
-			fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, label)
+			if groups := placement[nodeRef{node.Cluster, node.Name}]; len(groups) > 0 {
+				writePlacement(&b, indent, id, label, groups, opts)
+			} else {
+				fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, id, label)
+			}
+			switch {
+			case !node.Ready:
+				fmt.Fprintf(&b, "%sclass %s notReady\n", indent, id)
+				notReady = true
+			case !node.Schedulable:
+				fmt.Fprintf(&b, "%sclass %s cordoned\n", indent, id)
+				cordoned = true
+			}
+		}
+
+		if groups := nodeGroups(data.Nodes, opts.TopologyGroupLabel); len(groups) > 0 {
+			for gi, g := range groups {
+				fmt.Fprintf(&b, "    subgraph g%d[\"%s\"]\n", gi, g.name)
+				b.WriteString("      direction TB\n")
+				for _, i := range g.nodes {
+					writeNode(i, "      ")
+				}
+				b.WriteString("    end\n")
+			}
+		} else {
+			for i := range data.Nodes {
+				writeNode(i, "    ")
+			}
 		}
 
 		b.WriteString("  end\n")
+		if notReady {
+			b.WriteString("  classDef notReady stroke:#dc2626,stroke-width:2px\n")
+		}
+		if cordoned {
+			b.WriteString("  classDef cordoned stroke:#d97706,stroke-width:2px,stroke-dasharray:4\n")
+		}
 	}
 
 	return model.DiagramResult{
@@ -204,10 +306,138 @@ func generateK8sOnlyTopology(data *model.ClusterData) model.DiagramResult {
 		Title:   "Physical Topology",
 		Type:    "mermaid",
 		Content: b.String(),
+		Empty:   empty,
+	}
+}
+
+// nodeGroup is one Options.TopologyGroupLabel value and the indices of its
+// nodes.
+type nodeGroup struct {
+	name  string
+	nodes []int
+}
+
+// nodeGroups groups node indices by their value of label, sorted by value,
+// with unlabeled nodes last. Returns nil when label is empty or no node
+// carries it, so the topology falls back to one subgraph.
+func nodeGroups(nodes []model.NodeInfo, label string) []nodeGroup {
+	if label == "" {
+		return nil
+	}
+	byValue := make(map[string][]int)
+	var unlabeled []int
+	for i, n := range nodes {
+		if v := n.Labels[label]; v != "" {
+			byValue[v] = append(byValue[v], i)
+		} else {
+			unlabeled = append(unlabeled, i)
+		}
+	}
+	if len(byValue) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(byValue))
+	for v := range byValue {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	groups := make([]nodeGroup, 0, len(values)+1)
+	for _, v := range values {
+		groups = append(groups, nodeGroup{name: v, nodes: byValue[v]})
+	}
+	if len(unlabeled) > 0 {
+		groups = append(groups, nodeGroup{name: "No " + label, nodes: unlabeled})
 	}
+	return groups
 }
 
-func generateMeshTopology(data *model.ClusterData) *model.DiagramResult {
+// nodeRef identifies a node across clusters.
+type nodeRef struct{ cluster, name string }
+
+// placementGroup counts the pods of one workload scheduled on a node.
+type placementGroup struct {
+	namespace string
+	workload  string // "Kind/name", or the pod name for bare pods
+	pods      int
+}
+
+// podPlacement groups scheduled pods by node and owning workload. Each node's groups are sorted largest first. Returns
+// nil when a non-positive limit disables the overlay.
+func podPlacement(data *model.ClusterData, limit int) map[nodeRef][]placementGroup {
+	if limit <= 0 {
+		return nil
+	}
+	type groupKey struct {
+		node                nodeRef
+		namespace, workload string
+	}
+	counts := make(map[groupKey]int)
+	seen := make(map[string]bool) // PodImageInfo is per container
+	for _, p := range data.Pods {
+		if p.NodeName == "" {
+			continue
+		}
+		pod := p.Cluster + "/" + p.Namespace + "/" + p.PodName
+		if seen[pod] {
+			continue
+		}
+		seen[pod] = true
+		workload := p.Owner
+		if workload == "" {
+			workload = p.PodName
+		}
+		counts[groupKey{nodeRef{p.Cluster, p.NodeName}, p.Namespace, workload}]++
+	}
+
+	out := make(map[nodeRef][]placementGroup)
+	for k, n := range counts {
+		out[k.node] = append(out[k.node], placementGroup{namespace: k.namespace, workload: k.workload, pods: n})
+	}
+	for _, groups := range out {
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].pods != groups[j].pods {
+				return groups[i].pods > groups[j].pods
+			}
+			if groups[i].namespace != groups[j].namespace {
+				return groups[i].namespace < groups[j].namespace
+			}
+			return groups[i].workload < groups[j].workload
+		})
+	}
+	return out
+}
+
+// writePlacement draws a node as a subgraph holding its top
+// opts.PlacementLimit workloads, plus one entry summarizing the rest.
+func writePlacement(b *strings.Builder, indent, id, label string, groups []placementGroup, opts Options) {
+	fmt.Fprintf(b, "%ssubgraph %s[\"%s\"]\n", indent, id, label)
+	fmt.Fprintf(b, "%s  direction TB\n", indent)
+	shown := groups
+	if len(shown) > opts.PlacementLimit {
+		shown = shown[:opts.PlacementLimit]
+	}
+	for i, g := range shown {
+		fmt.Fprintf(b, "%s  %s_w%d[\"%s\"]\n", indent, id, i, opts.nodeLabel(g.workload, g.namespace, podCount(g.pods)))
+	}
+	if rest := groups[len(shown):]; len(rest) > 0 {
+		pods := 0
+		for _, g := range rest {
+			pods += g.pods
+		}
+		fmt.Fprintf(b, "%s  %s_more[\"+%d more<br/>%s\"]\n", indent, id, len(rest), podCount(pods))
+	}
+	fmt.Fprintf(b, "%send\n", indent)
+}
+
+func podCount(n int) string {
+	if n == 1 {
+		return "1 pod"
+	}
+	return fmt.Sprintf("%d pods", n)
+}
+
+func generateMeshTopology(data *model.ClusterData, opts Options) model.DiagramResult {
 	// Filter to MESH_EXTERNAL service entries (cross-cluster)
 	var crossCluster []model.ServiceEntryInfo
 	for _, se := range data.ServiceEntries {
@@ -217,7 +447,13 @@ func generateMeshTopology(data *model.ClusterData) *model.DiagramResult {
 	}
 
 	if len(data.EastWestGateways) == 0 && len(crossCluster) == 0 {
-		return nil
+		return model.DiagramResult{
+			ID:      "topology-mesh",
+			Title:   "Mesh Topology",
+			Type:    "markdown",
+			Content: "*No east-west gateways or cross-cluster services found.*",
+			Empty:   true,
+		}
 	}
 
 	// Build network-to-name map from InfraSources
@@ -239,9 +475,17 @@ func generateMeshTopology(data *model.ClusterData) *model.DiagramResult {
 		break
 	}
 
+	services := make([]meshService, len(crossCluster))
+	for i, se := range crossCluster {
+		services[i] = meshService{ServiceEntryInfo: se}
+	}
+	if opts.MergeMeshServiceEntries {
+		services = mergeReciprocalServiceEntries(crossCluster, data.EastWestGateways, localNetwork)
+	}
+
 	// Collect remote networks from service entries
 	remoteNetworks := make(map[string]string) // network → gateway IP
-	for _, se := range crossCluster {
+	for _, se := range services {
 		if se.Network != localNetwork {
 			remoteNetworks[se.Network] = se.EndpointAddress
 		}
@@ -291,28 +535,40 @@ func generateMeshTopology(data *model.ClusterData) *model.DiagramResult {
 	}
 
 	// Cross-cluster services subgraph
-	if len(crossCluster) > 0 {
+	if len(services) > 0 {
 		b.WriteString("  subgraph xcluster[\"Cross-Cluster Services\"]\n")
-		for i, se := range crossCluster {
+		for i, se := range services {
 			seID := fmt.Sprintf("se%d", i)
-			host := strings.Join(se.Hosts, ", ")
-			fmt.Fprintf(&b, "    %s[\"%s\"]\n", seID, host)
+			label := strings.Join(se.Hosts, ", ")
+			if len(se.Ports) > 0 {
+				ports := make([]string, len(se.Ports))
+				for i, p := range se.Ports {
+					ports[i] = strconv.Itoa(p)
+				}
+				label += "<br/>port " + strings.Join(ports, ", ")
+			}
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", seID, label)
 		}
 		b.WriteString("  end\n")
 
-		// Arrows: local gateway → service → remote gateway
-		for i, se := range crossCluster {
+		// Arrows: local gateway → service → remote gateway, both ways for
+		// merged reciprocal entries.
+		for i, se := range services {
 			seID := fmt.Sprintf("se%d", i)
+			arrow := "-->"
+			if se.bidirectional {
+				arrow = "<-->"
+			}
 			if hasLocalGW {
-				fmt.Fprintf(&b, "  ewgw_l0 --> %s\n", seID)
+				fmt.Fprintf(&b, "  ewgw_l0 %s %s\n", arrow, seID)
 			}
 			if rgw, ok := remoteGwIDs[se.Network]; ok {
-				fmt.Fprintf(&b, "  %s --> %s\n", seID, rgw)
+				fmt.Fprintf(&b, "  %s %s %s\n", seID, arrow, rgw)
 			}
 		}
 	}
 
-	return &model.DiagramResult{
+	return model.DiagramResult{
 		ID:      "topology-mesh",
 		Title:   "Mesh Topology",
 		Type:    "mermaid",
@@ -320,6 +576,59 @@ func generateMeshTopology(data *model.ClusterData) *model.DiagramResult {
 	}
 }
 
+// meshService is a cross-cluster service node of the mesh topology.
+type meshService struct {
+	model.ServiceEntryInfo
+	bidirectional bool // merged with its reciprocal entry in the other cluster
+}
+
+// mergeReciprocalServiceEntries merges entries for the same hosts whose
+// networks mirror each other: cluster A pointing at B's network and cluster B
+// pointing at A's. A cluster's own network comes from its east-west gateway,
+// falling back to the "<cluster>-network" convention. The merged node keeps
+// the entry pointing away from localNetwork so its remote gateway resolves.
+func mergeReciprocalServiceEntries(entries []model.ServiceEntryInfo, gateways []model.EastWestGateway, localNetwork string) []meshService {
+	ownNetwork := make(map[string]string) // cluster → network
+	for _, gw := range gateways {
+		if gw.Network != "" {
+			ownNetwork[gw.Cluster] = gw.Network
+		}
+	}
+	networkOf := func(cluster string) string {
+		if n, ok := ownNetwork[cluster]; ok {
+			return n
+		}
+		return strings.ToLower(cluster) + "-network"
+	}
+
+	var out []meshService
+	index := make(map[string]int) // canonical key → position in out
+	for _, se := range entries {
+		hosts := append([]string(nil), se.Hosts...)
+		sort.Strings(hosts)
+		from, to := networkOf(se.Cluster), se.Network
+		if from > to {
+			from, to = to, from
+		}
+		key := strings.Join(hosts, ",") + "|" + from + "↔" + to
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, meshService{ServiceEntryInfo: se})
+			continue
+		}
+		if out[i].Cluster == se.Cluster {
+			continue // duplicate within one cluster, not a reciprocal
+		}
+		out[i].bidirectional = true
+		if out[i].Network == localNetwork {
+			out[i].ServiceEntryInfo = se
+		}
+	}
+	return out
+}
+
 // extraK8sNodes returns K8s nodes not present in any tfstate source.
 func extraK8sNodes(data *model.ClusterData) []model.NodeInfo {
 	tfNames := make(map[string]bool)
@@ -0,0 +1,285 @@
+package diagram
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestComposeLabelTruncatesLongImage(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxLabelLength = 32
+
+	image := "registry.example.internal:5000/platform/observability/very-long-exporter-name:v1.2.3-alpine"
+	src := model.InfraSource{
+		Name: "nas",
+		Type: "docker-compose",
+		DockerCompose: &model.DockerCompose{Services: []model.DockerService{{
+			Name:    "exporter",
+			Image:   image,
+			Volumes: []string{"/a:/a", "/b:/b"},
+		}}},
+	}
+
+	got := generateDockerComposeDiagram("topology-nas", src, opts).Content
+
+	nodeLine := regexp.MustCompile(`(?m)^    svc0\["([^"]*)"\]$`)
+	m := nodeLine.FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("no valid mermaid node line for svc0 in:\n%s", got)
+	}
+	lines := strings.Split(m[1], "<br/>")
+	if lines[0] != "exporter" {
+		t.Errorf("name line = %q, want exporter kept whole", lines[0])
+	}
+
+	img := lines[1]
+	if n := utf8.RuneCountInString(img); n != 32 {
+		t.Errorf("image line %q has %d runes, want 32", img, n)
+	}
+	if !strings.Contains(img, "…") {
+		t.Errorf("image line %q has no ellipsis", img)
+	}
+	if !strings.HasPrefix(img, "registry.example") || !strings.HasSuffix(img, "3-alpine") {
+		t.Errorf("image line %q lost its registry or tag", img)
+	}
+	if lines[2] != "2 volume(s)" {
+		t.Errorf("volumes line = %q, want 2 volume(s)", lines[2])
+	}
+}
+
+func TestComposeFlagsSensitiveMounts(t *testing.T) {
+	src := model.InfraSource{
+		Name: "nas",
+		Type: "docker-compose",
+		DockerCompose: &model.DockerCompose{Services: []model.DockerService{
+			{
+				Name:    "portainer",
+				Volumes: []string{"/var/run/docker.sock:/var/run/docker.sock", "./data:/data"},
+				Mounts: []model.DockerMount{
+					{Type: "bind", Source: "/var/run/docker.sock", Target: "/var/run/docker.sock", Sensitive: true},
+					{Type: "bind", Source: "./data", Target: "/data"},
+				},
+			},
+			{
+				Name:    "web",
+				Volumes: []string{"named_vol:/data"},
+				Mounts:  []model.DockerMount{{Type: "volume", Source: "named_vol", Target: "/data"}},
+			},
+		}},
+	}
+
+	got := generateDockerComposeDiagram("topology-nas", src, DefaultOptions()).Content
+	if !strings.Contains(got, `svc0["portainer<br/>2 volume(s)<br/>⚠ host /var/run/docker.sock"]`) {
+		t.Errorf("docker.sock mount not listed on portainer:\n%s", got)
+	}
+	if !strings.Contains(got, "class svc0 risky\n") {
+		t.Errorf("portainer not flagged risky, web must not be:\n%s", got)
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"abcdefghijkl", 5, "ab…kl"},
+		{"abcdefghijkl", 6, "abc…kl"},
+		{"abcdefghijkl", 1, "…"},
+		{"abcdefghijkl", 0, "abcdefghijkl"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateMiddle(tt.in, tt.max); got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestK8sTopologyPlacement(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PlacementLimit = 2
+
+	pod := func(node, ns, name, owner string) model.PodImageInfo {
+		return model.PodImageInfo{Cluster: "Homelab", Namespace: ns, PodName: name, Container: "main", NodeName: node, Owner: owner}
+	}
+	data := &model.ClusterData{
+		Nodes: []model.NodeInfo{
+			{Name: "worker-1", Cluster: "Homelab"},
+			{Name: "worker-2", Cluster: "Homelab"},
+			{Name: "idle", Cluster: "Homelab"},
+		},
+		Pods: []model.PodImageInfo{
+			pod("worker-1", "apps", "web-a", "Deployment/web"),
+			pod("worker-1", "apps", "web-b", "Deployment/web"),
+			pod("worker-1", "apps", "web-c", "Deployment/web"),
+			{Cluster: "Homelab", Namespace: "apps", PodName: "web-c", Container: "sidecar", NodeName: "worker-1", Owner: "Deployment/web"},
+			pod("worker-1", "db", "pg-0", "StatefulSet/pg"),
+			pod("worker-1", "db", "pg-1", "StatefulSet/pg"),
+			pod("worker-1", "monitoring", "node-exporter-x", "DaemonSet/node-exporter"),
+			pod("worker-1", "default", "debug", ""),
+			pod("worker-2", "monitoring", "node-exporter-y", "DaemonSet/node-exporter"),
+			pod("", "apps", "web-pending", "Deployment/web"),
+		},
+	}
+
+	got := generateK8sOnlyTopology(data, opts).Content
+
+	for _, want := range []string{
+		"    subgraph n0[\"worker-1",
+		"      n0_w0[\"Deployment/web<br/>apps<br/>3 pods\"]",
+		"      n0_w1[\"StatefulSet/pg<br/>db<br/>2 pods\"]",
+		"      n0_more[\"+2 more<br/>2 pods\"]",
+		"    subgraph n1[\"worker-2",
+		"      n1_w0[\"DaemonSet/node-exporter<br/>monitoring<br/>1 pod\"]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("topology missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "n0_w2") {
+		t.Errorf("worker-1 lists more than %d workloads:\n%s", opts.PlacementLimit, got)
+	}
+	if strings.Contains(got, "subgraph n2") || !strings.Contains(got, "    n2[\"idle") {
+		t.Errorf("a node without pods should stay a plain node:\n%s", got)
+	}
+
+	opts.PlacementLimit = 0
+	if got := generateK8sOnlyTopology(data, opts).Content; strings.Contains(got, "_w0") {
+		t.Errorf("placement drawn while disabled:\n%s", got)
+	}
+}
+
+func TestK8sTopologyGroupsByLabel(t *testing.T) {
+	const zone = "topology.kubernetes.io/zone"
+	node := func(name, z string) model.NodeInfo {
+		n := model.NodeInfo{Name: name, Cluster: "Homelab", Ready: true, Schedulable: true}
+		if z != "" {
+			n.Labels = map[string]string{zone: z}
+		}
+		return n
+	}
+	data := &model.ClusterData{Nodes: []model.NodeInfo{
+		node("worker-1", "zone-b"),
+		node("worker-2", "zone-a"),
+		node("worker-3", "zone-b"),
+	}}
+
+	opts := DefaultOptions()
+	opts.TopologyGroupLabel = zone
+	got := generateK8sOnlyTopology(data, opts).Content
+	if n := strings.Count(got, "    subgraph g"); n != 2 {
+		t.Fatalf("zone subgraphs = %d, want 2:\n%s", n, got)
+	}
+	a := strings.Index(got, `subgraph g0["zone-a"]`)
+	b := strings.Index(got, `subgraph g1["zone-b"]`)
+	if a < 0 || b < 0 {
+		t.Fatalf("zone subgraphs not sorted by zone:\n%s", got)
+	}
+	for _, n := range []struct {
+		line    string
+		inZoneB bool
+	}{{"      n0[\"worker-1", true}, {"      n1[\"worker-2", false}, {"      n2[\"worker-3", true}} {
+		i := strings.Index(got, n.line)
+		if i < 0 {
+			t.Errorf("node %q not nested in a zone:\n%s", n.line, got)
+		} else if (i > b) != n.inZoneB {
+			t.Errorf("node %q in the wrong zone:\n%s", n.line, got)
+		}
+	}
+
+	data.Nodes = append(data.Nodes, node("edge", ""))
+	if got := generateK8sOnlyTopology(data, opts).Content; !strings.Contains(got, `subgraph g2["No `+zone+`"]`) {
+		t.Errorf("unlabeled node not grouped last:\n%s", got)
+	}
+
+	data.Nodes = []model.NodeInfo{node("worker-1", ""), node("worker-2", "")}
+	if got := generateK8sOnlyTopology(data, opts).Content; strings.Contains(got, "subgraph g") || !strings.Contains(got, "    n0[\"worker-1") {
+		t.Errorf("want a single flat subgraph without the label:\n%s", got)
+	}
+}
+
+func TestTFSourceDiagramOutputs(t *testing.T) {
+	src := model.InfraSource{
+		Name:           "proxmox",
+		Type:           "tfstate",
+		TerraformNodes: []model.TerraformNode{{Name: "cp-1", Role: "control-plane", Cores: 4}},
+		TerraformOutputs: []model.TerraformOutput{
+			{Name: "cluster_vip", Value: "192.168.1.50"},
+			{Name: "control_plane_endpoint", Value: "https://10.0.0.10:6443"},
+		},
+	}
+
+	got := generateTFSourceDiagram("topology-proxmox", src, &model.ClusterData{}, DefaultOptions()).Content
+	want := `    tfoutputs[/"Outputs<br/>cluster_vip: 192.168.1.50<br/>control_plane_endpoint: https://10.0.0.10:6443"/]`
+	if !strings.Contains(got, want) {
+		t.Errorf("diagram missing outputs caption %q:\n%s", want, got)
+	}
+}
+
+func TestTFSourceDiagramGroupsByHost(t *testing.T) {
+	src := model.InfraSource{
+		Name: "proxmox",
+		Type: "tfstate",
+		TerraformNodes: []model.TerraformNode{
+			{Name: "cp-1", Role: "control-plane", Host: "pve1"},
+			{Name: "worker-1", Host: "pve2"},
+			{Name: "worker-2", Host: "pve1"},
+			{Name: "nas-vm"},
+		},
+	}
+
+	got := generateTFSourceDiagram("topology-proxmox", src, &model.ClusterData{}, DefaultOptions()).Content
+	want := "    subgraph host0[\"pve1\"]\n" +
+		"      tf0[\"cp-1<br/>Control-Plane<br/>\"]\n" +
+		"      tf2[\"worker-2<br/>Worker<br/>\"]\n" +
+		"    end\n" +
+		"    subgraph host1[\"pve2\"]\n" +
+		"      tf1[\"worker-1<br/>Worker<br/>\"]\n" +
+		"    end\n" +
+		"    tf3[\"nas-vm<br/>Worker<br/>\"]\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("diagram does not group VMs by host, want\n%s\nin:\n%s", want, got)
+	}
+}
+
+func TestMeshTopologyMergesReciprocalServiceEntries(t *testing.T) {
+	data := &model.ClusterData{
+		EastWestGateways: []model.EastWestGateway{{Name: "eastwest", Cluster: "Homelab", IP: "10.0.0.1", Port: 15443, Network: "homelab-network"}},
+		ServiceEntries: []model.ServiceEntryInfo{
+			// Each cluster reaches the other's vault through its gateway.
+			{Name: "nas-vault", Cluster: "Homelab", Hosts: []string{"vault.vault.svc.cluster.local"}, Location: "MESH_EXTERNAL", Network: "nas-network", EndpointAddress: "10.0.1.1"},
+			{Name: "homelab-vault", Cluster: "NAS", Hosts: []string{"vault.vault.svc.cluster.local"}, Location: "MESH_EXTERNAL", Network: "homelab-network", EndpointAddress: "10.0.0.1"},
+			// One-way: stays its own node.
+			{Name: "nas-minio", Cluster: "Homelab", Hosts: []string{"minio.minio.svc.cluster.local"}, Location: "MESH_EXTERNAL", Network: "nas-network", EndpointAddress: "10.0.1.1"},
+		},
+	}
+
+	opts := DefaultOptions()
+	if got := strings.Count(generateMeshTopology(data, opts).Content, "vault.vault.svc.cluster.local"); got != 2 {
+		t.Errorf("without merging, vault nodes = %d, want 2", got)
+	}
+
+	opts.MergeMeshServiceEntries = true
+	got := generateMeshTopology(data, opts).Content
+	if n := strings.Count(got, "vault.vault.svc.cluster.local"); n != 1 {
+		t.Errorf("vault nodes = %d, want 1 merged node:\n%s", n, got)
+	}
+	for _, want := range []string{
+		"    se0[\"vault.vault.svc.cluster.local\"]",
+		"  ewgw_l0 <--> se0",
+		"  se0 <--> ewgw_r0",
+		"  ewgw_l0 --> se1",
+		"  se1 --> ewgw_r0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("mesh topology missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 )
@@ -18,6 +19,7 @@ type VeleroRow struct {
 	ExcludedNS string `json:"excludedNS"`
 	TTL        string `json:"ttl"`
 	Phase      string `json:"phase"`
+	LastBackup string `json:"lastBackup"` // age of the last backup it started, e.g. "5h"; "-" if none
 }
 
 // GenerateVelero produces a table of Velero backup schedules.
@@ -28,11 +30,17 @@ func GenerateVelero(data *model.ClusterData) model.DiagramResult {
 			Title:   "Backup Schedules",
 			Type:    "markdown",
 			Content: "*No Velero schedule data available.*",
+			Empty:   true,
 		}
 	}
 
+	now := time.Now()
 	var rows []VeleroRow
 	for _, v := range data.VeleroSchedules {
+		lastBackup := "-"
+		if !v.LastBackup.IsZero() {
+			lastBackup = formatAge(now.Sub(v.LastBackup))
+		}
 		rows = append(rows, VeleroRow{
 			Name:       v.Name,
 			Namespace:  v.Namespace,
@@ -42,6 +50,7 @@ func GenerateVelero(data *model.ClusterData) model.DiagramResult {
 			ExcludedNS: strings.Join(v.ExcludedNS, ", "),
 			TTL:        v.TTL,
 			Phase:      v.Phase,
+			LastBackup: lastBackup,
 		})
 	}
 
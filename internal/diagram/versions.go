@@ -2,9 +2,12 @@ package diagram
 
 import (
 	"encoding/json"
+	"fmt"
+	"path"
 	"sort"
 	"strings"
 
+	"github.com/fredericrous/cluster-vision/internal/imageref"
 	"github.com/fredericrous/cluster-vision/internal/model"
 	"github.com/fredericrous/cluster-vision/internal/versions"
 )
@@ -16,23 +19,42 @@ type VersionRow struct {
 	Namespace string `json:"namespace"`
 	Chart     string `json:"chart"`
 	Version   string `json:"version"`
+	// Constraint is the HelmRelease's version range, or "*" for latest;
+	// empty for an exact pin. Latest and Outdated are then within the range.
+	Constraint string `json:"constraint,omitempty"`
 	Latest    string `json:"latest"`
+	// LatestOverall is the chart's latest version when it lies outside
+	// Constraint, i.e. an upgrade the range won't pick up.
+	LatestOverall string `json:"latestOverall,omitempty"`
 	Outdated  bool   `json:"outdated"`
+	CheckError   string `json:"checkError,omitempty"` // why the latest-version lookup failed; distinct from up to date
+	UpdateType   string `json:"updateType"` // "major" | "minor" | "patch" | "" when current or unknown
+	AppVersion   string `json:"appVersion"`   // chart appVersion from the release status
+	ImageTag     string `json:"imageTag"`     // tag of the release's main container (see releaseTag)
+	Drift        bool   `json:"drift"`        // ImageTag doesn't match AppVersion (values override or drift)
 	RepoType     string `json:"repoType"`
 	RepoURL      string `json:"repoUrl"`
 	SecurityRisk string `json:"securityRisk"` // "critical" | "warning" | "none" | ""
 	VulnSummary  string `json:"vulnSummary"`  // human-readable tooltip
+	Failures        int    `json:"failures,omitempty"`        // consecutive reconcile failures
+	InstallFailures int    `json:"installFailures,omitempty"` // failed install attempts since the last success
+	UpgradeFailures int    `json:"upgradeFailures,omitempty"` // failed upgrade attempts since the last success
+	Thrashing       bool   `json:"thrashing,omitempty"`       // stuck in a reconcile/remediation retry loop
+	PolicyMinimum   string `json:"policyMinimum,omitempty"`   // minimum version Options.VersionPolicy requires for the chart
+	PolicyViolation bool   `json:"policyViolation,omitempty"` // Version is below PolicyMinimum, whatever upstream's latest
 }
 
-// GenerateVersions produces a table of deployed HelmRelease versions.
-func GenerateVersions(data *model.ClusterData, checker *versions.Checker) model.DiagramResult {
+// GenerateVersions produces a table of deployed HelmRelease versions and a
+// drift pie chart counting releases by how far behind latest they are.
+func GenerateVersions(data *model.ClusterData, checker *versions.Checker, opts Options) []model.DiagramResult {
 	if len(data.HelmReleases) == 0 {
-		return model.DiagramResult{
+		return []model.DiagramResult{{
 			ID:      "charts",
 			Title:   "Helm Charts",
 			Type:    "markdown",
 			Content: "*No HelmRelease data available.*",
-		}
+			Empty:   true,
+		}, versionDriftChart(nil)}
 	}
 
 	// Build repo lookup: "cluster/namespace/name" → HelmRepositoryInfo
@@ -64,6 +86,8 @@ func GenerateVersions(data *model.ClusterData, checker *versions.Checker) model.
 		}
 	}
 
+	tagsByRelease := releaseImageTags(data.Pods)
+
 	// Sort releases by cluster, namespace, then name
 	sorted := make([]model.HelmReleaseInfo, len(data.HelmReleases))
 	copy(sorted, data.HelmReleases)
@@ -95,21 +119,32 @@ func GenerateVersions(data *model.ClusterData, checker *versions.Checker) model.
 			repoURL = "-"
 		}
 
+		// A range release is compared with the latest version within its
+		// range, the one Flux would upgrade it to.
 		latest := "-"
+		latestOverall := ""
 		outdated := false
+		updateType := ""
+		checkError := ""
 		if checker != nil {
-			if v := checker.GetLatest(repo.URL, rel.ChartName); v != "" {
+			checkError = checker.CheckError(repo.URL, rel.ChartName)
+			if v := checker.GetLatestMatching(repo.URL, rel.ChartName, rel.VersionConstraint); v != "" {
 				latest = v
-				if latest != rel.Version && rel.Version != "" {
+				if versions.IsOutdated(rel.Version, latest) && checker.SettledMatching(repo.URL, rel.ChartName, rel.VersionConstraint) {
 					outdated = true
+					updateType = versions.UpdateType(rel.Version, latest)
 				}
 			}
+			if v := checker.GetLatest(repo.URL, rel.ChartName); v != "" && v != latest && (latest == "-" || versions.IsOutdated(latest, v)) {
+				latestOverall = v
+			}
 		}
 
 		version := rel.Version
 		if version == "" {
 			version = "-"
 		}
+		policyMin := opts.VersionPolicy.ChartMinimum(rel.ChartName)
 
 		// Aggregate security risk across all images in this release's workloads
 		secRisk := ""
@@ -133,29 +168,191 @@ func GenerateVersions(data *model.ClusterData, checker *versions.Checker) model.
 			vulnSum = strings.Join(summaryParts, "; ")
 		}
 
+		imageTag, drift := releaseTag(tagsByRelease[relKey], rel)
+
 		rows = append(rows, VersionRow{
 			Cluster:      rel.Cluster,
 			Release:      rel.Name,
 			Namespace:    rel.Namespace,
 			Chart:        rel.ChartName,
 			Version:      version,
+			Constraint:   rel.VersionConstraint,
 			Latest:       latest,
+			LatestOverall: latestOverall,
 			Outdated:     outdated,
+			CheckError:   checkError,
+			UpdateType:   updateType,
+			AppVersion:   rel.AppVersion,
+			ImageTag:     imageTag,
+			Drift:        drift,
 			RepoType:     repoType,
 			RepoURL:      repoURL,
 			SecurityRisk: secRisk,
 			VulnSummary:  vulnSum,
+			Failures:        rel.Failures,
+			InstallFailures: rel.InstallFailures,
+			UpgradeFailures: rel.UpgradeFailures,
+			Thrashing:       rel.Thrashing,
+			PolicyMinimum:   policyMin,
+			PolicyViolation: versions.BelowMinimum(rel.Version, policyMin),
 		})
 	}
 
 	tableJSON, _ := json.Marshal(rows)
 
-	return model.DiagramResult{
-		ID:      "charts",
-		Title:   "Helm Charts",
-		Type:    "table",
-		Content: string(tableJSON),
+	return []model.DiagramResult{
+		{
+			ID:      "charts",
+			Title:   "Helm Charts",
+			Type:    "table",
+			Content: string(tableJSON),
+		},
+		versionDriftChart(rows),
+	}
+}
+
+// releaseImage is an app container image of a release: the last segment
+// of its repository, e.g. "grafana" for grafana/grafana, and its tag.
+type releaseImage struct {
+	name, tag string
+}
+
+// releaseImageTags counts, per "cluster/namespace/release", the pods running
+// each app container image. Digest-pinned images are left out since a
+// digest can't be compared with a version.
+func releaseImageTags(pods []model.PodImageInfo) map[string]map[releaseImage]int {
+	seen := make(map[string]bool) // "cluster/namespace/pod|image"
+	out := make(map[string]map[releaseImage]int)
+	for _, p := range pods {
+		if p.HelmRelease == "" || p.ContainerType() != "app" {
+			continue
+		}
+		_, repo, tag := imageref.Parse(p.Image)
+		if strings.HasPrefix(tag, "sha256:") {
+			continue
+		}
+		img := releaseImage{name: path.Base(repo), tag: tag}
+		key := p.Cluster + "/" + p.Namespace + "/" + p.HelmRelease
+		podImage := p.Cluster + "/" + p.Namespace + "/" + p.PodName + "|" + img.name + ":" + tag
+		if seen[podImage] {
+			continue
+		}
+		seen[podImage] = true
+		if out[key] == nil {
+			out[key] = make(map[releaseImage]int)
+		}
+		out[key][img]++
+	}
+	return out
+}
+
+// releaseTag picks the image tag a release runs and whether it drifts from
+// the chart's appVersion. The main container is the one whose image is
+// named after the chart or the release; sidecars, such as a metrics
+// exporter, run versions of their own and don't count. Without a main
+// container, the release drifts only if none of its images match.
+func releaseTag(images map[releaseImage]int, rel model.HelmReleaseInfo) (tag string, drift bool) {
+	main := make(map[string]int)
+	all := make(map[string]int)
+	matching := make(map[string]int)
+	for img, n := range images {
+		all[img.tag] += n
+		if img.name == rel.ChartName || img.name == rel.Name {
+			main[img.tag] += n
+		}
+		if rel.AppVersion != "" && tagMatchesAppVersion(img.tag, rel.AppVersion) {
+			matching[img.tag] += n
+		}
+	}
+	if len(main) > 0 {
+		tag = dominantTag(main)
+		return tag, rel.AppVersion != "" && !tagMatchesAppVersion(tag, rel.AppVersion)
+	}
+	if len(matching) > 0 {
+		return dominantTag(matching), false
+	}
+	tag = dominantTag(all)
+	return tag, rel.AppVersion != "" && tag != ""
+}
+
+// dominantTag returns the tag run by the most pods, the lexically smallest
+// on a tie; "" if there are none.
+func dominantTag(counts map[string]int) string {
+	best, bestN := "", 0
+	for tag, n := range counts {
+		if n > bestN || (n == bestN && tag < best) {
+			best, bestN = tag, n
+		}
+	}
+	return best
+}
+
+// tagMatchesAppVersion reports whether an image tag is the chart's
+// appVersion, ignoring a "v" prefix on either side and allowing a variant
+// suffix such as "-alpine".
+func tagMatchesAppVersion(tag, appVersion string) bool {
+	tag = strings.TrimPrefix(tag, "v")
+	appVersion = strings.TrimPrefix(appVersion, "v")
+	if tag == appVersion {
+		return true
+	}
+	rest, ok := strings.CutPrefix(tag, appVersion)
+	return ok && (rest[0] == '-' || rest[0] == '_')
+}
+
+// versionDriftChart summarizes rows as a pie of up-to-date releases versus
+// those a patch, minor or major version behind. Releases whose latest version
+// is unknown are left out; outdated ones that aren't semver count as "Other".
+func versionDriftChart(rows []VersionRow) model.DiagramResult {
+	var current, patch, minor, major, other int
+	for _, r := range rows {
+		if r.Latest == "-" || r.Version == "-" {
+			continue
+		}
+		if !r.Outdated {
+			current++
+			continue
+		}
+		switch r.UpdateType {
+		case "major":
+			major++
+		case "minor":
+			minor++
+		case "patch":
+			patch++
+		default:
+			other++
+		}
+	}
+
+	result := model.DiagramResult{ID: "charts-chart", Title: "Chart Version Drift"}
+	if current+patch+minor+major+other == 0 {
+		result.Type = "markdown"
+		result.Content = "*Latest chart versions not checked yet.*"
+		result.Empty = true
+		return result
+	}
+
+	// Worst drift first so it leads the legend.
+	var b strings.Builder
+	b.WriteString("pie title Chart Version Drift\n")
+	for _, s := range []struct {
+		label string
+		n     int
+	}{
+		{"Major behind", major},
+		{"Minor behind", minor},
+		{"Patch behind", patch},
+		{"Other", other},
+		{"Up to date", current},
+	} {
+		if s.n > 0 {
+			fmt.Fprintf(&b, "  \"%s\" : %d\n", s.label, s.n)
+		}
 	}
+	result.Type = "mermaid"
+	result.Content = b.String()
+	return result
 }
 
 // vulnRiskPriority returns a numeric priority for string risk levels (higher = worse).
@@ -0,0 +1,271 @@
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestVersionDriftChartMatchesTable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: 2.1.1\n    - version: 2.1.0\n    - version: 2.0.0\n    - version: 1.0.0\n"))
+	}))
+	defer srv.Close()
+
+	release := func(name, version string) model.HelmReleaseInfo {
+		return model.HelmReleaseInfo{Name: name, Namespace: "apps", Cluster: "Homelab", ChartName: "app", Version: version, RepoName: "charts", RepoNS: "flux-system"}
+	}
+	unchecked := release("unchecked", "1.0.0")
+	unchecked.RepoName = "missing"
+	data := &model.ClusterData{
+		HelmRepositories: []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", Cluster: "Homelab", URL: srv.URL}},
+		HelmReleases: []model.HelmReleaseInfo{
+			release("major-a", "1.0.0"),
+			release("major-b", "1.0.0"),
+			release("minor", "2.0.0"),
+			release("patch-a", "2.1.0"),
+			release("patch-b", "2.1.0"),
+			release("patch-c", "2.1.0"),
+			release("current", "2.1.1"),
+			release("nightly", "nightly"),
+			unchecked, // latest unknown: left out
+		},
+	}
+	checker := versions.NewChecker(0, "")
+	checker.Check(data.HelmRepositories, data.HelmReleases)
+
+	results := SafeAll("charts", "Helm Charts", func() []model.DiagramResult {
+		return GenerateVersions(data, checker, DefaultOptions())
+	})
+	if len(results) != 2 {
+		t.Fatalf("got %d diagrams, want table and chart", len(results))
+	}
+	var rows []VersionRow
+	if err := json.Unmarshal(results[0].Data, &rows); err != nil {
+		t.Fatalf("decoding versions table data: %v", err)
+	}
+
+	want := map[string]int{}
+	for _, r := range rows {
+		switch {
+		case r.Latest == "-":
+		case !r.Outdated:
+			want["Up to date"]++
+		case r.UpdateType == "":
+			want["Other"]++
+		default:
+			want[strings.ToUpper(r.UpdateType[:1])+r.UpdateType[1:]+" behind"]++
+		}
+	}
+	if want["Major behind"] != 2 || want["Minor behind"] != 1 || want["Patch behind"] != 3 || want["Up to date"] != 1 {
+		t.Fatalf("unexpected table classification: %v", want)
+	}
+
+	chart := results[1]
+	if chart.ID != "charts-chart" || chart.Type != "mermaid" {
+		t.Fatalf("chart = %s/%s, want charts-chart/mermaid", chart.ID, chart.Type)
+	}
+	for label, n := range want {
+		slice := fmt.Sprintf("%q : %d", label, n)
+		if !strings.Contains(chart.Content, slice) {
+			t.Errorf("chart missing slice %s:\n%s", slice, chart.Content)
+		}
+	}
+	if got := strings.Count(chart.Content, " : "); got != len(want) {
+		t.Errorf("chart has %d slices, want %d:\n%s", got, len(want), chart.Content)
+	}
+	// Worst drift leads.
+	if !strings.HasPrefix(strings.SplitN(chart.Content, "\n", 3)[1], `  "Major behind"`) {
+		t.Errorf("major drift should come first:\n%s", chart.Content)
+	}
+}
+
+func TestGenerateVersionsUncheckedChart(t *testing.T) {
+	data := &model.ClusterData{
+		HelmReleases: []model.HelmReleaseInfo{{Name: "app", Namespace: "apps", Cluster: "Homelab", ChartName: "app", Version: "1.0.0"}},
+	}
+	results := GenerateVersions(data, nil, DefaultOptions())
+	if len(results) != 2 {
+		t.Fatalf("got %d diagrams, want table and chart", len(results))
+	}
+	if results[1].ID != "charts-chart" || results[1].Type != "markdown" {
+		t.Errorf("without version data the chart should be a markdown placeholder, got %s/%s", results[1].ID, results[1].Type)
+	}
+}
+
+func TestGenerateVersionsImageTagDrift(t *testing.T) {
+	pod := func(release, name, image string) model.PodImageInfo {
+		return model.PodImageInfo{Cluster: "Homelab", Namespace: "apps", PodName: name, Image: image, HelmRelease: release}
+	}
+	data := &model.ClusterData{
+		HelmReleases: []model.HelmReleaseInfo{
+			{Name: "grafana", Namespace: "apps", Cluster: "Homelab", ChartName: "grafana", Version: "8.0.0", AppVersion: "11.1.0"},
+			{Name: "loki", Namespace: "apps", Cluster: "Homelab", ChartName: "loki", Version: "6.0.0", AppVersion: "v3.1.0"},
+			{Name: "redis", Namespace: "apps", Cluster: "Homelab", ChartName: "redis", Version: "19.0.0", AppVersion: "7.2.5"},
+			{Name: "idle", Namespace: "apps", Cluster: "Homelab", ChartName: "idle", Version: "1.0.0", AppVersion: "1.0.0"},
+			{Name: "vault", Namespace: "apps", Cluster: "Homelab", ChartName: "vault", Version: "0.28.0", AppVersion: "1.17.2"},
+			{Name: "web", Namespace: "apps", Cluster: "Homelab", ChartName: "webapp", Version: "2.0.0", AppVersion: "2.0.0"},
+		},
+		Pods: []model.PodImageInfo{
+			// Overridden in values: two pods on 11.3.0, one straggler on the chart's tag.
+			pod("grafana", "grafana-a", "grafana/grafana:11.3.0"),
+			pod("grafana", "grafana-b", "grafana/grafana:11.3.0"),
+			pod("grafana", "grafana-c", "grafana/grafana:11.1.0"),
+			pod("loki", "loki-0", "grafana/loki:3.1.0"),
+			pod("redis", "redis-0", "bitnami/redis:7.2.5-debian-12-r0"),
+			{Cluster: "Homelab", Namespace: "apps", PodName: "redis-0", Image: "busybox:1.36", HelmRelease: "redis", InitContainer: true},
+			// Sidecar injector pods outnumber the server: only the container
+			// named after the chart is compared.
+			pod("vault", "vault-0", "hashicorp/vault:1.17.2"),
+			pod("vault", "vault-agent-injector-a", "hashicorp/vault-k8s:1.4.2"),
+			pod("vault", "vault-agent-injector-b", "hashicorp/vault-k8s:1.4.2"),
+			// No image named after the chart or release: a matching one is
+			// enough, whatever its sidecars run.
+			pod("web", "web-a", "acme/frontend:2.0.0"),
+			{Cluster: "Homelab", Namespace: "apps", PodName: "web-a", Image: "envoyproxy/envoy:v1.30.0", HelmRelease: "web"},
+			pod("web", "web-b", "acme/frontend:2.0.0"),
+			{Cluster: "Homelab", Namespace: "apps", PodName: "web-b", Image: "envoyproxy/envoy:v1.30.0", HelmRelease: "web"},
+			{Cluster: "Homelab", Namespace: "apps", PodName: "web-c", Image: "envoyproxy/envoy:v1.30.0", HelmRelease: "web"},
+		},
+	}
+
+	var rows []VersionRow
+	if err := json.Unmarshal([]byte(GenerateVersions(data, nil, DefaultOptions())[0].Content), &rows); err != nil {
+		t.Fatalf("decoding versions table: %v", err)
+	}
+	byRelease := make(map[string]VersionRow)
+	for _, r := range rows {
+		byRelease[r.Release] = r
+	}
+
+	tests := []struct {
+		release   string
+		wantTag   string
+		wantDrift bool
+	}{
+		{"grafana", "11.3.0", true},
+		{"loki", "3.1.0", false},               // "v" prefix ignored
+		{"redis", "7.2.5-debian-12-r0", false}, // variant suffix allowed
+		{"idle", "", false},                    // no pods: nothing to compare
+		{"vault", "1.17.2", false},             // vault-k8s sidecars ignored
+		{"web", "2.0.0", false},                // envoy sidecars don't drift
+	}
+	for _, tt := range tests {
+		r := byRelease[tt.release]
+		if r.ImageTag != tt.wantTag || r.Drift != tt.wantDrift {
+			t.Errorf("%s: imageTag=%q drift=%v, want %q/%v", tt.release, r.ImageTag, r.Drift, tt.wantTag, tt.wantDrift)
+		}
+	}
+}
+
+func TestGenerateVersionsPrereleaseRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: 1.3.0-rc.1\n    - version: 1.2.0\n    - version: 1.1.0\n"))
+	}))
+	defer srv.Close()
+
+	release := func(name, version string) model.HelmReleaseInfo {
+		return model.HelmReleaseInfo{Name: name, Namespace: "apps", Cluster: "Homelab", ChartName: "app", Version: version, RepoName: "charts", RepoNS: "flux-system"}
+	}
+	data := &model.ClusterData{
+		HelmRepositories: []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", Cluster: "Homelab", URL: srv.URL}},
+		HelmReleases: []model.HelmReleaseInfo{
+			release("rc-ahead", "1.3.0-rc.1"),
+			release("rc-released", "1.2.0-rc.2"),
+			release("stable", "1.2.0"),
+			release("old", "1.1.0"),
+		},
+	}
+	checker := versions.NewChecker(0, "")
+	checker.Check(data.HelmRepositories, data.HelmReleases)
+
+	var rows []VersionRow
+	if err := json.Unmarshal([]byte(GenerateVersions(data, checker, DefaultOptions())[0].Content), &rows); err != nil {
+		t.Fatalf("decoding versions table: %v", err)
+	}
+	want := map[string]bool{"rc-ahead": false, "rc-released": true, "stable": false, "old": true}
+	for _, r := range rows {
+		if r.Latest != "1.2.0" {
+			t.Errorf("%s: latest = %q, want 1.2.0 (highest stable)", r.Release, r.Latest)
+		}
+		if r.Outdated != want[r.Release] {
+			t.Errorf("%s (%s): outdated = %v, want %v", r.Release, r.Version, r.Outdated, want[r.Release])
+		}
+	}
+}
+
+func TestGenerateVersionsConstraint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: 2.0.0\n    - version: 1.5.0\n    - version: 1.4.2\n    - version: 1.2.0\n"))
+	}))
+	defer srv.Close()
+
+	release := func(name, version, constraint string) model.HelmReleaseInfo {
+		return model.HelmReleaseInfo{Name: name, Namespace: "apps", Cluster: "Homelab", ChartName: "app", Version: version, VersionConstraint: constraint, RepoName: "charts", RepoNS: "flux-system"}
+	}
+	data := &model.ClusterData{
+		HelmRepositories: []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", Cluster: "Homelab", URL: srv.URL}},
+		HelmReleases: []model.HelmReleaseInfo{
+			release("ranged", "1.4.2", ">=1.2.0 <2.0.0"),
+			release("ranged-current", "1.5.0", "~1.5"),
+			release("pinned", "1.2.0", ""),
+			release("latest", "2.0.0", "*"),
+		},
+	}
+	checker := versions.NewChecker(0, "")
+	checker.Check(data.HelmRepositories, data.HelmReleases)
+
+	var rows []VersionRow
+	if err := json.Unmarshal([]byte(GenerateVersions(data, checker, DefaultOptions())[0].Content), &rows); err != nil {
+		t.Fatalf("decoding versions table: %v", err)
+	}
+	want := map[string]VersionRow{
+		"ranged":         {Constraint: ">=1.2.0 <2.0.0", Latest: "1.5.0", LatestOverall: "2.0.0", Outdated: true},
+		"ranged-current": {Constraint: "~1.5", Latest: "1.5.0", LatestOverall: "2.0.0"},
+		"pinned":         {Latest: "2.0.0", Outdated: true},
+		"latest":         {Constraint: "*", Latest: "2.0.0"},
+	}
+	for _, r := range rows {
+		w := want[r.Release]
+		if r.Constraint != w.Constraint || r.Latest != w.Latest || r.LatestOverall != w.LatestOverall || r.Outdated != w.Outdated {
+			t.Errorf("%s: constraint %q, latest %q, overall %q, outdated %v; want %q, %q, %q, %v",
+				r.Release, r.Constraint, r.Latest, r.LatestOverall, r.Outdated, w.Constraint, w.Latest, w.LatestOverall, w.Outdated)
+		}
+	}
+}
+
+func TestGenerateVersionsPolicyViolation(t *testing.T) {
+	opts := DefaultOptions()
+	opts.VersionPolicy = &versions.Policy{Charts: map[string]string{"nginx": "1.25"}}
+
+	data := &model.ClusterData{
+		HelmReleases: []model.HelmReleaseInfo{
+			{Name: "old", Namespace: "apps", Cluster: "Homelab", ChartName: "nginx", Version: "1.24.0"},
+			{Name: "new", Namespace: "apps", Cluster: "Homelab", ChartName: "nginx", Version: "1.26.0"},
+			{Name: "other", Namespace: "apps", Cluster: "Homelab", ChartName: "redis", Version: "1.0.0"},
+		},
+	}
+	var rows []VersionRow
+	if err := json.Unmarshal([]byte(GenerateVersions(data, nil, opts)[0].Content), &rows); err != nil {
+		t.Fatalf("decoding versions table: %v", err)
+	}
+	byRelease := make(map[string]VersionRow)
+	for _, r := range rows {
+		byRelease[r.Release] = r
+	}
+	if r := byRelease["old"]; !r.PolicyViolation || r.PolicyMinimum != "1.25" {
+		t.Errorf("1.24.0 against >=1.25: violation=%v minimum=%q, want a violation", r.PolicyViolation, r.PolicyMinimum)
+	}
+	if r := byRelease["new"]; r.PolicyViolation {
+		t.Error("1.26.0 against >=1.25 flagged as a violation")
+	}
+	if r := byRelease["other"]; r.PolicyViolation || r.PolicyMinimum != "" {
+		t.Errorf("chart outside the policy: violation=%v minimum=%q", r.PolicyViolation, r.PolicyMinimum)
+	}
+}
@@ -0,0 +1,99 @@
+package diagram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// CollectWarnings returns the problems found while validating data, sorted
+// by cluster, namespace and name.
+func CollectWarnings(data *model.ClusterData) []model.Warning {
+	var out []model.Warning
+
+	for _, r := range data.HTTPRoutes {
+		for _, ref := range r.Backends {
+			if !ref.Ungranted {
+				continue
+			}
+			kind := ref.Kind
+			if kind == "" {
+				kind = "Service"
+			}
+			out = append(out, model.Warning{
+				Source:    "reference-grants",
+				Severity:  model.SeverityWarn,
+				Cluster:   r.Cluster,
+				Namespace: r.Namespace,
+				Name:      r.Name,
+				Message: fmt.Sprintf("HTTPRoute %s/%s references %s %s/%s but no ReferenceGrant in %s allows it",
+					r.Namespace, r.Name, kind, ref.Namespace, ref.Name, ref.Namespace),
+			})
+		}
+	}
+
+	// Integrations that aren't installed explain empty diagrams but are
+	// rarely a problem.
+	for _, c := range data.Capabilities {
+		if c.Installed {
+			continue
+		}
+		out = append(out, model.Warning{
+			Source:   "capabilities",
+			Severity: model.SeverityInfo,
+			Cluster:  c.Cluster,
+			Name:     c.Name,
+			Message:  fmt.Sprintf("%s is not installed on %s (no %s API group); its diagrams stay empty", c.Name, c.Cluster, strings.Join(c.Missing, ", ")),
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Cluster != out[j].Cluster {
+			return out[i].Cluster < out[j].Cluster
+		}
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// severityRank orders the warning severities.
+var severityRank = map[string]int{
+	model.SeverityInfo:  0,
+	model.SeverityWarn:  1,
+	model.SeverityError: 2,
+}
+
+// ParseSeverity validates a minimum warning severity, case-insensitively;
+// "" means model.SeverityWarn.
+func ParseSeverity(s string) (string, error) {
+	if s == "" {
+		return model.SeverityWarn, nil
+	}
+	s = strings.ToLower(s)
+	if _, ok := severityRank[s]; !ok {
+		return "", fmt.Errorf("unknown severity %q, want info, warn or error", s)
+	}
+	return s, nil
+}
+
+// FilterWarnings returns the warnings at or above minSeverity, which must
+// be valid (see ParseSeverity). Warnings without a severity count as warn.
+func FilterWarnings(warnings []model.Warning, minSeverity string) []model.Warning {
+	floor := severityRank[minSeverity]
+	var out []model.Warning
+	for _, w := range warnings {
+		sev := w.Severity
+		if sev == "" {
+			sev = model.SeverityWarn
+		}
+		if severityRank[sev] >= floor {
+			out = append(out, w)
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestUngrantedRefSurfaced(t *testing.T) {
+	data := &model.ClusterData{HTTPRoutes: []model.HTTPRouteInfo{{
+		Name:      "grafana",
+		Namespace: "apps",
+		Cluster:   "Homelab",
+		Backends: []model.BackendRef{
+			{Name: "grafana", Namespace: "monitoring", Port: 3000, Ungranted: true},
+			{Name: "oauth", Namespace: "auth", Port: 4180},
+		},
+	}}}
+
+	warnings := CollectWarnings(data)
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1: %+v", len(warnings), warnings)
+	}
+	if w := warnings[0]; w.Source != "reference-grants" || !strings.Contains(w.Message, "Service monitoring/grafana") {
+		t.Errorf("warning = %+v, want reference-grants warning naming monitoring/grafana", w)
+	}
+
+	network := GenerateNetwork(data).Content
+	if !strings.Contains(network, `apps_grafana -.->|"no ReferenceGrant"| xns_monitoring_grafana`) {
+		t.Errorf("network diagram lacks flagged edge:\n%s", network)
+	}
+	if !strings.Contains(network, "apps_grafana --> xns_auth_oauth") {
+		t.Errorf("network diagram lacks granted edge:\n%s", network)
+	}
+}
+
+func TestCollectWarningsMissingIntegrations(t *testing.T) {
+	data := &model.ClusterData{Capabilities: []model.Capability{
+		{Name: "Velero", Cluster: "Homelab", Missing: []string{"velero.io"}},
+		{Name: "Flux", Cluster: "Homelab", Installed: true, Found: []string{"kustomize.toolkit.fluxcd.io/v1"}},
+	}}
+
+	warnings := CollectWarnings(data)
+	if len(warnings) != 1 || warnings[0].Name != "Velero" || warnings[0].Severity != model.SeverityInfo {
+		t.Fatalf("warnings = %+v, want one info warning for Velero", warnings)
+	}
+	if got := FilterWarnings(warnings, model.SeverityWarn); len(got) != 0 {
+		t.Errorf("FilterWarnings(warn) = %+v, want the info warning hidden", got)
+	}
+	if _, err := ParseSeverity("verbose"); err == nil {
+		t.Error("ParseSeverity accepted an unknown severity")
+	}
+}
@@ -19,6 +19,10 @@ type WorkloadRow struct {
 	UpdateStrategy string `json:"updateStrategy"`
 	Images         string `json:"images"` // comma-separated
 	Age            string `json:"age"`
+	// Autoscaler summarizes the HPA or KEDA ScaledObject scaling the
+	// workload, e.g. "HPA 2–10 (3)"; ScaleMetrics lists what it scales on.
+	Autoscaler   string `json:"autoscaler,omitempty"`
+	ScaleMetrics string `json:"scaleMetrics,omitempty"`
 }
 
 // GenerateWorkloads produces a table of cluster workloads.
@@ -29,9 +33,16 @@ func GenerateWorkloads(data *model.ClusterData) model.DiagramResult {
 			Title:   "Workloads",
 			Type:    "markdown",
 			Content: "*No workload data available.*",
+			Empty:   true,
 		}
 	}
 
+	// "cluster/namespace/kind/name" of the scale target → autoscaler
+	autoscalers := make(map[string]model.AutoscalerInfo)
+	for _, a := range data.Autoscalers {
+		autoscalers[a.Cluster+"/"+a.Namespace+"/"+a.TargetKind+"/"+a.TargetName] = a
+	}
+
 	var rows []WorkloadRow
 	for _, w := range data.Workloads {
 		replicas := ""
@@ -41,7 +52,7 @@ func GenerateWorkloads(data *model.ClusterData) model.DiagramResult {
 			replicas = fmt.Sprintf("%d/%d", w.ReadyReplicas, w.Replicas)
 		}
 
-		rows = append(rows, WorkloadRow{
+		row := WorkloadRow{
 			Name:           w.Name,
 			Namespace:      w.Namespace,
 			Cluster:        w.Cluster,
@@ -50,7 +61,16 @@ func GenerateWorkloads(data *model.ClusterData) model.DiagramResult {
 			UpdateStrategy: w.UpdateStrategy,
 			Images:         strings.Join(w.Images, ", "),
 			Age:            w.CreatedAt,
-		})
+		}
+		if a, ok := autoscalers[w.Cluster+"/"+w.Namespace+"/"+w.Kind+"/"+w.Name]; ok {
+			kind := "HPA"
+			if a.Kind == "ScaledObject" {
+				kind = "KEDA"
+			}
+			row.Autoscaler = fmt.Sprintf("%s %d–%d (%d)", kind, a.MinReplicas, a.MaxReplicas, a.CurrentReplicas)
+			row.ScaleMetrics = strings.Join(a.Metrics, ", ")
+		}
+		rows = append(rows, row)
 	}
 
 	sort.Slice(rows, func(i, j int) bool {
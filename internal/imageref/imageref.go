@@ -0,0 +1,84 @@
+// Package imageref parses container image references. It is shared by the
+// version checkers and the diagram generators so an image is attributed to
+// the same registry everywhere.
+package imageref
+
+import "strings"
+
+// Parse splits a container image reference into registry, repo, and tag.
+// Docker Hub images are normalized to the "docker.io" registry, with official
+// images under "library/". A digest stands in for the tag only when the
+// reference has no tag.
+// Examples:
+//
+//	"ghcr.io/foo/bar:v1.2"        → "ghcr.io", "foo/bar", "v1.2"
+//	"nginx"                       → "docker.io", "library/nginx", "latest"
+//	"docker.io/nginx:1.27"        → "docker.io", "library/nginx", "1.27"
+//	"myregistry:5000/team/app:v1" → "myregistry:5000", "team/app", "v1"
+//	"localhost:5000/app"          → "localhost:5000", "app", "latest"
+//	"nginx@sha256:…"              → "docker.io", "library/nginx", "sha256:…"
+//	"nginx:1.27@sha256:…"         → "docker.io", "library/nginx", "1.27"
+func Parse(ref string) (registry, repo, tag string) {
+	var digest string
+	if idx := strings.Index(ref, "@"); idx != -1 {
+		digest = ref[idx+1:]
+		ref = ref[:idx]
+	}
+
+	// The first colon after the last slash starts the tag; one before it
+	// is a registry port.
+	slash := strings.LastIndex(ref, "/")
+	if idx := strings.Index(ref[slash+1:], ":"); idx != -1 {
+		tag = ref[slash+1+idx+1:]
+		ref = ref[:slash+1+idx]
+	}
+	if tag == "" {
+		tag = digest
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+
+	registry, repo = "docker.io", ref
+	if first, rest, ok := strings.Cut(ref, "/"); ok && isRegistry(first) {
+		registry, repo = first, rest
+	}
+	if registry == "index.docker.io" {
+		registry = "docker.io"
+	}
+	if registry == "docker.io" && !strings.Contains(repo, "/") {
+		repo = "library/" + repo
+	}
+	return registry, repo, tag
+}
+
+// isRegistry reports whether the first path segment of a reference names a
+// registry host rather than a Docker Hub namespace.
+func isRegistry(s string) bool {
+	return strings.Contains(s, ".") || strings.Contains(s, ":") || s == "localhost"
+}
+
+// Digest normalizes a container status imageID or digest reference to a
+// bare "sha256:…" digest, so the forms runtimes report compare equal:
+//
+//	"docker.io/library/nginx@sha256:ab…"     → "sha256:ab…"
+//	"docker-pullable://nginx@sha256:ab…"     → "sha256:ab…"
+//	"sha256:ab…", "docker://sha256:ab…"      → "sha256:ab…"
+//	"ab…" (64 hex characters, Docker ID)     → "sha256:ab…"
+//
+// It returns "" for anything without a digest, e.g. a plain "nginx:1.27".
+func Digest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i != -1 {
+		imageID = imageID[i+1:]
+	} else if _, rest, ok := strings.Cut(imageID, "://"); ok {
+		imageID = rest
+	}
+	imageID = strings.ToLower(imageID)
+	if strings.HasPrefix(imageID, "sha256:") && len(imageID) > len("sha256:") {
+		return imageID
+	}
+	if len(imageID) == 64 && strings.Trim(imageID, "0123456789abcdef") == "" {
+		return "sha256:" + imageID
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package imageref
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ref                     string
+		wantReg, wantRepo, want string
+	}{
+		{"ghcr.io/foo/bar:v1.2", "ghcr.io", "foo/bar", "v1.2"},
+		{"nginx", "docker.io", "library/nginx", "latest"},
+		{"nginx:latest", "docker.io", "library/nginx", "latest"},
+		{"grafana/grafana:11.0.0", "docker.io", "grafana/grafana", "11.0.0"},
+		{"docker.io/library/nginx:1.27", "docker.io", "library/nginx", "1.27"},
+		{"docker.io/nginx:1.27", "docker.io", "library/nginx", "1.27"},
+		{"index.docker.io/nginx", "docker.io", "library/nginx", "latest"},
+		{"myregistry:5000/app:v1", "myregistry:5000", "app", "v1"},
+		{"myregistry:5000/team/sub/app:v1", "myregistry:5000", "team/sub/app", "v1"},
+		{"myregistry:5000/team/app", "myregistry:5000", "team/app", "latest"},
+		{"localhost:5000/x", "localhost:5000", "x", "latest"},
+		{"localhost/x:dev", "localhost", "x", "dev"},
+		{"nginx@sha256:abc", "docker.io", "library/nginx", "sha256:abc"},
+		{"ghcr.io/foo/bar@sha256:abc", "ghcr.io", "foo/bar", "sha256:abc"},
+		{"nginx:1.27@sha256:abc", "docker.io", "library/nginx", "1.27"},
+		{"myregistry:5000/app:v1@sha256:abc", "myregistry:5000", "app", "v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			reg, repo, tag := Parse(tt.ref)
+			if reg != tt.wantReg || repo != tt.wantRepo || tag != tt.want {
+				t.Errorf("Parse(%q) = (%q, %q, %q), want (%q, %q, %q)", tt.ref, reg, repo, tag, tt.wantReg, tt.wantRepo, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzParse checks that Parse never panics, always names a registry and tag,
+// and is stable: re-parsing its normalized output yields the same parts.
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"nginx", "nginx:1.27@sha256:abc", "docker.io/library/nginx",
+		"myregistry:5000/team/app:v1", "localhost:5000/x", "ghcr.io/foo/bar@sha256:abc", "",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, ref string) {
+		reg, repo, tag := Parse(ref)
+		if reg == "" || tag == "" {
+			t.Fatalf("Parse(%q) = (%q, %q, %q): empty registry or tag", ref, reg, repo, tag)
+		}
+
+		sep := ":"
+		if strings.ContainsAny(tag, ":/@") {
+			sep = "@"
+		}
+		normalized := reg + "/" + repo + sep + tag
+		reg2, repo2, tag2 := Parse(normalized)
+		if reg2 != reg || repo2 != repo || tag2 != tag {
+			t.Errorf("Parse(%q) = (%q, %q, %q), but re-parsing %q gives (%q, %q, %q)", ref, reg, repo, tag, normalized, reg2, repo2, tag2)
+		}
+	})
+}
+
+func TestDigest(t *testing.T) {
+	const hex = "4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"
+	want := "sha256:" + hex
+	for _, id := range []string{
+		"docker.io/library/nginx@sha256:" + hex,
+		"docker-pullable://nginx@sha256:" + hex,
+		"nginx:1.27@sha256:" + hex,
+		"sha256:" + hex,
+		"docker://sha256:" + hex,
+		hex,
+		"SHA256:" + strings.ToUpper(hex),
+	} {
+		if got := Digest(id); got != want {
+			t.Errorf("Digest(%q) = %q, want %q", id, got, want)
+		}
+	}
+	for _, id := range []string{"", "nginx:1.27", "sha256:", "docker://4c0fdaa8"} {
+		if got := Digest(id); got != "" {
+			t.Errorf("Digest(%q) = %q, want none", id, got)
+		}
+	}
+}
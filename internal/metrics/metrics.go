@@ -38,6 +38,22 @@ var (
 		Name: "cluster_vision_enrichment_cve_total",
 		Help: "Number of CVEs currently cached, by source.",
 	}, []string{"source"})
+
+	// RefreshPanics: panics recovered from the refresh pipeline, by stage
+	// ("refresh" for the synchronous parse+generate pass, or the name of
+	// the async checker goroutine). Any increase means a bug worth a look;
+	// the previous data keeps being served meanwhile.
+	RefreshPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cluster_vision_refresh_panics_total",
+		Help: "Number of panics recovered from the refresh pipeline, by stage.",
+	}, []string{"stage"})
+
+	// DiagramGenSeconds: how long the last run of each diagram generator
+	// took, by diagram (or diagram group) ID — points at the slow one.
+	DiagramGenSeconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cluster_vision_diagram_generation_seconds",
+		Help: "Duration of the last run of each diagram generator.",
+	}, []string{"diagram"})
 )
 
 // EmitImageVulnMetrics emits gauges keyed by (cluster, namespace, image).
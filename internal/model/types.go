@@ -1,12 +1,25 @@
 package model
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // ClusterData holds all parsed cluster state.
 type ClusterData struct {
 	PrimaryCluster        string
 	Nodes                 []NodeInfo
+	APIServers            []APIServerInfo
 	Flux                  []FluxKustomization
+	ArgoApps              []ArgoApplication
+	CAPIClusters          []CAPICluster
+	FluxSources           []FluxSourceInfo
 	Gateways              []GatewayInfo
 	HTTPRoutes            []HTTPRouteInfo
+	IngressRoutes         []IngressRouteInfo
+	Ingresses             []IngressInfo
+	ReferenceGrants       []ReferenceGrantInfo
 	Namespaces            []NamespaceInfo
 	SecurityPolicies      []SecurityPolicyInfo
 	ClientTrafficPolicies []ClientTrafficPolicyInfo
@@ -18,8 +31,10 @@ type ClusterData struct {
 	HelmRepositories      []HelmRepositoryInfo
 	Pods                  []PodImageInfo
 	Workloads             []WorkloadInfo
+	Autoscalers           []AutoscalerInfo
 	Storage               []StorageInfo
 	CRDs                  []CRDInfo
+	Capabilities          []Capability
 	Quotas                []QuotaInfo
 	Certificates          []CertificateInfo
 	NetworkPolicies       []NetworkPolicyInfo
@@ -27,6 +42,9 @@ type ClusterData struct {
 	Services              []ServiceInfo
 	RBACBindings          []RBACBindingInfo
 	VeleroSchedules       []VeleroScheduleInfo
+	VeleroBackups         []VeleroBackupInfo
+	Events                []EventInfo
+	DeprecatedAPIs        []DeprecatedAPIUsage
 	ImageVulns            []ImageVuln
 }
 
@@ -67,6 +85,29 @@ type PodImageInfo struct {
 	Image         string // full image ref (registry/repo:tag)
 	ImageID       string // resolved digest from pod status
 	InitContainer bool
+	Ephemeral     bool   // ephemeral (kubectl debug) container
+	State         string // pod phase: "Running", "Pending", "Succeeded", "Failed", ...
+	NodeName      string // spec.nodeName; "" while unscheduled
+	Owner         string // top-level controlling workload as "Kind/name", e.g. "Deployment/web" or "CronJob/backup"; "" for bare pods
+	HelmRelease   string // helm.toolkit.fluxcd.io/name (or app.kubernetes.io/instance) pod label
+	// Version and VersionSource come from the cluster-vision.io/version and
+	// cluster-vision.io/version-source pod annotations, for images whose tag
+	// says nothing about the version (e.g. a git SHA). Version is shown and
+	// compared instead of the tag; VersionSource, e.g. "github.com/org/app",
+	// is where the latest version is looked up instead of the registry.
+	Version       string
+	VersionSource string
+}
+
+// ContainerType is "init", "ephemeral" or "app".
+func (p PodImageInfo) ContainerType() string {
+	switch {
+	case p.InitContainer:
+		return "init"
+	case p.Ephemeral:
+		return "ephemeral"
+	}
+	return "app"
 }
 
 // HelmReleaseInfo represents a Flux HelmRelease resource.
@@ -76,9 +117,21 @@ type HelmReleaseInfo struct {
 	Cluster    string
 	ChartName  string
 	Version    string // deployed chart version
+	// VersionConstraint is spec.chart.spec.version when it is a semver range
+	// rather than an exact version, e.g. ">=1.2.0 <2.0.0"; "*" (latest) when
+	// the spec leaves it unset. Empty for an exact pin.
+	VersionConstraint string
 	RepoName   string // sourceRef name
 	RepoNS     string // sourceRef namespace
 	AppVersion string // from status, if available
+	// Failure counters from the HelmRelease status, reset by Flux once a
+	// reconcile succeeds. Thrashing marks a release caught in a retry loop
+	// (see parser.releaseThrashing).
+	Failures               int
+	InstallFailures        int
+	UpgradeFailures        int
+	LastHandledReconcileAt string
+	Thrashing              bool
 }
 
 // HelmRepositoryInfo represents a Flux HelmRepository source.
@@ -97,6 +150,7 @@ type ServiceEntryInfo struct {
 	Cluster         string
 	Hosts           []string
 	Location        string // "MESH_EXTERNAL" etc
+	Ports           []int  // spec.ports[].number
 	EndpointAddress string // remote gateway IP
 	Network         string // e.g. "nas-network" from endpoint label
 }
@@ -126,6 +180,36 @@ type DataSource struct {
 	Type     string `json:"type"`     // "tfstate" | "docker-compose" | "kubernetes"
 	Path     string `json:"path"`     // path to the mounted file
 	Platform string `json:"platform"` // optional: platform name for K8s nodes (e.g. "QNAP")
+	// ExecEnv is extra environment for the kubeconfig's exec credential
+	// plugin (kubernetes sources only), e.g. {"AWS_PROFILE": "prod"}.
+	ExecEnv map[string]string `json:"execEnv,omitempty"`
+	// Context picks a kubeconfig context other than current-context
+	// (kubernetes sources only), so several sources can share one file.
+	Context string `json:"context,omitempty"`
+	// Interval is an optional Go duration, e.g. "1h": the source is only
+	// re-read once this much time has passed since it was last read, for
+	// sources that change rarely. Empty re-reads it on every refresh.
+	Interval string `json:"interval,omitempty"`
+	// Primary makes this kubernetes source the primary cluster instead of
+	// the one configured through KUBECONFIG/CLUSTER_NAME. At most one
+	// source may set it.
+	Primary bool `json:"primary,omitempty"`
+	// Profiles are the Compose profiles to activate (docker-compose sources
+	// only). Services gated on other profiles are left out, as `docker
+	// compose up` would; "*" activates them all.
+	Profiles []string `json:"profiles,omitempty"`
+}
+
+// RefreshInterval parses Interval; zero means every refresh.
+func (ds DataSource) RefreshInterval() (time.Duration, error) {
+	if ds.Interval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(ds.Interval)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("data source %q: invalid interval %q", ds.Name, ds.Interval)
+	}
+	return d, nil
 }
 
 // InfraSource holds parsed infrastructure data from one source.
@@ -133,7 +217,16 @@ type InfraSource struct {
 	Name           string
 	Type           string // "tfstate" | "docker-compose"
 	TerraformNodes []TerraformNode
-	DockerCompose  *DockerCompose
+	// TerraformOutputs are the tfstate's non-sensitive scalar outputs,
+	// e.g. cluster_vip, sorted by name.
+	TerraformOutputs []TerraformOutput
+	DockerCompose    *DockerCompose
+}
+
+// TerraformOutput is one root module output of a Terraform state.
+type TerraformOutput struct {
+	Name  string
+	Value string
 }
 
 // DockerCompose represents a parsed docker-compose file.
@@ -148,12 +241,24 @@ type DockerService struct {
 	Hostname   string
 	IP         string
 	Ports      []string
-	Volumes    []string
+	Volumes    []string // as written, "source:target[:mode]"
+	Mounts     []DockerMount
 	Networks   []string
 	Command    string
 	Privileged bool
 }
 
+// DockerMount is one volume of a docker-compose service, classified.
+type DockerMount struct {
+	Type     string // "bind", "volume" (named), "anonymous" or "tmpfs"
+	Source   string // host path or volume name; empty for anonymous and tmpfs
+	Target   string
+	ReadOnly bool
+	// Sensitive marks a bind of a host path that gives the container
+	// control of the host, such as the Docker socket or /.
+	Sensitive bool
+}
+
 // NodeInfo represents a Kubernetes node.
 type NodeInfo struct {
 	Name             string
@@ -170,6 +275,27 @@ type NodeInfo struct {
 	Architecture     string // e.g. "amd64"
 	ProviderID       string // node.Spec.ProviderID (e.g. "proxmox://region/zone/uuid")
 	Platform         string // platform name from DataSource config (e.g. "QNAP")
+	PodCapacity      int    // status.capacity.pods (often 110); 0 if unreported
+	Taints           []NodeTaint
+	Ready            bool     // Ready condition is True
+	Schedulable      bool     // false when cordoned (spec.unschedulable)
+	Conditions       []string // active problem conditions, e.g. "MemoryPressure", "DiskPressure"
+}
+
+// NodeTaint is one spec.taints entry of a node.
+type NodeTaint struct {
+	Key    string
+	Value  string
+	Effect string // "NoSchedule" | "PreferNoSchedule" | "NoExecute"
+}
+
+// String renders the taint like kubectl: "key=value:Effect" or "key:Effect".
+func (t NodeTaint) String() string {
+	s := t.Key
+	if t.Value != "" {
+		s += "=" + t.Value
+	}
+	return s + ":" + t.Effect
 }
 
 // FluxKustomization represents a Flux Kustomization resource.
@@ -181,6 +307,37 @@ type FluxKustomization struct {
 	Cluster   string
 }
 
+// ArgoApplication represents an Argo CD Application and its sync state.
+type ArgoApplication struct {
+	Name           string
+	Namespace      string // where the Application object lives, e.g. "argocd"
+	Cluster        string
+	Project        string
+	DestCluster    string // destination cluster name or API server URL
+	DestNamespace  string
+	RepoURL        string
+	Path           string // source path, or chart name for Helm sources
+	TargetRevision string
+	SyncStatus     string // "Synced", "OutOfSync", "Unknown"
+	Health         string // "Healthy", "Progressing", "Degraded", ...
+	SyncWave       int    // argocd.argoproj.io/sync-wave; lower syncs first
+}
+
+// FluxSourceInfo represents a Flux source (GitRepository, OCIRepository,
+// HelmRepository) and its last sync state.
+type FluxSourceInfo struct {
+	Name      string
+	Namespace string
+	Cluster   string
+	Kind      string // "GitRepository", "OCIRepository", "HelmRepository"
+	URL       string
+	Ref       string // tracked ref, e.g. "branch: main", "semver: >=1.0.0"
+	Revision  string // status.artifact.revision of the last fetched artifact
+	Ready     bool
+	Message   string // Ready condition message
+	LastSync  string // RFC3339 time of the last artifact update
+}
+
 // GatewayInfo represents a Gateway API Gateway resource.
 type GatewayInfo struct {
 	Name             string
@@ -208,10 +365,96 @@ type HTTPRouteInfo struct {
 	Backends    []BackendRef
 }
 
+// IngressRouteInfo represents a Traefik IngressRoute (traefik.io/v1alpha1).
+type IngressRouteInfo struct {
+	Name        string
+	Namespace   string
+	Cluster     string
+	EntryPoints []string // e.g. "websecure"
+	Routes      []IngressRouteRule
+}
+
+// IngressRouteRule is one route of an IngressRoute: its match expression,
+// the hosts and paths extracted from it, and the services it forwards to.
+type IngressRouteRule struct {
+	Match    string // raw rule, e.g. Host(`app.example.com`) && PathPrefix(`/api`)
+	Hosts    []string
+	Paths    []string // Path and PathPrefix values
+	Services []BackendRef
+}
+
+// IngressInfo represents a networking.k8s.io/v1 Ingress.
+type IngressInfo struct {
+	Name      string
+	Namespace string
+	Cluster   string
+	ClassName string // spec.ingressClassName, e.g. "nginx"
+	Rules     []IngressRule
+	TLSHosts  []string
+}
+
+// IngressRule is one host/path of an Ingress and the service it forwards to.
+// A rule without a host matches any host.
+type IngressRule struct {
+	Host    string
+	Path    string
+	Backend BackendRef
+}
+
 // BackendRef is a reference to a backend service.
 type BackendRef struct {
-	Name string
-	Port int
+	Name      string
+	Namespace string // as written in the route; "" means the route's own namespace
+	Group     string // "" means the core API group
+	Kind      string // "" means Service
+	Port      int
+	// Ungranted marks a cross-namespace ref that no ReferenceGrant in the
+	// target namespace allows, so the Gateway refuses to route to it.
+	Ungranted bool
+}
+
+// ReferenceGrantInfo represents a Gateway API ReferenceGrant: it allows the
+// From resources to reference the To resources in the grant's namespace.
+type ReferenceGrantInfo struct {
+	Name      string
+	Namespace string
+	Cluster   string
+	From      []ReferenceGrantFrom
+	To        []ReferenceGrantTo
+}
+
+// ReferenceGrantFrom is one allowed referrer of a ReferenceGrant.
+type ReferenceGrantFrom struct {
+	Group     string
+	Kind      string
+	Namespace string
+}
+
+// ReferenceGrantTo is one allowed target of a ReferenceGrant; Name "" allows
+// every resource of the kind.
+type ReferenceGrantTo struct {
+	Group string
+	Kind  string
+	Name  string
+}
+
+// Warning severities, lowest first. A Warning without one counts as
+// SeverityWarn.
+const (
+	SeverityInfo  = "info"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// Warning is a problem found while validating cluster data, surfaced next
+// to the diagrams in the API response.
+type Warning struct {
+	Source    string `json:"source"` // what raised it, e.g. "reference-grants"
+	Severity  string `json:"severity,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Message   string `json:"message"`
 }
 
 // NamespaceInfo holds security-relevant labels from a namespace.
@@ -223,6 +466,32 @@ type NamespaceInfo struct {
 	Backup      bool
 	MTLS        bool
 	PodSecurity string
+	Team        string            // owning team from the configured team label/annotation
+	Labels      map[string]string // all namespace labels, for configured label columns
+	System      bool              // default or a system/add-on namespace; diagrams hide it unless configured to include it
+	// LastBackup is when the newest completed Velero Backup covering the
+	// namespace finished; zero when there is none. BackupsListed says the
+	// Velero Backups could be listed at all: without it only the Backup
+	// label, which states intent rather than reality, is known.
+	LastBackup    time.Time
+	BackupsListed bool
+}
+
+// LabelColumn is a configured security matrix column: "yes" for namespaces
+// whose Key label equals Value, or carries Key at all when Value is empty.
+type LabelColumn struct {
+	Name  string // column header, e.g. "Compliance"
+	Key   string // label key, e.g. "compliance"
+	Value string // label value to match; "" matches any value
+}
+
+// Matches reports whether labels satisfy the column.
+func (c LabelColumn) Matches(labels map[string]string) bool {
+	v, ok := labels[c.Key]
+	if !ok {
+		return false
+	}
+	return c.Value == "" || v == c.Value
 }
 
 // SecurityPolicyInfo tracks external auth policies per namespace.
@@ -251,6 +520,15 @@ type TerraformNode struct {
 	GPU        string
 	Role       string
 	Provider   string
+	Host       string // physical host the VM runs on, e.g. the Proxmox node or the EC2 availability zone
+}
+
+// APIServerInfo is a cluster's Kubernetes API server version, as reported
+// by its /version endpoint.
+type APIServerInfo struct {
+	Cluster    string
+	GitVersion string // e.g. "v1.31.2" or "v1.31.4+k3s1"
+	Platform   string // e.g. "linux/amd64"
 }
 
 // WorkloadInfo represents a Kubernetes workload (Deployment, StatefulSet, DaemonSet, CronJob).
@@ -264,9 +542,25 @@ type WorkloadInfo struct {
 	UpdateStrategy string
 	Images         []string
 	Labels         map[string]string
+	Team           string // owning team from the configured team label/annotation
 	CreatedAt      string
 }
 
+// AutoscalerInfo is a HorizontalPodAutoscaler or KEDA ScaledObject and the
+// workload it scales.
+type AutoscalerInfo struct {
+	Name            string
+	Namespace       string
+	Cluster         string
+	Kind            string // "HorizontalPodAutoscaler" | "ScaledObject"
+	TargetKind      string // e.g. "Deployment"
+	TargetName      string
+	MinReplicas     int32
+	MaxReplicas     int32
+	CurrentReplicas int32    // from the HPA's status; for a ScaledObject, from the HPA KEDA manages for it
+	Metrics         []string // what it scales on, e.g. "cpu 80%" or KEDA trigger types such as "prometheus"
+}
+
 // StorageInfo represents a PV, PVC, or StorageClass.
 type StorageInfo struct {
 	Name          string
@@ -290,6 +584,18 @@ type CRDInfo struct {
 	Cluster  string
 }
 
+// Capability reports whether an optional integration (Flux, Gateway API,
+// Istio, ...) is installed in a cluster, judged by the API groups its CRDs
+// serve. It is served as is by /api/status.
+type Capability struct {
+	Name      string   `json:"name"`
+	Cluster   string   `json:"cluster"`
+	Installed bool     `json:"installed"`         // at least one of its API groups is served
+	Found     []string `json:"found,omitempty"`   // served groups with their preferred version, e.g. "gateway.networking.k8s.io/v1"
+	Missing   []string `json:"missing,omitempty"` // groups not served
+	Diagrams  []string `json:"diagrams"`          // diagram IDs fed by the integration
+}
+
 // QuotaInfo represents a ResourceQuota or LimitRange.
 type QuotaInfo struct {
 	Name      string
@@ -358,6 +664,38 @@ type RBACBindingInfo struct {
 	RoleKind    string // "Role", "ClusterRole"
 	Namespace   string
 	Cluster     string
+	Binding     string // name of the (Cluster)RoleBinding
+	ClusterWide bool   // bound by a ClusterRoleBinding
+	// BindingNamespace is the namespace of a RoleBinding, where it grants
+	// access; Namespace is the subject's, which may differ for a
+	// ServiceAccount bound from another namespace. Empty when ClusterWide.
+	BindingNamespace string
+	// Broad says why the role grants sweeping access: "cluster-admin" or
+	// "wildcard" (a rule with "*" verbs or resources). Empty otherwise.
+	Broad string
+}
+
+// CAPICluster is a workload cluster managed through Cluster API, as seen from
+// its management cluster.
+type CAPICluster struct {
+	Name                   string
+	Namespace              string
+	Cluster                string // the management cluster
+	Phase                  string // "Provisioning", "Provisioned", "Deleting", "Failed", ...
+	InfrastructureProvider string // infrastructureRef kind, e.g. "ProxmoxCluster"
+	ControlPlaneProvider   string // controlPlaneRef kind, e.g. "KubeadmControlPlane"
+	Machines               []CAPIMachine
+}
+
+// CAPIMachine is a Cluster API Machine: one node of a managed cluster.
+type CAPIMachine struct {
+	Name       string
+	Role       string // "control-plane" or "worker"
+	Provider   string // infrastructureRef kind, e.g. "ProxmoxMachine"
+	ProviderID string
+	Version    string // Kubernetes version, e.g. "v1.30.2"
+	Phase      string // "Pending", "Provisioning", "Running", "Failed", ...
+	NodeName   string // the node it became in the managed cluster
 }
 
 // VeleroScheduleInfo represents a Velero backup schedule.
@@ -370,6 +708,44 @@ type VeleroScheduleInfo struct {
 	ExcludedNS []string
 	TTL        string
 	Phase      string
+	LastBackup time.Time // status.lastBackup: when it last started a backup
+}
+
+// VeleroBackupInfo represents a Velero Backup, one-off or created by a
+// schedule.
+type VeleroBackupInfo struct {
+	Name       string
+	Namespace  string
+	Cluster    string
+	Schedule   string   // velero.io/schedule-name label; empty for one-off backups
+	IncludedNS []string // empty or "*" covers every namespace
+	ExcludedNS []string
+	Phase      string    // "Completed", "PartiallyFailed", "Failed", "InProgress", ...
+	Completed  time.Time // status.completionTimestamp; zero until it finishes
+}
+
+// DeprecatedAPIUsage is an object last written through a deprecated API
+// version.
+type DeprecatedAPIUsage struct {
+	Kind         string
+	Name         string
+	Namespace    string // empty for cluster-scoped objects
+	Cluster      string
+	APIVersion   string // deprecated group/version, e.g. "batch/v1beta1"
+	Replacement  string // group/version to migrate to; empty if there is none
+	DeprecatedIn string // Kubernetes minor release, e.g. "1.21"
+	RemovedIn    string
+}
+
+// EventInfo is a Warning event from the core events API.
+type EventInfo struct {
+	Namespace string
+	Cluster   string
+	Object    string // involved object, "Kind/name"
+	Reason    string
+	Message   string
+	Count     int32
+	LastSeen  time.Time // most recent occurrence, at the API's full precision
 }
 
 // DiagramResult holds a generated diagram.
@@ -378,4 +754,32 @@ type DiagramResult struct {
 	Title   string `json:"title"`
 	Type    string `json:"type"` // "mermaid", "markdown", "table", or "flow"
 	Content string `json:"content"`
+	// Data carries the payload of structured types (table, flow, detail) as
+	// embedded JSON. Content still holds the same JSON as a string for
+	// clients that predate Data; it will stop being filled for those types.
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"` // set when the generator failed; Content then explains the failure
+	// Empty marks a placeholder for a diagram with nothing to show yet;
+	// Content then holds a short explanation. Generators always return
+	// their diagrams, so the set of IDs doesn't change with the data.
+	Empty bool `json:"empty,omitempty"`
+	// GenMillis is how long the generator took, in milliseconds. Diagrams
+	// generated together (e.g. security and security-chart) share the
+	// group's time.
+	GenMillis int64 `json:"genMillis"`
+	// Hash identifies this version of Content; export URLs carry it as
+	// ?v=<hash> so they can be cached as immutable.
+	Hash string `json:"hash,omitempty"`
+}
+
+// structuredTypes are the diagram types whose Content is JSON.
+var structuredTypes = map[string]bool{"table": true, "flow": true, "detail": true}
+
+// WithData returns d with Data filled from Content when d is a structured
+// type whose Content is valid JSON.
+func (d DiagramResult) WithData() DiagramResult {
+	if d.Data == nil && structuredTypes[d.Type] && json.Valid([]byte(d.Content)) {
+		d.Data = json.RawMessage(d.Content)
+	}
+	return d
 }
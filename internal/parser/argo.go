@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// argoSyncWaveAnnotation orders Argo CD syncs; lower waves sync first.
+const argoSyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+
+func (p *KubernetesParser) parseArgoApplications(ctx context.Context) []model.ArgoApplication {
+	gvr := schema.GroupVersionResource{
+		Group:    "argoproj.io",
+		Version:  "v1alpha1",
+		Resource: "applications",
+	}
+
+	list, err := p.dynamic.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Debug("failed to list argo cd applications (CRD may not exist)", "error", err)
+		return nil
+	}
+
+	var result []model.ArgoApplication
+	for _, item := range list.Items {
+		spec, _ := item.Object["spec"].(map[string]interface{})
+		status, _ := item.Object["status"].(map[string]interface{})
+		dest, _ := spec["destination"].(map[string]interface{})
+		sync, _ := status["sync"].(map[string]interface{})
+		health, _ := status["health"].(map[string]interface{})
+
+		// Multi-source Applications list spec.sources; the first one
+		// stands for the app.
+		source, _ := spec["source"].(map[string]interface{})
+		if source == nil {
+			if sources, ok := spec["sources"].([]interface{}); ok && len(sources) > 0 {
+				source, _ = sources[0].(map[string]interface{})
+			}
+		}
+		path := strVal(source, "path")
+		if path == "" {
+			path = strVal(source, "chart")
+		}
+
+		destCluster := strVal(dest, "name")
+		if destCluster == "" {
+			destCluster = strVal(dest, "server")
+		}
+
+		wave := 0
+		if v := item.GetAnnotations()[argoSyncWaveAnnotation]; v != "" {
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				wave = n
+			} else {
+				slog.Debug("ignoring invalid argo cd sync-wave", "application", item.GetName(), "value", v)
+			}
+		}
+
+		result = append(result, model.ArgoApplication{
+			Name:           item.GetName(),
+			Namespace:      item.GetNamespace(),
+			Cluster:        p.clusterName,
+			Project:        strVal(spec, "project"),
+			DestCluster:    destCluster,
+			DestNamespace:  strVal(dest, "namespace"),
+			RepoURL:        strVal(source, "repoURL"),
+			Path:           path,
+			TargetRevision: strVal(source, "targetRevision"),
+			SyncStatus:     strVal(sync, "status"),
+			Health:         strVal(health, "status"),
+			SyncWave:       wave,
+		})
+	}
+	return result
+}
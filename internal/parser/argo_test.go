@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseArgoApplications(t *testing.T) {
+	app := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":        "grafana",
+			"namespace":   "argocd",
+			"annotations": map[string]interface{}{"argocd.argoproj.io/sync-wave": "2"},
+		},
+		"spec": map[string]interface{}{
+			"project":     "platform",
+			"destination": map[string]interface{}{"server": "https://kubernetes.default.svc", "namespace": "monitoring"},
+			"source": map[string]interface{}{
+				"repoURL":        "https://github.com/acme/gitops",
+				"path":           "apps/grafana",
+				"targetRevision": "main",
+			},
+		},
+		"status": map[string]interface{}{
+			"sync":   map[string]interface{}{"status": "OutOfSync"},
+			"health": map[string]interface{}{"status": "Healthy"},
+		},
+	}}
+	gvr := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	listKinds := map[schema.GroupVersionResource]string{gvr: "ApplicationList"}
+
+	p := &KubernetesParser{
+		dynamic:     dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, app),
+		clusterName: "Homelab",
+	}
+	apps := p.parseArgoApplications(context.Background())
+	want := model.ArgoApplication{
+		Name:           "grafana",
+		Namespace:      "argocd",
+		Cluster:        "Homelab",
+		Project:        "platform",
+		DestCluster:    "https://kubernetes.default.svc",
+		DestNamespace:  "monitoring",
+		RepoURL:        "https://github.com/acme/gitops",
+		Path:           "apps/grafana",
+		TargetRevision: "main",
+		SyncStatus:     "OutOfSync",
+		Health:         "Healthy",
+		SyncWave:       2,
+	}
+	if len(apps) != 1 || apps[0] != want {
+		t.Errorf("apps = %+v, want [%+v]", apps, want)
+	}
+
+	// Without the CRD the parser degrades to no applications.
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	dyn.PrependReactor("list", "applications", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New(`the server could not find the requested resource`)
+	})
+	p.dynamic = dyn
+	if apps := p.parseArgoApplications(context.Background()); len(apps) != 0 {
+		t.Errorf("apps without CRD = %+v, want none", apps)
+	}
+}
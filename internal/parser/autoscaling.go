@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// KEDA's defaults for a ScaledObject that leaves the replica bounds unset.
+const (
+	kedaDefaultMinReplicas = 0
+	kedaDefaultMaxReplicas = 100
+)
+
+// parseAutoscalers lists HorizontalPodAutoscalers and, when KEDA is
+// installed, ScaledObjects. KEDA scales through an HPA of its own, owned by
+// the ScaledObject: that HPA is not listed separately but lends the
+// ScaledObject its current replica count.
+func (p *KubernetesParser) parseAutoscalers(ctx context.Context) []model.AutoscalerInfo {
+	var result []model.AutoscalerInfo
+
+	// "namespace/scaledobject" → current replicas of the HPA KEDA manages
+	kedaReplicas := make(map[string]int32)
+	hpas, err := p.typed.AutoscalingV2().HorizontalPodAutoscalers("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list horizontalpodautoscalers", "error", err)
+	} else {
+		for _, h := range hpas.Items {
+			if owner := scaledObjectOwner(h.OwnerReferences); owner != "" {
+				kedaReplicas[h.Namespace+"/"+owner] = h.Status.CurrentReplicas
+				continue
+			}
+			var metrics []string
+			for _, m := range h.Spec.Metrics {
+				if s := hpaMetric(m); s != "" {
+					metrics = append(metrics, s)
+				}
+			}
+			result = append(result, model.AutoscalerInfo{
+				Name:            h.Name,
+				Namespace:       h.Namespace,
+				Cluster:         p.clusterName,
+				Kind:            "HorizontalPodAutoscaler",
+				TargetKind:      h.Spec.ScaleTargetRef.Kind,
+				TargetName:      h.Spec.ScaleTargetRef.Name,
+				MinReplicas:     ptrInt32(h.Spec.MinReplicas),
+				MaxReplicas:     h.Spec.MaxReplicas,
+				CurrentReplicas: h.Status.CurrentReplicas,
+				Metrics:         metrics,
+			})
+		}
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    "keda.sh",
+		Version:  "v1alpha1",
+		Resource: "scaledobjects",
+	}
+	list, err := p.dynamic.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Debug("failed to list keda scaledobjects (CRD may not exist)", "error", err)
+		return result
+	}
+	for _, item := range list.Items {
+		spec, _ := item.Object["spec"].(map[string]interface{})
+		target, _ := spec["scaleTargetRef"].(map[string]interface{})
+		targetKind := strVal(target, "kind")
+		if targetKind == "" {
+			targetKind = "Deployment"
+		}
+		minReplicas, maxReplicas := int32(kedaDefaultMinReplicas), int32(kedaDefaultMaxReplicas)
+		if n, ok := numVal(spec["minReplicaCount"]); ok {
+			minReplicas = int32(n)
+		}
+		if n, ok := numVal(spec["maxReplicaCount"]); ok {
+			maxReplicas = int32(n)
+		}
+		var metrics []string
+		triggers, _ := spec["triggers"].([]interface{})
+		for _, t := range triggers {
+			if tm, ok := t.(map[string]interface{}); ok && strVal(tm, "type") != "" {
+				metrics = append(metrics, strVal(tm, "type"))
+			}
+		}
+		result = append(result, model.AutoscalerInfo{
+			Name:            item.GetName(),
+			Namespace:       item.GetNamespace(),
+			Cluster:         p.clusterName,
+			Kind:            "ScaledObject",
+			TargetKind:      targetKind,
+			TargetName:      strVal(target, "name"),
+			MinReplicas:     minReplicas,
+			MaxReplicas:     maxReplicas,
+			CurrentReplicas: kedaReplicas[item.GetNamespace()+"/"+item.GetName()],
+			Metrics:         metrics,
+		})
+	}
+	return result
+}
+
+// scaledObjectOwner returns the name of the KEDA ScaledObject owning an HPA,
+// or "".
+func scaledObjectOwner(refs []metav1.OwnerReference) string {
+	for _, ref := range refs {
+		if ref.Kind == "ScaledObject" && strings.HasPrefix(ref.APIVersion, "keda.sh/") {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// hpaMetric describes what an HPA metric scales on: the resource or metric
+// name and its target, e.g. "cpu 80%" or "requests_per_second 100".
+func hpaMetric(m autoscalingv2.MetricSpec) string {
+	var name string
+	var target autoscalingv2.MetricTarget
+	switch m.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if m.Resource == nil {
+			return ""
+		}
+		name, target = string(m.Resource.Name), m.Resource.Target
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if m.ContainerResource == nil {
+			return ""
+		}
+		name, target = string(m.ContainerResource.Name)+" ("+m.ContainerResource.Container+")", m.ContainerResource.Target
+	case autoscalingv2.PodsMetricSourceType:
+		if m.Pods == nil {
+			return ""
+		}
+		name, target = m.Pods.Metric.Name, m.Pods.Target
+	case autoscalingv2.ObjectMetricSourceType:
+		if m.Object == nil {
+			return ""
+		}
+		name, target = m.Object.Metric.Name+" on "+m.Object.DescribedObject.Kind+"/"+m.Object.DescribedObject.Name, m.Object.Target
+	case autoscalingv2.ExternalMetricSourceType:
+		if m.External == nil {
+			return ""
+		}
+		name, target = m.External.Metric.Name, m.External.Target
+	default:
+		return string(m.Type)
+	}
+
+	switch {
+	case target.AverageUtilization != nil:
+		return fmt.Sprintf("%s %d%%", name, *target.AverageUtilization)
+	case target.AverageValue != nil:
+		return name + " " + target.AverageValue.String()
+	case target.Value != nil:
+		return name + " " + target.Value.String()
+	}
+	return name
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseAutoscalers(t *testing.T) {
+	minReplicas, cpu := int32(2), int32(80)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &cpu},
+				},
+			}},
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 3},
+	}
+	// The HPA KEDA creates for the ScaledObject below.
+	kedaHPA := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "keda-hpa-worker",
+			Namespace:       "apps",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Name: "worker"}},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "worker"},
+			MaxReplicas:    20,
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 4},
+	}
+	scaledObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "keda.sh/v1alpha1",
+		"kind":       "ScaledObject",
+		"metadata":   map[string]interface{}{"name": "worker", "namespace": "apps"},
+		"spec": map[string]interface{}{
+			"scaleTargetRef":  map[string]interface{}{"name": "worker"},
+			"maxReplicaCount": int64(20),
+			"triggers": []interface{}{
+				map[string]interface{}{"type": "rabbitmq", "metadata": map[string]interface{}{"queueName": "jobs"}},
+			},
+		},
+	}}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}: "ScaledObjectList",
+	}
+	p := &KubernetesParser{
+		typed:       fake.NewSimpleClientset(hpa, kedaHPA),
+		dynamic:     dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, scaledObject),
+		clusterName: "Homelab",
+	}
+	want := []model.AutoscalerInfo{
+		{Name: "web", Namespace: "apps", Cluster: "Homelab", Kind: "HorizontalPodAutoscaler", TargetKind: "Deployment", TargetName: "web",
+			MinReplicas: 2, MaxReplicas: 10, CurrentReplicas: 3, Metrics: []string{"cpu 80%"}},
+		{Name: "worker", Namespace: "apps", Cluster: "Homelab", Kind: "ScaledObject", TargetKind: "Deployment", TargetName: "worker",
+			MinReplicas: 0, MaxReplicas: 20, CurrentReplicas: 4, Metrics: []string{"rabbitmq"}},
+	}
+	if got := p.parseAutoscalers(context.Background()); !reflect.DeepEqual(got, want) {
+		t.Errorf("autoscalers =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Without KEDA the HPAs are still listed.
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	dyn.PrependReactor("list", "scaledobjects", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New(`the server could not find the requested resource`)
+	})
+	p.typed, p.dynamic = fake.NewSimpleClientset(hpa), dyn
+	if got := p.parseAutoscalers(context.Background()); len(got) != 1 || got[0].Name != "web" {
+		t.Errorf("autoscalers without KEDA = %+v, want only the web HPA", got)
+	}
+}
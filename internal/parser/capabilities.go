@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"log/slog"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// integrations are the optional CRD-based integrations the parsers read,
+// with the API groups that reveal each one and the diagrams it feeds.
+var integrations = []struct {
+	name     string
+	groups   []string
+	diagrams []string
+}{
+	{"Flux", []string{"kustomize.toolkit.fluxcd.io", "helm.toolkit.fluxcd.io", "source.toolkit.fluxcd.io"}, []string{"dependencies", "flux-sources", "charts"}},
+	{"Argo CD", []string{"argoproj.io"}, []string{"argo"}},
+	{"Gateway API", []string{"gateway.networking.k8s.io"}, []string{"network"}},
+	{"Envoy Gateway", []string{"gateway.envoyproxy.io"}, []string{"security"}},
+	{"Istio", []string{"networking.istio.io"}, []string{"topology"}},
+	{"Traefik", []string{"traefik.io", "traefik.containo.us"}, []string{"network"}},
+	{"cert-manager", []string{"cert-manager.io"}, []string{"certificates"}},
+	{"Velero", []string{"velero.io"}, []string{"velero"}},
+	{"Trivy Operator", []string{"aquasecurity.github.io"}, []string{"images"}},
+	{"Cluster API", []string{"cluster.x-k8s.io"}, []string{"capi"}},
+}
+
+// parseCapabilities reports which integrations the cluster serves, from the
+// discovery API, so an empty diagram can be told apart from a missing CRD.
+// It returns nil when discovery fails.
+func (p *KubernetesParser) parseCapabilities() []model.Capability {
+	groups, err := p.typed.Discovery().ServerGroups()
+	if err != nil {
+		slog.Warn("failed to discover api groups", "error", err)
+		return nil
+	}
+	served := make(map[string]string) // group → preferred group/version
+	for _, g := range groups.Groups {
+		served[g.Name] = g.PreferredVersion.GroupVersion
+		if served[g.Name] == "" && len(g.Versions) > 0 {
+			served[g.Name] = g.Versions[0].GroupVersion
+		}
+	}
+
+	result := make([]model.Capability, 0, len(integrations))
+	for _, in := range integrations {
+		c := model.Capability{Name: in.name, Cluster: p.clusterName, Diagrams: in.diagrams}
+		for _, g := range in.groups {
+			if gv, ok := served[g]; ok {
+				c.Found = append(c.Found, gv)
+			} else {
+				c.Missing = append(c.Missing, g)
+			}
+		}
+		c.Installed = len(c.Found) > 0
+		result = append(result, c)
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "apps/v1"},
+		{GroupVersion: "kustomize.toolkit.fluxcd.io/v1"},
+		{GroupVersion: "source.toolkit.fluxcd.io/v1"},
+		{GroupVersion: "gateway.networking.k8s.io/v1"},
+		{GroupVersion: "traefik.containo.us/v1alpha1"},
+	}
+	p := &KubernetesParser{typed: client, clusterName: "Homelab"}
+
+	caps := p.parseCapabilities()
+	if len(caps) != len(integrations) {
+		t.Fatalf("got %d capabilities, want one per integration (%d)", len(caps), len(integrations))
+	}
+	byName := make(map[string]int)
+	for i, c := range caps {
+		byName[c.Name] = i
+		if c.Cluster != "Homelab" {
+			t.Errorf("%s: cluster = %q, want Homelab", c.Name, c.Cluster)
+		}
+	}
+
+	flux := caps[byName["Flux"]]
+	if !flux.Installed ||
+		!slices.Equal(flux.Found, []string{"kustomize.toolkit.fluxcd.io/v1", "source.toolkit.fluxcd.io/v1"}) ||
+		!slices.Equal(flux.Missing, []string{"helm.toolkit.fluxcd.io"}) {
+		t.Errorf("Flux = %+v, want installed without helm-controller", flux)
+	}
+	if gw := caps[byName["Gateway API"]]; !gw.Installed || len(gw.Missing) != 0 {
+		t.Errorf("Gateway API = %+v, want installed", gw)
+	}
+	if tr := caps[byName["Traefik"]]; !tr.Installed || !slices.Equal(tr.Found, []string{"traefik.containo.us/v1alpha1"}) {
+		t.Errorf("Traefik = %+v, want installed through the legacy group", tr)
+	}
+	for _, name := range []string{"Istio", "Envoy Gateway", "Argo CD", "Cluster API"} {
+		if c := caps[byName[name]]; c.Installed || len(c.Found) != 0 || len(c.Missing) == 0 || len(c.Diagrams) == 0 {
+			t.Errorf("%s = %+v, want missing with the diagrams it feeds", name, c)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// capiClusterNameLabel names the Cluster a Machine belongs to.
+	capiClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+	// capiControlPlaneLabel marks control-plane Machines.
+	capiControlPlaneLabel = "cluster.x-k8s.io/control-plane"
+)
+
+// parseCAPIClusters lists the Cluster API Clusters this cluster manages,
+// each with its Machines. Outside a management cluster the CRDs are absent
+// and it returns nil.
+func (p *KubernetesParser) parseCAPIClusters(ctx context.Context) []model.CAPICluster {
+	clusterGVR := schema.GroupVersionResource{
+		Group:    "cluster.x-k8s.io",
+		Version:  "v1beta1",
+		Resource: "clusters",
+	}
+	machineGVR := schema.GroupVersionResource{
+		Group:    "cluster.x-k8s.io",
+		Version:  "v1beta1",
+		Resource: "machines",
+	}
+
+	list, err := p.dynamic.Resource(clusterGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Debug("failed to list cluster api clusters (CRD may not exist)", "error", err)
+		return nil
+	}
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	// "namespace/cluster" → machines
+	machinesByCluster := make(map[string][]model.CAPIMachine)
+	machines, err := p.dynamic.Resource(machineGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list cluster api machines", "error", err)
+	} else {
+		for _, item := range machines.Items {
+			spec, _ := item.Object["spec"].(map[string]interface{})
+			status, _ := item.Object["status"].(map[string]interface{})
+			infraRef, _ := spec["infrastructureRef"].(map[string]interface{})
+			nodeRef, _ := status["nodeRef"].(map[string]interface{})
+
+			clusterName := strVal(spec, "clusterName")
+			if clusterName == "" {
+				clusterName = item.GetLabels()[capiClusterNameLabel]
+			}
+			role := "worker"
+			if _, ok := item.GetLabels()[capiControlPlaneLabel]; ok {
+				role = "control-plane"
+			}
+
+			key := item.GetNamespace() + "/" + clusterName
+			machinesByCluster[key] = append(machinesByCluster[key], model.CAPIMachine{
+				Name:       item.GetName(),
+				Role:       role,
+				Provider:   strVal(infraRef, "kind"),
+				ProviderID: strVal(spec, "providerID"),
+				Version:    strVal(spec, "version"),
+				Phase:      strVal(status, "phase"),
+				NodeName:   strVal(nodeRef, "name"),
+			})
+		}
+	}
+
+	var result []model.CAPICluster
+	for _, item := range list.Items {
+		spec, _ := item.Object["spec"].(map[string]interface{})
+		status, _ := item.Object["status"].(map[string]interface{})
+		infraRef, _ := spec["infrastructureRef"].(map[string]interface{})
+		cpRef, _ := spec["controlPlaneRef"].(map[string]interface{})
+
+		ms := machinesByCluster[item.GetNamespace()+"/"+item.GetName()]
+		// Control plane first, then by name.
+		sort.Slice(ms, func(i, j int) bool {
+			if ms[i].Role != ms[j].Role {
+				return ms[i].Role == "control-plane"
+			}
+			return ms[i].Name < ms[j].Name
+		})
+
+		result = append(result, model.CAPICluster{
+			Name:                   item.GetName(),
+			Namespace:              item.GetNamespace(),
+			Cluster:                p.clusterName,
+			Phase:                  strVal(status, "phase"),
+			InfrastructureProvider: strVal(infraRef, "kind"),
+			ControlPlaneProvider:   strVal(cpRef, "kind"),
+			Machines:               ms,
+		})
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseCAPIClusters(t *testing.T) {
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cluster.x-k8s.io/v1beta1",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": "prod", "namespace": "clusters"},
+		"spec": map[string]interface{}{
+			"infrastructureRef": map[string]interface{}{"kind": "ProxmoxCluster", "name": "prod"},
+			"controlPlaneRef":   map[string]interface{}{"kind": "KubeadmControlPlane", "name": "prod-cp"},
+		},
+		"status": map[string]interface{}{"phase": "Provisioned"},
+	}}
+	machine := func(name string, labels map[string]interface{}, phase, node string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.x-k8s.io/v1beta1",
+			"kind":       "Machine",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "clusters", "labels": labels},
+			"spec": map[string]interface{}{
+				"clusterName":       "prod",
+				"version":           "v1.30.2",
+				"providerID":        "proxmox://" + name,
+				"infrastructureRef": map[string]interface{}{"kind": "ProxmoxMachine", "name": name},
+			},
+			"status": map[string]interface{}{"phase": phase, "nodeRef": map[string]interface{}{"kind": "Node", "name": node}},
+		}}
+	}
+	worker := machine("prod-md-0-abc", map[string]interface{}{"cluster.x-k8s.io/cluster-name": "prod"}, "Provisioning", "")
+	cp := machine("prod-cp-xyz", map[string]interface{}{
+		"cluster.x-k8s.io/cluster-name":  "prod",
+		"cluster.x-k8s.io/control-plane": "",
+	}, "Running", "prod-cp-xyz")
+	delete(worker.Object["status"].(map[string]interface{}), "nodeRef")
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "clusters"}: "ClusterList",
+		{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}: "MachineList",
+	}
+	p := &KubernetesParser{
+		dynamic:     dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, cluster, worker, cp),
+		clusterName: "Management",
+	}
+	clusters := p.parseCAPIClusters(context.Background())
+	if len(clusters) != 1 {
+		t.Fatalf("clusters = %+v, want 1", clusters)
+	}
+	c := clusters[0]
+	if c.Name != "prod" || c.Namespace != "clusters" || c.Cluster != "Management" || c.Phase != "Provisioned" ||
+		c.InfrastructureProvider != "ProxmoxCluster" || c.ControlPlaneProvider != "KubeadmControlPlane" {
+		t.Errorf("cluster = %+v", c)
+	}
+	want := []model.CAPIMachine{
+		{Name: "prod-cp-xyz", Role: "control-plane", Provider: "ProxmoxMachine", ProviderID: "proxmox://prod-cp-xyz", Version: "v1.30.2", Phase: "Running", NodeName: "prod-cp-xyz"},
+		{Name: "prod-md-0-abc", Role: "worker", Provider: "ProxmoxMachine", ProviderID: "proxmox://prod-md-0-abc", Version: "v1.30.2", Phase: "Provisioning"},
+	}
+	if len(c.Machines) != len(want) {
+		t.Fatalf("machines = %+v, want %+v", c.Machines, want)
+	}
+	for i := range want {
+		if c.Machines[i] != want[i] {
+			t.Errorf("machine %d = %+v, want %+v", i, c.Machines[i], want[i])
+		}
+	}
+
+	// Outside a management cluster the parser degrades to no clusters.
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	dyn.PrependReactor("list", "clusters", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New(`the server could not find the requested resource`)
+	})
+	p.dynamic = dyn
+	if clusters := p.parseCAPIClusters(context.Background()); len(clusters) != 0 {
+		t.Errorf("clusters without CRD = %+v, want none", clusters)
+	}
+}
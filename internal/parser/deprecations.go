@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	"github.com/Masterminds/semver/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// apiDeprecation is one entry of the bundled deprecation table.
+type apiDeprecation struct {
+	gvr          schema.GroupVersionResource
+	kind         string
+	replacement  string // group/version to migrate to; "" if the API has none
+	deprecatedIn string // Kubernetes minor release, e.g. "1.21"
+	removedIn    string
+}
+
+// deprecatedAPIs lists the served API versions Kubernetes has deprecated,
+// after https://kubernetes.io/docs/reference/using-api/deprecation-guide/.
+var deprecatedAPIs = []apiDeprecation{
+	{schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}, "Ingress", "networking.k8s.io/v1", "1.14", "1.22"},
+	{schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}, "Ingress", "networking.k8s.io/v1", "1.19", "1.22"},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "clusterroles"}, "ClusterRole", "rbac.authorization.k8s.io/v1", "1.17", "1.22"},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "clusterrolebindings"}, "ClusterRoleBinding", "rbac.authorization.k8s.io/v1", "1.17", "1.22"},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "roles"}, "Role", "rbac.authorization.k8s.io/v1", "1.17", "1.22"},
+	{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "rolebindings"}, "RoleBinding", "rbac.authorization.k8s.io/v1", "1.17", "1.22"},
+	{schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1beta1", Resource: "customresourcedefinitions"}, "CustomResourceDefinition", "apiextensions.k8s.io/v1", "1.16", "1.22"},
+	{schema.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}, "CronJob", "batch/v1", "1.21", "1.25"},
+	{schema.GroupVersionResource{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"}, "PodDisruptionBudget", "policy/v1", "1.21", "1.25"},
+	{schema.GroupVersionResource{Group: "policy", Version: "v1beta1", Resource: "podsecuritypolicies"}, "PodSecurityPolicy", "", "1.21", "1.25"},
+	{schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1beta1", Resource: "endpointslices"}, "EndpointSlice", "discovery.k8s.io/v1", "1.21", "1.25"},
+	{schema.GroupVersionResource{Group: "node.k8s.io", Version: "v1beta1", Resource: "runtimeclasses"}, "RuntimeClass", "node.k8s.io/v1", "1.20", "1.25"},
+	{schema.GroupVersionResource{Group: "autoscaling", Version: "v2beta1", Resource: "horizontalpodautoscalers"}, "HorizontalPodAutoscaler", "autoscaling/v2", "1.22", "1.25"},
+	{schema.GroupVersionResource{Group: "autoscaling", Version: "v2beta2", Resource: "horizontalpodautoscalers"}, "HorizontalPodAutoscaler", "autoscaling/v2", "1.23", "1.26"},
+	{schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1beta1", Resource: "csistoragecapacities"}, "CSIStorageCapacity", "storage.k8s.io/v1", "1.24", "1.27"},
+	{schema.GroupVersionResource{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Resource: "flowschemas"}, "FlowSchema", "flowcontrol.apiserver.k8s.io/v1", "1.26", "1.29"},
+	{schema.GroupVersionResource{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Resource: "flowschemas"}, "FlowSchema", "flowcontrol.apiserver.k8s.io/v1", "1.29", "1.32"},
+	{schema.GroupVersionResource{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Resource: "prioritylevelconfigurations"}, "PriorityLevelConfiguration", "flowcontrol.apiserver.k8s.io/v1", "1.29", "1.32"},
+}
+
+// parseDeprecatedAPIs finds objects written through a deprecated API version
+// the server still serves. The table is narrowed to versions already
+// deprecated in the cluster's Kubernetes release, then by discovery to
+// served ones; an object counts as using one when a field manager, or
+// kubectl's last-applied configuration, recorded that apiVersion. When the
+// server version can't be read, every served entry is checked.
+func (p *KubernetesParser) parseDeprecatedAPIs(ctx context.Context) []model.DeprecatedAPIUsage {
+	var cluster *semver.Version
+	if v, err := p.typed.Discovery().ServerVersion(); err != nil {
+		slog.Debug("failed to read server version for deprecated apis", "error", err)
+	} else if cluster, err = semver.NewVersion(v.GitVersion); err != nil {
+		slog.Debug("unparseable server version for deprecated apis", "version", v.GitVersion, "error", err)
+	}
+
+	var result []model.DeprecatedAPIUsage
+	served := make(map[string]map[string]bool) // group/version → resources
+	for _, d := range deprecatedAPIs {
+		if !deprecatedBy(d, cluster) {
+			continue
+		}
+		gv := d.gvr.GroupVersion().String()
+		resources, ok := served[gv]
+		if !ok {
+			resources = make(map[string]bool)
+			list, err := p.typed.Discovery().ServerResourcesForGroupVersion(gv)
+			if err != nil {
+				slog.Debug("deprecated api not served", "groupVersion", gv, "error", err)
+			} else {
+				for _, r := range list.APIResources {
+					resources[r.Name] = true
+				}
+			}
+			served[gv] = resources
+		}
+		if !resources[d.gvr.Resource] {
+			continue
+		}
+
+		list, err := p.dynamic.Resource(d.gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			slog.Warn("failed to list deprecated api resources", "groupVersion", gv, "resource", d.gvr.Resource, "error", err)
+			continue
+		}
+		for _, item := range list.Items {
+			if !writtenWith(&item, gv) {
+				continue
+			}
+			result = append(result, model.DeprecatedAPIUsage{
+				Kind:         d.kind,
+				Name:         item.GetName(),
+				Namespace:    item.GetNamespace(),
+				Cluster:      p.clusterName,
+				APIVersion:   gv,
+				Replacement:  d.replacement,
+				DeprecatedIn: d.deprecatedIn,
+				RemovedIn:    d.removedIn,
+			})
+		}
+	}
+	return result
+}
+
+// deprecatedBy reports whether d is deprecated in the cluster's release,
+// comparing minor versions so pre-releases and distribution suffixes
+// ("v1.29.3+k3s1") don't matter. A nil cluster version counts as deprecated.
+func deprecatedBy(d apiDeprecation, cluster *semver.Version) bool {
+	if cluster == nil {
+		return true
+	}
+	since, err := semver.NewVersion(d.deprecatedIn)
+	if err != nil {
+		return true
+	}
+	if cluster.Major() != since.Major() {
+		return cluster.Major() > since.Major()
+	}
+	return cluster.Minor() >= since.Minor()
+}
+
+// writtenWith reports whether obj was last written through apiVersion.
+func writtenWith(obj *unstructured.Unstructured, apiVersion string) bool {
+	for _, mf := range obj.GetManagedFields() {
+		if mf.APIVersion == apiVersion {
+			return true
+		}
+	}
+	if applied := obj.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; applied != "" {
+		var v struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		if json.Unmarshal([]byte(applied), &v) == nil && v.APIVersion == apiVersion {
+			return true
+		}
+	}
+	return false
+}
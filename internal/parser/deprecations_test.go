@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseDeprecatedAPIs(t *testing.T) {
+	cronJob := func(name, managedBy string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1beta1",
+			"kind":       "CronJob",
+		}}
+		u.SetName(name)
+		u.SetNamespace("backup")
+		u.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "helm", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: managedBy}})
+		return u
+	}
+	gvr := schema.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CronJobList"},
+		cronJob("legacy", "batch/v1beta1"), cronJob("migrated", "batch/v1"))
+
+	legacy := model.DeprecatedAPIUsage{
+		Kind:         "CronJob",
+		Name:         "legacy",
+		Namespace:    "backup",
+		Cluster:      "Homelab",
+		APIVersion:   "batch/v1beta1",
+		Replacement:  "batch/v1",
+		DeprecatedIn: "1.21",
+		RemovedIn:    "1.25",
+	}
+
+	tests := []struct {
+		name          string
+		serverVersion string
+		want          []model.DeprecatedAPIUsage
+	}{
+		{"deprecated in the cluster's release", "v1.24.3+k3s1", []model.DeprecatedAPIUsage{legacy}},
+		{"deprecated this release", "v1.21.0", []model.DeprecatedAPIUsage{legacy}},
+		{"cluster older than the deprecation", "v1.20.15", nil},
+		{"unknown server version", "", []model.DeprecatedAPIUsage{legacy}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typed := fake.NewSimpleClientset()
+			disc := typed.Discovery().(*fakediscovery.FakeDiscovery)
+			disc.Resources = []*metav1.APIResourceList{
+				{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{{Name: "cronjobs", Kind: "CronJob", Namespaced: true}}},
+			}
+			if tt.serverVersion != "" {
+				disc.FakedServerVersion = &version.Info{GitVersion: tt.serverVersion}
+			} else {
+				typed.PrependReactor("get", "version", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("unavailable")
+				})
+			}
+
+			p := &KubernetesParser{typed: typed, dynamic: dyn, clusterName: "Homelab"}
+			got := p.parseDeprecatedAPIs(context.Background())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deprecated APIs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
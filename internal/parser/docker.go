@@ -3,7 +3,10 @@ package parser
 import (
 	"fmt"
 	"log/slog"
+	"path"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 	"gopkg.in/yaml.v3"
@@ -19,18 +22,196 @@ type dockerServiceDef struct {
 	ContainerName string            `yaml:"container_name"`
 	Hostname      string            `yaml:"hostname"`
 	Command       interface{}       `yaml:"command"` // string or []string
-	Privileged    bool              `yaml:"privileged"`
+	Privileged    *bool             `yaml:"privileged"` // nil when unset, so extends can tell
 	Ports         []string          `yaml:"ports"`
-	Volumes       []string          `yaml:"volumes"`
+	Volumes       []dockerVolume    `yaml:"volumes"`
 	Networks      map[string]dockerNetworkConfig `yaml:"networks"`
+	Profiles      []string          `yaml:"profiles"`
+	Extends       *dockerExtends    `yaml:"extends"`
+}
+
+// dockerExtends is a service's extends: the name of the service it inherits
+// from, written either as that name alone or as a mapping.
+type dockerExtends struct {
+	Service string `yaml:"service"`
+	File    string `yaml:"file"`
+}
+
+func (e *dockerExtends) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&e.Service)
+	}
+	type long dockerExtends // no UnmarshalYAML: avoids recursing
+	return node.Decode((*long)(e))
+}
+
+// active reports whether a service runs with the given profiles enabled:
+// services without profiles always do, the others when one of theirs is
+// enabled. A "*" profile enables them all, like COMPOSE_PROFILES=*.
+func (d dockerServiceDef) active(profiles []string) bool {
+	if len(d.Profiles) == 0 || slices.Contains(profiles, "*") {
+		return true
+	}
+	return slices.ContainsFunc(d.Profiles, func(p string) bool { return slices.Contains(profiles, p) })
+}
+
+// merged returns d on top of base, following Compose's merge rules:
+// scalars set on d win, ports add up, and volumes and networks are merged
+// by target and name with d's entries winning. Profiles are d's own.
+func (d dockerServiceDef) merged(base dockerServiceDef) dockerServiceDef {
+	out := base
+	out.Profiles, out.Extends = d.Profiles, nil
+	if d.Image != "" {
+		out.Image = d.Image
+	}
+	if d.ContainerName != "" {
+		out.ContainerName = d.ContainerName
+	}
+	if d.Hostname != "" {
+		out.Hostname = d.Hostname
+	}
+	if d.Command != nil {
+		out.Command = d.Command
+	}
+	if d.Privileged != nil {
+		out.Privileged = d.Privileged
+	}
+	out.Ports = append(slices.Clone(base.Ports), d.Ports...)
+
+	out.Volumes = nil
+	for _, v := range base.Volumes {
+		if !slices.ContainsFunc(d.Volumes, func(o dockerVolume) bool { return o.mount().Target == v.mount().Target }) {
+			out.Volumes = append(out.Volumes, v)
+		}
+	}
+	out.Volumes = append(out.Volumes, d.Volumes...)
+
+	if len(d.Networks) > 0 {
+		out.Networks = make(map[string]dockerNetworkConfig, len(base.Networks)+len(d.Networks))
+		for name, cfg := range base.Networks {
+			out.Networks[name] = cfg
+		}
+		for name, cfg := range d.Networks {
+			out.Networks[name] = cfg
+		}
+	}
+	return out
+}
+
+// resolveExtends returns the named service with its extends chain merged
+// in. Only services of the same file can be extended; extending another
+// file's service keeps the service as written.
+func resolveExtends(services map[string]dockerServiceDef, name string, seen []string) (dockerServiceDef, error) {
+	def := services[name]
+	if def.Extends == nil {
+		return def, nil
+	}
+	if slices.Contains(seen, name) {
+		return def, fmt.Errorf("service %q: extends cycle %s", name, strings.Join(append(seen, name), " → "))
+	}
+	if def.Extends.File != "" {
+		slog.Warn("docker-compose extends from another file is not supported — ignoring it", "service", name, "file", def.Extends.File)
+		return def, nil
+	}
+	if _, ok := services[def.Extends.Service]; !ok {
+		return def, fmt.Errorf("service %q extends unknown service %q", name, def.Extends.Service)
+	}
+	base, err := resolveExtends(services, def.Extends.Service, append(seen, name))
+	if err != nil {
+		return def, err
+	}
+	return def.merged(base), nil
 }
 
 type dockerNetworkConfig struct {
 	IPv4Address string `yaml:"ipv4_address"`
 }
 
-// ParseDockerCompose parses a docker-compose YAML file into a DockerCompose model.
-func ParseDockerCompose(data []byte) (*model.DockerCompose, error) {
+// dockerVolume is a service volume in either the short "source:target:mode"
+// syntax or the long mapping syntax.
+type dockerVolume struct {
+	Short    string `yaml:"-"`
+	Type     string `yaml:"type"`
+	Source   string `yaml:"source"`
+	Target   string `yaml:"target"`
+	ReadOnly bool   `yaml:"read_only"`
+}
+
+func (v *dockerVolume) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&v.Short)
+	}
+	type long dockerVolume // no UnmarshalYAML: avoids recursing
+	return node.Decode((*long)(v))
+}
+
+// sensitiveHostPaths are host paths whose bind mount hands the container
+// control of the host or the container runtime.
+var sensitiveHostPaths = map[string]bool{
+	"/":                               true,
+	"/etc":                            true,
+	"/root":                           true,
+	"/proc":                           true,
+	"/sys":                            true,
+	"/dev":                            true,
+	"/boot":                           true,
+	"/var/lib/docker":                 true,
+	"/var/run/docker.sock":            true,
+	"/run/docker.sock":                true,
+	"/run/containerd/containerd.sock": true,
+	"/var/run/podman/podman.sock":     true,
+}
+
+// spec returns the volume in short syntax, as shown to users.
+func (v dockerVolume) spec() string {
+	if v.Short != "" {
+		return v.Short
+	}
+	s := v.Target
+	if v.Source != "" {
+		s = v.Source + ":" + v.Target
+	}
+	if v.ReadOnly {
+		s += ":ro"
+	}
+	return s
+}
+
+// mount classifies the volume. In the short syntax a source starting with
+// "/", "." or "~" is a host path, anything else names a volume, and no
+// source at all makes an anonymous volume.
+func (v dockerVolume) mount() model.DockerMount {
+	m := model.DockerMount{Type: v.Type, Source: v.Source, Target: v.Target, ReadOnly: v.ReadOnly}
+	if v.Short != "" {
+		parts := strings.Split(v.Short, ":")
+		m = model.DockerMount{Target: parts[0]}
+		if len(parts) > 1 {
+			m.Source, m.Target = parts[0], parts[1]
+		}
+		if len(parts) > 2 {
+			m.ReadOnly = slices.Contains(strings.Split(parts[2], ","), "ro")
+		}
+		switch {
+		case m.Source == "":
+			m.Type = "anonymous"
+		case strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~"):
+			m.Type = "bind"
+		default:
+			m.Type = "volume"
+		}
+	} else if m.Type == "volume" && m.Source == "" {
+		m.Type = "anonymous"
+	}
+	if m.Type == "bind" && strings.HasPrefix(m.Source, "/") {
+		m.Sensitive = sensitiveHostPaths[path.Clean(m.Source)]
+	}
+	return m
+}
+
+// ParseDockerCompose parses a docker-compose YAML file into a DockerCompose
+// model. Services extending another are merged with it, and services gated
+// on profiles other than the given active ones are left out.
+func ParseDockerCompose(data []byte, profiles []string) (*model.DockerCompose, error) {
 	var file dockerComposeFile
 	if err := yaml.Unmarshal(data, &file); err != nil {
 		return nil, fmt.Errorf("parsing docker-compose: %w", err)
@@ -43,21 +224,29 @@ func ParseDockerCompose(data []byte) (*model.DockerCompose, error) {
 
 	// Sort service names for deterministic output
 	names := make([]string, 0, len(file.Services))
-	for name := range file.Services {
-		names = append(names, name)
+	for name, def := range file.Services {
+		if def.active(profiles) {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
 
 	var services []model.DockerService
 	for _, name := range names {
-		def := file.Services[name]
+		def, err := resolveExtends(file.Services, name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("parsing docker-compose: %w", err)
+		}
 		svc := model.DockerService{
 			Name:       name,
 			Image:      def.Image,
 			Hostname:   def.Hostname,
 			Ports:      def.Ports,
-			Volumes:    def.Volumes,
-			Privileged: def.Privileged,
+			Privileged: def.Privileged != nil && *def.Privileged,
+		}
+		for _, v := range def.Volumes {
+			svc.Volumes = append(svc.Volumes, v.spec())
+			svc.Mounts = append(svc.Mounts, v.mount())
 		}
 
 		if svc.Hostname == "" {
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestParseDockerComposeVolumes(t *testing.T) {
+	compose := []byte(`
+services:
+  portainer:
+    image: portainer/portainer-ce
+    volumes:
+      - ./data:/data
+      - named_vol:/data
+      - /var/run/docker.sock:/var/run/docker.sock
+      - /:/host:ro
+      - /cache
+      - type: bind
+        source: /etc/
+        target: /host-etc
+        read_only: true
+      - type: volume
+        target: /scratch
+`)
+	dc, err := ParseDockerCompose(compose, nil)
+	if err != nil {
+		t.Fatalf("ParseDockerCompose: %v", err)
+	}
+	if len(dc.Services) != 1 {
+		t.Fatalf("got %d services, want 1", len(dc.Services))
+	}
+	svc := dc.Services[0]
+
+	want := []model.DockerMount{
+		{Type: "bind", Source: "./data", Target: "/data"},
+		{Type: "volume", Source: "named_vol", Target: "/data"},
+		{Type: "bind", Source: "/var/run/docker.sock", Target: "/var/run/docker.sock", Sensitive: true},
+		{Type: "bind", Source: "/", Target: "/host", ReadOnly: true, Sensitive: true},
+		{Type: "anonymous", Target: "/cache"},
+		{Type: "bind", Source: "/etc/", Target: "/host-etc", ReadOnly: true, Sensitive: true},
+		{Type: "anonymous", Target: "/scratch"},
+	}
+	if !reflect.DeepEqual(svc.Mounts, want) {
+		t.Errorf("mounts =\n%+v\nwant\n%+v", svc.Mounts, want)
+	}
+	wantVolumes := []string{
+		"./data:/data", "named_vol:/data", "/var/run/docker.sock:/var/run/docker.sock",
+		"/:/host:ro", "/cache", "/etc/:/host-etc:ro", "/scratch",
+	}
+	if !reflect.DeepEqual(svc.Volumes, wantVolumes) {
+		t.Errorf("volumes = %q, want %q", svc.Volumes, wantVolumes)
+	}
+}
+
+func TestParseDockerComposeProfiles(t *testing.T) {
+	compose := []byte(`
+services:
+  app:
+    image: ghcr.io/acme/app:1.0
+  debug:
+    image: busybox
+    profiles: [debug]
+  metrics:
+    image: prom/node-exporter
+    profiles: [monitoring, debug]
+`)
+	for _, tt := range []struct {
+		profiles []string
+		want     []string
+	}{
+		{nil, []string{"app"}},
+		{[]string{"monitoring"}, []string{"app", "metrics"}},
+		{[]string{"debug"}, []string{"app", "debug", "metrics"}},
+		{[]string{"*"}, []string{"app", "debug", "metrics"}},
+	} {
+		dc, err := ParseDockerCompose(compose, tt.profiles)
+		if err != nil {
+			t.Fatalf("ParseDockerCompose: %v", err)
+		}
+		var got []string
+		for _, svc := range dc.Services {
+			got = append(got, svc.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("profiles %q: services = %q, want %q", tt.profiles, got, tt.want)
+		}
+	}
+}
+
+func TestParseDockerComposeExtends(t *testing.T) {
+	compose := []byte(`
+services:
+  base:
+    image: ghcr.io/acme/worker:2.3
+    privileged: true
+    ports: ["9100:9100"]
+    volumes:
+      - ./config:/config:ro
+      - /var/run/docker.sock:/var/run/docker.sock
+    profiles: [templates]
+  worker:
+    extends: base
+    volumes:
+      - ./worker-config:/config:ro
+  pinned:
+    extends:
+      service: worker
+    image: ghcr.io/acme/worker:2.2
+    privileged: false
+    hostname: pinned
+`)
+	dc, err := ParseDockerCompose(compose, nil)
+	if err != nil {
+		t.Fatalf("ParseDockerCompose: %v", err)
+	}
+	byName := make(map[string]model.DockerService)
+	for _, svc := range dc.Services {
+		byName[svc.Name] = svc
+	}
+	if _, ok := byName["base"]; ok {
+		t.Error("profile-gated base service listed, want it only inherited from")
+	}
+
+	worker := byName["worker"]
+	if worker.Image != "ghcr.io/acme/worker:2.3" || !worker.Privileged {
+		t.Errorf("worker = %+v, want the base image and privileged", worker)
+	}
+	wantVolumes := []string{"/var/run/docker.sock:/var/run/docker.sock", "./worker-config:/config:ro"}
+	if !reflect.DeepEqual(worker.Volumes, wantVolumes) {
+		t.Errorf("worker volumes = %q, want %q", worker.Volumes, wantVolumes)
+	}
+
+	pinned := byName["pinned"]
+	if pinned.Image != "ghcr.io/acme/worker:2.2" || pinned.Privileged || pinned.Hostname != "pinned" ||
+		!reflect.DeepEqual(pinned.Ports, []string{"9100:9100"}) {
+		t.Errorf("pinned = %+v, want its own image, hostname and privileged: false over the inherited chain", pinned)
+	}
+
+	cyclic := []byte("services:\n  a:\n    extends: b\n  b:\n    extends: a\n")
+	if _, err := ParseDockerCompose(cyclic, nil); err == nil {
+		t.Error("ParseDockerCompose accepted an extends cycle")
+	}
+}
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ingressRouteGVRs are the Traefik IngressRoute APIs, newest first:
+// traefik.io since Traefik 2.10, traefik.containo.us before.
+var ingressRouteGVRs = []schema.GroupVersionResource{
+	{Group: "traefik.io", Version: "v1alpha1", Resource: "ingressroutes"},
+	{Group: "traefik.containo.us", Version: "v1alpha1", Resource: "ingressroutes"},
+}
+
+func (p *KubernetesParser) parseIngressRoutes(ctx context.Context) []model.IngressRouteInfo {
+	var list *unstructured.UnstructuredList
+	var err error
+	for _, gvr := range ingressRouteGVRs {
+		list, err = p.dynamic.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		slog.Debug("failed to list traefik ingressroutes (CRD may not exist)", "error", err)
+		return nil
+	}
+
+	var result []model.IngressRouteInfo
+	for _, item := range list.Items {
+		ir := model.IngressRouteInfo{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Cluster:   p.clusterName,
+		}
+		spec, _ := item.Object["spec"].(map[string]interface{})
+
+		if eps, ok := spec["entryPoints"].([]interface{}); ok {
+			for _, ep := range eps {
+				if s, ok := ep.(string); ok {
+					ir.EntryPoints = append(ir.EntryPoints, s)
+				}
+			}
+		}
+
+		routes, _ := spec["routes"].([]interface{})
+		for _, r := range routes {
+			rm, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			match := strVal(rm, "match")
+			rule := model.IngressRouteRule{
+				Match: match,
+				Hosts: matcherArgs(match, "Host"),
+				Paths: append(matcherArgs(match, "PathPrefix"), matcherArgs(match, "Path")...),
+			}
+			services, _ := rm["services"].([]interface{})
+			for _, svc := range services {
+				sm, ok := svc.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				ref := model.BackendRef{
+					Name:      strVal(sm, "name"),
+					Namespace: strVal(sm, "namespace"),
+					Kind:      strVal(sm, "kind"),
+				}
+				// port is an int or a named port; only numbers are kept.
+				ref.Port = intVal(sm, "port")
+				rule.Services = append(rule.Services, ref)
+			}
+			ir.Routes = append(ir.Routes, rule)
+		}
+
+		result = append(result, ir)
+	}
+	return result
+}
+
+// parseIngresses lists networking.k8s.io/v1 Ingresses, one rule per host and
+// path. A default backend becomes a rule with neither.
+func (p *KubernetesParser) parseIngresses(ctx context.Context) []model.IngressInfo {
+	list, err := p.typed.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list ingresses", "error", err)
+		return nil
+	}
+
+	var result []model.IngressInfo
+	for _, ing := range list.Items {
+		info := model.IngressInfo{
+			Name:      ing.Name,
+			Namespace: ing.Namespace,
+			Cluster:   p.clusterName,
+		}
+		if ing.Spec.IngressClassName != nil {
+			info.ClassName = *ing.Spec.IngressClassName
+		} else {
+			// Pre-IngressClass clusters still select the controller this way.
+			info.ClassName = ing.Annotations["kubernetes.io/ingress.class"]
+		}
+
+		if b := ing.Spec.DefaultBackend; b != nil {
+			info.Rules = append(info.Rules, model.IngressRule{Backend: ingressBackendRef(*b)})
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				info.Rules = append(info.Rules, model.IngressRule{
+					Host:    rule.Host,
+					Path:    path.Path,
+					Backend: ingressBackendRef(path.Backend),
+				})
+			}
+		}
+		for _, tls := range ing.Spec.TLS {
+			info.TLSHosts = append(info.TLSHosts, tls.Hosts...)
+		}
+
+		result = append(result, info)
+	}
+	return result
+}
+
+// ingressBackendRef converts an Ingress backend. Resource backends keep
+// their kind; named service ports leave Port at 0.
+func ingressBackendRef(b networkingv1.IngressBackend) model.BackendRef {
+	if b.Resource != nil {
+		return model.BackendRef{Name: b.Resource.Name, Kind: b.Resource.Kind}
+	}
+	if b.Service == nil {
+		return model.BackendRef{}
+	}
+	return model.BackendRef{Name: b.Service.Name, Port: int(b.Service.Port.Number)}
+}
+
+var (
+	// matcherCallRe finds "Name(args)" calls in a Traefik rule.
+	matcherCallRe = regexp.MustCompile(`\b([A-Za-z]+)\(([^)]*)\)`)
+	// matcherArgRe finds the backtick- or quote-delimited arguments of a call.
+	matcherArgRe = regexp.MustCompile("`([^`]*)`|\"([^\"]*)\"")
+)
+
+// matcherArgs returns the arguments of every call to matcher (exact name,
+// so "Path" doesn't pick up "PathPrefix") in a Traefik rule such as
+// Host(`a.example.com`) && PathPrefix(`/api`). Traefik v2 allows several
+// arguments per call, v3 one.
+func matcherArgs(rule, matcher string) []string {
+	var out []string
+	for _, call := range matcherCallRe.FindAllStringSubmatch(rule, -1) {
+		if !strings.EqualFold(call[1], matcher) {
+			continue
+		}
+		for _, arg := range matcherArgRe.FindAllStringSubmatch(call[2], -1) {
+			if v := arg[1] + arg[2]; v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseIngressRoutes(t *testing.T) {
+	newRoute := func(apiVersion string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       "IngressRoute",
+			"metadata":   map[string]interface{}{"name": "app", "namespace": "apps"},
+			"spec": map[string]interface{}{
+				"entryPoints": []interface{}{"websecure"},
+				"routes": []interface{}{map[string]interface{}{
+					"match": "Host(`app.example.com`) && PathPrefix(`/api`)",
+					"services": []interface{}{
+						map[string]interface{}{"name": "app-api", "port": int64(8080)},
+						map[string]interface{}{"name": "app-web", "namespace": "web", "port": "http"},
+					},
+				}},
+			},
+		}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		ingressRouteGVRs[0]: "IngressRouteList",
+		ingressRouteGVRs[1]: "IngressRouteList",
+	}
+	notFound := func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New(`the server could not find the requested resource`)
+	}
+
+	tests := []struct {
+		name string
+		dyn  func() *dynamicfake.FakeDynamicClient
+		want bool
+	}{
+		{"traefik.io", func() *dynamicfake.FakeDynamicClient {
+			return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, newRoute("traefik.io/v1alpha1"))
+		}, true},
+		{"legacy traefik.containo.us", func() *dynamicfake.FakeDynamicClient {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, newRoute("traefik.containo.us/v1alpha1"))
+			dyn.PrependReactor("list", "ingressroutes", func(a k8stesting.Action) (bool, runtime.Object, error) {
+				if a.GetResource().Group == "traefik.io" {
+					return notFound(a)
+				}
+				return false, nil, nil
+			})
+			return dyn
+		}, true},
+		{"CRD absent", func() *dynamicfake.FakeDynamicClient {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+			dyn.PrependReactor("list", "ingressroutes", notFound)
+			return dyn
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &KubernetesParser{dynamic: tt.dyn(), clusterName: "Homelab"}
+			routes := p.parseIngressRoutes(context.Background())
+			if !tt.want {
+				if len(routes) != 0 {
+					t.Fatalf("routes = %+v, want none", routes)
+				}
+				return
+			}
+
+			if len(routes) != 1 || len(routes[0].Routes) != 1 {
+				t.Fatalf("routes = %+v, want one IngressRoute with one rule", routes)
+			}
+			ir := routes[0]
+			if ir.Name != "app" || ir.Namespace != "apps" || ir.Cluster != "Homelab" {
+				t.Errorf("route = %s/%s@%s, want apps/app@Homelab", ir.Namespace, ir.Name, ir.Cluster)
+			}
+			if !reflect.DeepEqual(ir.EntryPoints, []string{"websecure"}) {
+				t.Errorf("EntryPoints = %v, want [websecure]", ir.EntryPoints)
+			}
+			rule := ir.Routes[0]
+			if !reflect.DeepEqual(rule.Hosts, []string{"app.example.com"}) {
+				t.Errorf("Hosts = %v, want [app.example.com]", rule.Hosts)
+			}
+			if !reflect.DeepEqual(rule.Paths, []string{"/api"}) {
+				t.Errorf("Paths = %v, want [/api]", rule.Paths)
+			}
+			wantServices := []model.BackendRef{
+				{Name: "app-api", Port: 8080},
+				{Name: "app-web", Namespace: "web"},
+			}
+			if !reflect.DeepEqual(rule.Services, wantServices) {
+				t.Errorf("Services = %+v, want %+v", rule.Services, wantServices)
+			}
+		})
+	}
+}
+
+func TestParseIngresses(t *testing.T) {
+	class := "nginx"
+	prefix := networkingv1.PathTypePrefix
+	backend := func(name string, port int32) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: name, Port: networkingv1.ServiceBackendPort{Number: port},
+		}}
+	}
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "apps"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &class,
+			TLS:              []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com", "api.example.com"}, SecretName: "shop-tls"}},
+			Rules: []networkingv1.IngressRule{
+				{Host: "shop.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{Path: "/", PathType: &prefix, Backend: backend("shop-web", 80)}},
+				}}},
+				{Host: "api.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/v1", PathType: &prefix, Backend: backend("shop-api", 8080)},
+						{Path: "/v2", PathType: &prefix, Backend: backend("shop-api-v2", 8080)},
+					},
+				}}},
+			},
+		},
+	}
+
+	t.Run("rules and TLS", func(t *testing.T) {
+		p := &KubernetesParser{typed: fake.NewSimpleClientset(ing), clusterName: "Homelab"}
+		got := p.parseIngresses(context.Background())
+		if len(got) != 1 {
+			t.Fatalf("ingresses = %+v, want one", got)
+		}
+		want := model.IngressInfo{
+			Name: "shop", Namespace: "apps", Cluster: "Homelab", ClassName: "nginx",
+			Rules: []model.IngressRule{
+				{Host: "shop.example.com", Path: "/", Backend: model.BackendRef{Name: "shop-web", Port: 80}},
+				{Host: "api.example.com", Path: "/v1", Backend: model.BackendRef{Name: "shop-api", Port: 8080}},
+				{Host: "api.example.com", Path: "/v2", Backend: model.BackendRef{Name: "shop-api-v2", Port: 8080}},
+			},
+			TLSHosts: []string{"shop.example.com", "api.example.com"},
+		}
+		if !reflect.DeepEqual(got[0], want) {
+			t.Errorf("ingress = %+v\nwant %+v", got[0], want)
+		}
+	})
+
+	t.Run("API unavailable", func(t *testing.T) {
+		typed := fake.NewSimpleClientset(ing)
+		typed.PrependReactor("list", "ingresses", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New(`the server could not find the requested resource`)
+		})
+		p := &KubernetesParser{typed: typed, clusterName: "Homelab"}
+		if got := p.parseIngresses(context.Background()); got != nil {
+			t.Errorf("ingresses = %+v, want nil", got)
+		}
+	})
+}
+
+func TestMatcherArgs(t *testing.T) {
+	tests := []struct {
+		rule    string
+		matcher string
+		want    []string
+	}{
+		{"Host(`a.example.com`) && PathPrefix(`/api`)", "Host", []string{"a.example.com"}},
+		{"Host(`a.example.com`, `b.example.com`)", "Host", []string{"a.example.com", "b.example.com"}},
+		{"Host(`a.example.com`) && PathPrefix(`/api`)", "Path", nil},
+		{"Path(\"/healthz\") || PathPrefix(`/static`)", "Path", []string{"/healthz"}},
+		{"HostRegexp(`{sub:[a-z]+}.example.com`)", "Host", nil},
+	}
+	for _, tt := range tests {
+		if got := matcherArgs(tt.rule, tt.matcher); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matcherArgs(%q, %q) = %v, want %v", tt.rule, tt.matcher, got, tt.want)
+		}
+	}
+}
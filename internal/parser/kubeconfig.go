@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// restConfigFromKubeconfig builds a client config from a kubeconfig file,
+// using opts.Context when set and the file's current context otherwise.
+// Credentials that need an external program are validated up front so a
+// missing plugin fails with an actionable error at startup instead of an
+// opaque one on the first API call.
+func restConfigFromKubeconfig(path string, opts Options) (*rest.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading kubeconfig %s: %w", path, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("kubeconfig %s is empty", path)
+	}
+
+	raw, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig %s: %w", path, err)
+	}
+
+	contextName := raw.CurrentContext
+	if opts.Context != "" {
+		if _, ok := raw.Contexts[opts.Context]; !ok {
+			return nil, fmt.Errorf("kubeconfig %s has no context %q", path, opts.Context)
+		}
+		contextName = opts.Context
+	}
+
+	if ctx, ok := raw.Contexts[contextName]; ok {
+		if auth, ok := raw.AuthInfos[ctx.AuthInfo]; ok {
+			if err := checkCredentialPlugin(ctx.AuthInfo, auth); err != nil {
+				return nil, fmt.Errorf("kubeconfig %s: %w", path, err)
+			}
+			addExecEnv(auth, opts.ExecEnv)
+		}
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	return clientcmd.NewDefaultClientConfig(*raw, overrides).ClientConfig()
+}
+
+// checkCredentialPlugin reports credentials this binary can't use: an exec
+// plugin that isn't installed, or a legacy auth-provider client-go dropped.
+func checkCredentialPlugin(user string, auth *clientcmdapi.AuthInfo) error {
+	if auth.Exec != nil {
+		if _, err := exec.LookPath(auth.Exec.Command); err != nil {
+			msg := fmt.Sprintf("user %q needs exec credential plugin %q, which is not installed or not on PATH; "+
+				"add it to the image or use a token or client-certificate kubeconfig", user, auth.Exec.Command)
+			if auth.Exec.InstallHint != "" {
+				msg += " (" + auth.Exec.InstallHint + ")"
+			}
+			return errors.New(msg)
+		}
+	}
+	if auth.AuthProvider != nil {
+		switch name := auth.AuthProvider.Name; name {
+		case "gcp", "azure":
+			return fmt.Errorf("user %q uses the %q auth-provider, which client-go no longer supports; "+
+				"regenerate the kubeconfig with an exec credential plugin (e.g. gke-gcloud-auth-plugin or kubelogin)", user, name)
+		}
+	}
+	return nil
+}
+
+// addExecEnv passes extra environment variables to an exec credential
+// plugin, overriding any the kubeconfig already sets.
+func addExecEnv(auth *clientcmdapi.AuthInfo, env map[string]string) {
+	if auth.Exec == nil || len(env) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var merged []clientcmdapi.ExecEnvVar
+	for _, e := range auth.Exec.Env {
+		if _, ok := env[e.Name]; !ok {
+			merged = append(merged, e)
+		}
+	}
+	for _, k := range keys {
+		merged = append(merged, clientcmdapi.ExecEnvVar{Name: k, Value: env[k]})
+	}
+	auth.Exec.Env = merged
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const execKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: gke
+  cluster:
+    server: https://203.0.113.10
+contexts:
+- name: gke
+  context:
+    cluster: gke
+    user: gke-user
+current-context: gke
+users:
+- name: gke-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: %s
+      installHint: Install gke-gcloud-auth-plugin for use with kubectl
+      env:
+      - name: CLOUDSDK_CORE_PROJECT
+        value: homelab
+      - name: USE_GKE_GCLOUD_AUTH_PLUGIN
+        value: "False"
+`
+
+func writeKubeconfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKubeconfigMissingExecPlugin(t *testing.T) {
+	path := writeKubeconfig(t, fmt.Sprintf(execKubeconfig, "gke-gcloud-auth-plugin-not-installed"))
+
+	_, err := NewKubernetesParser(path, "gke", "", Options{})
+	if err == nil {
+		t.Fatal("NewKubernetesParser() succeeded, want missing plugin error")
+	}
+	for _, want := range []string{
+		`exec credential plugin "gke-gcloud-auth-plugin-not-installed"`,
+		"not installed or not on PATH",
+		`user "gke-user"`,
+		"Install gke-gcloud-auth-plugin",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestKubeconfigExecEnvPassthrough(t *testing.T) {
+	// Any binary on PATH stands in for the plugin; it is never run here.
+	path := writeKubeconfig(t, fmt.Sprintf(execKubeconfig, "sh"))
+
+	cfg, err := restConfigFromKubeconfig(path, Options{ExecEnv: map[string]string{
+		"USE_GKE_GCLOUD_AUTH_PLUGIN": "True",
+		"HTTPS_PROXY":                "http://proxy:3128",
+	}})
+	if err != nil {
+		t.Fatalf("restConfigFromKubeconfig() error: %v", err)
+	}
+	if cfg.ExecProvider == nil {
+		t.Fatal("ExecProvider not set")
+	}
+
+	got := make(map[string]string)
+	for _, e := range cfg.ExecProvider.Env {
+		got[e.Name] = e.Value
+	}
+	want := map[string]string{
+		"CLOUDSDK_CORE_PROJECT":      "homelab",
+		"USE_GKE_GCLOUD_AUTH_PLUGIN": "True",
+		"HTTPS_PROXY":                "http://proxy:3128",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("exec env %s = %q, want %q", k, got[k], v)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("exec env = %v, want %v", got, want)
+	}
+}
+
+func TestKubeconfigRemovedAuthProvider(t *testing.T) {
+	path := writeKubeconfig(t, `apiVersion: v1
+kind: Config
+clusters:
+- name: old
+  cluster:
+    server: https://203.0.113.20
+contexts:
+- name: old
+  context:
+    cluster: old
+    user: old-user
+current-context: old
+users:
+- name: old-user
+  user:
+    auth-provider:
+      name: gcp
+`)
+
+	_, err := restConfigFromKubeconfig(path, Options{})
+	if err == nil || !strings.Contains(err.Error(), `"gcp" auth-provider`) {
+		t.Errorf("restConfigFromKubeconfig() error = %v, want unsupported gcp auth-provider", err)
+	}
+}
+
+func TestKubeconfigContextSelection(t *testing.T) {
+	path := writeKubeconfig(t, `apiVersion: v1
+kind: Config
+clusters:
+- name: homelab
+  cluster:
+    server: https://192.0.2.10:6443
+- name: nas
+  cluster:
+    server: https://192.0.2.20:6443
+contexts:
+- name: homelab
+  context:
+    cluster: homelab
+    user: admin
+- name: nas
+  context:
+    cluster: nas
+    user: admin
+current-context: homelab
+users:
+- name: admin
+  user:
+    token: secret
+`)
+
+	tests := []struct {
+		context    string
+		wantServer string
+		wantErr    string
+	}{
+		{"", "https://192.0.2.10:6443", ""},
+		{"homelab", "https://192.0.2.10:6443", ""},
+		{"nas", "https://192.0.2.20:6443", ""},
+		{"missing", "", `has no context "missing"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			cfg, err := restConfigFromKubeconfig(path, Options{Context: tt.context})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("restConfigFromKubeconfig() error: %v", err)
+			}
+			if cfg.Host != tt.wantServer {
+				t.Errorf("Host = %q, want %q", cfg.Host, tt.wantServer)
+			}
+		})
+	}
+}
+
+func TestRestConfigRateLimits(t *testing.T) {
+	path := writeKubeconfig(t, fmt.Sprintf(execKubeconfig, "sh"))
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantQPS   float32
+		wantBurst int
+	}{
+		{"defaults", Options{}, DefaultQPS, DefaultBurst},
+		{"configured", Options{QPS: 120, Burst: 240}, 120, 240},
+		{"only burst", Options{Burst: 30}, DefaultQPS, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := restConfig(path, tt.opts)
+			if err != nil {
+				t.Fatalf("restConfig() error: %v", err)
+			}
+			if cfg.QPS != tt.wantQPS || cfg.Burst != tt.wantBurst {
+				t.Errorf("QPS/Burst = %v/%d, want %v/%d", cfg.QPS, cfg.Burst, tt.wantQPS, tt.wantBurst)
+			}
+		})
+	}
+}
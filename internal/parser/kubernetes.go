@@ -2,21 +2,27 @@ package parser
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
+	"runtime/debug"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 
+	"github.com/Masterminds/semver/v3"
 	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // KubernetesParser queries the Kubernetes API for cluster state.
@@ -25,27 +31,89 @@ type KubernetesParser struct {
 	dynamic     dynamic.Interface
 	clusterName string
 	platform    string // optional: platform name applied to all nodes (e.g. "QNAP")
+	opts        Options
+	// derivedName is set when no cluster name was given and
+	// opts.ClusterNameLabel should supply it on each ParseAll.
+	derivedName bool
 }
 
+// Options tunes what a KubernetesParser collects. The zero value keeps the
+// default behavior.
+type Options struct {
+	// IncludeTerminatedPods keeps Succeeded/Failed pods in the image
+	// inventory, so images of recently completed Jobs stay visible for
+	// vulnerability tracking.
+	IncludeTerminatedPods bool
+
+	// TeamLabel is the label (or, failing that, annotation) key whose value
+	// names the owning team of a namespace or workload, e.g.
+	// "app.kubernetes.io/part-of". Empty disables team attribution.
+	TeamLabel string
+
+	// ExecEnv is passed to the kubeconfig's exec credential plugin, if any,
+	// on top of the env the kubeconfig itself declares.
+	ExecEnv map[string]string
+
+	// Context selects a context from the kubeconfig instead of its
+	// current-context, so one file can hold several clusters.
+	Context string
+
+	// ClusterNameLabel is a node label, e.g. "cluster.x-k8s.io/cluster-name",
+	// whose value names the cluster when the parser is created without a
+	// cluster name. Nodes without it leave DefaultClusterName.
+	ClusterNameLabel string
+
+	// QPS and Burst rate-limit the API clients. ParseAll fans out dozens of
+	// list calls, so zero means DefaultQPS/DefaultBurst rather than
+	// client-go's much lower 5/10.
+	QPS   float32
+	Burst int
+
+	// RefreshInterval is how often this cluster should be re-parsed; zero
+	// follows the caller's own schedule. The parser only reports it (see
+	// KubernetesParser.RefreshInterval): caching is up to the caller.
+	RefreshInterval time.Duration
+
+	// Primary marks the primary cluster: the one resources without a
+	// cluster of their own are attributed to. The parser only reports it
+	// (see KubernetesParser.Primary).
+	Primary bool
+
+	// EastWestNamespace and EastWestLabel locate Istio east-west gateway
+	// Services: those in the namespace carrying the label, whose value
+	// names the gateway's network. Empty means DefaultEastWestNamespace
+	// and DefaultEastWestLabel.
+	EastWestNamespace string
+	EastWestLabel     string
+
+	// RoleRules derive node roles from labels other than
+	// node-role.kubernetes.io/*, for distros that label nodes differently.
+	// They apply on top of DefaultRoleRules.
+	RoleRules []RoleRule
+}
+
+// DefaultClusterName names a cluster that is neither configured nor labelled.
+const DefaultClusterName = "Homelab"
+
+// Where east-west gateways are looked up when Options leaves it unset: the
+// namespace and network label of Istio's multi-network install guide.
+const (
+	DefaultEastWestNamespace = "istio-system"
+	DefaultEastWestLabel     = "topology.istio.io/network"
+)
+
+// Client-side rate limits used when Options leaves QPS/Burst unset.
+const (
+	DefaultQPS   float32 = 50
+	DefaultBurst         = 100
+)
+
 // NewKubernetesParser creates a parser from a kubeconfig path and cluster name.
-// Pass "" for kubeconfig to use in-cluster config.
+// Pass "" for kubeconfig to use in-cluster config, and "" for clusterName to
+// read it from opts.ClusterNameLabel (or use DefaultClusterName).
 // The platform parameter is optional; when set, all parsed nodes inherit it as a fallback.
-func NewKubernetesParser(kubeconfig, clusterName, platform string) (*KubernetesParser, error) {
-	var cfg *rest.Config
-	var err error
-
-	if kubeconfig != "" {
-		data, readErr := os.ReadFile(kubeconfig)
-		if readErr != nil {
-			return nil, fmt.Errorf("reading kubeconfig %s: %w", kubeconfig, readErr)
-		}
-		if len(data) == 0 {
-			return nil, fmt.Errorf("kubeconfig %s is empty", kubeconfig)
-		}
-		cfg, err = clientcmd.RESTConfigFromKubeConfig(data)
-	} else {
-		cfg, err = rest.InClusterConfig()
-	}
+func NewKubernetesParser(kubeconfig, clusterName, platform string, opts Options) (*KubernetesParser, error) {
+	cfg, err := restConfig(kubeconfig, opts)
 	if err != nil {
 		return nil, fmt.Errorf("building k8s config: %w", err)
 	}
@@ -60,7 +128,64 @@ func NewKubernetesParser(kubeconfig, clusterName, platform string) (*KubernetesP
 		return nil, fmt.Errorf("creating dynamic client: %w", err)
 	}
 
-	return &KubernetesParser{typed: typed, dynamic: dyn, clusterName: clusterName, platform: platform}, nil
+	p := &KubernetesParser{typed: typed, dynamic: dyn, clusterName: clusterName, platform: platform, opts: opts}
+	if clusterName == "" {
+		p.clusterName = DefaultClusterName
+		p.derivedName = opts.ClusterNameLabel != ""
+	}
+	return p, nil
+}
+
+// ClusterName returns the name stamped on everything this parser collects.
+func (p *KubernetesParser) ClusterName() string {
+	return p.clusterName
+}
+
+// Clientset returns the parser's typed client, for callers that read
+// cluster objects the parser doesn't model (e.g. configuration).
+func (p *KubernetesParser) Clientset() kubernetes.Interface {
+	return p.typed
+}
+
+// RefreshInterval returns Options.RefreshInterval.
+func (p *KubernetesParser) RefreshInterval() time.Duration {
+	return p.opts.RefreshInterval
+}
+
+// Primary returns Options.Primary.
+func (p *KubernetesParser) Primary() bool {
+	return p.opts.Primary
+}
+
+// Ping checks that the API server answers at all, by fetching /version.
+// Per-resource list errors (RBAC, missing CRDs) don't fail it.
+func (p *KubernetesParser) Ping(ctx context.Context) error {
+	return p.typed.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+}
+
+// restConfig builds the client config shared by the typed and dynamic
+// clients, with the rate limits from opts applied.
+func restConfig(kubeconfig string, opts Options) (*rest.Config, error) {
+	var cfg *rest.Config
+	var err error
+
+	if kubeconfig != "" {
+		cfg, err = restConfigFromKubeconfig(kubeconfig, opts)
+	} else {
+		cfg, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.QPS, cfg.Burst = DefaultQPS, DefaultBurst
+	if opts.QPS > 0 {
+		cfg.QPS = opts.QPS
+	}
+	if opts.Burst > 0 {
+		cfg.Burst = opts.Burst
+	}
+	return cfg, nil
 }
 
 // ParseSecurity returns only namespace and security policy data for this cluster.
@@ -78,6 +203,11 @@ func (p *KubernetesParser) ParseFlux(ctx context.Context) []model.FluxKustomizat
 	return p.parseFluxKustomizations(ctx)
 }
 
+// ParseFluxSources returns Flux source (Git/OCI/Helm repository) sync status for this cluster.
+func (p *KubernetesParser) ParseFluxSources(ctx context.Context) []model.FluxSourceInfo {
+	return p.parseFluxSources(ctx)
+}
+
 // ParseNodes returns node data for this cluster.
 func (p *KubernetesParser) ParseNodes(ctx context.Context) []model.NodeInfo {
 	return p.parseNodes(ctx)
@@ -146,40 +276,126 @@ func (p *KubernetesParser) ParseVulnReports(ctx context.Context) []model.ImageVu
 // ParseAll queries all supported resources and returns cluster data.
 // All parse methods run concurrently via errgroup for faster collection.
 func (p *KubernetesParser) ParseAll(ctx context.Context) *model.ClusterData {
+	if p.derivedName {
+		// Resolved before the parse steps start, so they all stamp the same name.
+		p.clusterName = p.clusterNameFromNodes(ctx)
+	}
+
 	data := &model.ClusterData{}
 	g, gctx := errgroup.WithContext(ctx)
 
-	g.Go(func() error { data.Nodes = p.parseNodes(gctx); return nil })
-	g.Go(func() error { data.Flux = p.parseFluxKustomizations(gctx); return nil })
-	g.Go(func() error { data.Gateways = p.parseGateways(gctx); return nil })
-	g.Go(func() error { data.HTTPRoutes = p.parseHTTPRoutes(gctx); return nil })
-	g.Go(func() error { data.Namespaces = p.parseNamespaces(gctx); return nil })
-	g.Go(func() error { data.SecurityPolicies = p.parseSecurityPolicies(gctx); return nil })
-	g.Go(func() error { data.ClientTrafficPolicies = p.parseClientTrafficPolicies(gctx); return nil })
-	g.Go(func() error { data.ServiceEntries = p.parseServiceEntries(gctx); return nil })
-	g.Go(func() error { data.EastWestGateways = p.parseEastWestGateways(gctx); return nil })
-	g.Go(func() error { data.LoadBalancers = p.parseLoadBalancers(gctx); return nil })
-	g.Go(func() error { data.HelmReleases = p.parseHelmReleases(gctx); return nil })
-	g.Go(func() error { data.HelmRepositories = p.parseHelmRepositories(gctx); return nil })
-	g.Go(func() error { data.Pods = p.parsePods(gctx); return nil })
-	g.Go(func() error { data.Workloads = p.parseWorkloads(gctx); return nil })
-	g.Go(func() error { data.Storage = p.parseStorage(gctx); return nil })
-	g.Go(func() error { data.CRDs = p.parseCRDs(gctx); return nil })
-	g.Go(func() error { data.Quotas = p.parseQuotas(gctx); return nil })
-	g.Go(func() error { data.Certificates = p.parseCertificates(gctx); return nil })
-	g.Go(func() error { data.NetworkPolicies = p.parseNetworkPolicies(gctx); return nil })
-	g.Go(func() error { data.Configs = p.parseConfigs(gctx); return nil })
-	g.Go(func() error { data.Services = p.parseServices(gctx); return nil })
-	g.Go(func() error { data.RBACBindings = p.parseRBAC(gctx); return nil })
-	g.Go(func() error { data.VeleroSchedules = p.parseVeleroSchedules(gctx); return nil })
-	g.Go(func() error { data.ImageVulns = p.parseVulnReports(gctx); return nil })
+	goParse(g, "parseNodes", func() { data.Nodes = p.parseNodes(gctx) })
+	goParse(g, "parseAPIServer", func() { data.APIServers = p.parseAPIServer() })
+	goParse(g, "parseFluxKustomizations", func() { data.Flux = p.parseFluxKustomizations(gctx) })
+	goParse(g, "parseArgoApplications", func() { data.ArgoApps = p.parseArgoApplications(gctx) })
+	goParse(g, "parseCAPIClusters", func() { data.CAPIClusters = p.parseCAPIClusters(gctx) })
+	goParse(g, "parseFluxSources", func() { data.FluxSources = p.parseFluxSources(gctx) })
+	goParse(g, "parseGateways", func() { data.Gateways = p.parseGateways(gctx) })
+	goParse(g, "parseHTTPRoutes", func() { data.HTTPRoutes = p.parseHTTPRoutes(gctx) })
+	goParse(g, "parseIngressRoutes", func() { data.IngressRoutes = p.parseIngressRoutes(gctx) })
+	goParse(g, "parseIngresses", func() { data.Ingresses = p.parseIngresses(gctx) })
+	var grantsListed bool
+	goParse(g, "parseReferenceGrants", func() { data.ReferenceGrants, grantsListed = p.parseReferenceGrants(gctx) })
+	goParse(g, "parseNamespaces", func() { data.Namespaces = p.parseNamespaces(gctx) })
+	goParse(g, "parseSecurityPolicies", func() { data.SecurityPolicies = p.parseSecurityPolicies(gctx) })
+	goParse(g, "parseClientTrafficPolicies", func() { data.ClientTrafficPolicies = p.parseClientTrafficPolicies(gctx) })
+	goParse(g, "parseServiceEntries", func() { data.ServiceEntries = p.parseServiceEntries(gctx) })
+	goParse(g, "parseEastWestGateways", func() { data.EastWestGateways = p.parseEastWestGateways(gctx) })
+	goParse(g, "parseLoadBalancers", func() { data.LoadBalancers = p.parseLoadBalancers(gctx) })
+	goParse(g, "parseHelmReleases", func() { data.HelmReleases = p.parseHelmReleases(gctx) })
+	goParse(g, "parseHelmRepositories", func() { data.HelmRepositories = p.parseHelmRepositories(gctx) })
+	goParse(g, "parsePods", func() { data.Pods = p.parsePods(gctx) })
+	goParse(g, "parseWorkloads", func() { data.Workloads = p.parseWorkloads(gctx) })
+	goParse(g, "parseAutoscalers", func() { data.Autoscalers = p.parseAutoscalers(gctx) })
+	goParse(g, "parseStorage", func() { data.Storage = p.parseStorage(gctx) })
+	goParse(g, "parseCRDs", func() { data.CRDs = p.parseCRDs(gctx) })
+	goParse(g, "parseCapabilities", func() { data.Capabilities = p.parseCapabilities() })
+	goParse(g, "parseQuotas", func() { data.Quotas = p.parseQuotas(gctx) })
+	goParse(g, "parseCertificates", func() { data.Certificates = p.parseCertificates(gctx) })
+	goParse(g, "parseNetworkPolicies", func() { data.NetworkPolicies = p.parseNetworkPolicies(gctx) })
+	goParse(g, "parseConfigs", func() { data.Configs = p.parseConfigs(gctx) })
+	goParse(g, "parseServices", func() { data.Services = p.parseServices(gctx) })
+	goParse(g, "parseRBAC", func() { data.RBACBindings = p.parseRBAC(gctx) })
+	goParse(g, "parseVeleroSchedules", func() { data.VeleroSchedules = p.parseVeleroSchedules(gctx) })
+	var backupsListed bool
+	goParse(g, "parseVeleroBackups", func() { data.VeleroBackups, backupsListed = p.parseVeleroBackups(gctx) })
+	goParse(g, "parseEvents", func() { data.Events = p.parseEvents(gctx) })
+	goParse(g, "parseDeprecatedAPIs", func() { data.DeprecatedAPIs = p.parseDeprecatedAPIs(gctx) })
+	goParse(g, "parseVulnReports", func() { data.ImageVulns = p.parseVulnReports(gctx) })
 
 	if err := g.Wait(); err != nil {
 		slog.Warn("error during parallel parse", "error", err)
 	}
+
+	// Without the ReferenceGrant CRD there is nothing to check against.
+	if grantsListed {
+		markUngrantedRefs(data.HTTPRoutes, data.ReferenceGrants)
+	}
+	// Without the Backup CRD the backup label is all there is.
+	if backupsListed {
+		markLastBackups(data.Namespaces, data.VeleroBackups)
+	}
 	return data
 }
 
+// teamOf returns the owning team from the configured team label, falling
+// back to an annotation of the same key.
+func (p *KubernetesParser) teamOf(labels, annotations map[string]string) string {
+	if p.opts.TeamLabel == "" {
+		return ""
+	}
+	if v := labels[p.opts.TeamLabel]; v != "" {
+		return v
+	}
+	return annotations[p.opts.TeamLabel]
+}
+
+// goParse runs one parse step on the errgroup, recovering a panic (e.g. an
+// unexpected CRD shape tripping a nil map) so it can't crash the process
+// from a goroutine the caller has no way to recover. The step's field stays
+// empty, like any other failed list. The panic is not returned as an error:
+// that would cancel gctx and fail every sibling step along with it.
+func goParse(g *errgroup.Group, name string, fn func()) {
+	g.Go(func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("parse step panicked", "step", name, "panic", r, "stack", string(debug.Stack()))
+			}
+		}()
+		fn()
+		return nil
+	})
+}
+
+// clusterNameFromNodes returns the opts.ClusterNameLabel value of the first
+// node carrying it, or DefaultClusterName.
+func (p *KubernetesParser) clusterNameFromNodes(ctx context.Context) string {
+	list, err := p.typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: p.opts.ClusterNameLabel})
+	if err != nil {
+		slog.Warn("failed to list nodes for cluster name — keeping the last one", "label", p.opts.ClusterNameLabel, "error", err)
+		return p.clusterName
+	}
+	// List order is not guaranteed; the alphabetically first labelled node wins.
+	name, first := DefaultClusterName, ""
+	for _, n := range list.Items {
+		if v := n.Labels[p.opts.ClusterNameLabel]; v != "" && (first == "" || n.Name < first) {
+			name, first = v, n.Name
+		}
+	}
+	return name
+}
+
+// parseAPIServer reads the API server version from discovery's /version.
+// Kubelets may lag it, so it is the cluster's version for skew checks.
+func (p *KubernetesParser) parseAPIServer() []model.APIServerInfo {
+	v, err := p.typed.Discovery().ServerVersion()
+	if err != nil {
+		slog.Warn("failed to read api server version", "error", err)
+		return nil
+	}
+	return []model.APIServerInfo{{Cluster: p.clusterName, GitVersion: v.GitVersion, Platform: v.Platform}}
+}
+
 func (p *KubernetesParser) parseNodes(ctx context.Context) []model.NodeInfo {
 	list, err := p.typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -197,10 +413,24 @@ func (p *KubernetesParser) parseNodes(ctx context.Context) []model.NodeInfo {
 			}
 		}
 
-		var roles []string
-		for label := range n.Labels {
-			if strings.HasPrefix(label, "node-role.kubernetes.io/") {
-				roles = append(roles, strings.TrimPrefix(label, "node-role.kubernetes.io/"))
+		roles := nodeRoles(n.Labels, p.opts.RoleRules)
+
+		var taints []model.NodeTaint
+		for _, t := range n.Spec.Taints {
+			taints = append(taints, model.NodeTaint{Key: t.Key, Value: t.Value, Effect: string(t.Effect)})
+		}
+
+		ready := false
+		var conditions []string
+		for _, c := range n.Status.Conditions {
+			if c.Type == corev1.NodeReady {
+				ready = c.Status == corev1.ConditionTrue
+				continue
+			}
+			// Every other node condition (MemoryPressure, DiskPressure,
+			// PIDPressure, NetworkUnavailable, ...) is a problem when True.
+			if c.Status == corev1.ConditionTrue {
+				conditions = append(conditions, string(c.Type))
 			}
 		}
 
@@ -223,6 +453,11 @@ func (p *KubernetesParser) parseNodes(ctx context.Context) []model.NodeInfo {
 			Architecture:     n.Status.NodeInfo.Architecture,
 			ProviderID:       n.Spec.ProviderID,
 			Platform:         p.platform,
+			PodCapacity:      int(n.Status.Capacity.Pods().Value()),
+			Taints:           taints,
+			Ready:            ready,
+			Schedulable:      !n.Spec.Unschedulable,
+			Conditions:       conditions,
 		})
 	}
 	return nodes
@@ -305,11 +540,7 @@ func (p *KubernetesParser) parseGateways(ctx context.Context) []model.GatewayInf
 				Hostname: strVal(lm, "hostname"),
 				Protocol: strVal(lm, "protocol"),
 			}
-			if port, ok := lm["port"].(int64); ok {
-				li.Port = int(port)
-			} else if port, ok := lm["port"].(float64); ok {
-				li.Port = int(port)
-			}
+			li.Port = intVal(lm, "port")
 			gw.Listeners = append(gw.Listeners, li)
 		}
 
@@ -370,12 +601,13 @@ func (p *KubernetesParser) parseHTTPRoutes(ctx context.Context) []model.HTTPRout
 						if !ok {
 							continue
 						}
-						ref := model.BackendRef{Name: strVal(bm, "name")}
-						if port, ok := bm["port"].(int64); ok {
-							ref.Port = int(port)
-						} else if port, ok := bm["port"].(float64); ok {
-							ref.Port = int(port)
+						ref := model.BackendRef{
+							Name:      strVal(bm, "name"),
+							Namespace: strVal(bm, "namespace"),
+							Group:     strVal(bm, "group"),
+							Kind:      strVal(bm, "kind"),
 						}
+						ref.Port = intVal(bm, "port")
 						route.Backends = append(route.Backends, ref)
 					}
 				}
@@ -387,37 +619,109 @@ func (p *KubernetesParser) parseHTTPRoutes(ctx context.Context) []model.HTTPRout
 	return result
 }
 
-func (p *KubernetesParser) parseNamespaces(ctx context.Context) []model.NamespaceInfo {
-	list, err := p.typed.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+// parseReferenceGrants lists Gateway API ReferenceGrants. ok is false when
+// they can't be listed (typically the CRD is not installed).
+func (p *KubernetesParser) parseReferenceGrants(ctx context.Context) (grants []model.ReferenceGrantInfo, ok bool) {
+	gvr := schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1beta1",
+		Resource: "referencegrants",
+	}
+
+	list, err := p.dynamic.Resource(gvr).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		slog.Warn("failed to list namespaces", "error", err)
-		return nil
+		slog.Debug("failed to list referencegrants (CRD may not exist)", "error", err)
+		return nil, false
 	}
 
-	// Filter to namespaces that look like app namespaces (not system ones)
-	systemPrefixes := []string{"kube-", "flux-", "cert-manager", "envoy-gateway", "istio-", "cnpg-", "rook-", "ot-operators"}
-	systemExact := map[string]bool{
-		"default": true, "kube-system": true, "kube-public": true,
-		"kube-node-lease": true, "flux-system": true, "local-path-storage": true,
+	for _, item := range list.Items {
+		grant := model.ReferenceGrantInfo{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Cluster:   p.clusterName,
+		}
+		spec, _ := item.Object["spec"].(map[string]interface{})
+		if from, ok := spec["from"].([]interface{}); ok {
+			for _, f := range from {
+				if fm, ok := f.(map[string]interface{}); ok {
+					grant.From = append(grant.From, model.ReferenceGrantFrom{
+						Group:     strVal(fm, "group"),
+						Kind:      strVal(fm, "kind"),
+						Namespace: strVal(fm, "namespace"),
+					})
+				}
+			}
+		}
+		if to, ok := spec["to"].([]interface{}); ok {
+			for _, t := range to {
+				if tm, ok := t.(map[string]interface{}); ok {
+					grant.To = append(grant.To, model.ReferenceGrantTo{
+						Group: strVal(tm, "group"),
+						Kind:  strVal(tm, "kind"),
+						Name:  strVal(tm, "name"),
+					})
+				}
+			}
+		}
+		grants = append(grants, grant)
 	}
+	return grants, true
+}
 
-	var result []model.NamespaceInfo
-	for _, ns := range list.Items {
-		name := ns.Name
-		if systemExact[name] {
+// markUngrantedRefs flags cross-namespace backend refs that no ReferenceGrant
+// in the backend's namespace allows from the route's namespace.
+func markUngrantedRefs(routes []model.HTTPRouteInfo, grants []model.ReferenceGrantInfo) {
+	for i := range routes {
+		route := &routes[i]
+		for j := range route.Backends {
+			ref := &route.Backends[j]
+			if ref.Namespace == "" || ref.Namespace == route.Namespace {
+				continue
+			}
+			ref.Ungranted = !refGranted(grants, route.Namespace, *ref)
+		}
+	}
+}
+
+// refGranted reports whether an HTTPRoute in fromNS may reference ref.
+func refGranted(grants []model.ReferenceGrantInfo, fromNS string, ref model.BackendRef) bool {
+	kind := ref.Kind
+	if kind == "" {
+		kind = "Service"
+	}
+	for _, g := range grants {
+		if g.Namespace != ref.Namespace {
 			continue
 		}
-		skip := false
-		for _, prefix := range systemPrefixes {
-			if strings.HasPrefix(name, prefix) {
-				skip = true
+		fromOK := false
+		for _, f := range g.From {
+			if f.Group == "gateway.networking.k8s.io" && f.Kind == "HTTPRoute" && f.Namespace == fromNS {
+				fromOK = true
 				break
 			}
 		}
-		if skip {
+		if !fromOK {
 			continue
 		}
+		for _, t := range g.To {
+			if t.Group == ref.Group && t.Kind == kind && (t.Name == "" || t.Name == ref.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *KubernetesParser) parseNamespaces(ctx context.Context) []model.NamespaceInfo {
+	list, err := p.typed.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list namespaces", "error", err)
+		return nil
+	}
 
+	var result []model.NamespaceInfo
+	for _, ns := range list.Items {
+		name := ns.Name
 		labels := ns.Labels
 		if labels == nil {
 			labels = map[string]string{}
@@ -431,11 +735,38 @@ func (p *KubernetesParser) parseNamespaces(ctx context.Context) []model.Namespac
 			Backup:      labels["backup"] == "velero",
 			MTLS:        labels["mtls.enabled"] == "true",
 			PodSecurity: labels["pod-security.kubernetes.io/enforce"],
+			Team:        p.teamOf(ns.Labels, ns.Annotations),
+			Labels:      ns.Labels,
+			System:      IsSystemNamespace(name),
 		})
 	}
 	return result
 }
 
+// System namespaces: Kubernetes' own, "default", and those of the cluster
+// add-ons, as opposed to app namespaces.
+var (
+	systemNamespacePrefixes = []string{"kube-", "flux-", "cert-manager", "envoy-gateway", "istio-", "cnpg-", "rook-", "ot-operators"}
+	systemNamespaces        = map[string]bool{
+		"default": true, "kube-system": true, "kube-public": true,
+		"kube-node-lease": true, "flux-system": true, "local-path-storage": true,
+	}
+)
+
+// IsSystemNamespace reports whether name is a system namespace rather than
+// one holding apps. Diagrams hide these unless configured to include them.
+func IsSystemNamespace(name string) bool {
+	if systemNamespaces[name] {
+		return true
+	}
+	for _, prefix := range systemNamespacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *KubernetesParser) parseSecurityPolicies(ctx context.Context) []model.SecurityPolicyInfo {
 	// Try Envoy Gateway SecurityPolicy
 	gvr := schema.GroupVersionResource{
@@ -533,6 +864,17 @@ func (p *KubernetesParser) parseServiceEntries(ctx context.Context) []model.Serv
 
 		location, _ := spec["location"].(string)
 
+		var ports []int
+		if ps, ok := spec["ports"].([]interface{}); ok {
+			for _, pv := range ps {
+				if pm, ok := pv.(map[string]interface{}); ok {
+					if n := intVal(pm, "number"); n > 0 {
+						ports = append(ports, n)
+					}
+				}
+			}
+		}
+
 		var endpointAddr, network string
 		if endpoints, ok := spec["endpoints"].([]interface{}); ok && len(endpoints) > 0 {
 			if ep, ok := endpoints[0].(map[string]interface{}); ok {
@@ -549,6 +891,7 @@ func (p *KubernetesParser) parseServiceEntries(ctx context.Context) []model.Serv
 			Cluster:         p.clusterName,
 			Hosts:           hosts,
 			Location:        location,
+			Ports:           ports,
 			EndpointAddress: endpointAddr,
 			Network:         network,
 		})
@@ -557,17 +900,24 @@ func (p *KubernetesParser) parseServiceEntries(ctx context.Context) []model.Serv
 }
 
 func (p *KubernetesParser) parseEastWestGateways(ctx context.Context) []model.EastWestGateway {
-	list, err := p.typed.CoreV1().Services("istio-system").List(ctx, metav1.ListOptions{
-		LabelSelector: "topology.istio.io/network",
+	namespace, label := p.opts.EastWestNamespace, p.opts.EastWestLabel
+	if namespace == "" {
+		namespace = DefaultEastWestNamespace
+	}
+	if label == "" {
+		label = DefaultEastWestLabel
+	}
+	list, err := p.typed.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: label,
 	})
 	if err != nil {
-		slog.Warn("failed to list east-west gateway services", "error", err)
+		slog.Warn("failed to list east-west gateway services", "namespace", namespace, "error", err)
 		return nil
 	}
 
 	var result []model.EastWestGateway
 	for _, svc := range list.Items {
-		network := svc.Labels["topology.istio.io/network"]
+		network := svc.Labels[label]
 
 		ip := ""
 		if len(svc.Status.LoadBalancer.Ingress) > 0 {
@@ -656,7 +1006,6 @@ func (p *KubernetesParser) parseHelmReleases(ctx context.Context) []model.HelmRe
 		chartSpec, _ := chart["spec"].(map[string]interface{})
 
 		chartName := strVal(chartSpec, "chart")
-		version := strVal(chartSpec, "version")
 
 		repoName := ""
 		repoNS := ""
@@ -668,30 +1017,67 @@ func (p *KubernetesParser) parseHelmReleases(ctx context.Context) []model.HelmRe
 			repoNS = item.GetNamespace()
 		}
 
-		// Try to get appVersion from status
-		appVersion := ""
-		if status, ok := item.Object["status"].(map[string]interface{}); ok {
-			if history, ok := status["history"].([]interface{}); ok && len(history) > 0 {
-				if latest, ok := history[0].(map[string]interface{}); ok {
-					appVersion = strVal(latest, "appVersion")
-				}
+		// Try to get the deployed chart and app versions from status
+		appVersion, deployed := "", ""
+		status, _ := item.Object["status"].(map[string]interface{})
+		if history, ok := status["history"].([]interface{}); ok && len(history) > 0 {
+			if latest, ok := history[0].(map[string]interface{}); ok {
+				appVersion = strVal(latest, "appVersion")
+				deployed = strVal(latest, "chartVersion")
 			}
 		}
-
-		result = append(result, model.HelmReleaseInfo{
-			Name:       item.GetName(),
-			Namespace:  item.GetNamespace(),
-			Cluster:    p.clusterName,
-			ChartName:  chartName,
-			Version:    version,
-			RepoName:   repoName,
-			RepoNS:     repoNS,
-			AppVersion: appVersion,
-		})
+		version, constraint := releaseVersion(strVal(chartSpec, "version"), deployed)
+
+		rel := model.HelmReleaseInfo{
+			Name:                   item.GetName(),
+			Namespace:              item.GetNamespace(),
+			Cluster:                p.clusterName,
+			ChartName:              chartName,
+			Version:                version,
+			VersionConstraint:      constraint,
+			RepoName:               repoName,
+			RepoNS:                 repoNS,
+			AppVersion:             appVersion,
+			Failures:               intVal(status, "failures"),
+			InstallFailures:        intVal(status, "installFailures"),
+			UpgradeFailures:        intVal(status, "upgradeFailures"),
+			LastHandledReconcileAt: strVal(status, "lastHandledReconcileAt"),
+		}
+		rel.Thrashing = releaseThrashing(rel)
+		result = append(result, rel)
 	}
 	return result
 }
 
+// releaseVersion splits a HelmRelease's spec.chart.spec.version into the
+// deployed version and the constraint it was resolved from. An exact version
+// is its own deployed version until the status says otherwise; a range, or
+// the "*" an unset version defaults to, is a constraint, and the deployed
+// version is only known from the status history.
+func releaseVersion(spec, deployed string) (version, constraint string) {
+	if spec == "" {
+		spec = "*"
+	}
+	if _, err := semver.StrictNewVersion(strings.TrimPrefix(spec, "v")); err != nil {
+		constraint = spec
+	} else if deployed == "" {
+		deployed = spec
+	}
+	return deployed, constraint
+}
+
+// thrashingFailures is the consecutive reconcile failure count from which a
+// HelmRelease counts as thrashing even without install/upgrade failures.
+const thrashingFailures = 3
+
+// releaseThrashing reports whether a HelmRelease is stuck retrying: an
+// install or upgrade keeps failing and being remediated, or reconciles have
+// failed thrashingFailures times in a row. Flux clears the counters on the
+// next success, so a release that recovered is not flagged.
+func releaseThrashing(rel model.HelmReleaseInfo) bool {
+	return rel.InstallFailures > 0 || rel.UpgradeFailures > 0 || rel.Failures >= thrashingFailures
+}
+
 func (p *KubernetesParser) parseHelmRepositories(ctx context.Context) []model.HelmRepositoryInfo {
 	gvr := schema.GroupVersionResource{
 		Group:    "source.toolkit.fluxcd.io",
@@ -725,6 +1111,71 @@ func (p *KubernetesParser) parseHelmRepositories(ctx context.Context) []model.He
 	return result
 }
 
+// fluxSourceKinds lists the Flux source CRDs shown in the sources table.
+var fluxSourceKinds = []struct {
+	kind string
+	gvr  schema.GroupVersionResource
+}{
+	{"GitRepository", schema.GroupVersionResource{Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "gitrepositories"}},
+	{"OCIRepository", schema.GroupVersionResource{Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Resource: "ocirepositories"}},
+	{"HelmRepository", schema.GroupVersionResource{Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "helmrepositories"}},
+}
+
+func (p *KubernetesParser) parseFluxSources(ctx context.Context) []model.FluxSourceInfo {
+	var result []model.FluxSourceInfo
+	for _, sk := range fluxSourceKinds {
+		list, err := p.dynamic.Resource(sk.gvr).Namespace("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			slog.Debug("failed to list flux sources (CRD may not exist)", "kind", sk.kind, "error", err)
+			continue
+		}
+
+		for _, item := range list.Items {
+			spec, _ := item.Object["spec"].(map[string]interface{})
+			status, _ := item.Object["status"].(map[string]interface{})
+			artifact, _ := status["artifact"].(map[string]interface{})
+
+			src := model.FluxSourceInfo{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+				Cluster:   p.clusterName,
+				Kind:      sk.kind,
+				URL:       strVal(spec, "url"),
+				Revision:  strVal(artifact, "revision"),
+				LastSync:  strVal(artifact, "lastUpdateTime"),
+			}
+
+			// Tracked ref: first of the ref fields set, e.g. "branch: main"
+			if ref, ok := spec["ref"].(map[string]interface{}); ok {
+				for _, key := range []string{"branch", "tag", "semver", "name", "commit", "digest"} {
+					if v := strVal(ref, key); v != "" {
+						src.Ref = key + ": " + v
+						break
+					}
+				}
+			}
+
+			if conditions, ok := status["conditions"].([]interface{}); ok {
+				for _, c := range conditions {
+					cm, ok := c.(map[string]interface{})
+					if !ok || strVal(cm, "type") != "Ready" {
+						continue
+					}
+					src.Ready = strVal(cm, "status") == "True"
+					src.Message = strVal(cm, "message")
+					if src.LastSync == "" {
+						src.LastSync = strVal(cm, "lastTransitionTime")
+					}
+					break
+				}
+			}
+
+			result = append(result, src)
+		}
+	}
+	return result
+}
+
 func (p *KubernetesParser) parsePods(ctx context.Context) []model.PodImageInfo {
 	list, err := p.typed.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -732,11 +1183,13 @@ func (p *KubernetesParser) parsePods(ctx context.Context) []model.PodImageInfo {
 		return nil
 	}
 
+	owners := p.listOwners(ctx)
+
 	var result []model.PodImageInfo
 	for _, pod := range list.Items {
-		// Skip terminal pods
+		// Skip terminal pods unless configured to keep them
 		phase := pod.Status.Phase
-		if phase == "Succeeded" || phase == "Failed" {
+		if (phase == "Succeeded" || phase == "Failed") && !p.opts.IncludeTerminatedPods {
 			continue
 		}
 
@@ -751,41 +1204,159 @@ func (p *KubernetesParser) parsePods(ctx context.Context) []model.PodImageInfo {
 			statusImages[cs.Name] = cs.Image
 			imageIDs[cs.Name] = cs.ImageID
 		}
+		for _, cs := range pod.Status.EphemeralContainerStatuses {
+			statusImages[cs.Name] = cs.Image
+			imageIDs[cs.Name] = cs.ImageID
+		}
 
-		for _, c := range pod.Spec.Containers {
-			img := c.Image
-			if resolved := statusImages[c.Name]; resolved != "" {
-				img = resolved
+		release := pod.Labels["helm.toolkit.fluxcd.io/name"]
+		if release == "" {
+			release = pod.Labels["app.kubernetes.io/instance"]
+		}
+		owner := podOwner(&pod, owners)
+
+		add := func(name, image string, init, ephemeral bool) {
+			if resolved := statusImages[name]; resolved != "" {
+				image = resolved
 			}
+			version, source := versionHint(&pod, name)
 			result = append(result, model.PodImageInfo{
 				Cluster:       p.clusterName,
 				Namespace:     pod.Namespace,
 				PodName:       pod.Name,
-				Container:     c.Name,
-				Image:         img,
-				ImageID:       imageIDs[c.Name],
-				InitContainer: false,
+				Container:     name,
+				Image:         image,
+				ImageID:       imageIDs[name],
+				InitContainer: init,
+				Ephemeral:     ephemeral,
+				State:         string(phase),
+				NodeName:      pod.Spec.NodeName,
+				HelmRelease:   release,
+				Owner:         owner,
+				Version:       version,
+				VersionSource: source,
 			})
 		}
+		for _, c := range pod.Spec.Containers {
+			add(c.Name, c.Image, false, false)
+		}
 		for _, c := range pod.Spec.InitContainers {
-			img := c.Image
-			if resolved := statusImages[c.Name]; resolved != "" {
-				img = resolved
-			}
-			result = append(result, model.PodImageInfo{
-				Cluster:       p.clusterName,
-				Namespace:     pod.Namespace,
-				PodName:       pod.Name,
-				Container:     c.Name,
-				Image:         img,
-				ImageID:       imageIDs[c.Name],
-				InitContainer: true,
-			})
+			add(c.Name, c.Image, true, false)
+		}
+		// Debug containers added with kubectl debug stay in the pod spec
+		// for the pod's lifetime, images and all.
+		for _, c := range pod.Spec.EphemeralContainers {
+			add(c.Name, c.Image, false, true)
 		}
 	}
 	return result
 }
 
+// Pod annotations naming the version of an image whose tag is uninformative
+// (see model.PodImageInfo.Version). The plain keys apply to the pod's default
+// container; "<key>.<container>" targets another one.
+const (
+	versionAnnotation       = "cluster-vision.io/version"
+	versionSourceAnnotation = "cluster-vision.io/version-source"
+	defaultContainerAnno    = "kubectl.kubernetes.io/default-container"
+)
+
+// versionHint returns the version annotations that apply to container.
+// Unsuffixed annotations belong to the default container: the one kubectl
+// picks (kubectl.kubernetes.io/default-container, else the first), so
+// injected sidecars don't inherit the app's version.
+func versionHint(pod *corev1.Pod, container string) (version, source string) {
+	version = pod.Annotations[versionAnnotation+"."+container]
+	source = pod.Annotations[versionSourceAnnotation+"."+container]
+	def := pod.Annotations[defaultContainerAnno]
+	if def == "" && len(pod.Spec.Containers) > 0 {
+		def = pod.Spec.Containers[0].Name
+	}
+	if container == def {
+		if version == "" {
+			version = pod.Annotations[versionAnnotation]
+		}
+		if source == "" {
+			source = pod.Annotations[versionSourceAnnotation]
+		}
+	}
+	return version, source
+}
+
+// ownerIndex maps the intermediate controllers pods are usually created
+// through, keyed "Kind/namespace/name", to their own owner; a nil value
+// marks a controller that is itself top-level.
+type ownerIndex map[string]*metav1.OwnerReference
+
+// maxOwnerDepth bounds the ownerReferences walk, in case of a cycle.
+const maxOwnerDepth = 5
+
+// listOwners indexes ReplicaSets (owned by Deployments) and Jobs (owned by
+// CronJobs). A kind that can't be listed is left out, and podOwner falls
+// back to naming conventions for it.
+func (p *KubernetesParser) listOwners(ctx context.Context) ownerIndex {
+	idx := make(ownerIndex)
+	rss, err := p.typed.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list replicasets", "error", err)
+	} else {
+		for i := range rss.Items {
+			rs := &rss.Items[i]
+			idx["ReplicaSet/"+rs.Namespace+"/"+rs.Name] = ownerOf(rs)
+		}
+	}
+	jobs, err := p.typed.BatchV1().Jobs("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list jobs", "error", err)
+	} else {
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			idx["Job/"+job.Namespace+"/"+job.Name] = ownerOf(job)
+		}
+	}
+	return idx
+}
+
+// ownerOf returns obj's controller, or its first owner when several own it
+// but none is marked as the controller.
+func ownerOf(obj metav1.Object) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOfNoCopy(obj); ref != nil {
+		return ref
+	}
+	if refs := obj.GetOwnerReferences(); len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
+
+// podOwner returns the pod's top-level workload as "Kind/name", walking
+// ownerReferences through owners (ReplicaSet → Deployment, Job → CronJob).
+// Bare pods have no owner. A ReplicaSet missing from owners is mapped to its
+// Deployment by stripping the pod-template-hash suffix.
+func podOwner(pod *corev1.Pod, owners ownerIndex) string {
+	ref := ownerOf(pod)
+	if ref == nil {
+		return ""
+	}
+	kind, name := ref.Kind, ref.Name
+	for range maxOwnerDepth {
+		parent, ok := owners[kind+"/"+pod.Namespace+"/"+name]
+		if !ok {
+			break
+		}
+		if parent == nil {
+			return kind + "/" + name
+		}
+		kind, name = parent.Kind, parent.Name
+	}
+	if kind == "ReplicaSet" {
+		if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(name, "-"+hash) {
+			return "Deployment/" + strings.TrimSuffix(name, "-"+hash)
+		}
+	}
+	return kind + "/" + name
+}
+
 func (p *KubernetesParser) parseWorkloads(ctx context.Context) []model.WorkloadInfo {
 	var result []model.WorkloadInfo
 
@@ -810,6 +1381,7 @@ func (p *KubernetesParser) parseWorkloads(ctx context.Context) []model.WorkloadI
 				UpdateStrategy: strategy,
 				Images:         images,
 				Labels:         d.Labels,
+				Team:           p.teamOf(d.Labels, d.Annotations),
 				CreatedAt:      d.CreationTimestamp.Format("2006-01-02"),
 			})
 		}
@@ -836,6 +1408,7 @@ func (p *KubernetesParser) parseWorkloads(ctx context.Context) []model.WorkloadI
 				UpdateStrategy: strategy,
 				Images:         images,
 				Labels:         s.Labels,
+				Team:           p.teamOf(s.Labels, s.Annotations),
 				CreatedAt:      s.CreationTimestamp.Format("2006-01-02"),
 			})
 		}
@@ -862,6 +1435,7 @@ func (p *KubernetesParser) parseWorkloads(ctx context.Context) []model.WorkloadI
 				UpdateStrategy: strategy,
 				Images:         images,
 				Labels:         d.Labels,
+				Team:           p.teamOf(d.Labels, d.Annotations),
 				CreatedAt:      d.CreationTimestamp.Format("2006-01-02"),
 			})
 		}
@@ -887,6 +1461,7 @@ func (p *KubernetesParser) parseWorkloads(ctx context.Context) []model.WorkloadI
 				UpdateStrategy: c.Spec.Schedule,
 				Images:         images,
 				Labels:         c.Labels,
+				Team:           p.teamOf(c.Labels, c.Annotations),
 				CreatedAt:      c.CreationTimestamp.Format("2006-01-02"),
 			})
 		}
@@ -1271,15 +1846,88 @@ func (p *KubernetesParser) parseServices(ctx context.Context) []model.ServiceInf
 	return result
 }
 
+func (p *KubernetesParser) parseEvents(ctx context.Context) []model.EventInfo {
+	list, err := p.typed.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		slog.Warn("failed to list events", "error", err)
+		return nil
+	}
+
+	var result []model.EventInfo
+	for _, ev := range list.Items {
+		count := ev.Count
+		if ev.Series != nil && ev.Series.Count > count {
+			count = ev.Series.Count
+		}
+		result = append(result, model.EventInfo{
+			Namespace: ev.Namespace,
+			Cluster:   p.clusterName,
+			Object:    ev.InvolvedObject.Kind + "/" + ev.InvolvedObject.Name,
+			Reason:    ev.Reason,
+			Message:   ev.Message,
+			Count:     count,
+			LastSeen:  eventTime(&ev),
+		})
+	}
+	return result
+}
+
+// eventTime returns when an event last happened. Events recorded through the
+// events.k8s.io API leave lastTimestamp empty and carry a microsecond
+// eventTime or series.lastObservedTime instead.
+func eventTime(ev *corev1.Event) time.Time {
+	var t time.Time
+	for _, c := range []time.Time{ev.LastTimestamp.Time, ev.EventTime.Time, ev.FirstTimestamp.Time, ev.CreationTimestamp.Time} {
+		if !c.IsZero() {
+			t = c
+			break
+		}
+	}
+	if ev.Series != nil && ev.Series.LastObservedTime.After(t) {
+		t = ev.Series.LastObservedTime.Time
+	}
+	return t
+}
+
 func (p *KubernetesParser) parseRBAC(ctx context.Context) []model.RBACBindingInfo {
 	var result []model.RBACBindingInfo
 
+	// Roles are only read to flag wildcard rules; without them bindings are
+	// still listed and cluster-admin is still recognized by name.
+	broad := make(map[string]string) // "ClusterRole/name" or "Role/ns/name" → reason
+	crs, err := p.typed.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list clusterroles", "error", err)
+	} else {
+		for _, cr := range crs.Items {
+			broad["ClusterRole/"+cr.Name] = broadRole(cr.Name, cr.Rules)
+		}
+	}
+	roles, err := p.typed.RbacV1().Roles("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("failed to list roles", "error", err)
+	} else {
+		for _, r := range roles.Items {
+			broad["Role/"+r.Namespace+"/"+r.Name] = broadRole("", r.Rules)
+		}
+	}
+	reason := func(ref rbacv1.RoleRef, namespace string) string {
+		if ref.Kind == "ClusterRole" {
+			if r, ok := broad["ClusterRole/"+ref.Name]; ok {
+				return r
+			}
+			return broadRole(ref.Name, nil)
+		}
+		return broad["Role/"+namespace+"/"+ref.Name]
+	}
+
 	// ClusterRoleBindings
 	crbs, err := p.typed.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		slog.Warn("failed to list clusterrolebindings", "error", err)
 	} else {
 		for _, crb := range crbs.Items {
+			why := reason(crb.RoleRef, "")
 			for _, subject := range crb.Subjects {
 				result = append(result, model.RBACBindingInfo{
 					SubjectName: subject.Name,
@@ -1288,6 +1936,9 @@ func (p *KubernetesParser) parseRBAC(ctx context.Context) []model.RBACBindingInf
 					RoleKind:    crb.RoleRef.Kind,
 					Namespace:   subject.Namespace,
 					Cluster:     p.clusterName,
+					Binding:     crb.Name,
+					ClusterWide: true,
+					Broad:       why,
 				})
 			}
 		}
@@ -1299,18 +1950,22 @@ func (p *KubernetesParser) parseRBAC(ctx context.Context) []model.RBACBindingInf
 		slog.Warn("failed to list rolebindings", "error", err)
 	} else {
 		for _, rb := range rbs.Items {
+			why := reason(rb.RoleRef, rb.Namespace)
 			for _, subject := range rb.Subjects {
 				ns := rb.Namespace
 				if subject.Namespace != "" {
 					ns = subject.Namespace
 				}
 				result = append(result, model.RBACBindingInfo{
-					SubjectName: subject.Name,
-					SubjectKind: subject.Kind,
-					RoleName:    rb.RoleRef.Name,
-					RoleKind:    rb.RoleRef.Kind,
-					Namespace:   ns,
-					Cluster:     p.clusterName,
+					SubjectName:      subject.Name,
+					SubjectKind:      subject.Kind,
+					RoleName:         rb.RoleRef.Name,
+					RoleKind:         rb.RoleRef.Kind,
+					Namespace:        ns,
+					Cluster:          p.clusterName,
+					Binding:          rb.Name,
+					BindingNamespace: rb.Namespace,
+					Broad:            why,
 				})
 			}
 		}
@@ -1319,6 +1974,20 @@ func (p *KubernetesParser) parseRBAC(ctx context.Context) []model.RBACBindingInf
 	return result
 }
 
+// broadRole reports why a role grants sweeping access: "cluster-admin" by
+// name, "wildcard" for a rule with "*" verbs or resources, or "".
+func broadRole(name string, rules []rbacv1.PolicyRule) string {
+	if name == "cluster-admin" {
+		return "cluster-admin"
+	}
+	for _, r := range rules {
+		if slices.Contains(r.Verbs, "*") || slices.Contains(r.Resources, "*") {
+			return "wildcard"
+		}
+	}
+	return ""
+}
+
 func (p *KubernetesParser) parseVeleroSchedules(ctx context.Context) []model.VeleroScheduleInfo {
 	gvr := schema.GroupVersionResource{
 		Group:    "velero.io",
@@ -1364,6 +2033,7 @@ func (p *KubernetesParser) parseVeleroSchedules(ctx context.Context) []model.Vel
 
 		status, _ := item.Object["status"].(map[string]interface{})
 		phase := strVal(status, "phase")
+		lastBackup := timeVal(status, "lastBackup")
 
 		result = append(result, model.VeleroScheduleInfo{
 			Name:       item.GetName(),
@@ -1374,6 +2044,7 @@ func (p *KubernetesParser) parseVeleroSchedules(ctx context.Context) []model.Vel
 			ExcludedNS: excludedNS,
 			TTL:        ttl,
 			Phase:      phase,
+			LastBackup: lastBackup,
 		})
 	}
 	return result
@@ -1505,18 +2176,48 @@ func (p *KubernetesParser) parseVulnReports(ctx context.Context) []model.ImageVu
 }
 
 func intVal(m map[string]interface{}, key string) int {
-	if m == nil {
-		return 0
-	}
-	switch v := m[key].(type) {
+	n, _ := numVal(m[key])
+	return n
+}
+
+// numVal coerces a numeric field of an unstructured object to an int. Besides
+// the int64/float64 the JSON decoder produces, it accepts what other codecs
+// round-trip numbers as: int, int32, json.Number, a numeric string, and an
+// IntOrString spelled out as {"intVal": …} or {"strVal": …}. ok is false for
+// anything else, e.g. a named port such as "https".
+func numVal(v interface{}) (n int, ok bool) {
+	switch v := v.(type) {
 	case int64:
-		return int(v)
+		return int(v), true
 	case float64:
-		return int(v)
+		return int(v), true
 	case int:
-		return v
+		return v, true
+	case int32:
+		return int(v), true
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			f, ferr := v.Float64()
+			if ferr != nil {
+				return 0, false
+			}
+			return int(f), true
+		}
+		return int(i), true
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		return i, err == nil
+	case map[string]interface{}:
+		for key, val := range v {
+			if strings.EqualFold(key, "intVal") || strings.EqualFold(key, "strVal") {
+				if n, ok := numVal(val); ok && n != 0 {
+					return n, true
+				}
+			}
+		}
 	}
-	return 0
+	return 0, false
 }
 
 // ptrInt32 dereferences an int32 pointer, returning 1 if nil (default replicas).
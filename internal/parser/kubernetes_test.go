@@ -0,0 +1,849 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParsePodsTerminated(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "apps"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-28901", Namespace: "apps"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "restic/restic:0.17.0"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantPods  []string
+		wantState map[string]string
+	}{
+		{"default excludes terminated", Options{}, []string{"web-1"}, map[string]string{"web-1": "Running"}},
+		{
+			"toggle includes terminated",
+			Options{IncludeTerminatedPods: true},
+			[]string{"web-1", "backup-28901"},
+			map[string]string{"web-1": "Running", "backup-28901": "Succeeded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &KubernetesParser{
+				typed:       fake.NewSimpleClientset(&pods[0], &pods[1]),
+				clusterName: "Homelab",
+				opts:        tt.opts,
+			}
+			got := p.parsePods(context.Background())
+
+			if len(got) != len(tt.wantPods) {
+				t.Fatalf("parsePods() returned %d images, want %d: %+v", len(got), len(tt.wantPods), got)
+			}
+			for _, img := range got {
+				want, ok := tt.wantState[img.PodName]
+				if !ok {
+					t.Errorf("unexpected pod %q in inventory", img.PodName)
+					continue
+				}
+				if img.State != want {
+					t.Errorf("pod %q state = %q, want %q", img.PodName, img.State, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFluxSources(t *testing.T) {
+	repo := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "source.toolkit.fluxcd.io/v1",
+		"kind":       "GitRepository",
+		"metadata":   map[string]interface{}{"name": "flux-system", "namespace": "flux-system"},
+		"spec": map[string]interface{}{
+			"url": "https://github.com/fredericrous/homelab",
+			"ref": map[string]interface{}{"branch": "main"},
+		},
+		"status": map[string]interface{}{
+			"artifact": map[string]interface{}{
+				"revision":       "main@sha1:1a2b3c4d",
+				"lastUpdateTime": "2026-01-02T03:04:05Z",
+			},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Reconciling", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "True", "message": "stored artifact for revision 'main@sha1:1a2b3c4d'"},
+			},
+		},
+	}}
+
+	scheme := runtime.NewScheme()
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "gitrepositories"}:      "GitRepositoryList",
+		{Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Resource: "ocirepositories"}: "OCIRepositoryList",
+		{Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "helmrepositories"}:     "HelmRepositoryList",
+	}, repo)
+	// OCIRepository CRD absent: that kind must be skipped without losing the others.
+	dyn.PrependReactor("list", "ocirepositories", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New(`the server could not find the requested resource`)
+	})
+
+	p := &KubernetesParser{dynamic: dyn, clusterName: "Homelab"}
+	got := p.parseFluxSources(context.Background())
+
+	if len(got) != 1 {
+		t.Fatalf("parseFluxSources() returned %d sources, want 1: %+v", len(got), got)
+	}
+	src := got[0]
+	want := model.FluxSourceInfo{
+		Name:      "flux-system",
+		Namespace: "flux-system",
+		Cluster:   "Homelab",
+		Kind:      "GitRepository",
+		URL:       "https://github.com/fredericrous/homelab",
+		Ref:       "branch: main",
+		Revision:  "main@sha1:1a2b3c4d",
+		Ready:     true,
+		Message:   "stored artifact for revision 'main@sha1:1a2b3c4d'",
+		LastSync:  "2026-01-02T03:04:05Z",
+	}
+	if src != want {
+		t.Errorf("parseFluxSources()[0] = %+v, want %+v", src, want)
+	}
+}
+
+func TestTeamOf(t *testing.T) {
+	const key = "app.kubernetes.io/part-of"
+	tests := []struct {
+		name        string
+		teamLabel   string
+		labels      map[string]string
+		annotations map[string]string
+		want        string
+	}{
+		{"disabled", "", map[string]string{key: "payments"}, nil, ""},
+		{"label", key, map[string]string{key: "payments"}, nil, "payments"},
+		{"annotation fallback", key, nil, map[string]string{key: "media"}, "media"},
+		{"label wins", key, map[string]string{key: "payments"}, map[string]string{key: "media"}, "payments"},
+		{"unlabelled", key, map[string]string{"app": "web"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &KubernetesParser{opts: Options{TeamLabel: tt.teamLabel}}
+			if got := p.teamOf(tt.labels, tt.annotations); got != tt.want {
+				t.Errorf("teamOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceGrantValidation(t *testing.T) {
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "grafana", "namespace": "apps"},
+		"spec": map[string]interface{}{
+			"hostnames": []interface{}{"grafana.example.com"},
+			"rules": []interface{}{map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "grafana", "namespace": "monitoring", "port": int64(3000)},
+					map[string]interface{}{"name": "oauth", "namespace": "auth", "port": int64(4180)},
+					map[string]interface{}{"name": "oauth-ext", "namespace": "auth", "group": "example.com", "kind": "Service", "port": int64(4180)},
+					map[string]interface{}{"name": "local", "port": int64(80)},
+				},
+			}},
+		},
+	}}
+	// auth grants HTTPRoutes from apps access to core Services only;
+	// monitoring has no grant.
+	grant := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1beta1",
+		"kind":       "ReferenceGrant",
+		"metadata":   map[string]interface{}{"name": "from-apps", "namespace": "auth"},
+		"spec": map[string]interface{}{
+			"from": []interface{}{map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "namespace": "apps"}},
+			"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service"}},
+		},
+	}}
+
+	newDynamic := func(crdInstalled bool) *dynamicfake.FakeDynamicClient {
+		dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}:           "HTTPRouteList",
+			{Group: "gateway.networking.k8s.io", Version: "v1beta1", Resource: "referencegrants"}: "ReferenceGrantList",
+		}, route.DeepCopy(), grant.DeepCopy())
+		if !crdInstalled {
+			dyn.PrependReactor("list", "referencegrants", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New(`the server could not find the requested resource`)
+			})
+		}
+		return dyn
+	}
+
+	tests := []struct {
+		name          string
+		crdInstalled  bool
+		wantUngranted map[string]bool
+	}{
+		{"missing grant flagged", true, map[string]bool{"grafana": true, "oauth": false, "oauth-ext": true, "local": false}},
+		{"CRD absent skips validation", false, map[string]bool{"grafana": false, "oauth": false, "oauth-ext": false, "local": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &KubernetesParser{dynamic: newDynamic(tt.crdInstalled), clusterName: "Homelab"}
+			routes := p.parseHTTPRoutes(context.Background())
+			grants, ok := p.parseReferenceGrants(context.Background())
+			if ok != tt.crdInstalled {
+				t.Fatalf("parseReferenceGrants() ok = %v, want %v", ok, tt.crdInstalled)
+			}
+			if ok {
+				markUngrantedRefs(routes, grants)
+			}
+
+			if len(routes) != 1 || len(routes[0].Backends) != 4 {
+				t.Fatalf("routes = %+v, want one route with 4 backends", routes)
+			}
+			for _, ref := range routes[0].Backends {
+				if ref.Ungranted != tt.wantUngranted[ref.Name] {
+					t.Errorf("backend %s/%s Ungranted = %v, want %v", ref.Namespace, ref.Name, ref.Ungranted, tt.wantUngranted[ref.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestNumVal(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     interface{}
+		want   int
+		wantOK bool
+	}{
+		{"int64", int64(443), 443, true},
+		{"float64", float64(443), 443, true},
+		{"int", 443, 443, true},
+		{"int32", int32(443), 443, true},
+		{"json.Number", json.Number("443"), 443, true},
+		{"json.Number float", json.Number("443.0"), 443, true},
+		{"numeric string", "443", 443, true},
+		{"padded string", " 8443 ", 8443, true},
+		{"IntOrString int", map[string]interface{}{"type": int64(0), "intVal": int64(443), "strVal": ""}, 443, true},
+		{"IntOrString string", map[string]interface{}{"Type": int64(1), "IntVal": int64(0), "StrVal": "8443"}, 8443, true},
+		{"named port", "https", 0, false},
+		{"missing", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := numVal(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("numVal(%#v) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParsePortRepresentations(t *testing.T) {
+	ports := []interface{}{int64(443), float64(443), json.Number("443"), "443"}
+	gatewaysGVR := schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	var objs []runtime.Object
+	var gateways []*unstructured.Unstructured
+	for i, port := range ports {
+		name := fmt.Sprintf("p%d", i)
+		gateways = append(gateways, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "Gateway",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "gateways"},
+			"spec": map[string]interface{}{
+				"listeners": []interface{}{map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": port}},
+			},
+		}})
+		objs = append(objs,
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "gateway.networking.k8s.io/v1",
+				"kind":       "HTTPRoute",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "apps"},
+				"spec": map[string]interface{}{
+					"rules": []interface{}{map[string]interface{}{
+						"backendRefs": []interface{}{map[string]interface{}{"name": "web", "port": port}},
+					}},
+				},
+			}},
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "networking.istio.io/v1",
+				"kind":       "ServiceEntry",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "mesh"},
+				"spec": map[string]interface{}{
+					"hosts": []interface{}{"web.remote"},
+					"ports": []interface{}{map[string]interface{}{"name": "https", "number": port, "protocol": "TLS"}},
+				},
+			}},
+		)
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gatewaysGVR: "GatewayList",
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}: "HTTPRouteList",
+		{Group: "networking.istio.io", Version: "v1", Resource: "serviceentries"}:   "ServiceEntryList",
+	}, objs...)
+	p := &KubernetesParser{dynamic: dyn, clusterName: "Homelab"}
+	ctx := context.Background()
+	// The fake tracker guesses "gatewaies" from the Gateway kind, so
+	// gateways are created under their real resource instead.
+	for _, gw := range gateways {
+		if _, err := dyn.Resource(gatewaysGVR).Namespace("gateways").Create(ctx, gw, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gws := p.parseGateways(ctx)
+	routes := p.parseHTTPRoutes(ctx)
+	entries := p.parseServiceEntries(ctx)
+	if len(gws) != len(ports) || len(routes) != len(ports) || len(entries) != len(ports) {
+		t.Fatalf("parsed %d gateways, %d routes, %d service entries; want %d each", len(gws), len(routes), len(entries), len(ports))
+	}
+	for _, gw := range gws {
+		if len(gw.Listeners) != 1 || gw.Listeners[0].Port != 443 {
+			t.Errorf("gateway %s listeners = %+v, want port 443", gw.Name, gw.Listeners)
+		}
+	}
+	for _, r := range routes {
+		if len(r.Backends) != 1 || r.Backends[0].Port != 443 {
+			t.Errorf("route %s backends = %+v, want port 443", r.Name, r.Backends)
+		}
+	}
+	for _, se := range entries {
+		if len(se.Ports) != 1 || se.Ports[0] != 443 {
+			t.Errorf("service entry %s ports = %v, want [443]", se.Name, se.Ports)
+		}
+	}
+}
+
+func TestPodOwner(t *testing.T) {
+	controller := true
+	pod := func(kind, name string, labels map[string]string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Labels: labels}}
+		if kind != "" {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want string
+	}{
+		{"deployment", pod("ReplicaSet", "web-7d4b9c", map[string]string{"pod-template-hash": "7d4b9c"}), "Deployment/web"},
+		{"bare replicaset", pod("ReplicaSet", "web", nil), "ReplicaSet/web"},
+		{"statefulset", pod("StatefulSet", "pg", nil), "StatefulSet/pg"},
+		{"bare pod", pod("", "", nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podOwner(tt.pod, nil); got != tt.want {
+				t.Errorf("podOwner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePodsResolvesOwner(t *testing.T) {
+	controller := true
+	ref := func(kind, name string, isController bool) metav1.OwnerReference {
+		r := metav1.OwnerReference{Kind: kind, Name: name}
+		if isController {
+			r.Controller = &controller
+		}
+		return r
+	}
+	pod := func(name string, owners ...metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", OwnerReferences: owners},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "app:1.0"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	typed := fake.NewSimpleClientset(
+		// No pod-template-hash label: only the ownerReferences name the Deployment.
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5f7c", Namespace: "apps",
+			OwnerReferences: []metav1.OwnerReference{ref("Deployment", "frontend", true)},
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "backup-28901", Namespace: "apps",
+			OwnerReferences: []metav1.OwnerReference{ref("CronJob", "backup", true)},
+		}},
+		pod("web-5f7c-abcde", ref("ReplicaSet", "web-5f7c", true)),
+		pod("backup-28901-xyz", ref("Job", "backup-28901", true)),
+		pod("debug"),
+		pod("shared", ref("ConfigMap", "owner-a", false), ref("StatefulSet", "pg", true)),
+		pod("adopted", ref("Job", "backup-28901", false), ref("ConfigMap", "owner-b", false)),
+	)
+
+	p := &KubernetesParser{typed: typed, clusterName: "Homelab"}
+	got := make(map[string]string)
+	for _, img := range p.parsePods(context.Background()) {
+		got[img.PodName] = img.Owner
+	}
+	want := map[string]string{
+		"web-5f7c-abcde":   "Deployment/frontend",
+		"backup-28901-xyz": "CronJob/backup",
+		"debug":            "",
+		"shared":           "StatefulSet/pg", // the controller among several owners
+		"adopted":          "CronJob/backup", // no controller: the first owner
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("owner of %s = %q, want %q", name, got[name], w)
+		}
+	}
+}
+
+func TestVersionHint(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"cluster-vision.io/version":               "2024.3.1",
+			"cluster-vision.io/version-source":        "github.com/acme/app",
+			"cluster-vision.io/version.migrate":       "7",
+			"kubectl.kubernetes.io/default-container": "app",
+		}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "istio-proxy"}, {Name: "app"}}},
+	}
+
+	tests := []struct {
+		container               string
+		wantVersion, wantSource string
+	}{
+		{"app", "2024.3.1", "github.com/acme/app"},
+		{"istio-proxy", "", ""},
+		{"migrate", "7", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.container, func(t *testing.T) {
+			version, source := versionHint(pod, tt.container)
+			if version != tt.wantVersion || source != tt.wantSource {
+				t.Errorf("versionHint() = %q, %q; want %q, %q", version, source, tt.wantVersion, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestParsePodsEphemeralContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "apps"},
+		Spec: corev1.PodSpec{
+			Containers:     []corev1.Container{{Name: "web", Image: "nginx:1.27"}},
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "ghcr.io/acme/migrate:1.0"}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger-x7k2", Image: "busybox:1.36"},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			EphemeralContainerStatuses: []corev1.ContainerStatus{{
+				Name: "debugger-x7k2", Image: "docker.io/library/busybox:1.36", ImageID: "docker.io/library/busybox@sha256:aaa",
+			}},
+		},
+	}
+	p := &KubernetesParser{typed: fake.NewSimpleClientset(pod), clusterName: "Homelab"}
+
+	got := make(map[string]model.PodImageInfo)
+	for _, img := range p.parsePods(context.Background()) {
+		got[img.Container] = img
+	}
+	for container, want := range map[string]string{"web": "app", "migrate": "init", "debugger-x7k2": "ephemeral"} {
+		if typ := got[container].ContainerType(); typ != want {
+			t.Errorf("%s type = %q, want %q", container, typ, want)
+		}
+	}
+	if dbg := got["debugger-x7k2"]; dbg.Image != "docker.io/library/busybox:1.36" || dbg.ImageID == "" {
+		t.Errorf("ephemeral container = %+v, want image and ID from its status", dbg)
+	}
+}
+
+func TestParseAPIServer(t *testing.T) {
+	typed := fake.NewSimpleClientset()
+	typed.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+		Major: "1", Minor: "31", GitVersion: "v1.31.4+k3s1", Platform: "linux/arm64",
+	}
+	p := &KubernetesParser{typed: typed, clusterName: "NAS"}
+
+	want := []model.APIServerInfo{{Cluster: "NAS", GitVersion: "v1.31.4+k3s1", Platform: "linux/arm64"}}
+	if got := p.parseAPIServer(); !reflect.DeepEqual(got, want) {
+		t.Errorf("api servers = %+v, want %+v", got, want)
+	}
+
+	typed.PrependReactor("get", "version", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	if got := p.parseAPIServer(); got != nil {
+		t.Errorf("api servers after a failed /version = %+v, want none", got)
+	}
+}
+
+func TestParseNodesReadiness(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+		}},
+	}
+	p := &KubernetesParser{typed: fake.NewSimpleClientset(node), clusterName: "Homelab"}
+
+	nodes := p.parseNodes(context.Background())
+	if len(nodes) != 1 {
+		t.Fatalf("got %d nodes, want 1", len(nodes))
+	}
+	n := nodes[0]
+	if n.Ready || n.Schedulable {
+		t.Errorf("Ready = %v, Schedulable = %v, want both false", n.Ready, n.Schedulable)
+	}
+	if len(n.Conditions) != 1 || n.Conditions[0] != "MemoryPressure" {
+		t.Errorf("Conditions = %v, want [MemoryPressure]", n.Conditions)
+	}
+}
+
+func TestParseEastWestGatewaysNamespace(t *testing.T) {
+	gateway := func(namespace, name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: namespace,
+				Labels: map[string]string{"topology.istio.io/network": "homelab-network"},
+			},
+			Spec: corev1.ServiceSpec{
+				LoadBalancerIP: "10.0.0.1",
+				Ports:          []corev1.ServicePort{{Name: "tls", Port: 15443}},
+			},
+		}
+	}
+	typed := fake.NewSimpleClientset(
+		gateway("istio-system", "istio-eastwestgateway"),
+		gateway("istio-gateways", "eastwest"),
+	)
+
+	tests := []struct {
+		name      string
+		namespace string
+		want      string
+	}{
+		{"default", "", "istio-eastwestgateway"},
+		{"custom namespace", "istio-gateways", "eastwest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &KubernetesParser{typed: typed, clusterName: "Homelab", opts: Options{EastWestNamespace: tt.namespace}}
+			gws := p.parseEastWestGateways(context.Background())
+			if len(gws) != 1 || gws[0].Name != tt.want {
+				t.Fatalf("gateways = %+v, want only %s", gws, tt.want)
+			}
+			if gws[0].Network != "homelab-network" || gws[0].Port != 15443 {
+				t.Errorf("network, port = %q, %d; want homelab-network, 15443", gws[0].Network, gws[0].Port)
+			}
+		})
+	}
+}
+
+func TestParseHelmReleasesThrashing(t *testing.T) {
+	release := func(name string, status map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "helm.toolkit.fluxcd.io/v2",
+			"kind":       "HelmRelease",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "apps"},
+			"spec": map[string]interface{}{
+				"chart": map[string]interface{}{"spec": map[string]interface{}{
+					"chart":     name,
+					"version":   "1.0.0",
+					"sourceRef": map[string]interface{}{"name": "charts", "namespace": "flux-system"},
+				}},
+			},
+			"status": status,
+		}}
+	}
+	gvr := schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "HelmReleaseList"},
+		release("looping", map[string]interface{}{
+			"failures":               int64(4),
+			"installFailures":        int64(2),
+			"lastHandledReconcileAt": "2026-10-16T09:00:00Z",
+		}),
+		release("flaky", map[string]interface{}{"failures": int64(1)}),
+		release("healthy", map[string]interface{}{}),
+	)
+
+	p := &KubernetesParser{dynamic: dyn, clusterName: "Homelab"}
+	got := make(map[string]model.HelmReleaseInfo)
+	for _, rel := range p.parseHelmReleases(context.Background()) {
+		got[rel.Name] = rel
+	}
+
+	looping := got["looping"]
+	if !looping.Thrashing {
+		t.Errorf("looping release not flagged thrashing: %+v", looping)
+	}
+	if looping.Failures != 4 || looping.InstallFailures != 2 || looping.LastHandledReconcileAt != "2026-10-16T09:00:00Z" {
+		t.Errorf("looping status = %+v, want 4 failures, 2 install failures and the reconcile time", looping)
+	}
+	for _, name := range []string{"flaky", "healthy"} {
+		if got[name].Thrashing {
+			t.Errorf("%s flagged thrashing: %+v", name, got[name])
+		}
+	}
+}
+
+func TestParseHelmReleasesVersionConstraint(t *testing.T) {
+	release := func(name string, version interface{}, history []interface{}) *unstructured.Unstructured {
+		chartSpec := map[string]interface{}{
+			"chart":     "app",
+			"sourceRef": map[string]interface{}{"name": "charts", "namespace": "flux-system"},
+		}
+		if version != nil {
+			chartSpec["version"] = version
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "helm.toolkit.fluxcd.io/v2",
+			"kind":       "HelmRelease",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "apps"},
+			"spec":       map[string]interface{}{"chart": map[string]interface{}{"spec": chartSpec}},
+			"status":     map[string]interface{}{"history": history},
+		}}
+	}
+	deployed := func(v string) []interface{} {
+		return []interface{}{
+			map[string]interface{}{"chartVersion": v, "appVersion": "v" + v},
+			map[string]interface{}{"chartVersion": "1.0.0"},
+		}
+	}
+	gvr := schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "HelmReleaseList"},
+		release("ranged", ">=1.2.0 <2.0.0", deployed("1.4.2")),
+		release("ranged-pending", "~1.4", nil),
+		release("pinned", "1.3.0", nil),
+		release("latest", nil, deployed("2.1.0")),
+	)
+
+	p := &KubernetesParser{dynamic: dyn, clusterName: "Homelab"}
+	got := make(map[string]model.HelmReleaseInfo)
+	for _, rel := range p.parseHelmReleases(context.Background()) {
+		got[rel.Name] = rel
+	}
+
+	tests := []struct {
+		name, version, constraint string
+	}{
+		{"ranged", "1.4.2", ">=1.2.0 <2.0.0"},
+		{"ranged-pending", "", "~1.4"},
+		{"pinned", "1.3.0", ""},
+		{"latest", "2.1.0", "*"},
+	}
+	for _, tt := range tests {
+		rel := got[tt.name]
+		if rel.Version != tt.version || rel.VersionConstraint != tt.constraint {
+			t.Errorf("%s: version, constraint = %q, %q; want %q, %q", tt.name, rel.Version, rel.VersionConstraint, tt.version, tt.constraint)
+		}
+	}
+	if got["ranged"].AppVersion != "v1.4.2" {
+		t.Errorf("ranged app version = %q, want the latest history entry's", got["ranged"].AppVersion)
+	}
+}
+
+func TestEventTime(t *testing.T) {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	last := time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	micro := time.Date(2026, 3, 1, 11, 30, 0, 123456000, time.UTC)
+
+	tests := []struct {
+		name string
+		ev   corev1.Event
+		want time.Time
+	}{
+		{"lastTimestamp", corev1.Event{LastTimestamp: metav1.NewTime(last)}, last},
+		{"eventTime keeps microseconds", corev1.Event{EventTime: metav1.NewMicroTime(micro)}, micro},
+		{"series newer than eventTime", corev1.Event{
+			EventTime: metav1.NewMicroTime(last),
+			Series:    &corev1.EventSeries{Count: 4, LastObservedTime: metav1.NewMicroTime(micro)},
+		}, micro},
+		{"creation only", corev1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}, created},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventTime(&tt.ev); !got.Equal(tt.want) {
+				t.Errorf("eventTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterNameFromNodeLabel(t *testing.T) {
+	const label = "cluster.x-k8s.io/cluster-name"
+	node := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}}
+
+	tests := []struct {
+		name  string
+		nodes []runtime.Object
+		want  string
+	}{
+		{"labelled nodes", []runtime.Object{
+			node("worker-2", map[string]string{label: "other"}),
+			node("worker-1", map[string]string{label: "prod-eu"}),
+			node("cp-0", nil),
+		}, "prod-eu"},
+		{"label absent", []runtime.Object{node("worker-1", nil)}, DefaultClusterName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			dyn.PrependReactor("list", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New(`the server could not find the requested resource`)
+			})
+			p := &KubernetesParser{
+				typed:       fake.NewSimpleClientset(append(tt.nodes, ns)...),
+				dynamic:     dyn,
+				clusterName: DefaultClusterName,
+				opts:        Options{ClusterNameLabel: label},
+				derivedName: true,
+			}
+
+			data := p.ParseAll(context.Background())
+			if got := p.ClusterName(); got != tt.want {
+				t.Errorf("ClusterName() = %q, want %q", got, tt.want)
+			}
+			for _, n := range data.Nodes {
+				if n.Cluster != tt.want {
+					t.Errorf("node %s cluster = %q, want %q", n.Name, n.Cluster, tt.want)
+				}
+			}
+			if len(data.Namespaces) != 1 || data.Namespaces[0].Cluster != tt.want {
+				t.Errorf("namespaces = %+v, want apps in %q", data.Namespaces, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRBACFlagsBroadRoles(t *testing.T) {
+	objs := []runtime.Object{
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "view"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "everything", Namespace: "ci"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"get"}}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployer-admin"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "deployer", Namespace: "ci"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "runner-everything", Namespace: "ci"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "everything"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "runner"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "builder-everything", Namespace: "ci"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "everything"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "builder", Namespace: "tools"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev-view", Namespace: "ci"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "dev"}},
+		},
+	}
+	p := &KubernetesParser{typed: fake.NewSimpleClientset(objs...), clusterName: "Homelab"}
+
+	got := make(map[string]model.RBACBindingInfo)
+	for _, b := range p.parseRBAC(context.Background()) {
+		got[b.SubjectName] = b
+	}
+	want := map[string]model.RBACBindingInfo{
+		"deployer": {SubjectName: "deployer", SubjectKind: "ServiceAccount", RoleName: "cluster-admin", RoleKind: "ClusterRole",
+			Namespace: "ci", Cluster: "Homelab", Binding: "deployer-admin", ClusterWide: true, Broad: "cluster-admin"},
+		"runner": {SubjectName: "runner", SubjectKind: "ServiceAccount", RoleName: "everything", RoleKind: "Role",
+			Namespace: "ci", Cluster: "Homelab", Binding: "runner-everything", BindingNamespace: "ci", Broad: "wildcard"},
+		"builder": {SubjectName: "builder", SubjectKind: "ServiceAccount", RoleName: "everything", RoleKind: "Role",
+			Namespace: "tools", Cluster: "Homelab", Binding: "builder-everything", BindingNamespace: "ci", Broad: "wildcard"},
+		"dev": {SubjectName: "dev", SubjectKind: "Group", RoleName: "view", RoleKind: "ClusterRole",
+			Namespace: "ci", Cluster: "Homelab", Binding: "dev-view", BindingNamespace: "ci"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d bindings, want %d: %+v", len(got), len(want), got)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("binding for %s = %+v, want %+v", name, got[name], w)
+		}
+	}
+}
+
+func TestParseNodesRoleRules(t *testing.T) {
+	node := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	client := fake.NewSimpleClientset(
+		node("k3s-server", map[string]string{"node.kubernetes.io/control-plane": ""}),
+		node("rke-cp", map[string]string{"node-role.kubernetes.io/controlplane": "true", "node-role.kubernetes.io/etcd": "true"}),
+		node("rke2-db", map[string]string{"rke2.io/tier": "db"}),
+		node("worker", map[string]string{"node-role.kubernetes.io/worker": ""}),
+	)
+	rules, err := ParseRoleRules("rke2.io/tier=db:etcd, rke2.io/tier=web:worker")
+	if err != nil {
+		t.Fatalf("ParseRoleRules: %v", err)
+	}
+	p := &KubernetesParser{typed: client, clusterName: "Homelab", opts: Options{RoleRules: rules}}
+
+	got := make(map[string][]string)
+	for _, n := range p.parseNodes(context.Background()) {
+		got[n.Name] = n.Roles
+	}
+	want := map[string][]string{
+		"k3s-server": {"control-plane"},
+		"rke-cp":     {"control-plane", "controlplane", "etcd"},
+		"rke2-db":    {"etcd"},
+		"worker":     {"worker"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("roles = %v, want %v", got, want)
+	}
+
+	if _, err := ParseRoleRules("node.kubernetes.io/control-plane"); err == nil {
+		t.Error("ParseRoleRules accepted a rule without a role")
+	}
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// nodeRolePrefix is the standard label prefix naming a node's roles, e.g.
+// node-role.kubernetes.io/control-plane.
+const nodeRolePrefix = "node-role.kubernetes.io/"
+
+// RoleRule maps a node label to a role ("control-plane", "etcd", "worker").
+// An empty Value matches the label whatever its value.
+type RoleRule struct {
+	Label string `json:"label"`
+	Value string `json:"value,omitempty"`
+	Role  string `json:"role"`
+}
+
+// DefaultRoleRules cover labelling that the node-role.kubernetes.io/ prefix
+// alone gets wrong: the valueless node.kubernetes.io/control-plane some
+// distros set, and RKE's "controlplane" spelling.
+var DefaultRoleRules = []RoleRule{
+	{Label: "node.kubernetes.io/control-plane", Role: "control-plane"},
+	{Label: "node-role.kubernetes.io/controlplane", Role: "control-plane"},
+}
+
+// ParseRoleRules parses a comma-separated list of label[=value]:role rules,
+// e.g. "node.kubernetes.io/control-plane:control-plane,tier=db:etcd".
+func ParseRoleRules(s string) ([]RoleRule, error) {
+	var rules []RoleRule
+	for _, raw := range strings.Split(s, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		match, role, ok := strings.Cut(raw, ":")
+		label, value, _ := strings.Cut(match, "=")
+		r := RoleRule{Label: strings.TrimSpace(label), Value: strings.TrimSpace(value), Role: strings.TrimSpace(role)}
+		if !ok || r.Label == "" || r.Role == "" {
+			return nil, fmt.Errorf("invalid node role rule %q: want label[=value]:role", raw)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// nodeRoles returns the sorted, deduplicated roles of a node: those named by
+// node-role.kubernetes.io/ labels, then those of DefaultRoleRules and the
+// matching extra rules.
+func nodeRoles(labels map[string]string, extra []RoleRule) []string {
+	seen := make(map[string]bool)
+	for label := range labels {
+		if strings.HasPrefix(label, nodeRolePrefix) {
+			seen[strings.TrimPrefix(label, nodeRolePrefix)] = true
+		}
+	}
+	for _, rules := range [][]RoleRule{DefaultRoleRules, extra} {
+		for _, r := range rules {
+			if v, ok := labels[r.Label]; ok && (r.Value == "" || v == r.Value) {
+				seen[r.Role] = true
+			}
+		}
+	}
+
+	var roles []string
+	for r := range seen {
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+	return roles
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
@@ -12,8 +14,14 @@ import (
 
 // tfState represents the top-level Terraform state structure.
 type tfState struct {
-	Version   int          `json:"version"`
-	Resources []tfResource `json:"resources"`
+	Version   int                 `json:"version"`
+	Resources []tfResource        `json:"resources"`
+	Outputs   map[string]tfOutput `json:"outputs"`
+}
+
+type tfOutput struct {
+	Value     interface{} `json:"value"`
+	Sensitive bool        `json:"sensitive"`
 }
 
 type tfResource struct {
@@ -68,12 +76,44 @@ func ParseTerraformStateBytes(data []byte) []model.TerraformNode {
 			nodes = append(nodes, parseProxmoxTelmate(res)...)
 		case "proxmox_virtual_environment_vm":
 			nodes = append(nodes, parseProxmoxBPG(res)...)
+		case "aws_instance":
+			nodes = append(nodes, parseAWSInstance(res)...)
 		}
 	}
 
 	return nodes
 }
 
+// ParseTerraformOutputsBytes extracts the root module outputs of a
+// terraform.tfstate, e.g. cluster_vip or control_plane_endpoint, sorted by
+// name. Sensitive outputs and non-scalar values (lists, maps) are skipped.
+func ParseTerraformOutputsBytes(data []byte) []model.TerraformOutput {
+	var state tfState
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Warn("failed to parse terraform state", "error", err)
+		return nil
+	}
+
+	var outputs []model.TerraformOutput
+	for name, out := range state.Outputs {
+		if out.Sensitive {
+			continue
+		}
+		var value string
+		switch v := out.Value.(type) {
+		case string:
+			value = v
+		case float64, bool:
+			value = fmt.Sprint(v)
+		default:
+			continue
+		}
+		outputs = append(outputs, model.TerraformOutput{Name: name, Value: value})
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	return outputs
+}
+
 // parseProxmoxTelmate handles VMs from the telmate/proxmox provider.
 func parseProxmoxTelmate(res tfResource) []model.TerraformNode {
 	var nodes []model.TerraformNode
@@ -85,6 +125,7 @@ func parseProxmoxTelmate(res tfResource) []model.TerraformNode {
 			Cores:    intAttr(a, "cores"),
 			MemoryMB: intAttr(a, "memory"),
 			Provider: "proxmox",
+			Host:     strAttr(a, "target_node"),
 		}
 
 		// Infer role from resource name or tags
@@ -139,6 +180,7 @@ func parseProxmoxBPG(res tfResource) []model.TerraformNode {
 			Name:     strAttr(a, "name"),
 			Provider: "proxmox",
 			Role:     inferRole(res.Name, strAttr(a, "name")),
+			Host:     strAttr(a, "node_name"),
 		}
 
 		// CPU
@@ -192,6 +234,117 @@ func parseProxmoxBPG(res tfResource) []model.TerraformNode {
 	return nodes
 }
 
+// parseAWSInstance handles EC2 instances from the hashicorp/aws provider.
+// The instance's availability zone stands in for the host, so instances
+// are grouped by failure domain like Proxmox VMs by node.
+func parseAWSInstance(res tfResource) []model.TerraformNode {
+	var nodes []model.TerraformNode
+	for _, inst := range res.Instances {
+		a := inst.Attributes
+		name := strAttr(a, "id")
+		if tags, ok := a["tags"].(map[string]interface{}); ok && strAttr(tags, "Name") != "" {
+			name = strAttr(tags, "Name")
+		}
+		node := model.TerraformNode{
+			Name:     name,
+			IP:       strAttr(a, "private_ip"),
+			Provider: "aws",
+			Role:     inferRole(res.Name, name),
+			Host:     strAttr(a, "availability_zone"),
+		}
+		if node.IP == "" {
+			node.IP = strAttr(a, "public_ip")
+		}
+
+		// CPU — explicit CPU options win over the instance type's default
+		node.Cores, node.MemoryMB = ec2Shape(strAttr(a, "instance_type"))
+		cores, threads := intAttr(a, "cpu_core_count"), intAttr(a, "cpu_threads_per_core")
+		if opts, ok := a["cpu_options"].([]interface{}); ok && len(opts) > 0 {
+			if om, ok := opts[0].(map[string]interface{}); ok && intAttr(om, "core_count") > 0 {
+				cores, threads = intAttr(om, "core_count"), intAttr(om, "threads_per_core")
+			}
+		}
+		if cores > 0 {
+			node.Cores = cores * max(threads, 1)
+		}
+
+		// Disks — the root volume, then every EBS data volume summed
+		for _, key := range []string{"root_block_device", "ebs_block_device"} {
+			if disks, ok := a[key].([]interface{}); ok {
+				for _, d := range disks {
+					if dm, ok := d.(map[string]interface{}); ok {
+						size := intAttr(dm, "volume_size")
+						if key == "root_block_device" {
+							node.OSDiskGB = size
+						} else {
+							node.DataDiskGB += size
+						}
+					}
+				}
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// ec2Sizes maps an EC2 instance size to its vCPUs in the fixed-performance
+// families; ec2MemPerVCPU gives each such family's GiB of memory per vCPU.
+// Families are listed explicitly: others (mac1, x2idn, older generations
+// with irregular ratios such as c5n) have no shape rather than a guess.
+var (
+	ec2Sizes = map[string]int{
+		"medium": 1, "large": 2, "xlarge": 4, "2xlarge": 8, "4xlarge": 16,
+		"8xlarge": 32, "12xlarge": 48, "16xlarge": 64, "24xlarge": 96,
+	}
+	ec2MemPerVCPU = map[string]int{
+		// General purpose
+		"m5": 4, "m5a": 4, "m5ad": 4, "m5d": 4, "m5dn": 4, "m5n": 4, "m5zn": 4, "m6a": 4, "m6g": 4,
+		"m6gd": 4, "m6i": 4, "m6id": 4, "m6idn": 4, "m6in": 4, "m7a": 4, "m7g": 4, "m7gd": 4,
+		"m7i": 4, "m7i-flex": 4, "m8g": 4,
+		// Compute optimized
+		"c5": 2, "c5a": 2, "c5ad": 2, "c5d": 2, "c6a": 2, "c6g": 2, "c6gd": 2, "c6gn": 2, "c6i": 2,
+		"c6id": 2, "c6in": 2, "c7a": 2, "c7g": 2, "c7gd": 2, "c7gn": 2, "c7i": 2, "c7i-flex": 2,
+		"c8g": 2,
+		// Memory optimized
+		"r5": 8, "r5a": 8, "r5ad": 8, "r5b": 8, "r5d": 8, "r5dn": 8, "r5n": 8, "r6a": 8, "r6g": 8,
+		"r6gd": 8, "r6i": 8, "r6id": 8, "r6idn": 8, "r6in": 8, "r7a": 8, "r7g": 8, "r7gd": 8,
+		"r7i": 8, "r7iz": 8, "r8g": 8,
+	}
+)
+
+// ec2Burstable maps a burstable (t3, t3a, t4g) instance size to its vCPUs
+// and memory in MB. The older t2 has a single vCPU up to small.
+var ec2Burstable = map[string][2]int{
+	"nano": {2, 512}, "micro": {2, 1024}, "small": {2, 2048}, "medium": {2, 4096},
+	"large": {2, 8192}, "xlarge": {4, 16384}, "2xlarge": {8, 32768},
+}
+
+// ec2Shape returns the vCPUs and memory (MB) of an instance type such as
+// "m6i.xlarge" or "t3.medium", or zeros for a type outside the table.
+func ec2Shape(instanceType string) (vcpus, memoryMB int) {
+	family, size, ok := strings.Cut(instanceType, ".")
+	if !ok || family == "" {
+		return 0, 0
+	}
+	if slices.Contains([]string{"t2", "t3", "t3a", "t4g"}, family) {
+		shape, ok := ec2Burstable[size]
+		if !ok {
+			return 0, 0
+		}
+		if family == "t2" && shape[1] <= 2048 {
+			return 1, shape[1]
+		}
+		return shape[0], shape[1]
+	}
+	perVCPU, ok := ec2MemPerVCPU[family]
+	if n := ec2Sizes[size]; ok && n > 0 {
+		return n, n * perVCPU * 1024
+	}
+	return 0, 0
+}
+
 func inferRole(resourceName, vmName string) string {
 	lower := strings.ToLower(resourceName + " " + vmName)
 	if strings.Contains(lower, "controlplane") || strings.Contains(lower, "control-plane") ||
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestParseTerraformOutputs(t *testing.T) {
+	state := []byte(`{
+		"version": 4,
+		"outputs": {
+			"cluster_vip": {"value": "192.168.1.50", "type": "string"},
+			"control_plane_endpoint": {"value": "https://k8s.example.com:6443", "type": "string"},
+			"node_count": {"value": 3, "type": "number"},
+			"talosconfig": {"value": "secret", "type": "string", "sensitive": true},
+			"node_ips": {"value": ["192.168.1.51", "192.168.1.52"], "type": ["list", "string"]}
+		},
+		"resources": [{
+			"mode": "managed",
+			"type": "proxmox_vm_qemu",
+			"name": "control_plane",
+			"instances": [{"attributes": {"name": "cp-1", "cores": 4, "memory": 8192}}]
+		}]
+	}`)
+
+	want := []model.TerraformOutput{
+		{Name: "cluster_vip", Value: "192.168.1.50"},
+		{Name: "control_plane_endpoint", Value: "https://k8s.example.com:6443"},
+		{Name: "node_count", Value: "3"},
+	}
+	if got := ParseTerraformOutputsBytes(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs = %+v, want %+v", got, want)
+	}
+	if nodes := ParseTerraformStateBytes(state); len(nodes) != 1 || nodes[0].Name != "cp-1" {
+		t.Errorf("nodes = %+v, want cp-1 alongside the outputs", nodes)
+	}
+	if got := ParseTerraformOutputsBytes([]byte(`{"version": 4, "resources": []}`)); got != nil {
+		t.Errorf("outputs of a state without outputs = %+v, want nil", got)
+	}
+}
+
+func TestParseTerraformProxmoxHost(t *testing.T) {
+	state := []byte(`{
+		"version": 4,
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "proxmox_vm_qemu",
+				"name": "worker",
+				"instances": [{"attributes": {"name": "worker-1", "target_node": "pve1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "proxmox_virtual_environment_vm",
+				"name": "control_plane",
+				"instances": [{"attributes": {"name": "cp-1", "node_name": "pve2"}}]
+			}
+		]
+	}`)
+
+	hosts := make(map[string]string)
+	for _, n := range ParseTerraformStateBytes(state) {
+		hosts[n.Name] = n.Host
+	}
+	want := map[string]string{"worker-1": "pve1", "cp-1": "pve2"}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+}
+
+func TestParseTerraformAWSInstance(t *testing.T) {
+	state := []byte(`{
+		"version": 4,
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "burst_worker",
+				"instances": [
+					{
+						"index_key": 0,
+						"attributes": {
+							"id": "i-0abc",
+							"instance_type": "m6i.xlarge",
+							"availability_zone": "eu-west-3a",
+							"private_ip": "10.0.1.12",
+							"public_ip": "15.188.1.2",
+							"tags": {"Name": "burst-1"},
+							"root_block_device": [{"volume_size": 30}],
+							"ebs_block_device": [
+								{"device_name": "/dev/sdf", "volume_size": 200},
+								{"device_name": "/dev/sdg", "volume_size": 50}
+							]
+						}
+					},
+					{
+						"index_key": 1,
+						"attributes": {
+							"id": "i-0def",
+							"instance_type": "c7g.large",
+							"availability_zone": "eu-west-3b",
+							"public_ip": "15.188.1.3",
+							"cpu_options": [{"core_count": 4, "threads_per_core": 2}],
+							"root_block_device": [{"volume_size": 20}]
+						}
+					}
+				]
+			},
+			{
+				"mode": "managed",
+				"type": "proxmox_vm_qemu",
+				"name": "worker",
+				"instances": [{"attributes": {"name": "worker-1", "target_node": "pve1"}}]
+			}
+		]
+	}`)
+
+	got := ParseTerraformStateBytes(state)
+	want := []model.TerraformNode{
+		{Name: "burst-1", IP: "10.0.1.12", Cores: 4, MemoryMB: 16384, OSDiskGB: 30, DataDiskGB: 250, Role: "worker", Provider: "aws", Host: "eu-west-3a"},
+		{Name: "i-0def", IP: "15.188.1.3", Cores: 8, MemoryMB: 4096, OSDiskGB: 20, Role: "worker", Provider: "aws", Host: "eu-west-3b"},
+		{Name: "worker-1", Role: "worker", Provider: "proxmox", Host: "pve1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEC2Shape(t *testing.T) {
+	tests := []struct {
+		instanceType    string
+		vcpus, memoryMB int
+	}{
+		{"t3.medium", 2, 4096},
+		{"t2.micro", 1, 1024},
+		{"t4g.xlarge", 4, 16384},
+		{"r6i.2xlarge", 8, 65536},
+		{"c6g.medium", 1, 2048},
+		{"m5zn.large", 2, 8192},
+		{"x2idn.metal", 0, 0},
+		{"mac1.metal", 0, 0},
+		{"mac2.xlarge", 0, 0},
+		{"c5n.large", 0, 0},
+		{"trn1.2xlarge", 0, 0},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		if vcpus, mem := ec2Shape(tt.instanceType); vcpus != tt.vcpus || mem != tt.memoryMB {
+			t.Errorf("ec2Shape(%q) = %d, %d; want %d, %d", tt.instanceType, vcpus, mem, tt.vcpus, tt.memoryMB)
+		}
+	}
+}
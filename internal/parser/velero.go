@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// veleroScheduleLabel names the Schedule a Backup was created by.
+const veleroScheduleLabel = "velero.io/schedule-name"
+
+// parseVeleroBackups lists Velero Backups. ok is false when they can't be
+// listed, typically because Velero isn't installed: namespaces then keep the
+// backup label as their only backup signal.
+func (p *KubernetesParser) parseVeleroBackups(ctx context.Context) (backups []model.VeleroBackupInfo, ok bool) {
+	gvr := schema.GroupVersionResource{
+		Group:    "velero.io",
+		Version:  "v1",
+		Resource: "backups",
+	}
+
+	list, err := p.dynamic.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Debug("failed to list velero backups (CRD may not exist)", "error", err)
+		return nil, false
+	}
+
+	for _, item := range list.Items {
+		spec, _ := item.Object["spec"].(map[string]interface{})
+		status, _ := item.Object["status"].(map[string]interface{})
+		backups = append(backups, model.VeleroBackupInfo{
+			Name:       item.GetName(),
+			Namespace:  item.GetNamespace(),
+			Cluster:    p.clusterName,
+			Schedule:   item.GetLabels()[veleroScheduleLabel],
+			IncludedNS: strList(spec, "includedNamespaces"),
+			ExcludedNS: strList(spec, "excludedNamespaces"),
+			Phase:      strVal(status, "phase"),
+			Completed:  timeVal(status, "completionTimestamp"),
+		})
+	}
+	return backups, true
+}
+
+// markLastBackups sets, on each namespace, when the newest completed backup
+// covering it finished. A PartiallyFailed or Failed backup doesn't count:
+// there is no telling whether the namespace made it in.
+func markLastBackups(namespaces []model.NamespaceInfo, backups []model.VeleroBackupInfo) {
+	for i := range namespaces {
+		ns := &namespaces[i]
+		ns.BackupsListed = true
+		for _, b := range backups {
+			if b.Phase != "Completed" || b.Cluster != ns.Cluster || !backupCovers(b, ns.Name) {
+				continue
+			}
+			if b.Completed.After(ns.LastBackup) {
+				ns.LastBackup = b.Completed
+			}
+		}
+	}
+}
+
+// backupCovers reports whether a backup includes namespace: its included
+// namespaces name it or are empty or "*" (every namespace), and its
+// excluded namespaces don't.
+func backupCovers(b model.VeleroBackupInfo, namespace string) bool {
+	if slices.Contains(b.ExcludedNS, namespace) {
+		return false
+	}
+	return len(b.IncludedNS) == 0 || slices.Contains(b.IncludedNS, "*") || slices.Contains(b.IncludedNS, namespace)
+}
+
+// strList returns the strings of a list field of an unstructured object.
+func strList(m map[string]interface{}, key string) []string {
+	items, _ := m[key].([]interface{})
+	var out []string
+	for _, it := range items {
+		if s, ok := it.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// timeVal parses an RFC 3339 timestamp field of an unstructured object,
+// returning the zero time when it is missing or malformed.
+func timeVal(m map[string]interface{}, key string) time.Time {
+	t, _ := time.Parse(time.RFC3339, strVal(m, key))
+	return t
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseVeleroBackupsLastBackup(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	backup := func(name, phase string, completed time.Time, included ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "velero.io/v1",
+			"kind":       "Backup",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "velero",
+				"labels":    map[string]interface{}{"velero.io/schedule-name": "daily"},
+			},
+			"spec": map[string]interface{}{
+				"includedNamespaces": included,
+				"excludedNamespaces": []interface{}{"scratch"},
+			},
+			"status": map[string]interface{}{
+				"phase":               phase,
+				"completionTimestamp": completed.UTC().Format(time.RFC3339),
+			},
+		}}
+	}
+	gvr := schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "BackupList"},
+		backup("daily-old", "Completed", now.Add(-30*time.Hour), "apps"),
+		backup("daily-new", "Completed", now.Add(-5*time.Hour), "apps"),
+		backup("daily-broken", "PartiallyFailed", now.Add(-time.Hour), "apps"),
+		backup("everything", "Completed", now.Add(-72*time.Hour), "*"),
+	)
+	typed := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: map[string]string{"backup": "velero"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}},
+	)
+
+	p := &KubernetesParser{dynamic: dyn, typed: typed, clusterName: "Homelab"}
+	backups, ok := p.parseVeleroBackups(context.Background())
+	if !ok || len(backups) != 4 {
+		t.Fatalf("parseVeleroBackups = %d backups, %v; want 4, true", len(backups), ok)
+	}
+	if backups[0].Schedule != "daily" {
+		t.Errorf("schedule = %q, want daily from the schedule-name label", backups[0].Schedule)
+	}
+
+	namespaces := p.parseNamespaces(context.Background())
+	markLastBackups(namespaces, backups)
+	want := map[string]time.Duration{
+		"apps":    5 * time.Hour,  // the newest completed backup; the partial one doesn't count
+		"db":      72 * time.Hour, // covered by the "*" backup only
+		"scratch": 0,              // excluded everywhere
+	}
+	for _, ns := range namespaces {
+		if !ns.BackupsListed {
+			t.Errorf("%s: BackupsListed false", ns.Name)
+		}
+		var age time.Duration
+		if !ns.LastBackup.IsZero() {
+			age = now.Sub(ns.LastBackup)
+		}
+		if age != want[ns.Name] {
+			t.Errorf("%s: last backup age = %v, want %v", ns.Name, age, want[ns.Name])
+		}
+	}
+}
+
+func TestParseVeleroBackupsWithoutCRD(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "BackupList"})
+	dyn.PrependReactor("list", "backups", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New(`the server could not find the requested resource`)
+	})
+	p := &KubernetesParser{dynamic: dyn, clusterName: "Homelab"}
+	if backups, ok := p.parseVeleroBackups(context.Background()); ok || backups != nil {
+		t.Errorf("parseVeleroBackups = %v, %v; want nil, false without the CRD", backups, ok)
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultRequestIDHeader carries the request ID when Config.RequestIDHeader
+// is unset.
+const defaultRequestIDHeader = "X-Request-Id"
+
+// withAccessLog logs every request with its method, path, status, duration
+// and request ID, and returns the ID in the response under header. An ID
+// sent by an upstream proxy in the same header is kept so logs correlate.
+// Probe and scrape endpoints log at Debug to keep Info readable.
+func withAccessLog(header string, next http.Handler) http.Handler {
+	if header == "" {
+		header = defaultRequestIDHeader
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(header)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+		w.Header().Set(header, id)
+
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		if p := r.URL.Path; strings.HasPrefix(p, "/api/health") || strings.HasPrefix(p, "/api/"+apiVersion+"/health") || p == "/metrics" {
+			level = slog.LevelDebug
+		}
+		slog.Log(context.Background(), level, "http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status(),
+			"duration", time.Since(start),
+			"bytes", rec.bytes,
+			"requestID", id,
+		)
+	})
+}
+
+// statusRecorder captures the status code and body size of a response while
+// still exposing Flush and Hijack (needed by /api/ws) from the wrapped writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	code  int
+	bytes int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	r.code = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// status returns the recorded status; a handler that never wrote sends 200.
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
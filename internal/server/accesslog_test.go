@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(prev)
+
+	h := withAccessLog("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	tests := []struct {
+		path      string
+		inID      string
+		wantLevel string
+		wantCode  int
+	}{
+		{"/api/diagrams", "", "INFO", http.StatusOK},
+		{"/api/missing", "", "INFO", http.StatusNotFound},
+		{"/api/health/live", "", "DEBUG", http.StatusOK},
+		{"/api/diagrams", "upstream-123", "INFO", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.inID != "" {
+				req.Header.Set("X-Request-Id", tt.inID)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			id := rec.Header().Get("X-Request-Id")
+			if id == "" {
+				t.Fatal("response has no X-Request-Id")
+			}
+			if tt.inID != "" && id != tt.inID {
+				t.Errorf("X-Request-Id = %q, want upstream %q kept", id, tt.inID)
+			}
+
+			var line struct {
+				Level     string `json:"level"`
+				Msg       string `json:"msg"`
+				Method    string `json:"method"`
+				Path      string `json:"path"`
+				Status    int    `json:"status"`
+				Duration  *int64 `json:"duration"`
+				RequestID string `json:"requestID"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("decoding log line %q: %v", buf.String(), err)
+			}
+			if line.Msg != "http request" || line.Level != tt.wantLevel {
+				t.Errorf("log = {msg:%q level:%q}, want {http request %s}", line.Msg, line.Level, tt.wantLevel)
+			}
+			if line.Method != http.MethodGet || line.Path != tt.path || line.Status != tt.wantCode {
+				t.Errorf("log = {%s %s %d}, want {GET %s %d}", line.Method, line.Path, line.Status, tt.path, tt.wantCode)
+			}
+			if line.Duration == nil {
+				t.Error("log line has no duration")
+			}
+			if line.RequestID != id {
+				t.Errorf("log requestID = %q, want %q", line.RequestID, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// apiVersion is the current API version. Routes are served under
+// /api/<apiVersion>/ and, for existing consumers, at their unversioned
+// /api/ paths; JSON envelopes carry it as "apiVersion". A breaking change to
+// a response shape ships under the next version instead.
+const apiVersion = "v1"
+
+// withAPIVersion serves /api/v1/<path> as /api/<path> through mux, so each
+// route is registered once and both spellings stay identical.
+func withAPIVersion(mux *http.ServeMux) http.Handler {
+	prefix := "/api/" + apiVersion
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/api" + strings.TrimPrefix(r.URL.Path, prefix)
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath = "/api" + strings.TrimPrefix(r.URL.RawPath, prefix)
+		}
+		mux.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestVersionedAndLegacyRoutesMatch(t *testing.T) {
+	s := &Server{
+		data:    []model.DiagramResult{{ID: "workloads", Title: "Workloads", Type: "table", Content: "[]"}},
+		lastGen: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	for _, route := range []string{"/diagrams", "/health", "/health/live", "/config"} {
+		t.Run(route, func(t *testing.T) {
+			legacy, versioned := get("/api"+route), get("/api/v1"+route)
+			if legacy.Code != http.StatusOK || versioned.Code != http.StatusOK {
+				t.Fatalf("status legacy=%d v1=%d, want 200", legacy.Code, versioned.Code)
+			}
+			if legacy.Body.String() != versioned.Body.String() {
+				t.Errorf("bodies differ:\nlegacy: %s\nv1:     %s", legacy.Body, versioned.Body)
+			}
+		})
+	}
+
+	var payload struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal(get("/api/v1/diagrams").Body.Bytes(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.APIVersion != "v1" {
+		t.Errorf("apiVersion = %q, want v1", payload.APIVersion)
+	}
+
+	if rec := get("/api/v1/nope"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown versioned route status = %d, want 404", rec.Code)
+	}
+}
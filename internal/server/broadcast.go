@@ -0,0 +1,50 @@
+package server
+
+import "sync"
+
+// broadcaster fans out "diagrams changed" notifications to live clients.
+// Notifications carry no payload and coalesce: a slow subscriber sees one
+// pending signal however many updates landed, then reads the current data.
+// The zero value is ready to use.
+type broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// subscribe registers a new listener. Callers must unsubscribe when done.
+func (b *broadcaster) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[chan struct{}]struct{})
+	}
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes a listener registered with subscribe.
+func (b *broadcaster) unsubscribe(ch chan struct{}) {
+	b.mu.Lock()
+	delete(b.subs, ch)
+	b.mu.Unlock()
+}
+
+// notify signals every listener without blocking.
+func (b *broadcaster) notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- struct{}{}:
+		default: // already has a pending signal
+		}
+	}
+}
+
+// count returns the number of live listeners.
+func (b *broadcaster) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
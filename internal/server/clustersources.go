@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// DataSourcesKey is the ConfigMap or Secret key DataSourcesFrom reads: a
+// JSON list in the same format as the DATA_SOURCES environment variable.
+const DataSourcesKey = "dataSources"
+
+// dataSourcesRetry is how long WatchDataSources waits before re-opening a
+// failed or expired watch.
+var dataSourcesRetry = 10 * time.Second
+
+// dataSourcesRef is a parsed Config.DataSourcesFrom.
+type dataSourcesRef struct {
+	kind      string // "configmap" or "secret"
+	namespace string
+	name      string
+}
+
+func parseDataSourcesRef(s string) (dataSourcesRef, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" || (parts[0] != "configmap" && parts[0] != "secret") {
+		return dataSourcesRef{}, fmt.Errorf("data sources from %q: want configmap/<namespace>/<name> or secret/<namespace>/<name>", s)
+	}
+	return dataSourcesRef{kind: parts[0], namespace: parts[1], name: parts[2]}, nil
+}
+
+// WatchDataSources keeps the data sources in sync with the ConfigMap or
+// Secret named by Config.DataSourcesFrom, read with the primary cluster's
+// client, until ctx is done. Each change is applied through Reload; an
+// object that is missing or holds an invalid list is logged and the
+// running sources are kept. It returns at once when DataSourcesFrom is
+// unset.
+func (s *Server) WatchDataSources(ctx context.Context) {
+	s.mu.RLock()
+	from, primary := s.cfg.DataSourcesFrom, primaryParser(s.k8sParsers)
+	s.mu.RUnlock()
+	if from == "" {
+		return
+	}
+	ref, err := parseDataSourcesRef(from)
+	if err != nil {
+		slog.Error("not watching data sources", "error", err)
+		return
+	}
+	s.watchDataSources(ctx, primary.Clientset(), ref)
+}
+
+func (s *Server) watchDataSources(ctx context.Context, client kubernetes.Interface, ref dataSourcesRef) {
+	for {
+		if err := s.followDataSources(ctx, client, ref); err != nil {
+			slog.Warn("data sources watch failed — retrying", "kind", ref.kind, "namespace", ref.namespace, "name", ref.name, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dataSourcesRetry):
+		}
+	}
+}
+
+// followDataSources applies the object's current content, then every change
+// to it until the watch ends.
+func (s *Server) followDataSources(ctx context.Context, client kubernetes.Interface, ref dataSourcesRef) error {
+	var obj runtime.Object
+	var err error
+	var w watch.Interface
+	byName := metav1.ListOptions{FieldSelector: "metadata.name=" + ref.name}
+	if ref.kind == "secret" {
+		obj, err = client.CoreV1().Secrets(ref.namespace).Get(ctx, ref.name, metav1.GetOptions{})
+	} else {
+		obj, err = client.CoreV1().ConfigMaps(ref.namespace).Get(ctx, ref.name, metav1.GetOptions{})
+	}
+	switch {
+	case apierrors.IsNotFound(err):
+		slog.Warn("data sources object not found — keeping running sources", "kind", ref.kind, "namespace", ref.namespace, "name", ref.name)
+	case err != nil:
+		return err
+	default:
+		s.applyClusterSources(obj)
+		byName.ResourceVersion = obj.(metav1.Object).GetResourceVersion()
+	}
+
+	if ref.kind == "secret" {
+		w, err = client.CoreV1().Secrets(ref.namespace).Watch(ctx, byName)
+	} else {
+		w, err = client.CoreV1().ConfigMaps(ref.namespace).Watch(ctx, byName)
+	}
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch ev.Type {
+			case watch.Added, watch.Modified:
+				s.applyClusterSources(ev.Object)
+			case watch.Deleted:
+				slog.Warn("data sources object deleted — keeping running sources", "kind", ref.kind, "namespace", ref.namespace, "name", ref.name)
+			case watch.Error:
+				return apierrors.FromObject(ev.Object)
+			}
+		}
+	}
+}
+
+// applyClusterSources decodes the data sources held by a ConfigMap or Secret
+// and reloads the server with them when they changed.
+func (s *Server) applyClusterSources(obj runtime.Object) {
+	var raw []byte
+	var ok bool
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		var v string
+		v, ok = o.Data[DataSourcesKey]
+		raw = []byte(v)
+	case *corev1.Secret:
+		raw, ok = o.Data[DataSourcesKey]
+	}
+	if !ok {
+		slog.Error("data sources object has no dataSources key — keeping running sources")
+		return
+	}
+
+	var sources []model.DataSource
+	if err := json.Unmarshal(raw, &sources); err != nil {
+		slog.Error("parsing cluster data sources — keeping running sources", "error", err)
+		return
+	}
+	if err := validateDataSources(sources); err != nil {
+		slog.Error("invalid cluster data sources — keeping running sources", "error", err)
+		return
+	}
+	if sources == nil {
+		sources = []model.DataSource{} // an empty list still overrides the environment
+	}
+
+	s.mu.Lock()
+	if s.clusterSources != nil && reflect.DeepEqual(s.clusterSources, sources) {
+		s.mu.Unlock()
+		return
+	}
+	prev := s.clusterSources
+	s.clusterSources = sources
+	cfg := s.cfg
+	s.mu.Unlock()
+
+	if err := s.Reload(cfg); err != nil {
+		s.mu.Lock()
+		s.clusterSources = prev
+		s.mu.Unlock()
+		slog.Error("cluster data sources rejected — keeping running sources", "error", err)
+		return
+	}
+	slog.Info("data sources reloaded from cluster", "dataSources", len(sources))
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestWatchDataSourcesFromConfigMap(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(reloadKubeconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const from = "configmap/cluster-vision/data-sources"
+	s, err := New(Config{
+		Kubeconfig:      kubeconfig,
+		RefreshInterval: time.Minute,
+		DataSourcesFrom: from,
+		DataSources:     []model.DataSource{{Name: "env", Type: "tfstate", Path: "/data/env.tfstate"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := func(sources string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-sources", Namespace: "cluster-vision"},
+			Data:       map[string]string{DataSourcesKey: sources},
+		}
+	}
+	client := fake.NewSimpleClientset(cm(`[{"name":"Terraform","type":"tfstate","path":"/data/a.tfstate"}]`))
+	events := watch.NewFake()
+	client.PrependWatchReactor("configmaps", k8stesting.DefaultWatchReactor(events, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ref, err := parseDataSourcesRef(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.watchDataSources(ctx, client, ref)
+
+	sources := func() []model.DataSource {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.cfg.DataSources
+	}
+	waitFor := func(what string, ok func([]model.DataSource) bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !ok(sources()) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: data sources = %+v", what, sources())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	drainRefresh := func() bool {
+		select {
+		case <-s.refreshReq:
+			return true
+		default:
+			return false
+		}
+	}
+
+	waitFor("initial ConfigMap not loaded", func(ds []model.DataSource) bool {
+		return len(ds) == 1 && ds[0].Path == "/data/a.tfstate"
+	})
+	if !drainRefresh() {
+		t.Error("loading the ConfigMap did not request a refresh")
+	}
+
+	// Modify blocks until the watcher reads the event, so the invalid list
+	// below is processed before the valid one that follows it.
+	events.Modify(cm(`[{"name":"x","type":"ansible"}]`))
+	events.Modify(cm(`[
+		{"name": "Terraform", "type": "tfstate", "path": "/data/b.tfstate"},
+		{"name": "NAS", "type": "docker-compose", "path": "/data/compose.yaml"}
+	]`))
+	waitFor("change not reloaded", func(ds []model.DataSource) bool { return len(ds) == 2 })
+	if got := sources(); got[0].Path != "/data/b.tfstate" || got[1].Name != "NAS" {
+		t.Errorf("data sources = %+v", got)
+	}
+	if !drainRefresh() {
+		t.Error("the ConfigMap change did not request a refresh")
+	}
+
+	// A SIGHUP-style reload from the environment keeps the cluster's list.
+	if err := s.Reload(Config{Kubeconfig: kubeconfig, RefreshInterval: time.Minute, DataSourcesFrom: from}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sources(); len(got) != 2 {
+		t.Errorf("data sources after reload = %+v, want the ConfigMap's", got)
+	}
+}
+
+func TestParseDataSourcesRef(t *testing.T) {
+	if ref, err := parseDataSourcesRef("secret/ops/sources"); err != nil || ref != (dataSourcesRef{"secret", "ops", "sources"}) {
+		t.Errorf("parseDataSourcesRef(secret/ops/sources) = %+v, %v", ref, err)
+	}
+	for _, bad := range []string{"ops/sources", "deployment/ops/sources", "configmap//sources", "configmap/ops/"} {
+		if _, err := parseDataSourcesRef(bad); err == nil {
+			t.Errorf("parseDataSourcesRef(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/parser"
+)
+
+// fileConfig is the JSON config file given with -config. Only settings that
+// can change without a restart live here; unset fields keep the value from
+// flags and environment.
+type fileConfig struct {
+	ClusterName           string             `json:"clusterName"`
+	ClusterNameLabel      string             `json:"clusterNameLabel"`
+	DataSources           []model.DataSource `json:"dataSources"`
+	RefreshInterval       string             `json:"refreshInterval"` // Go duration, e.g. "5m"
+	TeamLabel             string             `json:"teamLabel"`
+	IncludeTerminatedPods *bool              `json:"includeTerminatedPods"`
+	RegistryProxy         string             `json:"registryProxy"`
+	LocalRegistry         string             `json:"localRegistry"`
+	ImageNamespaces       []string           `json:"imageNamespaces"`
+	EastWestNamespace     string             `json:"eastWestNamespace"`
+	EastWestLabel         string             `json:"eastWestLabel"`
+	NodeRoleRules         []parser.RoleRule  `json:"nodeRoleRules"`
+	DiagramOrder          []string           `json:"diagramOrder"`
+	DisabledDiagrams      []string           `json:"disabledDiagrams"`
+}
+
+// ApplyConfigFile overlays the JSON config file at path onto cfg. Unknown
+// keys are rejected so a typo doesn't silently do nothing.
+func ApplyConfigFile(cfg Config, path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var fc fileConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return cfg, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if fc.ClusterName != "" {
+		cfg.ClusterName = fc.ClusterName
+	}
+	if fc.DataSources != nil {
+		cfg.DataSources = fc.DataSources
+	}
+	if fc.RefreshInterval != "" {
+		d, err := time.ParseDuration(fc.RefreshInterval)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("config file %s: invalid refreshInterval %q", path, fc.RefreshInterval)
+		}
+		cfg.RefreshInterval = d
+	}
+	if fc.ClusterNameLabel != "" {
+		cfg.ClusterNameLabel = fc.ClusterNameLabel
+	}
+	if fc.TeamLabel != "" {
+		cfg.TeamLabel = fc.TeamLabel
+	}
+	if fc.IncludeTerminatedPods != nil {
+		cfg.IncludeTerminatedPods = *fc.IncludeTerminatedPods
+	}
+	if fc.RegistryProxy != "" {
+		cfg.RegistryProxy = fc.RegistryProxy
+	}
+	if fc.LocalRegistry != "" {
+		cfg.LocalRegistry = fc.LocalRegistry
+	}
+	if fc.ImageNamespaces != nil {
+		cfg.ImageNamespaces = fc.ImageNamespaces
+	}
+	if fc.EastWestNamespace != "" {
+		cfg.EastWestNamespace = fc.EastWestNamespace
+	}
+	if fc.EastWestLabel != "" {
+		cfg.EastWestLabel = fc.EastWestLabel
+	}
+	if fc.NodeRoleRules != nil {
+		cfg.NodeRoleRules = fc.NodeRoleRules
+	}
+	if fc.DiagramOrder != nil {
+		cfg.DiagramOrder = fc.DiagramOrder
+	}
+	if fc.DisabledDiagrams != nil {
+		cfg.DisabledDiagrams = fc.DisabledDiagrams
+	}
+	return cfg, nil
+}
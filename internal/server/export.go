@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// exportExt maps diagram types to the file extension they're exported with.
+var exportExt = map[string]string{
+	"mermaid":  ".mmd",
+	"markdown": ".md",
+	"table":    ".json",
+	"flow":     ".json",
+	"detail":   ".json",
+}
+
+// exportContentTypes maps export file extensions to their Content-Type.
+var exportContentTypes = map[string]string{
+	".mmd":  "text/plain; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".json": "application/json",
+}
+
+// contentHash returns a short hash of a diagram's content, used as its
+// version in export URLs.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
+
+// handleExport serves a diagram as the file exportDiagrams would write, at
+// /api/export/<id>.<ext>. With ?v=<hash> matching the current content it is
+// marked immutable, so browsers and CDNs keep it until the hash in the URL
+// changes; a stale version redirects to the current one rather than being
+// cached under the old URL. Without ?v= it must be revalidated (ETag).
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := filepath.Ext(file)
+	id := strings.TrimSuffix(file, ext)
+
+	s.mu.RLock()
+	var d model.DiagramResult
+	found := false
+	for _, v := range s.data {
+		if v.ID == id {
+			d, found = v, true
+			break
+		}
+	}
+	lastGen := s.lastGen
+	s.mu.RUnlock()
+
+	if !found || exportExt[d.Type] != ext {
+		http.Error(w, fmt.Sprintf("no export %q", file), http.StatusNotFound)
+		return
+	}
+
+	hash := contentHash(d.Content)
+	switch v := r.URL.Query().Get("v"); v {
+	case "":
+		w.Header().Set("Cache-Control", "no-cache")
+	case hash:
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	default:
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, r.URL.Path+"?v="+url.QueryEscape(hash), http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", exportContentTypes[ext])
+	w.Header().Set("ETag", `"`+hash+`"`)
+	http.ServeContent(w, r, file, lastGen, strings.NewReader(d.Content))
+}
+
+// exportDiagrams writes each diagram to dir as <id>.<ext>. Files are
+// replaced atomically (temp file + rename) and left alone when their content
+// is unchanged, so a committed export only churns on real changes. Returns
+// the number of files written.
+func exportDiagrams(dir string, diagrams []model.DiagramResult) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating export dir: %w", err)
+	}
+
+	written := 0
+	var errs []string
+	for _, d := range diagrams {
+		ext, ok := exportExt[d.Type]
+		if !ok || d.ID == "" || strings.ContainsAny(d.ID, `/\`) || strings.HasPrefix(d.ID, ".") {
+			continue
+		}
+		path := filepath.Join(dir, d.ID+ext)
+		content := []byte(d.Content)
+
+		if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, content) {
+			continue
+		}
+		if err := writeFileAtomic(path, content); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", d.ID, err))
+			continue
+		}
+		written++
+	}
+	if len(errs) > 0 {
+		return written, fmt.Errorf("exporting diagrams: %s", strings.Join(errs, "; "))
+	}
+	return written, nil
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it
+// into place, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestExportDiagrams(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	diagrams := []model.DiagramResult{
+		{ID: "topology", Type: "mermaid", Content: "graph TB\n  a --> b\n"},
+		{ID: "nodes", Type: "table", Content: `[{"name":"n1"}]`},
+		{ID: "dependencies", Type: "flow", Content: `{"nodes":[],"edges":[]}`},
+		{ID: "charts", Type: "markdown", Content: "*No HelmRelease data available.*"},
+		{ID: "../escape", Type: "markdown", Content: "nope"},
+	}
+
+	n, err := exportDiagrams(dir, diagrams)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("written = %d, want 4", n)
+	}
+	want := map[string]string{
+		"topology.mmd":      "graph TB\n  a --> b\n",
+		"nodes.json":        `[{"name":"n1"}]`,
+		"dependencies.json": `{"nodes":[],"edges":[]}`,
+		"charts.md":         "*No HelmRelease data available.*",
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(want) {
+		t.Errorf("export dir has %d entries, want %d (no temp files or escapes)", len(entries), len(want))
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+
+	// Backdate the files so a rewrite would be visible in the mtime.
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for name := range want {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diagrams[1].Content = `[{"name":"n1"},{"name":"n2"}]`
+	n, err = exportDiagrams(dir, diagrams)
+	if err != nil {
+		t.Fatalf("second export: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("second export wrote %d files, want only the changed one", n)
+	}
+	for name := range want {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		changed := !fi.ModTime().Equal(old)
+		if changed != (name == "nodes.json") {
+			t.Errorf("%s rewritten = %v", name, changed)
+		}
+	}
+}
+
+func TestHandleExport(t *testing.T) {
+	mermaid := "graph TB\n  a --> b\n"
+	hash := contentHash(mermaid)
+	s := &Server{
+		data:    []model.DiagramResult{{ID: "topology", Type: "mermaid", Content: mermaid}},
+		lastGen: time.Now(),
+	}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	tests := []struct {
+		path         string
+		wantStatus   int
+		wantCache    string
+		wantLocation string
+	}{
+		{"/api/export/topology.mmd?v=" + hash, http.StatusOK, "public, max-age=31536000, immutable", ""},
+		{"/api/export/topology.mmd", http.StatusOK, "no-cache", ""},
+		{"/api/export/topology.mmd?v=0123456789abcdef", http.StatusFound, "no-store", "/api/export/topology.mmd?v=" + hash},
+		{"/api/export/topology.json", http.StatusNotFound, "", ""},
+		{"/api/export/nope.md", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if rec.Code == http.StatusOK {
+				if rec.Body.String() != mermaid {
+					t.Errorf("body = %q, want %q", rec.Body.String(), mermaid)
+				}
+				if got := rec.Header().Get("ETag"); got != `"`+hash+`"` {
+					t.Errorf("ETag = %q, want %q", got, hash)
+				}
+			}
+		})
+	}
+}
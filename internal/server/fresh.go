@@ -0,0 +1,44 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// liveDiagrams are the generators whose output depends on version checker
+// state, which moves on between refreshes as checks complete.
+var liveDiagrams = []string{"images", "charts", "nodes", "cluster-info"}
+
+// regenerateLive reruns the live generators against cd and the checkers'
+// current state, returning diagrams with their output swapped in. Disabled
+// generators stay disabled: only diagrams already in the list are replaced.
+func (s *Server) regenerateLive(diagrams []model.DiagramResult, cd *model.ClusterData) []model.DiagramResult {
+	var results []model.DiagramResult
+	for _, g := range diagramRegistry {
+		if slices.Contains(liveDiagrams, g.id) {
+			results = append(results, g.run(s, cd)...)
+		}
+	}
+	return withDiagrams(diagrams, results)
+}
+
+// withDiagrams returns a copy of diagrams with those sharing an ID with one
+// of results replaced by it, its Hash computed from the new content. The
+// input slice is left untouched, so readers holding it never see it change
+// underneath them.
+func withDiagrams(diagrams []model.DiagramResult, results []model.DiagramResult) []model.DiagramResult {
+	byID := make(map[string]model.DiagramResult, len(results))
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	out := make([]model.DiagramResult, len(diagrams))
+	copy(out, diagrams)
+	for i, d := range out {
+		if r, ok := byID[d.ID]; ok {
+			r.Hash = contentHash(r.Content)
+			out[i] = r
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestHandleDiagramsFresh(t *testing.T) {
+	var latest atomic.Value
+	latest.Store("1.2.0")
+	repo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: " + latest.Load().(string) + "\n"))
+	}))
+	defer repo.Close()
+
+	cd := &model.ClusterData{
+		HelmRepositories: []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", URL: repo.URL}},
+		HelmReleases:     []model.HelmReleaseInfo{{Name: "app", Namespace: "apps", ChartName: "app", RepoName: "charts", RepoNS: "flux-system", Version: "1.2.0"}},
+	}
+	s := &Server{
+		checker:      versions.NewChecker(time.Hour, ""),
+		imageChecker: versions.NewImageChecker("", nil),
+		nodeChecker:  versions.NewNodeChecker(nil, 0),
+		clusterData:  cd,
+		lastGen:      time.Now(),
+	}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+	s.checker.Check(cd.HelmRepositories, cd.HelmReleases)
+	s.data = s.generateDiagrams(cd)
+
+	// The repository publishes 1.3.0 and the checker picks it up after the
+	// refresh rendered the diagrams.
+	latest.Store("1.3.0")
+	if _, ok := s.checker.ForceCheck(cd.HelmRepositories, cd.HelmReleases); !ok {
+		t.Fatal("ForceCheck did not run")
+	}
+
+	charts := func(url string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var resp struct {
+			Diagrams []model.DiagramResult `json:"diagrams"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decoding %s: %v", url, err)
+		}
+		for _, d := range resp.Diagrams {
+			if d.ID == "charts" {
+				return d.Content
+			}
+		}
+		t.Fatalf("%s has no charts diagram", url)
+		return ""
+	}
+
+	if got := charts("/api/diagrams"); strings.Contains(got, "1.3.0") {
+		t.Errorf("cached charts already show 1.3.0: %s", got)
+	}
+	if got := charts("/api/diagrams?fresh=1"); !strings.Contains(got, "1.3.0") {
+		t.Errorf("fresh charts don't show 1.3.0: %s", got)
+	}
+	if got := charts("/api/diagrams"); strings.Contains(got, "1.3.0") {
+		t.Errorf("a fresh request changed the cached charts: %s", got)
+	}
+}
+
+func TestReplaceDiagramHashesContent(t *testing.T) {
+	s := &Server{data: []model.DiagramResult{
+		{ID: "nodes", Content: "graph TD", Hash: contentHash("graph TD")},
+		{ID: "images", Content: "*Loading…*", Hash: contentHash("*Loading…*")},
+	}}
+
+	// An async checker finishing swaps in a diagram it generated itself,
+	// without a hash.
+	s.replaceDiagram(model.DiagramResult{ID: "images", Type: "table", Content: "[]"})
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if got, want := s.data[1].Hash, contentHash("[]"); got != want {
+		t.Errorf("replaced diagram hash = %q, want %q from its new content", got, want)
+	}
+	if got, want := s.data[0].Hash, contentHash("graph TD"); got != want {
+		t.Errorf("untouched diagram hash = %q, want %q", got, want)
+	}
+}
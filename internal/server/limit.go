@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// withConcurrencyLimit caps the /api requests handled at once at limit,
+// answering 503 with Retry-After to any beyond it rather than queueing them.
+// The WebSocket would hold a slot for its whole lifetime, and probes must
+// keep answering under load, so both bypass the cap, as do non-API paths
+// (web UI, /metrics). A limit of zero or less
+// returns next unchanged.
+func withConcurrencyLimit(limit int, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	slots := make(chan struct{}, limit)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limitedPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, `{"error":"too many concurrent requests"}`, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// limitedPath reports whether an /api path counts against the concurrency
+// limit: everything but the WebSocket and health probes.
+func limitedPath(path string) bool {
+	p, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return false
+	}
+	p = strings.TrimPrefix(p, apiVersion+"/")
+	switch {
+	case p == "ws", p == "health", strings.HasPrefix(p, "health/"):
+		return false
+	}
+	return true
+}
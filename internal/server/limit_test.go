@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	const limit = 2
+	s := &Server{}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+	// Requests marked X-Block hold their slot until release, then reach
+	// the real mux like every other request.
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	h := withConcurrencyLimit(limit, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Block") != "" {
+			entered <- struct{}{}
+			<-release
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	serve := func(path string, block bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if block {
+			req.Header.Set("X-Block", "1")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Saturate the limit with requests blocked before the handler.
+	var wg sync.WaitGroup
+	for range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := serve("/api/diagrams", true); rec.Code != http.StatusOK {
+				t.Errorf("in-limit request status = %d, want 200", rec.Code)
+			}
+		}()
+		<-entered
+	}
+
+	for _, path := range []string{"/api/diagrams", "/api/diagrams/events", "/api/v1/status", "/api/export/diagrams.json"} {
+		rec := serve(path, false)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s while saturated: status = %d, want 503", path, rec.Code)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Errorf("%s while saturated: no Retry-After header", path)
+		}
+	}
+	// The WebSocket answers a plain GET with 400; it must still get
+	// through to its handler.
+	for path, want := range map[string]int{
+		"/api/health/live": http.StatusOK,
+		"/api/v1/ws":       http.StatusBadRequest,
+		"/metrics":         http.StatusOK,
+	} {
+		if rec := serve(path, false); rec.Code != want {
+			t.Errorf("%s is exempt from the limit: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	close(release)
+	wg.Wait()
+	if rec := serve("/api/diagrams", false); rec.Code != http.StatusOK {
+		t.Errorf("after release: status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/diagram"
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+// proxyProbeInterval is how often registryProxyLoop probes the registry
+// proxy.
+var proxyProbeInterval = time.Minute
+
+// registryProxyLoop probes the registry proxy at startup and then every
+// proxyProbeInterval, so a proxy that is down shows up in /api/status and
+// as a warning rather than only as failed version checks. A reload that
+// changes or clears the proxy is picked up on the next probe.
+func (s *Server) registryProxyLoop(ctx context.Context) {
+	ticker := time.NewTicker(proxyProbeInterval)
+	defer ticker.Stop()
+	for {
+		s.probeRegistryProxy(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeRegistryProxy probes the proxy and, when its reachability changed,
+// logs the change and refreshes the served warnings.
+func (s *Server) probeRegistryProxy(ctx context.Context) versions.ProxyHealth {
+	prev := s.checker.RegistryProxyHealth()
+	h := s.checker.ProbeRegistryProxy(ctx)
+	if h.Proxy == prev.Proxy && h.Reachable == prev.Reachable {
+		return h
+	}
+	switch {
+	case h.Proxy == "":
+	case h.Reachable:
+		slog.Info("registry proxy reachable", "proxy", h.Proxy)
+	default:
+		slog.Warn("registry proxy unreachable — OCI version checks through it will fail", "proxy", h.Proxy, "error", h.Error)
+	}
+
+	s.mu.Lock()
+	if s.clusterData != nil {
+		s.warnings = s.withProxyWarning(diagram.CollectWarnings(s.clusterData))
+	}
+	s.mu.Unlock()
+	s.updates.notify()
+	return h
+}
+
+// withProxyWarning appends a warning to ws when the last probe found the
+// registry proxy unreachable.
+func (s *Server) withProxyWarning(ws []model.Warning) []model.Warning {
+	h := s.checker.RegistryProxyHealth()
+	if h.Proxy == "" || h.Reachable {
+		return ws
+	}
+	return append(ws, model.Warning{
+		Source:   "registry-proxy",
+		Severity: model.SeverityWarn,
+		Name:     h.Proxy,
+		Message:  "registry proxy " + h.Proxy + " is unreachable (" + h.Error + "); latest versions resolved through it may be stale",
+	})
+}
+
+// registryProxyStatus returns the last probe outcome for API responses, or
+// nil when no proxy is configured.
+func (s *Server) registryProxyStatus() *versions.ProxyHealth {
+	h := s.checker.RegistryProxyHealth()
+	if h.Proxy == "" {
+		return nil
+	}
+	return &h
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestStatusReportsRegistryProxyDown(t *testing.T) {
+	proxy := httptest.NewServer(http.NotFoundHandler())
+	addr := proxy.Listener.Addr().String()
+	proxy.Close() // nothing listens there any more
+
+	s := &Server{
+		checker:     versions.NewChecker(time.Hour, addr),
+		clusterData: &model.ClusterData{},
+	}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+	s.probeRegistryProxy(context.Background())
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	var resp struct {
+		RegistryProxy *versions.ProxyHealth `json:"registryProxy"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if resp.RegistryProxy == nil || resp.RegistryProxy.Reachable || resp.RegistryProxy.Proxy != addr || resp.RegistryProxy.Error == "" {
+		t.Fatalf("status registryProxy = %+v, want %s unreachable with an error", resp.RegistryProxy, addr)
+	}
+
+	if len(s.warnings) != 1 || s.warnings[0].Source != "registry-proxy" {
+		t.Errorf("warnings = %+v, want one registry-proxy warning", s.warnings)
+	}
+
+	// Without a proxy the status omits it and the warning goes away.
+	s.checker.SetRegistryProxy("")
+	s.probeRegistryProxy(context.Background())
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	resp.RegistryProxy = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if resp.RegistryProxy != nil {
+		t.Errorf("status registryProxy = %+v with no proxy configured, want omitted", resp.RegistryProxy)
+	}
+	if len(s.warnings) != 0 {
+		t.Errorf("warnings = %+v after the proxy was removed, want none", s.warnings)
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/diagram"
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// diagramGen is a registered diagram generator. Most produce the single
+// diagram named by id; a few (topology, argo, security, charts) produce a
+// group of diagrams that is enabled, disabled and ordered as one.
+type diagramGen struct {
+	id  string
+	run func(s *Server, cd *model.ClusterData) []model.DiagramResult
+}
+
+// one registers a generator of a single diagram that only needs the data.
+func one(id, title string, gen func(*model.ClusterData) model.DiagramResult) diagramGen {
+	return diagramGen{id, func(_ *Server, cd *model.ClusterData) []model.DiagramResult {
+		return []model.DiagramResult{diagram.Safe(id, title, func() model.DiagramResult { return gen(cd) })}
+	}}
+}
+
+// group registers a generator of several diagrams that only needs the data.
+func group(id, title string, gen func(*model.ClusterData) []model.DiagramResult) diagramGen {
+	return diagramGen{id, func(_ *Server, cd *model.ClusterData) []model.DiagramResult {
+		return diagram.SafeAll(id, title, func() []model.DiagramResult { return gen(cd) })
+	}}
+}
+
+// oneWithOptions registers a generator of a single diagram that needs the
+// data and the server's diagram options.
+func oneWithOptions(id, title string, gen func(*model.ClusterData, diagram.Options) model.DiagramResult) diagramGen {
+	return diagramGen{id, func(s *Server, cd *model.ClusterData) []model.DiagramResult {
+		return []model.DiagramResult{diagram.Safe(id, title, func() model.DiagramResult { return gen(cd, s.diagramOpts) })}
+	}}
+}
+
+// groupWithOptions registers a generator of several diagrams that needs the
+// data and the server's diagram options.
+func groupWithOptions(id, title string, gen func(*model.ClusterData, diagram.Options) []model.DiagramResult) diagramGen {
+	return diagramGen{id, func(s *Server, cd *model.ClusterData) []model.DiagramResult {
+		return diagram.SafeAll(id, title, func() []model.DiagramResult { return gen(cd, s.diagramOpts) })
+	}}
+}
+
+// diagramRegistry lists every generator, in the default tab order.
+var diagramRegistry = []diagramGen{
+	groupWithOptions("topology", "Physical Topology", diagram.GenerateTopologySections),
+	oneWithOptions("capi", "Cluster API", diagram.GenerateCAPI),
+	one("dependencies", "Flux Dependencies", diagram.GenerateDependencies),
+	one("dependencies-mermaid", "Flux Dependencies (Mermaid)", diagram.GenerateDependenciesMermaid),
+	one("flux-sources", "Flux Sources", diagram.GenerateFluxSources),
+	group("argo", "Argo CD Sync Waves", diagram.GenerateArgo),
+	one("network", "Network & Ingress", diagram.GenerateNetwork),
+	groupWithOptions("security", "Security Matrix", diagram.GenerateSecurity),
+	{"images", func(s *Server, cd *model.ClusterData) []model.DiagramResult {
+		return []model.DiagramResult{diagram.Safe("images", "Container Images", func() model.DiagramResult {
+			return diagram.GenerateImages(cd, s.imageChecker, s.vulnScanner, s.checker.RegistryProxy(), s.diagramOpts)
+		})}
+	}},
+	{"charts", func(s *Server, cd *model.ClusterData) []model.DiagramResult {
+		return diagram.SafeAll("charts", "Helm Charts", func() []model.DiagramResult {
+			return diagram.GenerateVersions(cd, s.checker, s.diagramOpts)
+		})
+	}},
+	{"nodes", func(s *Server, cd *model.ClusterData) []model.DiagramResult {
+		return []model.DiagramResult{diagram.Safe("nodes", "Cluster Nodes", func() model.DiagramResult {
+			return diagram.GenerateNodes(cd, s.nodeChecker, s.securityChecker)
+		})}
+	}},
+	{"cluster-info", func(s *Server, cd *model.ClusterData) []model.DiagramResult {
+		return []model.DiagramResult{diagram.Safe("cluster-info", "Cluster Info", func() model.DiagramResult {
+			return diagram.GenerateClusterInfo(cd, s.nodeChecker)
+		})}
+	}},
+	one("workloads", "Workloads", diagram.GenerateWorkloads),
+	one("storage", "Storage", diagram.GenerateStorage),
+	one("crds", "Custom Resource Definitions", diagram.GenerateCRDs),
+	one("capabilities", "Integrations", diagram.GenerateCapabilities),
+	one("deprecated-apis", "Deprecated APIs", diagram.GenerateDeprecatedAPIs),
+	one("quotas", "Resource Quotas & Limits", diagram.GenerateQuotas),
+	one("certificates", "Certificates", diagram.GenerateCertificates),
+	one("network-policies", "Network Policies", diagram.GenerateNetworkPolicies),
+	one("configs", "ConfigMaps & Secrets", diagram.GenerateConfigs),
+	one("helm-workloads", "Helm to Workloads", diagram.GenerateHelmWorkloads),
+	one("service-map", "Service Mapping", diagram.GenerateServiceMap),
+	one("namespace-summary", "Namespace Summary", diagram.GenerateNamespaceSummary),
+	one("rbac", "RBAC Inventory", diagram.GenerateRBAC),
+	one("rbac-privileged", "Privileged RBAC Subjects", diagram.GeneratePrivilegedRBAC),
+	one("labels", "Labels & Annotations", diagram.GenerateLabels),
+	one("velero", "Backup Schedules", diagram.GenerateVelero),
+	one("events", "Warning Events", func(cd *model.ClusterData) model.DiagramResult {
+		return diagram.GenerateEvents(cd, diagram.DefaultEventsWindow, time.Now())
+	}),
+}
+
+// regenerate reruns the enabled generators with the given IDs against cd,
+// e.g. once a version check has updated what they show, through the same
+// registry entries (and Safe wrappers) as a full refresh. Disabled
+// generators are skipped: their diagrams aren't served.
+func (s *Server) regenerate(cd *model.ClusterData, ids ...string) []model.DiagramResult {
+	s.mu.RLock()
+	gens := s.diagrams
+	s.mu.RUnlock()
+	if gens == nil {
+		gens = diagramRegistry
+	}
+
+	var out []model.DiagramResult
+	for _, g := range gens {
+		if slices.Contains(ids, g.id) {
+			out = append(out, g.run(s, cd)...)
+		}
+	}
+	return out
+}
+
+// enabledDiagrams resolves the generators to run: those named in order
+// first, in that order, then the rest in registry order, minus disabled.
+// Unknown or repeated IDs are an error so a typo doesn't go unnoticed.
+func enabledDiagrams(order, disabled []string) ([]diagramGen, error) {
+	byID := make(map[string]diagramGen, len(diagramRegistry))
+	for _, g := range diagramRegistry {
+		byID[g.id] = g
+	}
+	for _, id := range disabled {
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("disabled diagram %q: no such generator", id)
+		}
+	}
+
+	var out []diagramGen
+	seen := make(map[string]bool)
+	for _, id := range order {
+		g, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("diagram order %q: no such generator", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("diagram order lists %q twice", id)
+		}
+		seen[id] = true
+		if !slices.Contains(disabled, id) {
+			out = append(out, g)
+		}
+	}
+	for _, g := range diagramRegistry {
+		if !seen[g.id] && !slices.Contains(disabled, g.id) {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
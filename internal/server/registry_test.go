@@ -0,0 +1,82 @@
+package server
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestEnabledDiagramsOrderAndDisable(t *testing.T) {
+	gens, err := enabledDiagrams([]string{"nodes", "workloads"}, []string{"images"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		checker:         versions.NewChecker(time.Hour, ""),
+		imageChecker:    versions.NewImageChecker("", nil),
+		nodeChecker:     versions.NewNodeChecker(nil, 0),
+		securityChecker: versions.NewSecurityChecker(),
+		diagrams:        gens,
+	}
+
+	var ids []string
+	for _, d := range s.generateDiagrams(&model.ClusterData{PrimaryCluster: "Homelab"}) {
+		ids = append(ids, d.ID)
+	}
+	if slices.Contains(ids, "images") {
+		t.Errorf("disabled images diagram generated: %v", ids)
+	}
+	if len(ids) < 3 || ids[0] != "nodes" || ids[1] != "workloads" || ids[2] != "topology" {
+		t.Errorf("ids = %v, want nodes, workloads, then the rest from topology on", ids)
+	}
+	// The rest keep their registry order.
+	if i, j := slices.Index(ids, "charts"), slices.Index(ids, "storage"); i < 0 || j < 0 || i > j {
+		t.Errorf("charts at %d, storage at %d, want charts first: %v", i, j, ids)
+	}
+	if slices.Contains(ids[1:], "nodes") {
+		t.Errorf("nodes generated more than once: %v", ids)
+	}
+}
+
+func TestEnabledDiagramsRejectsUnknownIDs(t *testing.T) {
+	for _, tt := range []struct{ order, disabled []string }{
+		{order: []string{"imgaes"}},
+		{disabled: []string{"imgaes"}},
+		{order: []string{"nodes", "nodes"}},
+	} {
+		if _, err := enabledDiagrams(tt.order, tt.disabled); err == nil {
+			t.Errorf("enabledDiagrams(%v, %v) succeeded, want error", tt.order, tt.disabled)
+		}
+	}
+}
+
+func TestRegenerateRunsRegistryEntries(t *testing.T) {
+	calls := 0
+	gen := func(id string) diagramGen {
+		return one(id, id, func(*model.ClusterData) model.DiagramResult {
+			calls++
+			return model.DiagramResult{ID: id, Type: "markdown", Content: id}
+		})
+	}
+	s := &Server{diagrams: []diagramGen{
+		gen("nodes"),
+		gen("cluster-info"),
+		one("images", "Container Images", func(*model.ClusterData) model.DiagramResult { panic("boom") }),
+	}}
+
+	var ids []string
+	for _, d := range s.regenerate(&model.ClusterData{}, "cluster-info", "nodes", "charts") {
+		ids = append(ids, d.ID)
+	}
+	if !slices.Equal(ids, []string{"nodes", "cluster-info"}) || calls != 2 {
+		t.Errorf("regenerated %v in %d calls, want nodes and cluster-info only (charts is disabled)", ids, calls)
+	}
+
+	// Entries keep their Safe wrapper.
+	if got := s.regenerate(&model.ClusterData{}, "images"); len(got) != 1 || got[0].Error != "boom" {
+		t.Errorf("regenerate(images) = %+v, want the error placeholder", got)
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"github.com/fredericrous/cluster-vision/internal/diagram"
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/parser"
+)
+
+// WatchReload reloads the configuration on SIGHUP until ctx is done. load
+// re-reads flags, environment and config file; a config that fails to load
+// or apply is logged and the running one is kept.
+func (s *Server) WatchReload(ctx context.Context, load func() (Config, error)) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			cfg, err := load()
+			if err == nil {
+				err = s.Reload(cfg)
+			}
+			if err != nil {
+				slog.Error("config reload rejected — keeping running config", "error", err)
+				continue
+			}
+			slog.Info("config reloaded", "dataSources", len(cfg.DataSources))
+		}
+	}
+}
+
+// Reload applies cfg to the running server and triggers a refresh. Data
+// sources, the primary kubeconfig, cluster name, parser options, refresh
+// interval, stale-read refresh and threshold, image/chart checker options
+// and the diagram order and enabled set take effect; anything else (port,
+// database, static dir, scanners, ...) needs a restart and is ignored with
+// a warning. In-flight requests keep being served from the current diagrams
+// until the refresh completes.
+func (s *Server) Reload(cfg Config) error {
+	if cfg.ClusterName == "" && cfg.ClusterNameLabel == "" {
+		cfg.ClusterName = parser.DefaultClusterName
+	}
+	if cfg.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh interval must be positive, got %s", cfg.RefreshInterval)
+	}
+	// Sources read from the cluster win over the environment and config
+	// file, so a SIGHUP doesn't drop them.
+	s.mu.RLock()
+	if cfg.DataSourcesFrom != "" && cfg.DataSourcesFrom == s.cfg.DataSourcesFrom && s.clusterSources != nil {
+		cfg.DataSources = s.clusterSources
+	}
+	s.mu.RUnlock()
+	if err := validateDataSources(cfg.DataSources); err != nil {
+		return err
+	}
+
+	gens, err := enabledDiagrams(cfg.DiagramOrder, cfg.DisabledDiagrams)
+	if err != nil {
+		return err
+	}
+	minSeverity, err := diagram.ParseSeverity(cfg.WarningMinSeverity)
+	if err != nil {
+		return fmt.Errorf("warning min severity: %w", err)
+	}
+
+	parsers, err := newParsers(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if !reflect.DeepEqual(restartOnly(s.cfg), restartOnly(cfg)) {
+		slog.Warn("config reload: some changed settings only apply after a restart")
+	}
+	s.k8sParsers = parsers
+	s.cfg.Kubeconfig = cfg.Kubeconfig
+	s.cfg.ClusterName = cfg.ClusterName
+	s.cfg.DataSources = cfg.DataSources
+	s.cfg.RefreshInterval = cfg.RefreshInterval
+	s.cfg.RefreshBackoffMax = cfg.RefreshBackoffMax
+	s.cfg.RefreshIfStale = cfg.RefreshIfStale
+	s.cfg.StaleAfter = cfg.StaleAfter
+	s.cfg.WarningMinSeverity = minSeverity
+	s.cfg.IncludeTerminatedPods = cfg.IncludeTerminatedPods
+	s.cfg.TeamLabel = cfg.TeamLabel
+	s.cfg.ClusterNameLabel = cfg.ClusterNameLabel
+	s.cfg.EastWestNamespace = cfg.EastWestNamespace
+	s.cfg.EastWestLabel = cfg.EastWestLabel
+	s.cfg.NodeRoleRules = cfg.NodeRoleRules
+	s.cfg.KubeQPS = cfg.KubeQPS
+	s.cfg.KubeBurst = cfg.KubeBurst
+	s.cfg.RegistryProxy = cfg.RegistryProxy
+	s.cfg.LocalRegistry = cfg.LocalRegistry
+	s.cfg.ImageNamespaces = cfg.ImageNamespaces
+	s.cfg.DiagramOrder = cfg.DiagramOrder
+	s.cfg.DisabledDiagrams = cfg.DisabledDiagrams
+	s.diagrams = gens
+	s.mu.Unlock()
+
+	s.checker.SetRegistryProxy(cfg.RegistryProxy)
+	s.imageChecker.SetScope(cfg.LocalRegistry, cfg.ImageNamespaces)
+	s.requestRefresh()
+	return nil
+}
+
+// validateDataSources rejects data sources of an unknown type.
+func validateDataSources(sources []model.DataSource) error {
+	for _, ds := range sources {
+		switch ds.Type {
+		case "kubernetes", "tfstate", "docker-compose":
+		default:
+			return fmt.Errorf("data source %q: unknown type %q", ds.Name, ds.Type)
+		}
+	}
+	return nil
+}
+
+// restartOnly returns cfg without the settings Reload can apply.
+func restartOnly(cfg Config) Config {
+	cfg.Kubeconfig = ""
+	cfg.ClusterName = ""
+	cfg.DataSources = nil
+	cfg.RefreshInterval = 0
+	cfg.RefreshBackoffMax = 0
+	cfg.RefreshIfStale = 0
+	cfg.StaleAfter = 0
+	cfg.WarningMinSeverity = ""
+	cfg.IncludeTerminatedPods = false
+	cfg.TeamLabel = ""
+	cfg.ClusterNameLabel = ""
+	cfg.EastWestNamespace = ""
+	cfg.EastWestLabel = ""
+	cfg.NodeRoleRules = nil
+	cfg.KubeQPS = 0
+	cfg.KubeBurst = 0
+	cfg.RegistryProxy = ""
+	cfg.LocalRegistry = ""
+	cfg.ImageNamespaces = nil
+	cfg.DiagramOrder = nil
+	cfg.DisabledDiagrams = nil
+	return cfg
+}
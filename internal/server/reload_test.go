@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+const reloadKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: lab
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: lab
+  context:
+    cluster: lab
+    user: lab
+current-context: lab
+users:
+- name: lab
+  user:
+    token: test
+`
+
+func TestSIGHUPReloadsDataSources(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	configFile := filepath.Join(dir, "config.json")
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(kubeconfig, reloadKubeconfig)
+	write(configFile, `{"dataSources":[{"name":"Terraform","type":"tfstate","path":"/data/a.tfstate"}]}`)
+
+	base := Config{Kubeconfig: kubeconfig, RefreshInterval: time.Minute}
+	load := func() (Config, error) { return ApplyConfigFile(base, configFile) }
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep SIGHUP from reaching its default action (exit) before
+	// WatchReload has subscribed.
+	hold := make(chan os.Signal, 1)
+	signal.Notify(hold, syscall.SIGHUP)
+	defer signal.Stop(hold)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.WatchReload(ctx, load)
+
+	sources := func() []model.DataSource {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.cfg.DataSources
+	}
+
+	write(configFile, `{
+		"dataSources": [
+			{"name": "Terraform", "type": "tfstate", "path": "/data/b.tfstate"},
+			{"name": "NAS", "type": "docker-compose", "path": "/data/compose.yaml"}
+		],
+		"imageNamespaces": ["media"]
+	}`)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sources()) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("data sources after SIGHUP = %+v, want the reloaded pair", sources())
+		}
+		_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := sources(); got[0].Path != "/data/b.tfstate" || got[1].Name != "NAS" {
+		t.Errorf("data sources = %+v", got)
+	}
+	if s.imageChecker.InScope("kube-system") {
+		t.Error("reloaded image namespace allowlist not applied")
+	}
+	select {
+	case <-s.refreshReq:
+	default:
+		t.Error("reload did not request a refresh")
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(reloadKubeconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	running := []model.DataSource{{Name: "Terraform", Type: "tfstate", Path: "/data/a.tfstate"}}
+	s, err := New(Config{Kubeconfig: kubeconfig, RefreshInterval: time.Minute, DataSources: running})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown source type", Config{Kubeconfig: kubeconfig, RefreshInterval: time.Minute, DataSources: []model.DataSource{{Name: "x", Type: "ansible"}}}},
+		{"unreadable primary kubeconfig", Config{Kubeconfig: filepath.Join(dir, "missing"), RefreshInterval: time.Minute}},
+		{"no refresh interval", Config{Kubeconfig: kubeconfig}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Reload(tt.cfg); err == nil {
+				t.Fatal("expected Reload to reject the config")
+			}
+			if len(s.cfg.DataSources) != 1 || s.cfg.DataSources[0].Path != "/data/a.tfstate" {
+				t.Errorf("running data sources changed to %+v", s.cfg.DataSources)
+			}
+		})
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"dataSource": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyConfigFile(Config{}, bad); err == nil {
+		t.Error("expected an unknown config key to be rejected")
+	}
+}
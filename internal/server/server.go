@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"path"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/agent"
@@ -18,6 +23,7 @@ import (
 	cvmetrics "github.com/fredericrous/cluster-vision/internal/metrics"
 	"github.com/fredericrous/cluster-vision/internal/model"
 	"github.com/fredericrous/cluster-vision/internal/parser"
+	"github.com/fredericrous/cluster-vision/internal/snapshot"
 	"github.com/fredericrous/cluster-vision/internal/store"
 	"github.com/fredericrous/cluster-vision/internal/versions"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -25,12 +31,148 @@ import (
 
 // Config holds server configuration.
 type Config struct {
-	Port            int
-	Kubeconfig      string
-	ClusterName     string
-	DataSources     []model.DataSource
+	Port        int
+	Kubeconfig  string
+	ClusterName string
+	DataSources []model.DataSource
+	// DataSourcesFrom, "configmap/<namespace>/<name>" or
+	// "secret/<namespace>/<name>", reads DataSources from that object's
+	// dataSources key in the primary cluster and reloads on every change
+	// (see WatchDataSources). It replaces DataSources once read.
+	DataSourcesFrom string
 	RefreshInterval time.Duration
-	RegistryProxy   string // host:port of local OCI proxy (e.g. Zot) for upstream resolution
+	// RefreshBackoffMax caps the delay between refreshes while the primary
+	// cluster's API server is unreachable: each failed refresh doubles the
+	// delay from RefreshInterval up to this. Zero means
+	// DefaultRefreshBackoffMax; a cap at or below RefreshInterval disables
+	// the backoff.
+	RefreshBackoffMax time.Duration
+	// RefreshIfStale, when positive, makes a GET /api/diagrams whose data is
+	// older than this trigger a background refresh. The stale data is still
+	// served; the fresh data arrives on the next poll or push.
+	RefreshIfStale time.Duration
+	// WarningMinSeverity hides served warnings below this severity ("info",
+	// "warn" or "error"); empty means "warn". ?minSeverity= overrides it
+	// per request.
+	WarningMinSeverity string
+	// StaleAfter, when positive, marks the served data stale once the last
+	// successful refresh is older than this, e.g. while the API server is
+	// unreachable; responses carry the flag so the UI can say so.
+	StaleAfter time.Duration
+	// ChartCheckInterval is the minimum time between Helm chart version
+	// checks against the repositories; 0 checks on every refresh.
+	ChartCheckInterval time.Duration
+	// OutdatedGrace is how long a new upstream version must stay the latest
+	// before charts and images behind it are flagged outdated; zero flags
+	// them right away.
+	OutdatedGrace time.Duration
+	RegistryProxy string // host:port of local OCI proxy (e.g. Zot) for upstream resolution
+	// LocalRegistry is the host:port of a registry cache treated as the only
+	// source of image tags (air-gapped clusters); upstream registries are
+	// never contacted for tag listing.
+	LocalRegistry string
+	// ImageNamespaces limits the image inventory, tag checks and scans to
+	// these namespaces (names or globs); empty covers every namespace.
+	ImageNamespaces []string
+	// ImageTagCompare is what pinned image tags are compared against:
+	// "latest" (default), "major" or "minor". Floating tags like "1.2"
+	// always stay within their own series.
+	ImageTagCompare string
+	// PolicyFile is a JSON file of minimum chart and image versions (see
+	// versions.Policy); releases and images below them are flagged as policy
+	// violations. Read at startup.
+	PolicyFile string
+	// MaxTagsPerImage caps the tags listed per image or OCI chart; zero
+	// lists them all. See versions.Checker.SetMaxTags for the tradeoff.
+	MaxTagsPerImage int
+	// TagCacheSize and TagCacheTTL bound the tag listings shared by the chart
+	// and image checkers: a repo listed less than TagCacheTTL ago is not
+	// listed again. A zero TagCacheSize disables the cache; a zero
+	// TagCacheTTL defaults to the shorter checker interval, so a cached
+	// listing never outlives the check that follows it.
+	TagCacheSize int
+	TagCacheTTL  time.Duration
+	// DiagramOrder lists diagram generator IDs to run first, in this order;
+	// the rest follow in their default order. DisabledDiagrams are skipped.
+	DiagramOrder     []string
+	DisabledDiagrams []string
+	// IncludeTerminatedPods keeps Succeeded/Failed pods in the image inventory.
+	IncludeTerminatedPods bool
+	// TeamLabel is the label/annotation key naming the owning team of a
+	// namespace or workload; enables GET /api/diagrams?team=<name>.
+	TeamLabel string
+	// ClusterNameLabel is a node label naming the primary cluster when
+	// ClusterName is unset, e.g. cluster.x-k8s.io/cluster-name.
+	ClusterNameLabel string
+	// EastWestNamespace and EastWestLabel locate Istio east-west gateway
+	// Services; empty keeps istio-system and topology.istio.io/network.
+	EastWestNamespace string
+	EastWestLabel     string
+	// NodeRoleRules derive node roles from labels beyond
+	// node-role.kubernetes.io/*, e.g. for k3s or RKE2 control planes.
+	NodeRoleRules []parser.RoleRule
+	// EOLProducts maps node OS distros to endoflife.date products; those
+	// distros resolve latest version and EOL date from endoflife.date.
+	EOLProducts map[string]string
+	EOLWarnDays int // flag OS cycles reaching EOL within this many days
+	// CheckRuntimes compares node containerd/CRI-O versions against their
+	// latest GitHub release.
+	CheckRuntimes bool
+	// RequestIDHeader names the header carrying the per-request ID in access
+	// logs and responses (default X-Request-Id).
+	RequestIDHeader string
+	// MaxConcurrentRequests caps the /api requests handled at once; those
+	// beyond it get 503 (see withConcurrencyLimit). Zero is unlimited.
+	MaxConcurrentRequests int
+	// WSAllowedOrigins lists the origins, e.g. "https://grafana.example.com",
+	// whose pages may open /api/ws besides the server's own; "*" allows any.
+	// Browsers attach cookies to cross-site WebSockets, so the default is
+	// same-origin only.
+	WSAllowedOrigins []string
+	// Optional image vulnerability scanning: a Trivy server wrapper
+	// (POST /scan) and/or a directory of Trivy/Grype JSON reports.
+	TrivyServerURL string
+	VulnReportDir  string
+	// KubeQPS and KubeBurst rate-limit every Kubernetes client; zero uses
+	// the parser defaults.
+	KubeQPS   float32
+	KubeBurst int
+	// MaxLabelLength caps detail lines in Mermaid node labels (0 = default,
+	// negative = no limit).
+	MaxLabelLength int
+	// PlacementLimit lists up to this many workloads under each node in the
+	// topology diagram (0 = no placement overlay).
+	PlacementLimit int
+	// TopologyGroupLabel groups nodes in the topology diagram into one
+	// subgraph per value of this node label, e.g. a zone or rack.
+	TopologyGroupLabel string
+	// MergeMeshServiceEntries draws reciprocal cross-cluster ServiceEntries
+	// as one bidirectional node in the mesh topology.
+	MergeMeshServiceEntries bool
+	// NamespaceColumns adds label-driven boolean columns to the security
+	// matrix.
+	NamespaceColumns []model.LabelColumn
+	// IncludeSystemNamespaces maps a diagram ID to the system namespaces
+	// (e.g. "default") it shows anyway; see
+	// diagram.Options.IncludeSystemNamespaces.
+	IncludeSystemNamespaces map[string][]string
+	// BackupMaxAge is how recent a namespace's last Velero backup must be
+	// for the security matrix to count it as backed up; zero keeps the
+	// diagram.DefaultOptions value.
+	BackupMaxAge time.Duration
+	// RegistryAliases maps registry hosts, or path.Match patterns of them,
+	// to the names the images table shows; see
+	// diagram.Options.RegistryAliases.
+	RegistryAliases map[string]string
+	// StaticDir, when set, serves the built web UI from this directory
+	// alongside the API, falling back to index.html for client routes.
+	StaticDir string
+	// ExportDir, when set, receives every diagram as a file after each
+	// refresh (<id>.mmd, <id>.md or <id>.json).
+	ExportDir string
+	// SnapshotDB, when set, is replaced after each refresh with a SQLite
+	// snapshot of the cluster data, one table per resource type.
+	SnapshotDB string
 	// EAM (all optional)
 	DatabaseURL  string // enables EAM features
 	LiteLLMURL   string // enables AI enrichment
@@ -46,57 +188,124 @@ type Server struct {
 	imageChecker    *versions.ImageChecker
 	nodeChecker     *versions.NodeChecker
 	securityChecker *versions.SecurityChecker
+	vulnScanner     *versions.VulnScanner     // nil unless a scanner is configured
 	exploit         *versions.ExploitEnricher // CISA KEV + FIRST EPSS, nil tolerated
 	mu              sync.RWMutex
 	data            []model.DiagramResult
-	lastGen         time.Time
+	warnings        []model.Warning
+	lastGen         time.Time            // last successful refresh
+	lastAttempt     time.Time            // last refresh started, successful or not
+	chartChecks     versions.CheckResult // outcome of the last chart version check
+	clusterSources  []model.DataSource   // last read from DataSourcesFrom; nil until then
 	// EAM (nil when DATABASE_URL not set)
 	db          *store.DB
 	syncer      *discovery.Syncer
 	eamHandler  *eam.Handler
 	enricher    *agent.Enricher
 	clusterData *model.ClusterData // cached for EAM sync-on-demand
+	// Live updates: updates signals WebSocket clients when s.data changes;
+	// refreshReq carries client-requested refreshes to refreshLoop.
+	updates    broadcaster
+	refreshReq chan struct{}
+	refreshing atomic.Bool // a refresh is running
+	sources    sourceCache // last parse per cluster and data source
+	// after waits between refreshes; nil means time.After. Tests inject it
+	// to observe the backoff without sleeping.
+	after func(time.Duration) <-chan time.Time
+	// diagrams are the enabled generators in tab order; nil runs the
+	// whole registry.
+	diagrams []diagramGen
+	// diagramOpts configures the generators. Set once by New: the settings
+	// behind it need a restart, so refreshes read it without locking.
+	diagramOpts diagram.Options
 }
 
 // New creates a new Server.
 func New(cfg Config) (*Server, error) {
-	if cfg.ClusterName == "" {
-		cfg.ClusterName = "Homelab"
+	if cfg.ClusterName == "" && cfg.ClusterNameLabel == "" {
+		cfg.ClusterName = parser.DefaultClusterName
 	}
 
-	k8s, err := parser.NewKubernetesParser(cfg.Kubeconfig, cfg.ClusterName, "")
-	if err != nil {
-		return nil, fmt.Errorf("creating k8s parser: %w", err)
+	opts := diagram.DefaultOptions()
+	if cfg.MaxLabelLength != 0 {
+		opts.MaxLabelLength = cfg.MaxLabelLength
+	}
+	if cfg.DataSourcesFrom != "" {
+		if _, err := parseDataSourcesRef(cfg.DataSourcesFrom); err != nil {
+			return nil, err
+		}
 	}
 
-	parsers := []*parser.KubernetesParser{k8s}
-
-	for _, ds := range cfg.DataSources {
-		if ds.Type != "kubernetes" {
-			continue
+	minSeverity, err := diagram.ParseSeverity(cfg.WarningMinSeverity)
+	if err != nil {
+		return nil, fmt.Errorf("warning min severity: %w", err)
+	}
+	cfg.WarningMinSeverity = minSeverity
+
+	opts.PlacementLimit = cfg.PlacementLimit
+	opts.TopologyGroupLabel = cfg.TopologyGroupLabel
+	opts.MergeMeshServiceEntries = cfg.MergeMeshServiceEntries
+	opts.NamespaceLabelColumns = cfg.NamespaceColumns
+	if cfg.BackupMaxAge != 0 {
+		opts.BackupMaxAge = cfg.BackupMaxAge
+	}
+	for id := range cfg.IncludeSystemNamespaces {
+		if !slices.ContainsFunc(diagramRegistry, func(g diagramGen) bool { return g.id == id }) {
+			return nil, fmt.Errorf("include system namespaces for %q: no such generator", id)
 		}
-		if _, err := os.Stat(ds.Path); err != nil {
-			slog.Warn("skipping kubernetes data source: kubeconfig not readable", "name", ds.Name, "path", ds.Path, "error", err)
-			continue
+	}
+	opts.IncludeSystemNamespaces = cfg.IncludeSystemNamespaces
+	for pattern := range cfg.RegistryAliases {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("registry alias %q: %w", pattern, err)
 		}
-		p, err := parser.NewKubernetesParser(ds.Path, ds.Name, ds.Platform)
+	}
+	opts.RegistryAliases = cfg.RegistryAliases
+	if cfg.PolicyFile != "" {
+		policy, err := versions.LoadPolicy(cfg.PolicyFile)
 		if err != nil {
-			slog.Warn("skipping kubernetes data source: failed to create parser", "name", ds.Name, "error", err)
-			continue
+			return nil, err
 		}
-		parsers = append(parsers, p)
-		slog.Info("added kubernetes data source", "name", ds.Name)
+		opts.VersionPolicy = policy
+	}
+
+	gens, err := enabledDiagrams(cfg.DiagramOrder, cfg.DisabledDiagrams)
+	if err != nil {
+		return nil, err
 	}
 
-	checker := versions.NewChecker(cfg.RefreshInterval, cfg.RegistryProxy)
-	imageChecker := versions.NewImageChecker()
-	nodeChecker := versions.NewNodeChecker()
+	parsers, err := newParsers(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := versions.NewChecker(cfg.ChartCheckInterval, cfg.RegistryProxy)
+	imageChecker := versions.NewImageChecker(cfg.LocalRegistry, cfg.ImageNamespaces)
+	tagCompare, err := versions.ParseTagCompare(cfg.ImageTagCompare)
+	if err != nil {
+		return nil, err
+	}
+	imageChecker.SetTagCompare(tagCompare)
+	checker.SetGrace(cfg.OutdatedGrace)
+	checker.SetMaxTags(cfg.MaxTagsPerImage)
+	imageChecker.SetMaxTags(cfg.MaxTagsPerImage)
+	tagCache := versions.NewTagCache(cfg.TagCacheSize, tagCacheTTL(cfg))
+	checker.SetTagCache(tagCache)
+	imageChecker.SetTagCache(tagCache)
+	imageChecker.SetGrace(cfg.OutdatedGrace)
+	nodeChecker := versions.NewNodeChecker(cfg.EOLProducts, time.Duration(cfg.EOLWarnDays)*24*time.Hour)
+	nodeChecker.SetRuntimeCheck(cfg.CheckRuntimes)
 	securityChecker := versions.NewSecurityChecker()
 	// ExploitEnricher works in-memory if db is nil; it's wired with the
 	// db (if any) below after DB connect.
 	exploitEnricher := versions.NewExploitEnricher(nil)
 
-	s := &Server{cfg: cfg, k8sParsers: parsers, checker: checker, imageChecker: imageChecker, nodeChecker: nodeChecker, securityChecker: securityChecker, exploit: exploitEnricher}
+	s := &Server{cfg: cfg, k8sParsers: parsers, checker: checker, imageChecker: imageChecker, nodeChecker: nodeChecker, securityChecker: securityChecker, exploit: exploitEnricher, refreshReq: make(chan struct{}, 1), diagrams: gens, diagramOpts: opts}
+
+	if cfg.TrivyServerURL != "" || cfg.VulnReportDir != "" {
+		s.vulnScanner = versions.NewVulnScanner(cfg.TrivyServerURL, cfg.VulnReportDir)
+		slog.Info("image vulnerability scanning enabled", "server", cfg.TrivyServerURL, "reportDir", cfg.VulnReportDir)
+	}
 
 	// Optional EAM database
 	if cfg.DatabaseURL != "" {
@@ -124,6 +333,110 @@ func New(cfg Config) (*Server, error) {
 	return s, nil
 }
 
+// newParsers creates the default Kubernetes parser and one per kubernetes
+// data source. The default parser is the primary cluster unless a source is
+// marked Primary. Sources whose kubeconfig can't be used are skipped with a
+// warning; only a broken primary, several primaries, or an invalid source
+// interval is an error.
+func newParsers(cfg Config) ([]*parser.KubernetesParser, error) {
+	var primary string
+	for _, ds := range cfg.DataSources {
+		if !ds.Primary {
+			continue
+		}
+		if ds.Type != "kubernetes" {
+			return nil, fmt.Errorf("data source %q: only kubernetes sources can be primary", ds.Name)
+		}
+		if primary != "" {
+			return nil, fmt.Errorf("data sources %q and %q are both marked primary", primary, ds.Name)
+		}
+		primary = ds.Name
+	}
+
+	parseOpts := parser.Options{
+		IncludeTerminatedPods: cfg.IncludeTerminatedPods,
+		TeamLabel:             cfg.TeamLabel,
+		ClusterNameLabel:      cfg.ClusterNameLabel,
+		EastWestNamespace:     cfg.EastWestNamespace,
+		EastWestLabel:         cfg.EastWestLabel,
+		RoleRules:             cfg.NodeRoleRules,
+		QPS:                   cfg.KubeQPS,
+		Burst:                 cfg.KubeBurst,
+	}
+
+	defaultOpts := parseOpts
+	defaultOpts.Primary = primary == ""
+	k8s, err := parser.NewKubernetesParser(cfg.Kubeconfig, cfg.ClusterName, "", defaultOpts)
+	if err != nil {
+		return nil, fmt.Errorf("creating k8s parser: %w", err)
+	}
+
+	parsers := []*parser.KubernetesParser{k8s}
+
+	for _, ds := range cfg.DataSources {
+		interval, err := ds.RefreshInterval()
+		if err != nil {
+			return nil, err
+		}
+		if ds.Type != "kubernetes" {
+			continue
+		}
+		if _, err := os.Stat(ds.Path); err != nil {
+			if ds.Primary {
+				return nil, fmt.Errorf("primary data source %q: %w", ds.Name, err)
+			}
+			slog.Warn("skipping kubernetes data source: kubeconfig not readable", "name", ds.Name, "path", ds.Path, "error", err)
+			continue
+		}
+		opts := parseOpts
+		opts.ExecEnv = ds.ExecEnv
+		opts.Context = ds.Context
+		opts.RefreshInterval = interval
+		opts.Primary = ds.Primary
+		p, err := parser.NewKubernetesParser(ds.Path, ds.Name, ds.Platform, opts)
+		if err != nil {
+			if ds.Primary {
+				return nil, fmt.Errorf("creating parser for primary data source %q: %w", ds.Name, err)
+			}
+			slog.Warn("skipping kubernetes data source: failed to create parser", "name", ds.Name, "error", err)
+			continue
+		}
+		parsers = append(parsers, p)
+		slog.Info("added kubernetes data source", "name", ds.Name, "context", ds.Context)
+	}
+	return parsers, nil
+}
+
+// primaryParser returns the parser marked primary (see newParsers), or the
+// first one if none is.
+func primaryParser(parsers []*parser.KubernetesParser) *parser.KubernetesParser {
+	for _, p := range parsers {
+		if p.Primary() {
+			return p
+		}
+	}
+	return parsers[0]
+}
+
+// tagCacheTTL returns cfg.TagCacheTTL or, when zero, the shorter of the
+// image and chart check intervals (charts checked every refresh count the
+// refresh interval), so a scheduled check never reuses a listing from
+// before the previous one.
+func tagCacheTTL(cfg Config) time.Duration {
+	if cfg.TagCacheTTL > 0 {
+		return cfg.TagCacheTTL
+	}
+	ttl := versions.ImageCheckInterval
+	chart := cfg.ChartCheckInterval
+	if chart <= 0 {
+		chart = cfg.RefreshInterval
+	}
+	if chart > 0 && chart < ttl {
+		ttl = chart
+	}
+	return ttl
+}
+
 // Start begins serving HTTP and starts the background refresh loop.
 func (s *Server) Start(ctx context.Context) error {
 	// Warm the KEV/EPSS cache from the persisted table so the first
@@ -133,18 +446,55 @@ func (s *Server) Start(ctx context.Context) error {
 		slog.Warn("exploit enrichment LoadFromDB failed — first refresh will run with empty cache", "error", err)
 	}
 
-	// Initial generation
-	s.refresh(ctx)
+	// Build routes first so a bad StaticDir fails fast.
+	mux, err := s.routes()
+	if err != nil {
+		return err
+	}
+
+	// Initial generation; a failure is logged and retried by refreshLoop.
+	_ = s.refresh(ctx)
 
 	// Background refresh
 	go s.refreshLoop(ctx)
 	go s.exploitEnrichmentLoop(ctx)
+	go s.registryProxyLoop(ctx)
+
+	addr := fmt.Sprintf(":%d", s.cfg.Port)
+	slog.Info("starting server", "addr", addr, "refresh", s.cfg.RefreshInterval, "dataSources", len(s.cfg.DataSources))
 
+	handler := withConcurrencyLimit(s.cfg.MaxConcurrentRequests, withCORS(mux))
+	srv := &http.Server{Addr: addr, Handler: withAccessLog(s.cfg.RequestIDHeader, handler)}
+
+	go func() {
+		<-ctx.Done()
+		if s.db != nil {
+			s.db.Close()
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	return srv.ListenAndServe()
+}
+
+// routes builds the HTTP mux: the /api routes, /metrics, and, when StaticDir
+// is set, the web UI on every other path. Every /api route is also served
+// under /api/v1 (see withAPIVersion).
+func (s *Server) routes() (*http.ServeMux, error) {
 	mux := http.NewServeMux()
+	mux.Handle("/api/"+apiVersion+"/", withAPIVersion(mux))
 	mux.HandleFunc("GET /api/diagrams", s.handleDiagrams)
+	mux.HandleFunc("GET /api/diagrams/events", s.handleEvents)
+	mux.HandleFunc("GET /api/diagrams/{id}/raw", s.handleDiagramRaw)
+	mux.HandleFunc("GET /api/export/{file}", s.handleExport)
+	mux.HandleFunc("GET /api/ws", s.handleWS)
 	mux.HandleFunc("GET /api/health", s.handleHealth)
 	mux.HandleFunc("GET /api/health/live", s.handleHealthLive)
 	mux.HandleFunc("GET /api/config", s.handleConfig)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("POST /api/version-check/{type}", s.handleVersionCheck)
 	// Prometheus scrape endpoint — no auth (cluster-internal only via the
 	// new `api` Service port; not on the public Gateway).
 	mux.Handle("GET /metrics", promhttp.Handler())
@@ -161,38 +511,65 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}
 
-	addr := fmt.Sprintf(":%d", s.cfg.Port)
-	slog.Info("starting server", "addr", addr, "refresh", s.cfg.RefreshInterval, "dataSources", len(s.cfg.DataSources))
-
-	srv := &http.Server{Addr: addr, Handler: withCORS(mux)}
-
-	go func() {
-		<-ctx.Done()
-		if s.db != nil {
-			s.db.Close()
+	if s.cfg.StaticDir != "" {
+		spa, err := spaHandler(s.cfg.StaticDir)
+		if err != nil {
+			return nil, fmt.Errorf("static dir %s: %w", s.cfg.StaticDir, err)
 		}
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		_ = srv.Shutdown(shutdownCtx)
-	}()
-
-	return srv.ListenAndServe()
+		mux.Handle("/", spa)
+	}
+	return mux, nil
 }
 
 func (s *Server) refreshLoop(ctx context.Context) {
-	ticker := time.NewTicker(s.cfg.RefreshInterval)
-	defer ticker.Stop()
+	after := s.after
+	if after == nil {
+		after = time.After
+	}
+	var failures int
+	d := s.refreshDelay(failures)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			s.refresh(ctx)
+		case <-after(d):
+		case <-s.refreshReq:
+		}
+		if err := s.refresh(ctx); err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+		// Read every time: a reload may have changed the interval.
+		d = s.refreshDelay(failures)
+		if failures > 0 {
+			slog.Warn("refresh failed — backing off", "failures", failures, "retryIn", d)
 		}
 	}
 }
 
+// DefaultRefreshBackoffMax caps the refresh backoff when
+// Config.RefreshBackoffMax is unset.
+const DefaultRefreshBackoffMax = 30 * time.Minute
+
+// refreshDelay returns how long to wait before the next refresh after
+// failures consecutive failed ones: the refresh interval, doubled per
+// failure up to the backoff cap.
+func (s *Server) refreshDelay(failures int) time.Duration {
+	s.mu.RLock()
+	interval, ceiling := s.cfg.RefreshInterval, s.cfg.RefreshBackoffMax
+	s.mu.RUnlock()
+	if ceiling <= 0 {
+		ceiling = DefaultRefreshBackoffMax
+	}
+	d := interval
+	for i := 0; i < failures && d < ceiling; i++ {
+		d *= 2
+	}
+	return max(interval, min(d, ceiling))
+}
+
 // exploitEnrichmentLoop refreshes the KEV/EPSS cache once a day. Runs
 // once at startup (kicked off here, not in Start, to keep boot fast),
 // then every 24 hours. Failures are logged; the previous cache stays
@@ -238,7 +615,7 @@ func (s *Server) enrichImageVulns(vulns []model.ImageVuln) {
 	for i := range vulns {
 		v := &vulns[i]
 		v.KEVCount = 0
-		v.KEVCVEs = v.KEVCVEs[:0]
+		v.KEVCVEs = nil // the backing array may be shared with a cached parse
 		v.MaxEPSS = 0
 		v.MaxEPSSCVE = ""
 		for _, cve := range v.CVEs {
@@ -255,45 +632,59 @@ func (s *Server) enrichImageVulns(vulns []model.ImageVuln) {
 	}
 }
 
-func (s *Server) refresh(ctx context.Context) {
+// primaryPingTimeout bounds the reachability check of the primary cluster at
+// the start of each refresh.
+const primaryPingTimeout = 10 * time.Second
+
+// refresh parses every cluster and data source and regenerates the
+// diagrams. When the primary cluster's API server can't be reached at all,
+// its previous data is kept while the other clusters and sources are still
+// refreshed, and refresh fails so that refreshLoop backs off and the data
+// ages towards stale.
+func (s *Server) refresh(ctx context.Context) error {
+	// A panic anywhere in parsing or generation must not take the API
+	// down. s.data is only swapped at the end, so recovering here keeps
+	// serving the last good snapshot and refreshLoop keeps ticking.
+	defer recoverRefresh("refresh")
+
+	s.refreshing.Store(true)
+	defer s.refreshing.Store(false)
+
+	s.mu.Lock()
+	s.lastAttempt = time.Now()
+	s.mu.Unlock()
+
 	slog.Info("refreshing cluster data")
 	start := time.Now()
 
-	// All Kubernetes clusters get the same parsing treatment.
-	// The first parser remains the primary cluster for UI semantics.
-	clusterData := s.k8sParsers[0].ParseAll(ctx)
-	clusterData.PrimaryCluster = s.cfg.ClusterName
-
-	// Merge full data from secondary clusters.
-	for _, p := range s.k8sParsers[1:] {
-		secondary := p.ParseAll(ctx)
-		clusterData.Nodes = append(clusterData.Nodes, secondary.Nodes...)
-		clusterData.Flux = append(clusterData.Flux, secondary.Flux...)
-		clusterData.Gateways = append(clusterData.Gateways, secondary.Gateways...)
-		clusterData.HTTPRoutes = append(clusterData.HTTPRoutes, secondary.HTTPRoutes...)
-		clusterData.Namespaces = append(clusterData.Namespaces, secondary.Namespaces...)
-		clusterData.SecurityPolicies = append(clusterData.SecurityPolicies, secondary.SecurityPolicies...)
-		clusterData.ClientTrafficPolicies = append(clusterData.ClientTrafficPolicies, secondary.ClientTrafficPolicies...)
-		clusterData.InfraSources = append(clusterData.InfraSources, secondary.InfraSources...)
-		clusterData.ServiceEntries = append(clusterData.ServiceEntries, secondary.ServiceEntries...)
-		clusterData.EastWestGateways = append(clusterData.EastWestGateways, secondary.EastWestGateways...)
-		clusterData.LoadBalancers = append(clusterData.LoadBalancers, secondary.LoadBalancers...)
-		clusterData.HelmReleases = append(clusterData.HelmReleases, secondary.HelmReleases...)
-		clusterData.HelmRepositories = append(clusterData.HelmRepositories, secondary.HelmRepositories...)
-		clusterData.Pods = append(clusterData.Pods, secondary.Pods...)
-		clusterData.Workloads = append(clusterData.Workloads, secondary.Workloads...)
-		clusterData.Storage = append(clusterData.Storage, secondary.Storage...)
-		clusterData.CRDs = append(clusterData.CRDs, secondary.CRDs...)
-		clusterData.Quotas = append(clusterData.Quotas, secondary.Quotas...)
-		clusterData.Certificates = append(clusterData.Certificates, secondary.Certificates...)
-		clusterData.NetworkPolicies = append(clusterData.NetworkPolicies, secondary.NetworkPolicies...)
-		clusterData.Configs = append(clusterData.Configs, secondary.Configs...)
-		clusterData.Services = append(clusterData.Services, secondary.Services...)
-		clusterData.RBACBindings = append(clusterData.RBACBindings, secondary.RBACBindings...)
-		clusterData.VeleroSchedules = append(clusterData.VeleroSchedules, secondary.VeleroSchedules...)
-		clusterData.ImageVulns = append(clusterData.ImageVulns, secondary.ImageVulns...)
+	// Parsers and sources may be swapped by Reload; work on a snapshot.
+	s.mu.RLock()
+	parsers, dataSources := s.k8sParsers, s.cfg.DataSources
+	s.mu.RUnlock()
+
+	primary := primaryParser(parsers)
+	pingCtx, cancel := context.WithTimeout(ctx, primaryPingTimeout)
+	pingErr := primary.Ping(pingCtx)
+	cancel()
+	if pingErr != nil {
+		slog.Warn("primary cluster unreachable — keeping its previous data", "cluster", primary.ClusterName(), "error", pingErr)
 	}
 
+	// All Kubernetes clusters get the same parsing treatment; clusters with
+	// their own interval are served from the source cache until due.
+	// The parser marked primary names the primary cluster for UI semantics.
+	clusterData := &model.ClusterData{}
+	for _, p := range parsers {
+		if p == primary && pingErr != nil {
+			if cd := s.sources.lastClusterData(p); cd != nil {
+				mergeClusterData(clusterData, cd)
+			}
+			continue
+		}
+		mergeClusterData(clusterData, s.sources.clusterData(ctx, p, start))
+	}
+	clusterData.PrimaryCluster = primary.ClusterName()
+
 	// Sort namespaces and security policies deterministically
 	sort.Slice(clusterData.Namespaces, func(i, j int) bool {
 		if clusterData.Namespaces[i].Cluster != clusterData.Namespaces[j].Cluster {
@@ -312,11 +703,11 @@ func (s *Server) refresh(ctx context.Context) {
 	})
 
 	// Resolve each infra data source (tfstate, docker-compose)
-	for _, ds := range s.cfg.DataSources {
+	for _, ds := range dataSources {
 		if ds.Type == "kubernetes" {
 			continue
 		}
-		src, err := resolveDataSource(ds)
+		src, err := s.sources.infraSource(ds, start)
 		if err != nil {
 			slog.Warn("failed to resolve data source", "name", ds.Name, "error", err)
 			continue
@@ -325,6 +716,7 @@ func (s *Server) refresh(ctx context.Context) {
 			clusterData.InfraSources = append(clusterData.InfraSources, *src)
 		}
 	}
+	s.sources.prune()
 
 	// Cross-reference each image's CVEs with the cached KEV/EPSS data.
 	// Pure in-memory map lookups — sub-millisecond even with thousands
@@ -335,42 +727,40 @@ func (s *Server) refresh(ctx context.Context) {
 	// joining ImageVulns × Pods. Reset between refreshes inside the call.
 	cvmetrics.EmitImageVulnMetrics(clusterData.Pods, clusterData.ImageVulns)
 
-	diagrams := diagram.GenerateTopologySections(clusterData)
-	diagrams = append(diagrams,
-		diagram.GenerateDependencies(clusterData),
-		diagram.GenerateNetwork(clusterData),
-	)
-	diagrams = append(diagrams, diagram.GenerateSecurity(clusterData)...)
-	diagrams = append(diagrams, diagram.GenerateImages(clusterData, s.imageChecker))
-	diagrams = append(diagrams, diagram.GenerateVersions(clusterData, s.checker))
-	diagrams = append(diagrams, diagram.GenerateNodes(clusterData, s.nodeChecker, s.securityChecker))
-	diagrams = append(diagrams,
-		diagram.GenerateWorkloads(clusterData),
-		diagram.GenerateStorage(clusterData),
-		diagram.GenerateCRDs(clusterData),
-		diagram.GenerateQuotas(clusterData),
-		diagram.GenerateCertificates(clusterData),
-		diagram.GenerateNetworkPolicies(clusterData),
-		diagram.GenerateConfigs(clusterData),
-		diagram.GenerateHelmWorkloads(clusterData),
-		diagram.GenerateServiceMap(clusterData),
-		diagram.GenerateNamespaceSummary(clusterData),
-		diagram.GenerateRBAC(clusterData),
-		diagram.GenerateLabels(clusterData),
-		diagram.GenerateVelero(clusterData),
-	)
+	diagrams := s.generateDiagrams(clusterData)
+	warnings := s.withProxyWarning(diagram.CollectWarnings(clusterData))
 
 	s.mu.Lock()
 	s.data = diagrams
-	s.lastGen = time.Now()
+	s.warnings = warnings
+	if pingErr == nil {
+		s.lastGen = time.Now()
+	}
 	s.clusterData = clusterData
 	s.mu.Unlock()
+	s.updates.notify()
+
+	if s.cfg.ExportDir != "" {
+		n, err := exportDiagrams(s.cfg.ExportDir, diagrams)
+		if err != nil {
+			slog.Warn("diagram export incomplete", "dir", s.cfg.ExportDir, "error", err)
+		}
+		slog.Debug("diagrams exported", "dir", s.cfg.ExportDir, "written", n)
+	}
+	if s.cfg.SnapshotDB != "" {
+		if rows, err := snapshot.Write(s.cfg.SnapshotDB, clusterData, start); err != nil {
+			slog.Warn("sqlite snapshot failed", "path", s.cfg.SnapshotDB, "error", err)
+		} else {
+			slog.Debug("sqlite snapshot written", "path", s.cfg.SnapshotDB, "tables", len(rows))
+		}
+	}
 
 	slog.Info("refresh complete", "duration", time.Since(start))
 
 	// Run EAM discovery sync asynchronously, then AI enrichment for new apps
 	if s.syncer != nil {
 		go func() {
+			defer recoverRefresh("eam-sync")
 			result := s.syncer.Sync(ctx, clusterData)
 			if s.enricher != nil && result.AppsCreated > 0 {
 				if err := s.enricher.EnrichNew(ctx); err != nil {
@@ -382,65 +772,142 @@ func (s *Server) refresh(ctx context.Context) {
 
 	// Check latest versions asynchronously — updates arrive on next page load
 	go func() {
-		s.checker.Check(clusterData.HelmRepositories, clusterData.HelmReleases)
-
-		// Regenerate versions diagram with updated latest versions
-		versionsResult := diagram.GenerateVersions(clusterData, s.checker)
-		s.mu.Lock()
-		for i, d := range s.data {
-			if d.ID == "charts" {
-				s.data[i] = versionsResult
-				break
-			}
+		defer recoverRefresh("chart-versions")
+		if res := s.checker.Check(clusterData.HelmRepositories, clusterData.HelmReleases); !res.At.IsZero() {
+			s.mu.Lock()
+			s.chartChecks = res
+			s.mu.Unlock()
 		}
-		s.mu.Unlock()
+
+		// Regenerate versions diagrams with updated latest versions
+		s.replaceDiagram(s.regenerate(clusterData, "charts")...)
 	}()
 
 	// Check latest image tags asynchronously
 	go func() {
+		defer recoverRefresh("image-versions")
 		s.imageChecker.Check(clusterData.Pods)
-
-		imagesResult := diagram.GenerateImages(clusterData, s.imageChecker)
-		s.mu.Lock()
-		for i, d := range s.data {
-			if d.ID == "images" {
-				s.data[i] = imagesResult
-				break
-			}
-		}
-		s.mu.Unlock()
+		s.replaceDiagram(s.regenerate(clusterData, "images")...)
 	}()
 
+	// Scan images for vulnerabilities asynchronously (opt-in)
+	if s.vulnScanner != nil {
+		go func() {
+			defer recoverRefresh("image-vulns")
+			s.vulnScanner.Check(s.imageChecker.ScopePods(clusterData.Pods))
+			s.replaceDiagram(s.regenerate(clusterData, "images")...)
+		}()
+	}
+
 	// Check latest node OS/kubelet versions asynchronously
 	go func() {
-		s.nodeChecker.Check(clusterData.Nodes)
-
-		nodesResult := diagram.GenerateNodes(clusterData, s.nodeChecker, s.securityChecker)
-		s.mu.Lock()
-		for i, d := range s.data {
-			if d.ID == "nodes" {
-				s.data[i] = nodesResult
-				break
-			}
-		}
-		s.mu.Unlock()
+		defer recoverRefresh("node-versions")
+		s.nodeChecker.Check(clusterData.Nodes, clusterData.APIServers)
+		s.replaceDiagram(s.regenerate(clusterData, "nodes", "cluster-info")...)
 	}()
 
 	// Check node security vulnerabilities via OSV.dev asynchronously
 	go func() {
+		defer recoverRefresh("node-security")
 		queries := versions.NodeSecurityQueries(clusterData.Nodes)
 		s.securityChecker.Check(queries)
-
-		nodesResult := diagram.GenerateNodes(clusterData, s.nodeChecker, s.securityChecker)
-		s.mu.Lock()
-		for i, d := range s.data {
-			if d.ID == "nodes" {
-				s.data[i] = nodesResult
-				break
-			}
-		}
-		s.mu.Unlock()
+		s.replaceDiagram(s.regenerate(clusterData, "nodes")...)
 	}()
+
+	if pingErr != nil {
+		return fmt.Errorf("primary cluster %s unreachable: %w", primary.ClusterName(), pingErr)
+	}
+	return nil
+}
+
+// replaceDiagram swaps in regenerated diagrams with the same IDs and notifies
+// live clients.
+func (s *Server) replaceDiagram(results ...model.DiagramResult) {
+	s.mu.Lock()
+	s.data = withDiagrams(s.data, results)
+	s.mu.Unlock()
+	s.updates.notify()
+}
+
+// requestRefresh asks the refresh loop for an immediate refresh. Requests
+// made while one is already queued are coalesced.
+func (s *Server) requestRefresh() {
+	select {
+	case s.refreshReq <- struct{}{}:
+	default:
+	}
+}
+
+// recoverRefresh logs and counts a panic from a refresh stage instead of
+// letting it crash the process. Must be called directly via defer.
+func recoverRefresh(stage string) {
+	if r := recover(); r != nil {
+		cvmetrics.RefreshPanics.WithLabelValues(stage).Inc()
+		slog.Error("refresh panicked — keeping previous data", "stage", stage, "panic", r, "stack", string(debug.Stack()))
+	}
+}
+
+// generateDiagrams runs the enabled generators, in the configured order,
+// against clusterData. Each one is
+// wrapped with diagram.Safe so a panic degrades that diagram to an error
+// card while the rest still render.
+func (s *Server) generateDiagrams(clusterData *model.ClusterData) []model.DiagramResult {
+	s.mu.RLock()
+	gens := s.diagrams
+	s.mu.RUnlock()
+	if gens == nil {
+		gens = diagramRegistry
+	}
+
+	var diagrams []model.DiagramResult
+	for _, g := range gens {
+		diagrams = append(diagrams, g.run(s, clusterData)...)
+	}
+	for i := range diagrams {
+		diagrams[i].Hash = contentHash(diagrams[i].Content)
+	}
+	return diagrams
+}
+
+// mergeClusterData appends everything in src to dst.
+func mergeClusterData(dst, src *model.ClusterData) {
+	dst.Nodes = append(dst.Nodes, src.Nodes...)
+	dst.APIServers = append(dst.APIServers, src.APIServers...)
+	dst.Flux = append(dst.Flux, src.Flux...)
+	dst.FluxSources = append(dst.FluxSources, src.FluxSources...)
+	dst.ArgoApps = append(dst.ArgoApps, src.ArgoApps...)
+	dst.CAPIClusters = append(dst.CAPIClusters, src.CAPIClusters...)
+	dst.Gateways = append(dst.Gateways, src.Gateways...)
+	dst.HTTPRoutes = append(dst.HTTPRoutes, src.HTTPRoutes...)
+	dst.IngressRoutes = append(dst.IngressRoutes, src.IngressRoutes...)
+	dst.Ingresses = append(dst.Ingresses, src.Ingresses...)
+	dst.ReferenceGrants = append(dst.ReferenceGrants, src.ReferenceGrants...)
+	dst.Namespaces = append(dst.Namespaces, src.Namespaces...)
+	dst.SecurityPolicies = append(dst.SecurityPolicies, src.SecurityPolicies...)
+	dst.ClientTrafficPolicies = append(dst.ClientTrafficPolicies, src.ClientTrafficPolicies...)
+	dst.InfraSources = append(dst.InfraSources, src.InfraSources...)
+	dst.ServiceEntries = append(dst.ServiceEntries, src.ServiceEntries...)
+	dst.EastWestGateways = append(dst.EastWestGateways, src.EastWestGateways...)
+	dst.LoadBalancers = append(dst.LoadBalancers, src.LoadBalancers...)
+	dst.HelmReleases = append(dst.HelmReleases, src.HelmReleases...)
+	dst.HelmRepositories = append(dst.HelmRepositories, src.HelmRepositories...)
+	dst.Pods = append(dst.Pods, src.Pods...)
+	dst.Workloads = append(dst.Workloads, src.Workloads...)
+	dst.Autoscalers = append(dst.Autoscalers, src.Autoscalers...)
+	dst.Storage = append(dst.Storage, src.Storage...)
+	dst.CRDs = append(dst.CRDs, src.CRDs...)
+	dst.Capabilities = append(dst.Capabilities, src.Capabilities...)
+	dst.Quotas = append(dst.Quotas, src.Quotas...)
+	dst.Certificates = append(dst.Certificates, src.Certificates...)
+	dst.NetworkPolicies = append(dst.NetworkPolicies, src.NetworkPolicies...)
+	dst.Configs = append(dst.Configs, src.Configs...)
+	dst.Services = append(dst.Services, src.Services...)
+	dst.RBACBindings = append(dst.RBACBindings, src.RBACBindings...)
+	dst.VeleroSchedules = append(dst.VeleroSchedules, src.VeleroSchedules...)
+	dst.VeleroBackups = append(dst.VeleroBackups, src.VeleroBackups...)
+	dst.Events = append(dst.Events, src.Events...)
+	dst.DeprecatedAPIs = append(dst.DeprecatedAPIs, src.DeprecatedAPIs...)
+	dst.ImageVulns = append(dst.ImageVulns, src.ImageVulns...)
 }
 
 // resolveDataSource fetches and parses a single data source.
@@ -461,12 +928,14 @@ func resolveDataSource(ds model.DataSource) (*model.InfraSource, error) {
 	switch ds.Type {
 	case "tfstate":
 		nodes := parser.ParseTerraformStateBytes(data)
-		if len(nodes) == 0 {
+		outputs := parser.ParseTerraformOutputsBytes(data)
+		if len(nodes) == 0 && len(outputs) == 0 {
 			return nil, nil
 		}
 		src.TerraformNodes = nodes
+		src.TerraformOutputs = outputs
 	case "docker-compose":
-		dc, err := parser.ParseDockerCompose(data)
+		dc, err := parser.ParseDockerCompose(data, ds.Profiles)
 		if err != nil {
 			return nil, fmt.Errorf("parsing docker-compose: %w", err)
 		}
@@ -489,29 +958,155 @@ func fetchSourceData(ds model.DataSource) ([]byte, error) {
 	return os.ReadFile(ds.Path)
 }
 
+// diagramsPayload is the body of GET /api/v1/diagrams and of every update
+// pushed over /api/v1/ws. APIVersion names the response shape; fields may be
+// added within a version, anything else lands under a new one.
+type diagramsPayload struct {
+	APIVersion  string                `json:"apiVersion"`
+	Diagrams    []model.DiagramResult `json:"diagrams"`
+	Warnings    []model.Warning       `json:"warnings,omitempty"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	// Stale is set once GeneratedAt is older than Config.StaleAfter;
+	// AgeSeconds is its age, zero before the first refresh.
+	Stale      bool  `json:"stale"`
+	AgeSeconds int64 `json:"ageSeconds"`
+}
+
+// staleness returns how old data generated at generatedAt is, and whether
+// that exceeds staleAfter. Nothing is stale before the first refresh or
+// with staleAfter unset.
+func staleness(generatedAt time.Time, staleAfter time.Duration, now time.Time) (stale bool, ageSeconds int64) {
+	if generatedAt.IsZero() {
+		return false, 0
+	}
+	age := now.Sub(generatedAt)
+	return staleAfter > 0 && age > staleAfter, int64(age / time.Second)
+}
+
 func (s *Server) handleDiagrams(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	diagrams, warnings, generatedAt, clusterData := s.data, s.warnings, s.lastGen, s.clusterData
+	refreshIfStale, staleAfter := s.cfg.RefreshIfStale, s.cfg.StaleAfter
+	minSeverity := s.cfg.WarningMinSeverity
+	s.mu.RUnlock()
 
-	resp := struct {
-		Diagrams    []model.DiagramResult `json:"diagrams"`
-		GeneratedAt time.Time             `json:"generated_at"`
-	}{
-		Diagrams:    s.data,
-		GeneratedAt: s.lastGen,
+	if v := r.URL.Query().Get("minSeverity"); v != "" {
+		sev, err := diagram.ParseSeverity(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+		minSeverity = sev
+	}
+
+	// After a long idle spell, answer now and refresh in the background.
+	// Before the first refresh, and while one runs, there is nothing to do.
+	if refreshIfStale > 0 && !generatedAt.IsZero() && time.Since(generatedAt) > refreshIfStale && !s.refreshing.Load() {
+		slog.Debug("diagrams stale on read — scheduling refresh", "age", time.Since(generatedAt).Round(time.Second))
+		s.requestRefresh()
+	}
+
+	// ?team=payments regenerates every diagram against that team's
+	// namespaces only, and ?fresh=1 the version diagrams against what the
+	// checkers know now rather than at the last refresh. Before the first
+	// refresh there is nothing to regenerate.
+	if team := r.URL.Query().Get("team"); team != "" && clusterData != nil {
+		filtered := filterClusterData(clusterData, team)
+		diagrams = s.generateDiagrams(filtered)
+		warnings = s.withProxyWarning(diagram.CollectWarnings(filtered))
+	} else if r.URL.Query().Get("fresh") == "1" && clusterData != nil {
+		diagrams = s.regenerateLive(diagrams, clusterData)
 	}
 
+	resp := diagramsPayload{
+		APIVersion:  apiVersion,
+		Diagrams:    diagrams,
+		Warnings:    diagram.FilterWarnings(warnings, minSeverity),
+		GeneratedAt: generatedAt,
+	}
+	resp.Stale, resp.AgeSeconds = staleness(generatedAt, staleAfter, time.Now())
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// handleEvents serves the Warning events diagram for a chosen window:
+// ?since=30m (any Go duration, default diagram.DefaultEventsWindow) and,
+// like /api/diagrams, an optional ?team= filter.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	since := diagram.DefaultEventsWindow
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf(`{"error":"invalid since %q: want a positive duration such as 30m"}`, v), http.StatusBadRequest)
+			return
+		}
+		since = d
+	}
+
+	s.mu.RLock()
+	cd := s.clusterData
+	s.mu.RUnlock()
+
+	if cd == nil {
+		http.Error(w, `{"error":"no cluster data available yet"}`, http.StatusServiceUnavailable)
+		return
+	}
+	if team := r.URL.Query().Get("team"); team != "" {
+		cd = filterClusterData(cd, team)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(diagram.GenerateEvents(cd, since, time.Now()))
+}
+
+// rawContentTypes maps a diagram type to the Content-Type of its bare
+// Content; types missing here have no raw form.
+var rawContentTypes = map[string]string{
+	"mermaid":  "text/plain; charset=utf-8",
+	"markdown": "text/plain; charset=utf-8",
+	"table":    "application/json",
+	"flow":     "application/json",
+}
+
+// handleDiagramRaw serves one diagram's Content without the DiagramResult
+// envelope, e.g. for `curl …/api/diagrams/topology/raw | mmdc`.
+func (s *Server) handleDiagramRaw(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.RLock()
+	var d model.DiagramResult
+	found := false
+	for _, v := range s.data {
+		if v.ID == id {
+			d, found = v, true
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown diagram %q", id), http.StatusNotFound)
+		return
+	}
+	ct, ok := rawContentTypes[d.Type]
+	if !ok {
+		http.Error(w, fmt.Sprintf("diagram %q of type %q has no raw form", id, d.Type), http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", ct)
+	_, _ = io.WriteString(w, d.Content)
+}
+
 // handleHealth is the readiness probe: returns 503 until first refresh
 // populates s.data AND (if EAM is enabled) the DB pool can ping. pgxpool's
 // own health-check loop usually self-heals stuck connections, but if it
 // can't, dropping the pod from Service endpoints lets kubelet recover.
+// Stale data is reported as status "stale" but stays ready: the pod is the
+// only one that can tell users their data is old.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	hasData := len(s.data) > 0
+	stale, age := staleness(s.lastGen, s.cfg.StaleAfter, time.Now())
 	s.mu.RUnlock()
 
 	if !hasData {
@@ -531,6 +1126,10 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if stale {
+		_, _ = fmt.Fprintf(w, `{"status":"stale","ageSeconds":%d}`, age)
+		return
+	}
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 }
 
@@ -545,12 +1144,45 @@ func (s *Server) handleHealthLive(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	resp := struct {
-		EAM bool `json:"eam"`
-		AI  bool `json:"ai"`
+		APIVersion string `json:"apiVersion"`
+		EAM        bool   `json:"eam"`
+		AI         bool   `json:"ai"`
 	}{
-		EAM: s.db != nil,
-		AI:  s.cfg.LiteLLMURL != "",
+		APIVersion: apiVersion,
+		EAM:        s.db != nil,
+		AI:         s.cfg.LiteLLMURL != "",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// handleStatus reports the background checks' outcome: which charts the
+// last version check resolved and which failed, and why.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	checks := s.chartChecks
+	lastRefresh, lastAttempt := s.lastGen, s.lastAttempt
+	cd := s.clusterData
+	s.mu.RUnlock()
+
+	resp := struct {
+		LastRefresh time.Time `json:"lastRefresh"`
+		LastAttempt time.Time `json:"lastAttempt"`
+		ChartChecks struct {
+			versions.CheckResult
+			Failed int `json:"failed"`
+		} `json:"chartChecks"`
+		RegistryProxy *versions.ProxyHealth `json:"registryProxy,omitempty"`
+		// Capabilities says which optional integrations each cluster has
+		// installed, so empty diagrams can be explained.
+		Capabilities []model.Capability `json:"capabilities,omitempty"`
+	}{LastRefresh: lastRefresh, LastAttempt: lastAttempt, RegistryProxy: s.registryProxyStatus()}
+	if cd != nil {
+		resp.Capabilities = cd.Capabilities
 	}
+	resp.ChartChecks.CheckResult = checks
+	resp.ChartChecks.Failed = checks.Failed()
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
@@ -0,0 +1,679 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/diagram"
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestRefreshRecoversPanicAndKeepsData(t *testing.T) {
+	prev := []model.DiagramResult{{ID: "workloads", Title: "Workloads", Type: "table", Content: "[]"}}
+	genAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// No parsers configured: indexing s.k8sParsers[0] panics mid-pipeline,
+	// standing in for any nil dereference during parse or generation.
+	s := &Server{cfg: Config{ClusterName: "Homelab"}, data: prev, lastGen: genAt}
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("refresh let a panic escape: %v", r)
+			}
+		}()
+		s.refresh(context.Background())
+	}()
+
+	rec := httptest.NewRecorder()
+	s.handleDiagrams(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams", nil))
+
+	var resp struct {
+		Diagrams    []model.DiagramResult `json:"diagrams"`
+		GeneratedAt time.Time             `json:"generated_at"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(resp.Diagrams) != 1 || resp.Diagrams[0].ID != "workloads" {
+		t.Errorf("diagrams = %+v, want previous data preserved", resp.Diagrams)
+	}
+	if !resp.GeneratedAt.Equal(genAt) {
+		t.Errorf("generated_at = %v, want %v", resp.GeneratedAt, genAt)
+	}
+}
+
+func TestGenerateDiagramsRecoversPanickingGenerator(t *testing.T) {
+	s := &Server{diagrams: []diagramGen{
+		one("workloads", "Workloads", diagram.GenerateWorkloads),
+		one("broken", "Broken", func(*model.ClusterData) model.DiagramResult { panic("boom") }),
+		one("storage", "Storage", diagram.GenerateStorage),
+	}}
+	cd := &model.ClusterData{
+		Workloads: []model.WorkloadInfo{{Name: "api", Namespace: "apps", Cluster: "Homelab", Kind: "Deployment", Replicas: 1}},
+	}
+
+	var got []model.DiagramResult
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("generateDiagrams let a panic escape: %v", r)
+			}
+		}()
+		got = s.generateDiagrams(cd)
+	}()
+
+	if len(got) != 3 {
+		t.Fatalf("got %d diagrams, want 3", len(got))
+	}
+	if b := got[1]; b.ID != "broken" || b.Title != "Broken" || b.Type != "markdown" || b.Error != "boom" || !strings.Contains(b.Content, "boom") {
+		t.Errorf("broken = {ID:%q Title:%q Type:%q Error:%q Content:%q}, want the placeholder for boom", b.ID, b.Title, b.Type, b.Error, b.Content)
+	}
+	for i, id := range map[int]string{0: "workloads", 2: "storage"} {
+		if d := got[i]; d.ID != id || d.Error != "" || d.Content == "" {
+			t.Errorf("diagram %d = {ID:%q Error:%q}, want %s rendered despite the panic", i, d.ID, d.Error, id)
+		}
+	}
+}
+
+func TestHandleDiagramsTeamFilter(t *testing.T) {
+	cd := &model.ClusterData{
+		PrimaryCluster: "Homelab",
+		Namespaces: []model.NamespaceInfo{
+			{Name: "payments", Cluster: "Homelab", Team: "payments"},
+			{Name: "checkout", Cluster: "Homelab"},
+			{Name: "media", Cluster: "Homelab", Team: "media"},
+		},
+		Workloads: []model.WorkloadInfo{
+			// checkout has no team label of its own; its workload does.
+			{Name: "checkout-api", Namespace: "checkout", Cluster: "Homelab", Kind: "Deployment", Team: "payments"},
+			{Name: "jellyfin", Namespace: "media", Cluster: "Homelab", Kind: "Deployment", Team: "media"},
+		},
+		HelmReleases: []model.HelmReleaseInfo{
+			{Name: "ledger", Namespace: "payments", Cluster: "Homelab", ChartName: "ledger", Version: "1.0.0"},
+			{Name: "checkout", Namespace: "checkout", Cluster: "Homelab", ChartName: "checkout", Version: "2.0.0"},
+			{Name: "jellyfin", Namespace: "media", Cluster: "Homelab", ChartName: "jellyfin", Version: "3.0.0"},
+		},
+	}
+
+	s := &Server{
+		cfg:             Config{ClusterName: "Homelab"},
+		checker:         versions.NewChecker(time.Hour, ""),
+		imageChecker:    versions.NewImageChecker("", nil),
+		nodeChecker:     versions.NewNodeChecker(nil, 0),
+		securityChecker: versions.NewSecurityChecker(),
+		clusterData:     cd,
+	}
+	s.data = s.generateDiagrams(cd)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"checkout", "media", "payments"}},
+		{"?team=payments", []string{"checkout", "payments"}},
+		{"?team=unknown", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.handleDiagrams(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams"+tt.query, nil))
+
+			var resp struct {
+				Diagrams []model.DiagramResult `json:"diagrams"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+
+			for _, id := range []string{"security", "charts"} {
+				if got := tableNamespaces(t, resp.Diagrams, id); !slices.Equal(got, tt.want) {
+					t.Errorf("%s namespaces = %v, want %v", id, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleDiagramsRefreshIfStale(t *testing.T) {
+	prev := []model.DiagramResult{{ID: "workloads", Title: "Workloads", Type: "table", Content: "[]"}}
+
+	tests := []struct {
+		name        string
+		staleAfter  time.Duration
+		age         time.Duration
+		refreshing  bool
+		wantRefresh bool
+	}{
+		{"stale read schedules refresh", time.Hour, 2 * time.Hour, false, true},
+		{"fresh read", time.Hour, time.Minute, false, false},
+		{"refresh already running", time.Hour, 2 * time.Hour, true, false},
+		{"disabled", 0, 2 * time.Hour, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{
+				cfg:        Config{ClusterName: "Homelab", RefreshIfStale: tt.staleAfter},
+				data:       prev,
+				lastGen:    time.Now().Add(-tt.age),
+				refreshReq: make(chan struct{}, 1),
+			}
+			s.refreshing.Store(tt.refreshing)
+
+			// Nothing drains refreshReq: a handler waiting on the refresh
+			// would hang here.
+			rec := httptest.NewRecorder()
+			s.handleDiagrams(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams", nil))
+
+			var resp struct {
+				Diagrams []model.DiagramResult `json:"diagrams"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(resp.Diagrams) != 1 || resp.Diagrams[0].ID != "workloads" {
+				t.Errorf("diagrams = %+v, want the existing data", resp.Diagrams)
+			}
+			if got := len(s.refreshReq) == 1; got != tt.wantRefresh {
+				t.Errorf("refresh scheduled = %v, want %v", got, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestGenerateDiagramsStableIDs(t *testing.T) {
+	s := &Server{
+		cfg:             Config{ClusterName: "Homelab"},
+		checker:         versions.NewChecker(time.Hour, ""),
+		imageChecker:    versions.NewImageChecker("", nil),
+		nodeChecker:     versions.NewNodeChecker(nil, 0),
+		securityChecker: versions.NewSecurityChecker(),
+	}
+	populated := &model.ClusterData{
+		PrimaryCluster:   "Homelab",
+		Nodes:            []model.NodeInfo{{Name: "worker-1", Cluster: "Homelab"}},
+		Namespaces:       []model.NamespaceInfo{{Name: "apps", Cluster: "Homelab"}},
+		Flux:             []model.FluxKustomization{{Name: "apps", Namespace: "flux-system", Cluster: "Homelab"}},
+		HelmReleases:     []model.HelmReleaseInfo{{Name: "web", Namespace: "apps", Cluster: "Homelab", ChartName: "web", Version: "1.0.0"}},
+		Pods:             []model.PodImageInfo{{Cluster: "Homelab", Namespace: "apps", PodName: "web-1", Image: "nginx:1.27", NodeName: "worker-1"}},
+		Workloads:        []model.WorkloadInfo{{Name: "web", Namespace: "apps", Cluster: "Homelab", Kind: "Deployment", Replicas: 1}},
+		Services:         []model.ServiceInfo{{Name: "web", Namespace: "apps", Cluster: "Homelab", Selector: map[string]string{"app": "web"}}},
+		HTTPRoutes:       []model.HTTPRouteInfo{{Name: "web", Namespace: "apps", Cluster: "Homelab", Hostnames: []string{"web.example.com"}}},
+		EastWestGateways: []model.EastWestGateway{{Name: "eastwest", Network: "homelab-network", IP: "10.0.0.1", Port: 15443}},
+	}
+
+	ids := func(cd *model.ClusterData) []string {
+		var out []string
+		for _, d := range s.generateDiagrams(cd) {
+			out = append(out, d.ID)
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	empty, full := ids(&model.ClusterData{PrimaryCluster: "Homelab"}), ids(populated)
+	if !slices.Equal(empty, full) {
+		t.Errorf("diagram IDs differ between an empty and a populated cluster:\nempty: %v\nfull:  %v", empty, full)
+	}
+
+	for _, d := range s.generateDiagrams(&model.ClusterData{PrimaryCluster: "Homelab"}) {
+		if !d.Empty {
+			t.Errorf("%s: Empty = false for an empty cluster", d.ID)
+		}
+	}
+}
+
+// tableNamespaces returns the sorted, deduplicated namespace column of the
+// table diagram with the given ID. A markdown placeholder yields nil.
+func tableNamespaces(t *testing.T, diagrams []model.DiagramResult, id string) []string {
+	t.Helper()
+	for _, d := range diagrams {
+		if d.ID != id {
+			continue
+		}
+		if d.Type != "table" {
+			return nil
+		}
+		var rows []struct {
+			Namespace string `json:"namespace"`
+		}
+		if err := json.Unmarshal([]byte(d.Content), &rows); err != nil {
+			t.Fatalf("decoding %s table: %v", id, err)
+		}
+		var out []string
+		for _, r := range rows {
+			out = append(out, r.Namespace)
+		}
+		slices.Sort(out)
+		return slices.Compact(out)
+	}
+	t.Fatalf("diagram %q not found", id)
+	return nil
+}
+
+func TestHandleEventsSince(t *testing.T) {
+	now := time.Now()
+	s := &Server{clusterData: &model.ClusterData{
+		Events: []model.EventInfo{
+			{Cluster: "Homelab", Namespace: "apps", Object: "Pod/web-1", Reason: "BackOff", LastSeen: now.Add(-5 * time.Minute)},
+			{Cluster: "Homelab", Namespace: "apps", Object: "Pod/web-2", Reason: "FailedMount", LastSeen: now.Add(-45 * time.Minute)},
+			{Cluster: "Homelab", Namespace: "apps", Object: "Pod/old", Reason: "Evicted", LastSeen: now.Add(-3 * time.Hour)},
+		},
+	}}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?since=30m", []string{"Pod/web-1"}},
+		{"", []string{"Pod/web-1", "Pod/web-2"}}, // default window
+		{"?since=24h", []string{"Pod/web-1", "Pod/web-2", "Pod/old"}},
+		{"?since=1m", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams/events"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			var d model.DiagramResult
+			if err := json.NewDecoder(rec.Body).Decode(&d); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			var got []string
+			if !d.Empty {
+				var rows []struct {
+					Object string `json:"object"`
+				}
+				if err := json.Unmarshal([]byte(d.Content), &rows); err != nil {
+					t.Fatalf("decoding events table: %v", err)
+				}
+				for _, r := range rows {
+					got = append(got, r.Object)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams/events?since=soon", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid since status = %d, want 400", rec.Code)
+	}
+}
+
+// reachableKubeconfig returns a kubeconfig for an API server that answers
+// /version and nothing else, so a refresh succeeds with empty clusters.
+func reachableKubeconfig(t *testing.T) string {
+	t.Helper()
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"31","gitVersion":"v1.31.0"}`))
+	}))
+	t.Cleanup(api.Close)
+	return strings.Replace(reloadKubeconfig, "server: https://127.0.0.1:1", "server: "+api.URL+"\n    insecure-skip-tls-verify: true", 1)
+}
+
+func TestRefreshPrimaryByFlag(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(reachableKubeconfig(t)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	nas := model.DataSource{Name: "NAS", Type: "kubernetes", Path: kubeconfig}
+	edge := model.DataSource{Name: "Edge", Type: "kubernetes", Path: kubeconfig}
+	flagged := edge
+	flagged.Primary = true
+
+	for _, tt := range []struct {
+		name    string
+		sources []model.DataSource
+		want    string
+	}{
+		{"none flagged", []model.DataSource{nas, edge}, "Homelab"},
+		{"flagged last", []model.DataSource{nas, flagged}, "Edge"},
+		{"flagged first", []model.DataSource{flagged, nas}, "Edge"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(Config{Kubeconfig: kubeconfig, ClusterName: "Homelab", RefreshInterval: time.Minute, DataSources: tt.sources})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.refresh(context.Background())
+			if got := s.clusterData.PrimaryCluster; got != tt.want {
+				t.Errorf("primary cluster = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	other := nas
+	other.Primary = true
+	if _, err := New(Config{Kubeconfig: kubeconfig, RefreshInterval: time.Minute, DataSources: []model.DataSource{flagged, other}}); err == nil {
+		t.Error("New accepted two primary data sources")
+	}
+	missing := flagged
+	missing.Path = filepath.Join(dir, "missing")
+	if _, err := New(Config{Kubeconfig: kubeconfig, RefreshInterval: time.Minute, DataSources: []model.DataSource{missing}}); err == nil {
+		t.Error("New accepted a primary data source whose kubeconfig is missing")
+	}
+}
+
+func TestHandleDiagramsStale(t *testing.T) {
+	s := &Server{
+		cfg:     Config{StaleAfter: 10 * time.Minute},
+		data:    []model.DiagramResult{{ID: "workloads", Title: "Workloads", Type: "table", Content: "[]"}},
+		lastGen: time.Now().Add(-5 * time.Minute),
+	}
+	get := func() (stale bool, age int64) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handleDiagrams(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams", nil))
+		var resp struct {
+			Stale      bool  `json:"stale"`
+			AgeSeconds int64 `json:"ageSeconds"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return resp.Stale, resp.AgeSeconds
+	}
+
+	if stale, age := get(); stale || age < 300 || age > 310 {
+		t.Errorf("5 minutes old: stale = %v, ageSeconds = %d; want false, ~300", stale, age)
+	}
+
+	// The next refreshes fail: lastGen stays put while time passes.
+	s.lastGen = time.Now().Add(-11 * time.Minute)
+	if stale, age := get(); !stale || age < 660 {
+		t.Errorf("11 minutes old: stale = %v, ageSeconds = %d; want true, >= 660", stale, age)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleHealth(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatalf("decoding health: %v", err)
+	}
+	if rec.Code != http.StatusOK || health.Status != "stale" {
+		t.Errorf("health = %d %q, want 200 \"stale\"", rec.Code, health.Status)
+	}
+}
+
+func TestRefreshLoopBacksOffWhileUnreachable(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(reloadKubeconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const interval, ceiling = 20 * time.Millisecond, 80 * time.Millisecond
+	s, err := New(Config{Kubeconfig: kubeconfig, ClusterName: "Homelab", RefreshInterval: interval, RefreshBackoffMax: ceiling})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for failures, want := range []time.Duration{interval, 40 * time.Millisecond, ceiling, ceiling, ceiling} {
+		if got := s.refreshDelay(failures); got != want {
+			t.Errorf("refreshDelay(%d) = %v, want %v", failures, got, want)
+		}
+	}
+
+	// Every refresh fails: the API server at 127.0.0.1:1 refuses
+	// connections. Record each wait and fire it right away.
+	waits := make(chan time.Duration)
+	s.after = func(d time.Duration) <-chan time.Time {
+		waits <- d
+		fired := make(chan time.Time, 1)
+		fired <- time.Now()
+		return fired
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.refreshLoop(ctx)
+	}()
+
+	var got []time.Duration
+	for range 5 {
+		select {
+		case d := <-waits:
+			got = append(got, d)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("refresh loop stalled after waits %v", got)
+		}
+	}
+	cancel()
+	// Keep answering waits until the loop sees the cancellation.
+	for stopped := false; !stopped; {
+		select {
+		case <-waits:
+		case <-done:
+			stopped = true
+		}
+	}
+
+	// The first wait is the interval, then it doubles per failure up to the cap.
+	want := []time.Duration{interval, 40 * time.Millisecond, ceiling, ceiling, ceiling}
+	if !slices.Equal(got, want) {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.lastGen.IsZero() {
+		t.Error("a refresh without the primary cluster counted as successful")
+	}
+}
+
+func TestRefreshKeepsPrimaryDataWhileUnreachable(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(reloadKubeconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	compose := filepath.Join(dir, "compose.yaml")
+	if err := os.WriteFile(compose, []byte("services:\n  app:\n    image: app:1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(Config{
+		Kubeconfig:      kubeconfig,
+		ClusterName:     "Homelab",
+		RefreshInterval: time.Minute,
+		DataSources:     []model.DataSource{{Name: "NAS", Type: "docker-compose", Path: compose}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The primary was parsed before its API server went away.
+	primary := primaryParser(s.k8sParsers)
+	_, _ = cachedRead(&s.sources, primary, time.Minute, time.Now().Add(-time.Hour), func() (*model.ClusterData, error) {
+		return &model.ClusterData{Namespaces: []model.NamespaceInfo{{Name: "apps", Cluster: "Homelab"}}}, nil
+	})
+
+	if err := s.refresh(context.Background()); err == nil {
+		t.Fatal("refresh succeeded with the primary cluster unreachable")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if cd := s.clusterData; cd == nil || len(cd.Namespaces) != 1 || cd.Namespaces[0].Name != "apps" {
+		t.Errorf("cluster data = %+v, want the primary's previous namespaces kept", cd)
+	}
+	if cd := s.clusterData; cd == nil || len(cd.InfraSources) != 1 {
+		t.Errorf("cluster data = %+v, want the compose source still refreshed", cd)
+	}
+	if !s.lastGen.IsZero() {
+		t.Error("a refresh without the primary cluster counted as successful")
+	}
+}
+
+func TestHandleDiagramsWarningSeverity(t *testing.T) {
+	s := &Server{
+		cfg: Config{WarningMinSeverity: model.SeverityWarn},
+		warnings: []model.Warning{
+			{Source: "capabilities", Severity: model.SeverityInfo, Cluster: "Homelab", Name: "Velero", Message: "Velero is not installed on Homelab (no velero.io API group); its diagrams stay empty"},
+			{Source: "rbac", Severity: model.SeverityError, Cluster: "Homelab", Message: "listing secrets is forbidden"},
+		},
+	}
+	sources := func(url string) []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handleDiagrams(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var resp struct {
+			Warnings []model.Warning `json:"warnings"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding %s: %v", url, err)
+		}
+		var out []string
+		for _, w := range resp.Warnings {
+			out = append(out, w.Source)
+		}
+		return out
+	}
+
+	if got := sources("/api/diagrams"); !slices.Equal(got, []string{"rbac"}) {
+		t.Errorf("default warnings = %v, want only the rbac error", got)
+	}
+	if got := sources("/api/diagrams?minSeverity=info"); !slices.Equal(got, []string{"capabilities", "rbac"}) {
+		t.Errorf("minSeverity=info warnings = %v, want both", got)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleDiagrams(rec, httptest.NewRequest(http.MethodGet, "/api/diagrams?minSeverity=loud", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid minSeverity status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleDiagramRaw(t *testing.T) {
+	mermaid := "flowchart LR\n  a --> b\n"
+	s := &Server{
+		data: []model.DiagramResult{
+			{ID: "topology", Title: "Topology", Type: "mermaid", Content: mermaid},
+			{ID: "workloads", Title: "Workloads", Type: "table", Content: `[{"name":"web"}]`},
+			{ID: "odd", Title: "Odd", Type: "svg", Content: "<svg/>"},
+		},
+	}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantType   string
+		wantBody   string
+	}{
+		{"/api/diagrams/topology/raw", http.StatusOK, "text/plain; charset=utf-8", mermaid},
+		{"/api/v1/diagrams/topology/raw", http.StatusOK, "text/plain; charset=utf-8", mermaid},
+		{"/api/diagrams/workloads/raw", http.StatusOK, "application/json", `[{"name":"web"}]`},
+		{"/api/diagrams/nope/raw", http.StatusNotFound, "", ""},
+		{"/api/diagrams/odd/raw", http.StatusUnsupportedMediaType, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRefreshWritesSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(reachableKubeconfig(t)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var sources []model.DataSource
+	for _, name := range []string{"nas", "edge"} {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte("services:\n  app:\n    image: app:1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, model.DataSource{Name: name, Type: "docker-compose", Path: path})
+	}
+	dbPath := filepath.Join(dir, "snapshot.db")
+
+	s, err := New(Config{Kubeconfig: kubeconfig, ClusterName: "Homelab", RefreshInterval: time.Minute, DataSources: sources, SnapshotDB: dbPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for table, want := range map[string]int{"snapshot": 1, "infra_sources": 2, "nodes": 0, "helm_releases": 0, "namespaces": 0, "pods": 0} {
+		var n int
+		if err := db.QueryRow("SELECT count(*) FROM " + table).Scan(&n); err != nil {
+			t.Errorf("counting %s: %v", table, err)
+			continue
+		}
+		if n != want {
+			t.Errorf("%s has %d rows, want %d", table, n, want)
+		}
+	}
+	var primary string
+	if err := db.QueryRow("SELECT primary_cluster FROM snapshot").Scan(&primary); err != nil || primary != "Homelab" {
+		t.Errorf("snapshot primary cluster = %q (%v), want Homelab", primary, err)
+	}
+	var compose string
+	if err := db.QueryRow("SELECT docker_compose FROM infra_sources WHERE name = 'nas'").Scan(&compose); err != nil || !strings.Contains(compose, `"app:1"`) {
+		t.Errorf("nas docker_compose = %q (%v), want the services as JSON", compose, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".snapshot.db") {
+			t.Errorf("temp file %s left behind", e.Name())
+		}
+	}
+}
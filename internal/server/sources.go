@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/parser"
+)
+
+// sourceCache keeps the last parse of every cluster and data source, so a
+// source with its own interval is only re-read once that interval has
+// passed; the rest of each refresh reuses its previous result. The zero
+// value is ready to use.
+type sourceCache struct {
+	mu      sync.Mutex
+	entries map[any]*cachedSource
+	round   int // bumped by prune; entries not used since are dropped
+}
+
+type cachedSource struct {
+	at    time.Time
+	data  any
+	round int
+}
+
+// cachedRead returns the cached data for key if it was read less than every
+// ago, and otherwise reads it again with fetch. Failed reads are not cached.
+func cachedRead[T any](c *sourceCache, key any, every time.Duration, now time.Time, fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok {
+		e.round = c.round
+		if every > 0 && now.Sub(e.at) < every {
+			c.mu.Unlock()
+			return e.data.(T), nil
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := fetch()
+	if err != nil {
+		return data, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[any]*cachedSource)
+	}
+	c.entries[key] = &cachedSource{at: now, data: data, round: c.round}
+	c.mu.Unlock()
+	return data, nil
+}
+
+// prune drops the entries not used since the previous prune, e.g. those of
+// parsers or sources removed by a reload. Call it once per refresh.
+func (c *sourceCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if e.round != c.round {
+			delete(c.entries, key)
+		}
+	}
+	c.round++
+}
+
+// clusterData returns p's cluster data, parsing it again when its refresh
+// interval is due. Callers must not modify the result.
+func (c *sourceCache) clusterData(ctx context.Context, p *parser.KubernetesParser, now time.Time) *model.ClusterData {
+	cd, _ := cachedRead(c, p, p.RefreshInterval(), now, func() (*model.ClusterData, error) {
+		return p.ParseAll(ctx), nil
+	})
+	return cd
+}
+
+// lastClusterData returns p's cluster data from its last parse, however old,
+// or nil if it was never parsed. Callers must not modify the result.
+func (c *sourceCache) lastClusterData(p *parser.KubernetesParser) *model.ClusterData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[p]
+	if !ok {
+		return nil
+	}
+	e.round = c.round
+	return e.data.(*model.ClusterData)
+}
+
+// infraSource resolves a tfstate or docker-compose source, reading it again
+// when its interval is due.
+func (c *sourceCache) infraSource(ds model.DataSource, now time.Time) (*model.InfraSource, error) {
+	every, err := ds.RefreshInterval()
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s|%s|%s|%s", ds.Type, ds.Name, ds.Path, strings.Join(ds.Profiles, ","))
+	return cachedRead(c, key, every, now, func() (*model.InfraSource, error) {
+		return resolveDataSource(ds)
+	})
+}
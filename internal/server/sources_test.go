@@ -0,0 +1,79 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestSourceCachePerSourceInterval(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, image string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("services:\n  app:\n    image: "+image+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	fast := model.DataSource{Name: "fast", Type: "docker-compose", Path: write("fast.yaml", "app:1")}
+	slow := model.DataSource{Name: "slow", Type: "docker-compose", Path: write("slow.yaml", "app:1"), Interval: "1h"}
+
+	var c sourceCache
+	image := func(ds model.DataSource, now time.Time) string {
+		t.Helper()
+		src, err := c.infraSource(ds, now)
+		if err != nil {
+			t.Fatalf("%s: %v", ds.Name, err)
+		}
+		return src.DockerCompose.Services[0].Image
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, ds := range []model.DataSource{fast, slow} {
+		if got := image(ds, start); got != "app:1" {
+			t.Fatalf("%s first read = %q, want app:1", ds.Name, got)
+		}
+	}
+	c.prune()
+
+	// Both files change; on the next (5-minute) tick only the fast source
+	// is read again.
+	write("fast.yaml", "app:2")
+	write("slow.yaml", "app:2")
+	tick := start.Add(5 * time.Minute)
+	if got := image(fast, tick); got != "app:2" {
+		t.Errorf("fast source on tick = %q, want app:2", got)
+	}
+	if got := image(slow, tick); got != "app:1" {
+		t.Errorf("slow source on fast tick = %q, want cached app:1", got)
+	}
+	c.prune()
+
+	// Once its interval has passed the slow source is read again.
+	if got := image(slow, start.Add(time.Hour)); got != "app:2" {
+		t.Errorf("slow source after its interval = %q, want app:2", got)
+	}
+}
+
+func TestSourceCachePrunesUnusedSources(t *testing.T) {
+	var c sourceCache
+	read := func() (string, error) { return "data", nil }
+	now := time.Now()
+	_, _ = cachedRead(&c, "kept", time.Hour, now, read)
+	_, _ = cachedRead(&c, "removed", time.Hour, now, read)
+	c.prune()
+
+	_, _ = cachedRead(&c, "kept", time.Hour, now, read)
+	c.prune()
+
+	if _, ok := c.entries["removed"]; ok {
+		t.Error("entry unused for a whole refresh was not pruned")
+	}
+	if _, ok := c.entries["kept"]; !ok {
+		t.Error("entry in use was pruned")
+	}
+}
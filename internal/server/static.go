@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// spaHandler serves a single-page app from dir. Existing files are served as
+// is; any other path that isn't an API route gets index.html so client-side
+// routes survive a reload (history-API fallback).
+//
+// Files are opened through an os.Root, so neither ".." segments nor symlinks
+// can reach outside dir.
+func spaHandler(dir string) (http.Handler, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := root.Stat("index.html"); err != nil {
+		_ = root.Close()
+		return nil, err
+	}
+	fsys := root.FS()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p := path.Clean("/" + r.URL.Path)
+		// Unknown API paths are real 404s, not the app shell.
+		if p == "/api" || strings.HasPrefix(p, "/api/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		name := strings.TrimPrefix(p, "/")
+		if name != "" {
+			fi, err := fs.Stat(fsys, name)
+			if err == nil && !fi.IsDir() {
+				http.ServeFileFS(w, r, fsys, name)
+				return
+			}
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				// Escapes from dir (os.Root) and unreadable files alike.
+				http.NotFound(w, r)
+				return
+			}
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFileFS(w, r, fsys, "index.html")
+	}), nil
+}
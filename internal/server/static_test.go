@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRoutesServeSPA(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "build")
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "index.html"), "<html>app shell</html>")
+	write(filepath.Join(dir, "assets", "app.js"), "console.log('app')")
+	write(filepath.Join(parent, "secret.txt"), "do not serve")
+
+	s := &Server{cfg: Config{StaticDir: dir}}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string // substring
+	}{
+		{"client route falls back to index", http.MethodGet, "/clusters/home/nodes", http.StatusOK, "app shell"},
+		{"root serves index", http.MethodGet, "/", http.StatusOK, "app shell"},
+		{"existing asset", http.MethodGet, "/assets/app.js", http.StatusOK, "console.log"},
+		{"api route hits its handler", http.MethodGet, "/api/health/live", http.StatusOK, "ok"},
+		{"unknown api path is not the app", http.MethodGet, "/api/nope", http.StatusNotFound, "not found"},
+		{"non-GET rejected", http.MethodPost, "/clusters", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+
+	// The mux cleans paths before routing; the handler must hold on its own.
+	spa, err := spaHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/../secret.txt", "/assets/../../secret.txt", "../secret.txt"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = p
+		rec := httptest.NewRecorder()
+		spa.ServeHTTP(rec, req)
+		if strings.Contains(rec.Body.String(), "do not serve") {
+			t.Errorf("%s served a file outside the static dir", p)
+		}
+	}
+}
+
+func TestRoutesStaticDirWithoutIndex(t *testing.T) {
+	s := &Server{cfg: Config{StaticDir: t.TempDir()}}
+	if _, err := s.routes(); err == nil {
+		t.Fatal("expected an error for a static dir without index.html")
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import "github.com/fredericrous/cluster-vision/internal/model"
+
+// nsKey identifies a namespace across clusters.
+type nsKey struct{ cluster, namespace string }
+
+// teamNamespaces returns the namespaces owned by team: those labelled for
+// the team themselves, plus any namespace holding a workload labelled for it.
+func teamNamespaces(cd *model.ClusterData, team string) map[nsKey]bool {
+	owned := make(map[nsKey]bool)
+	for _, ns := range cd.Namespaces {
+		if ns.Team == team {
+			owned[nsKey{ns.Cluster, ns.Name}] = true
+		}
+	}
+	for _, w := range cd.Workloads {
+		if w.Team == team {
+			owned[nsKey{w.Cluster, w.Namespace}] = true
+		}
+	}
+	return owned
+}
+
+// filterByNamespace keeps the items whose cluster/namespace is in owned.
+func filterByNamespace[T any](items []T, owned map[nsKey]bool, key func(T) nsKey) []T {
+	var out []T
+	for _, it := range items {
+		if owned[key(it)] {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// filterClusterData returns a copy of cd restricted to the namespaces owned by
+// team. Namespaced resources outside those namespaces are dropped; cluster-wide
+// data (nodes, gateways, CRDs, repositories, vulnerability reports, infra
+// sources) is kept so lookups made by the generators still resolve.
+func filterClusterData(cd *model.ClusterData, team string) *model.ClusterData {
+	owned := teamNamespaces(cd, team)
+	out := *cd
+
+	out.Namespaces = filterByNamespace(cd.Namespaces, owned, func(v model.NamespaceInfo) nsKey { return nsKey{v.Cluster, v.Name} })
+	out.Flux = filterByNamespace(cd.Flux, owned, func(v model.FluxKustomization) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.ArgoApps = filterByNamespace(cd.ArgoApps, owned, func(v model.ArgoApplication) nsKey { return nsKey{v.Cluster, v.DestNamespace} })
+	out.FluxSources = filterByNamespace(cd.FluxSources, owned, func(v model.FluxSourceInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.HTTPRoutes = filterByNamespace(cd.HTTPRoutes, owned, func(v model.HTTPRouteInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.IngressRoutes = filterByNamespace(cd.IngressRoutes, owned, func(v model.IngressRouteInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Ingresses = filterByNamespace(cd.Ingresses, owned, func(v model.IngressInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.ReferenceGrants = filterByNamespace(cd.ReferenceGrants, owned, func(v model.ReferenceGrantInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.SecurityPolicies = filterByNamespace(cd.SecurityPolicies, owned, func(v model.SecurityPolicyInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.ServiceEntries = filterByNamespace(cd.ServiceEntries, owned, func(v model.ServiceEntryInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.LoadBalancers = filterByNamespace(cd.LoadBalancers, owned, func(v model.LoadBalancerService) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.HelmReleases = filterByNamespace(cd.HelmReleases, owned, func(v model.HelmReleaseInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Pods = filterByNamespace(cd.Pods, owned, func(v model.PodImageInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Workloads = filterByNamespace(cd.Workloads, owned, func(v model.WorkloadInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Autoscalers = filterByNamespace(cd.Autoscalers, owned, func(v model.AutoscalerInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Storage = filterByNamespace(cd.Storage, owned, func(v model.StorageInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Quotas = filterByNamespace(cd.Quotas, owned, func(v model.QuotaInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Certificates = filterByNamespace(cd.Certificates, owned, func(v model.CertificateInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.NetworkPolicies = filterByNamespace(cd.NetworkPolicies, owned, func(v model.NetworkPolicyInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Configs = filterByNamespace(cd.Configs, owned, func(v model.ConfigInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Services = filterByNamespace(cd.Services, owned, func(v model.ServiceInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.RBACBindings = filterByNamespace(cd.RBACBindings, owned, func(v model.RBACBindingInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.VeleroSchedules = filterByNamespace(cd.VeleroSchedules, owned, func(v model.VeleroScheduleInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.VeleroBackups = filterByNamespace(cd.VeleroBackups, owned, func(v model.VeleroBackupInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.Events = filterByNamespace(cd.Events, owned, func(v model.EventInfo) nsKey { return nsKey{v.Cluster, v.Namespace} })
+	out.DeprecatedAPIs = filterByNamespace(cd.DeprecatedAPIs, owned, func(v model.DeprecatedAPIUsage) nsKey { return nsKey{v.Cluster, v.Namespace} })
+
+	return &out
+}
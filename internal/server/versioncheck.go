@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+// handleVersionCheck runs one version checker now, bypassing its interval
+// gate, and regenerates the diagrams it feeds. It is meant for diagnosing
+// registry auth or proxy problems without waiting for the next scheduled
+// check. {type} is helm, image or node; a checker already running answers
+// 409 rather than queueing a second run. Helm and image checks probe the
+// registry proxy first and report its reachability alongside the counts.
+func (s *Server) handleVersionCheck(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	cd := s.clusterData
+	s.mu.RUnlock()
+
+	if cd == nil {
+		http.Error(w, `{"error":"no cluster data available yet"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	typ := r.PathValue("type")
+	if typ == "helm" || typ == "image" {
+		// Probe first so a failed check can be blamed on the proxy.
+		s.probeRegistryProxy(r.Context())
+	}
+	var counts versions.CheckCounts
+	var ok bool
+	switch typ {
+	case "helm":
+		var res versions.CheckResult
+		res, ok = s.checker.ForceCheck(cd.HelmRepositories, cd.HelmReleases)
+		if ok {
+			counts = versions.CheckCounts{Checked: len(res.Charts), Resolved: len(res.Charts) - res.Failed()}
+			s.mu.Lock()
+			s.chartChecks = res
+			s.mu.Unlock()
+			s.replaceDiagram(s.regenerate(cd, "charts")...)
+		}
+	case "image":
+		counts, ok = s.imageChecker.ForceCheck(cd.Pods)
+		if ok {
+			s.replaceDiagram(s.regenerate(cd, "images")...)
+		}
+	case "node":
+		counts, ok = s.nodeChecker.ForceCheck(cd.Nodes, cd.APIServers)
+		if ok {
+			s.replaceDiagram(s.regenerate(cd, "nodes", "cluster-info")...)
+		}
+	default:
+		http.Error(w, `{"error":"unknown checker, want helm, image or node"}`, http.StatusNotFound)
+		return
+	}
+
+	if !ok {
+		http.Error(w, fmt.Sprintf(`{"error":"a %s check is already running"}`, typ), http.StatusConflict)
+		return
+	}
+	slog.Info("on-demand version check complete", "type", typ, "checked", counts.Checked, "resolved", counts.Resolved)
+
+	resp := struct {
+		Type string `json:"type"`
+		versions.CheckCounts
+		RegistryProxy *versions.ProxyHealth `json:"registryProxy,omitempty"`
+	}{typ, counts, s.registryProxyStatus()}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/fredericrous/cluster-vision/internal/versions"
+)
+
+func TestHandleVersionCheckBypassesIntervalGate(t *testing.T) {
+	var latest atomic.Value
+	latest.Store("1.2.0")
+	repo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: " + latest.Load().(string) + "\n"))
+	}))
+	defer repo.Close()
+
+	cd := &model.ClusterData{
+		HelmRepositories: []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", URL: repo.URL}},
+		HelmReleases:     []model.HelmReleaseInfo{{Name: "app", Namespace: "apps", ChartName: "app", RepoName: "charts", RepoNS: "flux-system", Version: "1.2.0"}},
+	}
+	s := &Server{
+		checker:     versions.NewChecker(time.Hour, ""),
+		clusterData: cd,
+		data:        []model.DiagramResult{{ID: "charts"}},
+		lastGen:     time.Now(),
+	}
+	mux, err := s.routes()
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	s.checker.Check(cd.HelmRepositories, cd.HelmReleases)
+	latest.Store("1.3.0")
+	if res := s.checker.Check(cd.HelmRepositories, cd.HelmReleases); !res.At.IsZero() {
+		t.Fatal("scheduled check ran inside the interval, want it gated")
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/version-check/helm", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Type     string `json:"type"`
+		Checked  int    `json:"checked"`
+		Resolved int    `json:"resolved"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Type != "helm" || resp.Checked != 1 || resp.Resolved != 1 {
+		t.Errorf("response = %+v, want helm with 1 checked and 1 resolved", resp)
+	}
+	if got := s.checker.GetLatest(repo.URL, "app"); got != "1.3.0" {
+		t.Errorf("latest after forced check = %q, want 1.3.0", got)
+	}
+	if s.data[0].Type != "table" {
+		t.Errorf("charts diagram not regenerated: %+v", s.data[0])
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/version-check/registry", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown checker status = %d, want 404", rec.Code)
+	}
+}
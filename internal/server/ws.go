@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/diagram"
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 4096 // control messages are tiny
+)
+
+// wsControl is a client → server message on /api/ws:
+//
+//	{"action":"refresh"}
+//	{"action":"subscribe","ids":["nodes","images"]}
+//
+// An empty ids list subscribes to every diagram again.
+type wsControl struct {
+	Action string   `json:"action"`
+	IDs    []string `json:"ids,omitempty"`
+}
+
+// wsError is sent back for a control message the server can't act on.
+type wsError struct {
+	Error string `json:"error"`
+}
+
+// handleWS upgrades to a WebSocket that pushes the /api/diagrams payload on
+// connect and whenever diagrams change, and accepts wsControl messages.
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: s.wsOriginAllowed}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response.
+		slog.Debug("websocket upgrade failed", "error", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	updates := s.updates.subscribe()
+	defer s.updates.unsubscribe(updates)
+
+	controls := make(chan wsControl)
+	readDone := make(chan struct{})
+	writeDone := make(chan struct{})
+	defer close(writeDone)
+
+	go func() {
+		defer close(readDone)
+		conn.SetReadLimit(wsMaxMessage)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					slog.Debug("websocket read failed", "error", err)
+				}
+				return
+			}
+			var c wsControl
+			if err := json.Unmarshal(msg, &c); err != nil {
+				c = wsControl{} // answered as an unknown action
+			}
+			select {
+			case controls <- c:
+			case <-writeDone:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	var ids map[string]bool // nil = all diagrams
+	send := func(v any) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err := conn.WriteJSON(v); err != nil {
+			slog.Debug("websocket write failed", "error", err)
+			return false
+		}
+		return true
+	}
+
+	if !send(s.wsSnapshot(ids)) {
+		return
+	}
+	for {
+		select {
+		case <-readDone:
+			return
+		case <-updates:
+			if !send(s.wsSnapshot(ids)) {
+				return
+			}
+		case c := <-controls:
+			var ok bool
+			switch c.Action {
+			case "refresh":
+				s.requestRefresh()
+				ok = true // the refreshed data arrives as a regular update
+			case "subscribe":
+				ids = nil
+				if len(c.IDs) > 0 {
+					ids = make(map[string]bool, len(c.IDs))
+					for _, id := range c.IDs {
+						ids[id] = true
+					}
+				}
+				ok = send(s.wsSnapshot(ids))
+			default:
+				ok = send(wsError{Error: fmt.Sprintf("unknown action %q", c.Action)})
+			}
+			if !ok {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsSnapshot returns the current diagrams payload, restricted to ids when set.
+func (s *Server) wsSnapshot(ids map[string]bool) diagramsPayload {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p := diagramsPayload{APIVersion: apiVersion, Diagrams: s.data, Warnings: diagram.FilterWarnings(s.warnings, s.cfg.WarningMinSeverity), GeneratedAt: s.lastGen}
+	p.Stale, p.AgeSeconds = staleness(s.lastGen, s.cfg.StaleAfter, time.Now())
+	if ids != nil {
+		p.Diagrams = make([]model.DiagramResult, 0, len(ids))
+		for _, d := range s.data {
+			if ids[d.ID] {
+				p.Diagrams = append(p.Diagrams, d)
+			}
+		}
+	}
+	return p
+}
+
+// wsOriginAllowed accepts a WebSocket handshake from the server's own
+// origin, from Config.WSAllowedOrigins, or without an Origin header
+// (non-browser clients). Unlike the read-only JSON API, which answers any
+// origin, the socket is refused to other sites' pages: a browser would open
+// it with the user's cookies.
+func (s *Server) wsOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	s.mu.RLock()
+	allowed := s.cfg.WSAllowedOrigins
+	s.mu.RUnlock()
+	return slices.Contains(allowed, "*") || slices.ContainsFunc(allowed, func(o string) bool {
+		return strings.EqualFold(strings.TrimSuffix(o, "/"), origin)
+	})
+}
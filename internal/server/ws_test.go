@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketPushesUpdates(t *testing.T) {
+	s := &Server{
+		data: []model.DiagramResult{
+			{ID: "nodes", Title: "Cluster Nodes", Type: "markdown", Content: "*checking*"},
+			{ID: "images", Title: "Container Images", Type: "markdown", Content: "*checking*"},
+		},
+		refreshReq: make(chan struct{}, 1),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(s.handleWS))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	read := func() diagramsPayload {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var p diagramsPayload
+		if err := conn.ReadJSON(&p); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return p
+	}
+	ids := func(p diagramsPayload) string {
+		var out []string
+		for _, d := range p.Diagrams {
+			out = append(out, d.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := ids(read()); got != "nodes,images" {
+		t.Fatalf("initial snapshot = %q, want nodes,images", got)
+	}
+
+	if err := conn.WriteJSON(wsControl{Action: "subscribe", IDs: []string{"images"}}); err != nil {
+		t.Fatalf("write subscribe: %v", err)
+	}
+	if got := ids(read()); got != "images" {
+		t.Fatalf("snapshot after subscribe = %q, want images", got)
+	}
+
+	// An async checker finishing mid-refresh swaps in its diagram.
+	s.replaceDiagram(model.DiagramResult{ID: "images", Title: "Container Images", Type: "table", Content: "[]"})
+	p := read()
+	if len(p.Diagrams) != 1 || p.Diagrams[0].Type != "table" {
+		t.Fatalf("pushed update = %+v, want refreshed images table", p.Diagrams)
+	}
+
+	if err := conn.WriteJSON(wsControl{Action: "refresh"}); err != nil {
+		t.Fatalf("write refresh: %v", err)
+	}
+	select {
+	case <-s.refreshReq:
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresh action did not request a refresh")
+	}
+
+	// Closing the socket unregisters the client.
+	_ = conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.updates.count() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("client still subscribed after close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocketOriginCheck(t *testing.T) {
+	s := &Server{
+		cfg:        Config{WSAllowedOrigins: []string{"https://grafana.example.com"}},
+		refreshReq: make(chan struct{}, 1),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(s.handleWS))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/ws"
+
+	for _, tc := range []struct {
+		origin string
+		ok     bool
+	}{
+		{"", true},
+		{srv.URL, true},
+		{"https://grafana.example.com", true},
+		{"https://evil.example.com", false},
+	} {
+		h := http.Header{}
+		if tc.origin != "" {
+			h.Set("Origin", tc.origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, h)
+		if conn != nil {
+			_ = conn.Close()
+		}
+		if tc.ok && err != nil {
+			t.Errorf("origin %q: dial: %v", tc.origin, err)
+		}
+		if !tc.ok && (err == nil || resp == nil || resp.StatusCode != http.StatusForbidden) {
+			t.Errorf("origin %q: want 403, got err=%v", tc.origin, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package snapshot writes ClusterData to a SQLite file for ad-hoc SQL
+// queries and historical snapshots, e.g. "which namespaces lack backup":
+//
+//	SELECT cluster, name FROM namespaces WHERE NOT backup AND NOT system;
+//
+// Every slice of ClusterData becomes a table named after the field in
+// snake_case (HelmReleases → helm_releases), with a column per struct field.
+// Scalars keep their SQL type; slices, maps and nested structs are stored as
+// JSON text. The snapshot table holds the primary cluster and the time the
+// data was collected.
+package snapshot
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+
+	_ "modernc.org/sqlite" // pure Go driver, registered as "sqlite"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Write replaces the SQLite file at path with data collected at at. The
+// database is built in a temp file next to path and renamed into place, so
+// readers never see a partial snapshot. Returns the number of rows per table.
+func Write(path string, data *model.ClusterData, at time.Time) (map[string]int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("creating snapshot: %w", err)
+	}
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op once renamed
+
+	counts, err := write(tmp.Name(), data, at)
+	if err != nil {
+		return nil, fmt.Errorf("writing snapshot %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("replacing snapshot: %w", err)
+	}
+	return counts, nil
+}
+
+// write fills the empty database file at path in one transaction.
+func write(path string, data *model.ClusterData, at time.Time) (map[string]int, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op once committed
+
+	if _, err := tx.Exec(`CREATE TABLE snapshot (primary_cluster TEXT, generated_at TEXT)`); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`INSERT INTO snapshot VALUES (?, ?)`, data.PrimaryCluster, at.UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	v := reflect.ValueOf(data).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Type.Kind() != reflect.Slice || f.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		table := snakeCase(f.Name)
+		n, err := writeTable(tx, table, v.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table, err)
+		}
+		counts[table] = n
+	}
+	return counts, tx.Commit()
+}
+
+// writeTable creates table with a column per field of rows' element type and
+// inserts rows into it.
+func writeTable(tx *sql.Tx, table string, rows reflect.Value) (int, error) {
+	elem := rows.Type().Elem()
+	var fields []int
+	var cols, marks []string
+	for i := 0; i < elem.NumField(); i++ {
+		f := elem.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fields = append(fields, i)
+		cols = append(cols, fmt.Sprintf("%q %s", snakeCase(f.Name), columnType(f.Type)))
+		marks = append(marks, "?")
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %q (%s)", table, strings.Join(cols, ", "))); err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %q VALUES (%s)", table, strings.Join(marks, ", ")))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	args := make([]any, len(fields))
+	for r := 0; r < rows.Len(); r++ {
+		row := rows.Index(r)
+		for j, i := range fields {
+			if args[j], err = columnValue(row.Field(i)); err != nil {
+				return r, fmt.Errorf("%s: %w", elem.Field(i).Name, err)
+			}
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return r, err
+		}
+	}
+	return rows.Len(), nil
+}
+
+// columnType returns the SQLite type a field of type t is stored as.
+func columnType(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	}
+	return "TEXT"
+}
+
+// columnValue converts a field to the value stored for it: scalars as
+// themselves, times as RFC 3339, anything else as JSON. Nil pointers, slices
+// and maps and zero times are NULL.
+func columnValue(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.Kind() == reflect.Pointer && v.Elem().Kind() != reflect.Struct {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil, nil
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// snakeCase converts a Go field name to snake_case, keeping acronyms whole:
+// "HelmReleases" → "helm_releases", "HTTPRoutes" → "http_routes", "CRDs" →
+// "crds", "ImageID" → "image_id".
+func snakeCase(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) {
+			prevLower := unicode.IsLower(r[i-1]) || unicode.IsDigit(r[i-1])
+			// An acronym ends before an upper case letter starting a word,
+			// but a trailing "s" pluralizes the acronym.
+			nextLower := i+1 < len(r) && unicode.IsLower(r[i+1]) && !(r[i+1] == 's' && i+2 == len(r))
+			if prevLower || (unicode.IsUpper(r[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestWrite(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	backup := time.Date(2026, 2, 28, 23, 30, 0, 0, time.UTC)
+	data := &model.ClusterData{
+		PrimaryCluster: "Homelab",
+		Nodes: []model.NodeInfo{
+			{Name: "cp-1", Cluster: "Homelab", Roles: []string{"control-plane", "etcd"}, Labels: map[string]string{"zone": "a"}, PodCapacity: 110, Ready: true},
+			{Name: "worker-1", Cluster: "Homelab", PodCapacity: 250},
+		},
+		HelmReleases: []model.HelmReleaseInfo{
+			{Name: "grafana", Namespace: "monitoring", Cluster: "Homelab", ChartName: "grafana", Version: "8.5.1", UpgradeFailures: 3, Thrashing: true},
+		},
+		Pods: []model.PodImageInfo{
+			{Cluster: "Homelab", Namespace: "monitoring", PodName: "grafana-0", Container: "grafana", Image: "grafana/grafana:11.3.0"},
+			{Cluster: "Homelab", Namespace: "monitoring", PodName: "grafana-0", Container: "init", Image: "busybox:1.36", InitContainer: true},
+		},
+		Namespaces: []model.NamespaceInfo{
+			{Name: "monitoring", Cluster: "Homelab", LastBackup: backup},
+			{Name: "scratch", Cluster: "Homelab"},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	counts, err := Write(path, data, at)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for table, want := range map[string]int{"nodes": 2, "helm_releases": 1, "pods": 2, "namespaces": 2} {
+		if counts[table] != want {
+			t.Errorf("counts[%s] = %d, want %d", table, counts[table], want)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var generatedAt string
+	if err := db.QueryRow(`SELECT generated_at FROM snapshot`).Scan(&generatedAt); err != nil || generatedAt != "2026-03-01T12:00:00Z" {
+		t.Errorf("generated_at = %q (%v), want 2026-03-01T12:00:00Z", generatedAt, err)
+	}
+
+	// Scalars keep their type; slices and maps are JSON.
+	var roles, labels string
+	var capacity int
+	var ready bool
+	if err := db.QueryRow(`SELECT roles, labels, pod_capacity, ready FROM nodes WHERE name = 'cp-1'`).Scan(&roles, &labels, &capacity, &ready); err != nil {
+		t.Fatalf("reading cp-1: %v", err)
+	}
+	if roles != `["control-plane","etcd"]` || labels != `{"zone":"a"}` || capacity != 110 || !ready {
+		t.Errorf("cp-1 = roles %s, labels %s, capacity %d, ready %v", roles, labels, capacity, ready)
+	}
+	var nilRoles sql.NullString
+	if err := db.QueryRow(`SELECT roles FROM nodes WHERE name = 'worker-1'`).Scan(&nilRoles); err != nil || nilRoles.Valid {
+		t.Errorf("worker-1 roles = %v (%v), want NULL for a nil slice", nilRoles, err)
+	}
+
+	var version string
+	var failures int
+	var thrashing bool
+	if err := db.QueryRow(`SELECT version, upgrade_failures, thrashing FROM helm_releases WHERE name = 'grafana'`).Scan(&version, &failures, &thrashing); err != nil {
+		t.Fatalf("reading grafana release: %v", err)
+	}
+	if version != "8.5.1" || failures != 3 || !thrashing {
+		t.Errorf("grafana release = version %s, upgrade failures %d, thrashing %v", version, failures, thrashing)
+	}
+
+	var image string
+	if err := db.QueryRow(`SELECT image FROM pods WHERE init_container`).Scan(&image); err != nil || image != "busybox:1.36" {
+		t.Errorf("init container image = %q (%v), want busybox:1.36", image, err)
+	}
+
+	// Times are RFC 3339; zero times are NULL.
+	lastBackup := map[string]sql.NullString{}
+	rows, err := db.Query(`SELECT name, last_backup FROM namespaces`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var v sql.NullString
+		if err := rows.Scan(&name, &v); err != nil {
+			t.Fatal(err)
+		}
+		lastBackup[name] = v
+	}
+	if v := lastBackup["monitoring"]; v.String != "2026-02-28T23:30:00Z" {
+		t.Errorf("monitoring last_backup = %v, want 2026-02-28T23:30:00Z", v)
+	}
+	if v := lastBackup["scratch"]; v.Valid {
+		t.Errorf("scratch last_backup = %v, want NULL", v)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"HelmReleases":   "helm_releases",
+		"HTTPRoutes":     "http_routes",
+		"CRDs":           "crds",
+		"CAPIClusters":   "capi_clusters",
+		"ImageID":        "image_id",
+		"KEVCVEs":        "kevcves",
+		"MTLS":           "mtls",
+		"PrimaryCluster": "primary_cluster",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
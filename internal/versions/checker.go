@@ -1,19 +1,24 @@
 package versions
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fredericrous/cluster-vision/internal/model"
 
+	mmsemver "github.com/Masterminds/semver/v3"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,17 +26,61 @@ import (
 type Checker struct {
 	mu            sync.RWMutex
 	latest        map[string]string // "repoURL/chartName" → latest version
-	tokenCache    map[string]string // host → bearer token (for paginated requests)
+	failures      map[string]string // "repoURL/chartName" → error of the last check
+	grace         graceWindow       // see SetGrace
+	maxTags       int               // see SetMaxTags
+	tokens        *lruCache[string] // tag list URL → bearer token, until it expires
+	tags          *TagCache         // see SetTagCache
+	ociLayouts    map[string]string // "repoURL/chartName" → OCI image path that listed tags
 	interval      time.Duration
-	registryProxy string // e.g. "192.168.1.43:5000" — if set, OCI URLs through this host are resolved to upstream
+	lastCheck     time.Time
+	checking      atomic.Bool
+	registryProxy string      // e.g. "192.168.1.43:5000" — if set, OCI URLs through this host are resolved to upstream
+	proxyHealth   ProxyHealth // see ProbeRegistryProxy
 	client        *http.Client
 }
 
-// NewChecker creates a version checker with the given check interval.
+// CheckCounts summarizes an on-demand check: how many items (image repos,
+// distros and kubelet minors) were looked up, and how many resolved.
+type CheckCounts struct {
+	Checked  int `json:"checked"`
+	Resolved int `json:"resolved"`
+}
+
+// ChartCheck is the outcome of one chart's latest-version lookup: the
+// version found, or why none could be.
+type ChartCheck struct {
+	RepoURL string `json:"repoUrl"`
+	Chart   string `json:"chart"`
+	Latest  string `json:"latest,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CheckResult reports a Check chart by chart, so a lookup that failed can
+// be told apart from a chart that is up to date.
+type CheckResult struct {
+	At     time.Time    `json:"at"`
+	Charts []ChartCheck `json:"charts"`
+}
+
+// Failed returns how many charts could not be checked.
+func (r CheckResult) Failed() int {
+	n := 0
+	for _, ch := range r.Charts {
+		if ch.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// NewChecker creates a version checker with the given check interval; a
+// zero interval checks on every call.
 // registryProxy is the host:port of a local OCI proxy (e.g. Zot); empty disables proxy resolution.
 func NewChecker(interval time.Duration, registryProxy string) *Checker {
 	return &Checker{
 		latest:        make(map[string]string),
+		tokens:        newLRUCache[string](tokenCacheSize, tokenTTL),
 		interval:      interval,
 		registryProxy: registryProxy,
 		client: &http.Client{
@@ -40,8 +89,38 @@ func NewChecker(interval time.Duration, registryProxy string) *Checker {
 	}
 }
 
-// Check fetches latest versions for all unique repo+chart combinations.
-func (c *Checker) Check(repos []model.HelmRepositoryInfo, releases []model.HelmReleaseInfo) {
+// Check fetches latest versions for all unique repo+chart combinations and
+// reports the outcome for each.
+// Single-flight: returns immediately if already checking.
+// Interval gate: skips if the last check finished less than interval ago.
+// A skipped check returns the zero CheckResult.
+func (c *Checker) Check(repos []model.HelmRepositoryInfo, releases []model.HelmReleaseInfo) CheckResult {
+	res, _ := c.check(repos, releases, false)
+	return res
+}
+
+// ForceCheck is Check without the interval gate, for on-demand checks: OCI
+// tags are listed afresh rather than taken from the tag cache. It reports
+// false, without checking, if a check is already running.
+func (c *Checker) ForceCheck(repos []model.HelmRepositoryInfo, releases []model.HelmReleaseInfo) (CheckResult, bool) {
+	return c.check(repos, releases, true)
+}
+
+// check runs Check; force skips the interval gate. ok is false when another
+// check is running.
+func (c *Checker) check(repos []model.HelmRepositoryInfo, releases []model.HelmReleaseInfo, force bool) (res CheckResult, ok bool) {
+	if !c.checking.CompareAndSwap(false, true) {
+		return CheckResult{}, false
+	}
+	defer c.checking.Store(false)
+
+	c.mu.RLock()
+	tooSoon := time.Since(c.lastCheck) < c.interval
+	c.mu.RUnlock()
+	if tooSoon && !force {
+		return CheckResult{}, true
+	}
+
 	// Build repo lookup: "namespace/name" → HelmRepositoryInfo
 	repoByKey := make(map[string]model.HelmRepositoryInfo)
 	for _, r := range repos {
@@ -56,6 +135,8 @@ func (c *Checker) Check(repos []model.HelmRepositoryInfo, releases []model.HelmR
 	}
 	seen := make(map[string]bool)
 	var checks []chartRef
+	// Version ranges releases resolve the chart from, per "repoURL/chartName"
+	constraints := make(map[string]map[string]bool)
 
 	for _, rel := range releases {
 		repo, ok := repoByKey[rel.RepoNS+"/"+rel.RepoName]
@@ -64,6 +145,12 @@ func (c *Checker) Check(repos []model.HelmRepositoryInfo, releases []model.HelmR
 		}
 
 		key := repo.URL + "/" + rel.ChartName
+		if rangeConstraint(rel.VersionConstraint) {
+			if constraints[key] == nil {
+				constraints[key] = make(map[string]bool)
+			}
+			constraints[key][rel.VersionConstraint] = true
+		}
 		if seen[key] {
 			continue
 		}
@@ -76,39 +163,79 @@ func (c *Checker) Check(repos []model.HelmRepositoryInfo, releases []model.HelmR
 	}
 
 	results := make(map[string]string)
+	failures := make(map[string]string)
+	var report CheckResult
 
 	for _, ch := range checks {
 		key := ch.repoURL + "/" + ch.chartName
 
-		var version string
+		var available []string
 		var err error
 
 		if ch.repoType == "oci" {
-			version, err = c.checkOCI(ch.repoURL, ch.chartName)
+			available, err = c.ociVersions(ch.repoURL, ch.chartName, force)
 		} else {
-			version, err = c.checkHTTP(ch.repoURL, ch.chartName)
+			available, err = c.httpVersions(ch.repoURL, ch.chartName)
 		}
 
 		if err != nil {
 			slog.Warn("version check failed", "repo", ch.repoURL, "chart", ch.chartName, "error", err)
+			failures[key] = err.Error()
+			report.Charts = append(report.Charts, ChartCheck{RepoURL: ch.repoURL, Chart: ch.chartName, Error: err.Error()})
 			continue
 		}
 
+		version := highestStableSemver(available)
 		if version != "" {
 			results[key] = version
 		}
+		for constraint := range constraints[key] {
+			if v := highestMatching(available, constraint); v != "" {
+				results[constraintKey(key, constraint)] = v
+			}
+		}
+		report.Charts = append(report.Charts, ChartCheck{RepoURL: ch.repoURL, Chart: ch.chartName, Latest: version})
 
 		// Rate limit: max 1 request/second
 		time.Sleep(time.Second)
 	}
 
+	deployed := make(map[string]bool)
+	for _, ch := range checks {
+		key := ch.repoURL + "/" + ch.chartName
+		deployed[key] = true
+		for constraint := range constraints[key] {
+			deployed[constraintKey(key, constraint)] = true
+		}
+	}
+
 	c.mu.Lock()
 	for k, v := range results {
 		c.latest[k] = v
+		c.grace.observe(k, v)
 	}
+	c.grace.retain(deployed)
+	c.failures = failures
+	c.lastCheck = time.Now()
+	report.At = c.lastCheck
+	c.mu.Unlock()
+
+	slog.Info("version check complete", "checked", len(checks), "resolved", len(results), "failed", len(failures))
+	return report, true
+}
+
+// SetRegistryProxy replaces the proxy host used by resolveUpstream.
+func (c *Checker) SetRegistryProxy(registryProxy string) {
+	c.mu.Lock()
+	c.registryProxy = registryProxy
 	c.mu.Unlock()
+}
 
-	slog.Info("version check complete", "checked", len(checks), "resolved", len(results))
+// RegistryProxy returns the proxy host used by resolveUpstream.
+func (c *Checker) RegistryProxy() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.registryProxy
 }
 
 // GetLatest returns the latest known version for a repo+chart combination.
@@ -118,21 +245,99 @@ func (c *Checker) GetLatest(repoURL, chartName string) string {
 	return c.latest[repoURL+"/"+chartName]
 }
 
-// resolveUpstream converts a proxy OCI URL to the upstream registry.
+// GetLatestMatching returns the latest known version of a repo+chart
+// combination within a HelmRelease's version constraint, e.g. the newest
+// 1.x for ">=1.2.0 <2.0.0". An empty or "*" constraint is GetLatest.
+func (c *Checker) GetLatestMatching(repoURL, chartName, constraint string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.latest[constraintKey(repoURL+"/"+chartName, constraint)]
+}
+
+// SetMaxTags caps the tags fetched per chart or image: pagination stops
+// once n tags are gathered, bounding memory and CPU on repos with tens of
+// thousands of tags. The tradeoff: registries page tags in their own order
+// (lexical for most, push order for some), so tags past the cap are never
+// compared and the latest version can be under-reported. Set it above the
+// tag count of the repos you track, or to zero for no cap.
+func (c *Checker) SetMaxTags(n int) {
+	c.mu.Lock()
+	c.maxTags = n
+	c.mu.Unlock()
+}
+
+// SetTagCache shares a cache of tag listings with the checker (and
+// typically an ImageChecker), so an OCI chart listed within the cache's TTL
+// isn't listed again. Nil disables caching.
+func (c *Checker) SetTagCache(tc *TagCache) {
+	c.mu.Lock()
+	c.tags = tc
+	c.mu.Unlock()
+}
+
+// SetGrace sets how long a new latest version must stay the latest before
+// Settled reports it, so releases aren't flagged outdated the moment
+// upstream publishes. Zero disables the grace window.
+func (c *Checker) SetGrace(d time.Duration) {
+	c.mu.Lock()
+	c.grace.window = d
+	c.mu.Unlock()
+}
+
+// Settled reports whether the latest version of a repo+chart combination
+// has been the latest for at least the grace window.
+func (c *Checker) Settled(repoURL, chartName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.grace.settled(repoURL + "/" + chartName)
+}
+
+// SettledMatching is Settled for the latest version within constraint (see
+// GetLatestMatching).
+func (c *Checker) SettledMatching(repoURL, chartName, constraint string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.grace.settled(constraintKey(repoURL+"/"+chartName, constraint))
+}
+
+// CheckError returns why the last check of a repo+chart combination failed,
+// or "" if it succeeded or wasn't checked.
+func (c *Checker) CheckError(repoURL, chartName string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.failures[repoURL+"/"+chartName]
+}
+
+// resolveUpstream converts a proxy OCI URL to the upstream registry API host.
 // e.g. "oci://192.168.1.43:5000/ghcr.io/grafana/helm-charts" → ("ghcr.io", "grafana/helm-charts")
 // If not a proxy URL, returns the host and path as-is.
 func (c *Checker) resolveUpstream(repoURL string) (host, path string) {
-	addr := strings.TrimPrefix(repoURL, "oci://")
+	host, path = ResolveUpstream(c.RegistryProxy(), repoURL)
+
+	// docker.io → registry-1.docker.io
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+
+	return host, path
+}
+
+// ResolveUpstream splits a reference into host and path, undoing a pull
+// through proxy: when the host is proxy, the first path segment is the
+// upstream registry.
+// e.g. ("192.168.1.43:5000", "192.168.1.43:5000/ghcr.io/org/app") → ("ghcr.io", "org/app")
+// An "oci://" prefix is ignored. Other references come back as-is.
+func ResolveUpstream(proxy, ref string) (host, path string) {
+	addr := strings.TrimPrefix(ref, "oci://")
 	parts := strings.SplitN(addr, "/", 2)
 	host = parts[0]
 	if len(parts) > 1 {
 		path = parts[1]
 	}
 
-	// If the host matches our proxy, the first path segment is the upstream registry
-	if c.registryProxy != "" && host == c.registryProxy {
+	if proxy != "" && host == proxy {
 		pathParts := strings.SplitN(path, "/", 2)
-		if len(pathParts) >= 1 && strings.Contains(pathParts[0], ".") {
+		if strings.Contains(pathParts[0], ".") {
 			host = pathParts[0]
 			path = ""
 			if len(pathParts) > 1 {
@@ -140,52 +345,146 @@ func (c *Checker) resolveUpstream(repoURL string) (host, path string) {
 			}
 		}
 	}
+	return host, path
+}
 
-	// docker.io → registry-1.docker.io
-	if host == "docker.io" {
-		host = "registry-1.docker.io"
+// ociVersions lists the tags of a chart in an OCI registry.
+// The chart usually lives at "path/chartName", but some repositories point at
+// the chart itself; both layouts are tried and the one that lists tags is
+// remembered for the next check. fresh skips the tag cache.
+func (c *Checker) ociVersions(repoURL, chartName string, fresh bool) ([]string, error) {
+	host, path := c.resolveUpstream(repoURL)
+	key := repoURL + "/" + chartName
+
+	candidates := ociImagePaths(path, chartName)
+	c.mu.RLock()
+	known, ok := c.ociLayouts[key]
+	c.mu.RUnlock()
+	if ok {
+		candidates = append([]string{known}, slices.DeleteFunc(candidates, func(p string) bool { return p == known })...)
 	}
 
-	return host, path
+	var firstErr error
+	for _, imagePath := range candidates {
+		tags, err := c.listOCITags(host, imagePath, fresh)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		c.mu.Lock()
+		if c.ociLayouts == nil {
+			c.ociLayouts = make(map[string]string)
+		}
+		c.ociLayouts[key] = imagePath
+		c.mu.Unlock()
+		return tags, nil
+	}
+	return nil, firstErr
 }
 
-// checkOCI queries an OCI registry for the latest tag of a chart.
-// Follows pagination (Link headers) to collect all tags.
-func (c *Checker) checkOCI(repoURL, chartName string) (string, error) {
-	host, path := c.resolveUpstream(repoURL)
+// ociImagePaths returns the image paths a chart may live at under path.
+// When path already ends with the chart it is the artifact itself and is
+// tried first, so ".../charts/mychart" isn't looked up as
+// ".../charts/mychart/mychart" unless that 404s. Otherwise the chart is
+// appended, falling back to path itself for a Flux HelmRepository whose OCI
+// URL points straight at a chart published under another name.
+func ociImagePaths(path, chartName string) []string {
+	if path == "" {
+		return []string{chartName}
+	}
+	nested := path + "/" + chartName
+	if path == chartName || strings.HasSuffix(path, "/"+chartName) {
+		return []string{path, nested}
+	}
+	return []string{nested, path}
+}
 
-	imagePath := chartName
-	if path != "" {
-		imagePath = path + "/" + chartName
+// listOCITags lists every tag of an OCI image, following pagination (Link
+// headers). Unless fresh, a listing in the tag cache is reused.
+func (c *Checker) listOCITags(host, imagePath string, fresh bool) ([]string, error) {
+	c.mu.RLock()
+	maxTags, cache := c.maxTags, c.tags
+	c.mu.RUnlock()
+	if !fresh {
+		if tags, ok := cache.get(host, imagePath, maxTags); ok {
+			return tags, nil
+		}
 	}
 
 	var allTags []string
-	url := fmt.Sprintf("https://%s/v2/%s/tags/list?n=1000", host, imagePath)
+	url := fmt.Sprintf("https://%s/v2/%s/tags/list?n=%d", host, imagePath, tagPageSize(maxTags))
 
-	for url != "" {
+	for url != "" && !tagCapReached(allTags, maxTags) {
 		body, nextURL, err := c.fetchWithAuthPaginated(url)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		var tagList struct {
 			Tags []string `json:"tags"`
 		}
 		if err := json.Unmarshal(body, &tagList); err != nil {
-			return "", fmt.Errorf("parsing tags: %w", err)
+			return nil, fmt.Errorf("parsing tags: %w", err)
 		}
 
 		allTags = append(allTags, tagList.Tags...)
 		url = nextURL
 	}
 
-	return highestStableSemver(allTags), nil
+	allTags = capTags(allTags, maxTags)
+	cache.add(host, imagePath, maxTags, allTags)
+	return allTags, nil
+}
+
+// tagPageSize is the page size requested when listing tags: 1000, or the
+// tag cap when that is smaller.
+func tagPageSize(maxTags int) int {
+	if maxTags > 0 && maxTags < 1000 {
+		return maxTags
+	}
+	return 1000
+}
+
+// tagCapReached reports whether tags already holds maxTags tags; a zero
+// maxTags never caps.
+func tagCapReached(tags []string, maxTags int) bool {
+	return maxTags > 0 && len(tags) >= maxTags
 }
 
+// capTags trims tags to the first maxTags, for a registry that returned a
+// larger page than asked.
+func capTags(tags []string, maxTags int) []string {
+	if tagCapReached(tags, maxTags) {
+		return tags[:maxTags]
+	}
+	return tags
+}
+
+// Registry bearer tokens are reused for the pages and later checks of a repo
+// until tokenTTL, a little under the 5 minutes Docker Hub issues them for.
+const (
+	tokenTTL       = 4 * time.Minute
+	tokenCacheSize = 256
+)
+
 // fetchWithAuthPaginated performs an HTTP GET with OCI token auth, returning the body
-// and the next page URL (from Link header) if any.
+// and the next page URL (from Link header) if any. A token cached for the
+// repo is sent up front; one the registry rejects is replaced.
 func (c *Checker) fetchWithAuthPaginated(url string) (body []byte, nextURL string, err error) {
-	resp, err := c.client.Get(url)
+	repo, _, _ := strings.Cut(url, "?")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if token, ok := c.tokens.get(repo); ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("fetching %s: %w", url, err)
 	}
@@ -193,6 +492,7 @@ func (c *Checker) fetchWithAuthPaginated(url string) (body []byte, nextURL strin
 
 	// If 401, try token auth
 	if resp.StatusCode == http.StatusUnauthorized {
+		c.tokens.remove(repo)
 		challenge := resp.Header.Get("Www-Authenticate")
 		if challenge == "" {
 			return nil, "", fmt.Errorf("401 with no WWW-Authenticate header")
@@ -203,18 +503,8 @@ func (c *Checker) fetchWithAuthPaginated(url string) (body []byte, nextURL strin
 			return nil, "", fmt.Errorf("getting auth token: %w", err)
 		}
 
-		// Cache token for subsequent paginated requests
-		c.mu.Lock()
-		if c.tokenCache == nil {
-			c.tokenCache = make(map[string]string)
-		}
-		c.tokenCache[extractHost(url)] = token
-		c.mu.Unlock()
-
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, "", err
-		}
+		// Cache token for subsequent pages and checks of the repo
+		c.tokens.add(repo, token)
 		req.Header.Set("Authorization", "Bearer "+token)
 
 		resp2, err := c.client.Do(req)
@@ -224,7 +514,7 @@ func (c *Checker) fetchWithAuthPaginated(url string) (body []byte, nextURL strin
 		defer func() { _ = resp2.Body.Close() }()
 
 		if resp2.StatusCode != http.StatusOK {
-			return nil, "", fmt.Errorf("registry returned %d after auth", resp2.StatusCode)
+			return nil, "", registryStatusError(resp2, " after auth")
 		}
 
 		b, err := io.ReadAll(io.LimitReader(resp2.Body, 1<<20))
@@ -232,14 +522,43 @@ func (c *Checker) fetchWithAuthPaginated(url string) (body []byte, nextURL strin
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("registry returned %d", resp.StatusCode)
+		return nil, "", registryStatusError(resp, "")
 	}
 
 	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
 	return b, parseLinkNext(resp.Header.Get("Link"), url), err
 }
 
-// extractHost returns the scheme+host portion of a URL for token cache keying.
+// ErrTagListUnsupported means a registry refuses to list tags, as some ECR
+// and GAR setups do: the latest version can't be known from the registry.
+var ErrTagListUnsupported = errors.New("registry does not support tag listing")
+
+// registryStatusError describes a non-200 registry response, wrapping
+// ErrTagListUnsupported for a 405 or an UNSUPPORTED error code (OCI
+// distribution spec). context is appended to the status, e.g. " after auth".
+func registryStatusError(resp *http.Response, context string) error {
+	unsupported := resp.StatusCode == http.StatusMethodNotAllowed
+	if !unsupported {
+		var body struct {
+			Errors []struct {
+				Code string `json:"code"`
+			} `json:"errors"`
+		}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(b, &body) == nil {
+			for _, e := range body.Errors {
+				unsupported = unsupported || e.Code == "UNSUPPORTED"
+			}
+		}
+	}
+	if unsupported {
+		return fmt.Errorf("registry returned %d%s: %w", resp.StatusCode, context, ErrTagListUnsupported)
+	}
+	return fmt.Errorf("registry returned %d%s", resp.StatusCode, context)
+}
+
+// extractHost returns the scheme+host portion of a URL, for resolving
+// relative Link headers.
 func extractHost(rawURL string) string {
 	if idx := strings.Index(rawURL, "//"); idx >= 0 {
 		rest := rawURL[idx+2:]
@@ -344,33 +663,34 @@ func parseAuthParams(s string) map[string]string {
 	return params
 }
 
-// checkHTTP fetches a Helm HTTP repo's index.yaml and finds the latest chart version.
-func (c *Checker) checkHTTP(repoURL, chartName string) (string, error) {
+// httpVersions fetches a Helm HTTP repo's index.yaml and lists the chart's
+// versions.
+func (c *Checker) httpVersions(repoURL, chartName string) ([]string, error) {
 	url := strings.TrimRight(repoURL, "/") + "/index.yaml"
 
 	resp, err := c.client.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("fetching index: %w", err)
+		return nil, fmt.Errorf("fetching index: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("index returned %d", resp.StatusCode)
+		return nil, fmt.Errorf("index returned %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20)) // 10MB limit
 	if err != nil {
-		return "", fmt.Errorf("reading index: %w", err)
+		return nil, fmt.Errorf("reading index: %w", err)
 	}
 
 	var index helmIndex
 	if err := yaml.Unmarshal(body, &index); err != nil {
-		return "", fmt.Errorf("parsing index: %w", err)
+		return nil, fmt.Errorf("parsing index: %w", err)
 	}
 
 	entries, ok := index.Entries[chartName]
 	if !ok || len(entries) == 0 {
-		return "", fmt.Errorf("chart %q not found in index", chartName)
+		return nil, fmt.Errorf("chart %q not found in index", chartName)
 	}
 
 	var versions []string
@@ -380,7 +700,7 @@ func (c *Checker) checkHTTP(repoURL, chartName string) (string, error) {
 		}
 	}
 
-	return highestStableSemver(versions), nil
+	return versions, nil
 }
 
 type helmIndex struct {
@@ -392,6 +712,9 @@ type helmEntry struct {
 }
 
 // highestStableSemver returns the highest stable (non-pre-release) semantic version.
+// Two-component versions count as patch zero, so "1.2" and "1.2.0" tie; ties
+// go to the more specific spelling (see preferOriginal) so the result does
+// not depend on input order.
 func highestStableSemver(versions []string) string {
 	var semvers []semver
 	for _, v := range versions {
@@ -407,24 +730,114 @@ func highestStableSemver(versions []string) string {
 	}
 
 	sort.Slice(semvers, func(i, j int) bool {
-		return semvers[j].less(semvers[i]) // descending
+		if semvers[i].less(semvers[j]) || semvers[j].less(semvers[i]) {
+			return semvers[j].less(semvers[i]) // descending
+		}
+		return preferOriginal(semvers[i], semvers[j])
 	})
 
 	return semvers[0].original
 }
 
+// rangeConstraint reports whether a HelmRelease version constraint is a
+// range with a latest of its own: "" is an exact pin, and "*" resolves to
+// the overall latest.
+func rangeConstraint(constraint string) bool {
+	return constraint != "" && constraint != "*"
+}
+
+// constraintKey extends a "repoURL/chartName" key with a range constraint,
+// keying the latest version within it.
+func constraintKey(key, constraint string) string {
+	if !rangeConstraint(constraint) {
+		return key
+	}
+	return key + "@" + constraint
+}
+
+// highestMatching returns the highest stable version satisfying a Helm
+// (Masterminds semver) constraint such as ">=1.2.0 <2.0.0" or "~1.4", as
+// Flux resolves it, or "" when none does or the constraint doesn't parse.
+func highestMatching(versions []string, constraint string) string {
+	cons, err := mmsemver.NewConstraint(constraint)
+	if err != nil {
+		return ""
+	}
+	var matching []string
+	for _, v := range versions {
+		if sv, err := mmsemver.NewVersion(v); err == nil && cons.Check(sv) {
+			matching = append(matching, v)
+		}
+	}
+	return highestStableSemver(matching)
+}
+
+// preferOriginal breaks a tie between two equal versions: the canonical
+// three-part form wins over two parts ("1.2.0" over "1.2"), then the longer
+// original ("v1.2.0" over "1.2.0"), then the lexically smaller one.
+func preferOriginal(a, b semver) bool {
+	if a.hasPatch != b.hasPatch {
+		return a.hasPatch
+	}
+	if len(a.original) != len(b.original) {
+		return len(a.original) > len(b.original)
+	}
+	return a.original < b.original
+}
+
+// IsOutdated reports whether latest is ahead of current. Semver versions are
+// compared by precedence, so a pre-release running ahead of the latest
+// stable ("1.3.0-rc.1" vs "1.2.0") or a different spelling of the same
+// version ("v1.2" vs "1.2.0") is not outdated. Anything else falls back to
+// plain inequality.
+func IsOutdated(current, latest string) bool {
+	if current == "" || latest == "" || current == latest {
+		return false
+	}
+	cur, curOK := parseSemver(current)
+	lat, latOK := parseSemver(latest)
+	if curOK && latOK {
+		return cur.less(lat)
+	}
+	return true
+}
+
+// UpdateType classifies the gap between a deployed and a latest version as
+// "major", "minor" or "patch". It returns "" when either side isn't semver
+// or latest is not ahead of current.
+func UpdateType(current, latest string) string {
+	cur, ok := parseSemver(current)
+	if !ok {
+		return ""
+	}
+	lat, ok := parseSemver(latest)
+	if !ok || !cur.less(lat) {
+		return ""
+	}
+	switch {
+	case lat.major != cur.major:
+		return "major"
+	case lat.minor != cur.minor:
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
 type semver struct {
 	major, minor, patch int
-	pre                 string
+	pre                 string // pre-release with its leading "-"; build metadata is dropped
 	original            string
+	hasPatch            bool // false for two-component versions like "1.2"
 }
 
 func parseSemver(s string) (semver, bool) {
 	v := semver{original: s}
 	s = strings.TrimPrefix(s, "v")
 
-	// Split off pre-release
-	if idx := strings.IndexAny(s, "-+"); idx >= 0 {
+	// Build metadata has no bearing on precedence; then split off pre-release
+	s, _, _ = strings.Cut(s, "+")
+	if idx := strings.IndexByte(s, '-'); idx >= 0 {
 		v.pre = s[idx:]
 		s = s[:idx]
 	}
@@ -444,6 +857,7 @@ func parseSemver(s string) (semver, bool) {
 		return semver{}, false
 	}
 	if len(parts) == 3 {
+		v.hasPatch = true
 		v.patch, err = strconv.Atoi(parts[2])
 		if err != nil {
 			return semver{}, false
@@ -470,5 +884,33 @@ func (a semver) less(b semver) bool {
 	if a.pre == "" && b.pre != "" {
 		return false
 	}
-	return a.pre < b.pre
+	return comparePre(a.pre, b.pre) < 0
+}
+
+// comparePre orders two pre-releases ("-rc.9", "-rc.10") by semver 2.0
+// precedence: dot-separated identifiers left to right, numeric ones
+// compared as numbers and ranked below alphanumeric ones, and a shorter
+// list ranked below a longer one it prefixes.
+func comparePre(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "-"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "-"), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := cmp.Compare(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
 }
@@ -1,8 +1,15 @@
 package versions
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
 )
 
 func TestHighestStableSemver(t *testing.T) {
@@ -21,6 +28,10 @@ func TestHighestStableSemver(t *testing.T) {
 		{"non-semver ignored", []string{"latest", "main", "1.0.0"}, "1.0.0"},
 		{"mixed", []string{"0.1.0", "0.2.0", "0.1.5"}, "0.2.0"},
 		{"two part", []string{"1.0", "2.0", "1.5"}, "2.0"},
+		{"two part ties three part", []string{"1.2", "1.2.0"}, "1.2.0"},
+		{"two part ties three part reversed", []string{"1.2.0", "1.2"}, "1.2.0"},
+		{"v prefix preferred on tie", []string{"1.2.0", "v1.2.0", "1.2"}, "v1.2.0"},
+		{"higher two part still wins", []string{"1.2.0", "1.3"}, "1.3"},
 	}
 
 	for _, tt := range tests {
@@ -45,7 +56,8 @@ func TestParseSemver(t *testing.T) {
 		{"1.2.3", true, 1, 2, 3, ""},
 		{"v1.2.3", true, 1, 2, 3, ""},
 		{"1.2.3-rc1", true, 1, 2, 3, "-rc1"},
-		{"1.2.3+build", true, 1, 2, 3, "+build"},
+		{"1.2.3+build", true, 1, 2, 3, ""},
+		{"1.2.3-rc.1+build.5", true, 1, 2, 3, "-rc.1"},
 		{"1.2", true, 1, 2, 0, ""},
 		{"latest", false, 0, 0, 0, ""},
 		{"1", false, 0, 0, 0, ""},
@@ -200,3 +212,356 @@ func TestSemverLess(t *testing.T) {
 		})
 	}
 }
+
+func TestIsOutdated(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"1.2.0", "1.3.0", true},
+		{"1.2.0", "1.2.0", false},
+		{"1.3.0-rc.1", "1.2.0", false}, // pre-release running ahead of the latest stable
+		{"1.2.0-rc.1", "1.2.0", true},  // its release is out
+		{"1.2.0-rc.1", "1.1.9", false},
+		{"1.0.0-rc.9", "1.0.0-rc.10", true}, // numeric identifiers compare as numbers
+		{"1.0.0-rc.10", "1.0.0-rc.9", false},
+		{"1.0.0-alpha", "1.0.0-alpha.1", true},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", true},
+		{"1.0.0", "1.0.0+build.1", false}, // build metadata doesn't count
+		{"1.0.0+build.1", "1.0.0", false},
+		{"v1.2", "1.2.0", false}, // same version, different spelling
+		{"2.0.0", "1.9.9", false},
+		{"latest", "1.2.3", true}, // not semver: plain inequality
+		{"1.2.3", "", false},
+		{"", "1.2.3", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"_to_"+tt.latest, func(t *testing.T) {
+			if got := IsOutdated(tt.current, tt.latest); got != tt.want {
+				t.Errorf("IsOutdated(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateType(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            string
+	}{
+		{"1.2.3", "2.0.0", "major"},
+		{"1.2.3", "1.3.0", "minor"},
+		{"1.2.3", "1.2.4", "patch"},
+		{"v1.2", "1.2.1", "patch"},
+		{"1.2.3-rc.1", "1.2.3", "patch"},
+		{"1.2.3", "1.2.3", ""},
+		{"2.0.0", "1.9.9", ""}, // deployed ahead of the repo
+		{"latest", "1.2.3", ""},
+		{"1.2.3", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"_to_"+tt.latest, func(t *testing.T) {
+			if got := UpdateType(tt.current, tt.latest); got != tt.want {
+				t.Errorf("UpdateType(%q, %q) = %q, want %q", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckerIntervalGate(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: 1.2.0\n"))
+	}))
+	defer srv.Close()
+
+	repos := []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", URL: srv.URL}}
+	releases := []model.HelmReleaseInfo{{Name: "app", ChartName: "app", RepoName: "charts", RepoNS: "flux-system"}}
+
+	c := NewChecker(time.Hour, "")
+	c.Check(repos, releases)
+	if got := c.GetLatest(srv.URL, "app"); got != "1.2.0" {
+		t.Fatalf("latest = %q, want 1.2.0", got)
+	}
+	c.Check(repos, releases)
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("index fetched %d times, want 1: second check within the interval must be a no-op", got)
+	}
+
+	c = NewChecker(0, "")
+	c.Check(repos, releases)
+	c.Check(repos, releases)
+	if got := fetches.Load(); got != 3 {
+		t.Errorf("index fetched %d times, want 3 with the gate disabled", got)
+	}
+}
+
+func TestOCIVersionsRootLayoutFallback(t *testing.T) {
+	var requests []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/v2/charts/podinfo/tags/list" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"charts/podinfo","tags":["6.5.0","6.7.1","6.8.0-rc.1"]}`))
+	}))
+	defer srv.Close()
+
+	c := NewChecker(0, "")
+	c.client = srv.Client()
+	repoURL := "oci://" + srv.Listener.Addr().String() + "/charts/podinfo"
+
+	tags, err := c.ociVersions(repoURL, "podinfo", false)
+	if err != nil {
+		t.Fatalf("ociVersions: %v", err)
+	}
+	if got := highestStableSemver(tags); got != "6.7.1" {
+		t.Errorf("latest = %q, want 6.7.1", got)
+	}
+
+	// The root layout is remembered: the next check goes straight to it.
+	requests = nil
+	if _, err := c.ociVersions(repoURL, "podinfo", false); err != nil {
+		t.Fatalf("second ociVersions: %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "/v2/charts/podinfo/tags/list" {
+		t.Errorf("second check requested %v, want only the cached root layout", requests)
+	}
+}
+
+func TestOCIVersionsChartNamedRepoPath(t *testing.T) {
+	var requests []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/v2/foo/charts/mychart/tags/list":
+			_, _ = w.Write([]byte(`{"name":"foo/charts/mychart","tags":["1.0.0","1.2.0"]}`))
+		case "/v2/foo/charts/mychart-oci/tags/list":
+			_, _ = w.Write([]byte(`{"name":"foo/charts/mychart-oci","tags":["2.0.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewChecker(0, "")
+	c.client = srv.Client()
+	host := srv.Listener.Addr().String()
+
+	// The repo URL already ends with the chart: one lookup, not .../mychart/mychart.
+	tags, err := c.ociVersions("oci://"+host+"/foo/charts/mychart", "mychart", false)
+	if err != nil {
+		t.Fatalf("ociVersions: %v", err)
+	}
+	if got := highestStableSemver(tags); got != "1.2.0" {
+		t.Errorf("latest = %q, want 1.2.0", got)
+	}
+	if len(requests) != 1 || requests[0] != "/v2/foo/charts/mychart/tags/list" {
+		t.Errorf("requested %v, want only /v2/foo/charts/mychart/tags/list", requests)
+	}
+
+	// A repo URL pointing at a chart published under another name.
+	tags, err = c.ociVersions("oci://"+host+"/foo/charts/mychart-oci", "mychart", false)
+	if err != nil {
+		t.Fatalf("ociVersions with a differently named artifact: %v", err)
+	}
+	if got := highestStableSemver(tags); got != "2.0.0" {
+		t.Errorf("latest = %q, want 2.0.0 from the repo URL itself", got)
+	}
+}
+
+func TestCheckerTokenExpires(t *testing.T) {
+	var tokens atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			tokens.Add(1)
+			_, _ = w.Write([]byte(`{"token":"t"}`))
+		case r.Header.Get("Authorization") != "Bearer t":
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+srv.URL+`/token",scope="repository:charts/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(`{"tags":["1.0.0"]}`))
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	c := NewChecker(0, "")
+	c.client = srv.Client()
+	c.tokens.now = func() time.Time { return now }
+	host := srv.Listener.Addr().String()
+
+	for range 2 {
+		if _, err := c.listOCITags(host, "charts/app", false); err != nil {
+			t.Fatalf("listOCITags: %v", err)
+		}
+	}
+	if got := tokens.Load(); got != 1 {
+		t.Errorf("fetched %d tokens, want 1 reused within its TTL", got)
+	}
+
+	now = now.Add(tokenTTL)
+	if _, err := c.listOCITags(host, "charts/app", false); err != nil {
+		t.Fatalf("listOCITags after expiry: %v", err)
+	}
+	if got := tokens.Load(); got != 2 {
+		t.Errorf("fetched %d tokens, want a new one after the TTL", got)
+	}
+}
+
+func TestListOCITagsFreshBypassesTagCache(t *testing.T) {
+	var lists atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lists.Add(1)
+		_, _ = w.Write([]byte(`{"tags":["1.0.0"]}`))
+	}))
+	defer srv.Close()
+
+	c := NewChecker(0, "")
+	c.client = srv.Client()
+	c.SetTagCache(NewTagCache(10, time.Hour))
+	host := srv.Listener.Addr().String()
+
+	for _, fresh := range []bool{false, false, true} {
+		if _, err := c.listOCITags(host, "charts/app", fresh); err != nil {
+			t.Fatalf("listOCITags: %v", err)
+		}
+	}
+	if got := lists.Load(); got != 2 {
+		t.Errorf("listed %d times, want the cache reused once and bypassed when fresh", got)
+	}
+}
+
+func TestCheckReportsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/good/index.yaml" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: 1.2.0\n"))
+	}))
+	defer srv.Close()
+
+	repos := []model.HelmRepositoryInfo{
+		{Name: "good", Namespace: "flux-system", URL: srv.URL + "/good"},
+		{Name: "bad", Namespace: "flux-system", URL: srv.URL + "/bad"},
+	}
+	releases := []model.HelmReleaseInfo{
+		{Name: "app", ChartName: "app", RepoName: "good", RepoNS: "flux-system"},
+		{Name: "db", ChartName: "db", RepoName: "bad", RepoNS: "flux-system"},
+	}
+
+	c := NewChecker(0, "")
+	res := c.Check(repos, releases)
+	if res.At.IsZero() {
+		t.Fatal("check result has no timestamp")
+	}
+	byChart := make(map[string]ChartCheck)
+	for _, ch := range res.Charts {
+		byChart[ch.Chart] = ch
+	}
+	if got := byChart["app"]; got.Latest != "1.2.0" || got.Error != "" {
+		t.Errorf("app = %+v, want latest 1.2.0 and no error", got)
+	}
+	if got := byChart["db"]; got.Latest != "" || !strings.Contains(got.Error, "500") {
+		t.Errorf("db = %+v, want an error mentioning the 500", got)
+	}
+	if got := res.Failed(); got != 1 {
+		t.Errorf("Failed() = %d, want 1", got)
+	}
+	if got := c.CheckError(srv.URL+"/bad", "db"); got == "" {
+		t.Error("CheckError for the failing chart is empty")
+	}
+	if got := c.CheckError(srv.URL+"/good", "app"); got != "" {
+		t.Errorf("CheckError for the resolved chart = %q, want empty", got)
+	}
+}
+
+func TestCheckerGraceWindow(t *testing.T) {
+	latest := "1.2.0"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: " + latest + "\n"))
+	}))
+	defer srv.Close()
+
+	repos := []model.HelmRepositoryInfo{{Name: "charts", Namespace: "flux-system", URL: srv.URL}}
+	releases := []model.HelmReleaseInfo{{Name: "app", ChartName: "app", RepoName: "charts", RepoNS: "flux-system"}}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := NewChecker(0, "")
+	c.grace.now = func() time.Time { return now }
+	c.SetGrace(24 * time.Hour)
+
+	c.Check(repos, releases)
+	if c.Settled(srv.URL, "app") {
+		t.Fatal("latest seen just now is settled, want it held back by the grace window")
+	}
+
+	// Seeing the same latest again keeps its first-seen time.
+	now = now.Add(23 * time.Hour)
+	c.Check(repos, releases)
+	if c.Settled(srv.URL, "app") {
+		t.Error("settled after 23h, want the 24h window to still apply")
+	}
+	now = now.Add(time.Hour)
+	if !c.Settled(srv.URL, "app") {
+		t.Error("not settled once the grace window passed")
+	}
+
+	// A newer upstream release restarts the window.
+	latest = "1.3.0"
+	c.Check(repos, releases)
+	if c.Settled(srv.URL, "app") {
+		t.Error("a newly published latest is settled, want the window restarted")
+	}
+
+	c.SetGrace(0)
+	if !c.Settled(srv.URL, "app") {
+		t.Error("not settled with the grace window disabled")
+	}
+
+	// A chart no longer deployed is forgotten.
+	c.Check(repos, nil)
+	if n := len(c.grace.seen); n != 0 {
+		t.Errorf("grace window tracks %d keys after the release went away, want 0", n)
+	}
+}
+
+func TestProbeRegistryProxy(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized) // auth required still means the proxy answers
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	downAddr := down.Listener.Addr().String()
+	down.Close()
+
+	c := NewChecker(0, up.Listener.Addr().String())
+	if h := c.ProbeRegistryProxy(context.Background()); !h.Reachable || h.Error != "" || h.CheckedAt.IsZero() {
+		t.Errorf("probe of a live proxy = %+v, want reachable", h)
+	}
+
+	c.SetRegistryProxy(downAddr)
+	h := c.ProbeRegistryProxy(context.Background())
+	if h.Reachable || h.Error == "" || h.Proxy != downAddr {
+		t.Errorf("probe of a proxy that is down = %+v, want unreachable with an error", h)
+	}
+	if got := c.RegistryProxyHealth(); got != h {
+		t.Errorf("RegistryProxyHealth = %+v, want the last probe %+v", got, h)
+	}
+
+	c.SetRegistryProxy("")
+	if h := c.ProbeRegistryProxy(context.Background()); h != (ProxyHealth{}) || c.RegistryProxyHealth() != (ProxyHealth{}) {
+		t.Errorf("probe without a proxy = %+v, want the zero ProxyHealth", h)
+	}
+}
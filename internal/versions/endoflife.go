@@ -0,0 +1,149 @@
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eolCycle is a single release cycle from the endoflife.date API.
+type eolCycle struct {
+	Cycle  string
+	Latest string
+	EOL    time.Time // zero if the cycle has no announced EOL date
+	Ended  bool      // true when the API reports eol=true without a date
+}
+
+// EOLStatus describes the end-of-life state of a node's running OS cycle.
+type EOLStatus struct {
+	Cycle   string // e.g. "22.04"
+	Date    string // EOL date as YYYY-MM-DD, empty if unknown
+	EOL     bool   // cycle is past its EOL date
+	EOLSoon bool   // cycle reaches EOL within the configured warning window
+}
+
+// fetchEOLCycles fetches the release cycles for a product from endoflife.date.
+// Cycles are returned in API order (newest first).
+func (nc *NodeChecker) fetchEOLCycles(product string) ([]eolCycle, error) {
+	url := fmt.Sprintf("%s/api/%s.json", strings.TrimRight(nc.eolBaseURL, "/"), product)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := nc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("endoflife.date returned %d for %s", resp.StatusCode, product)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseEOLCycles(body)
+}
+
+// parseEOLCycles decodes an endoflife.date product response. The API uses
+// loose typing: "cycle" may be a string or number and "eol" may be a
+// YYYY-MM-DD date or a boolean.
+func parseEOLCycles(body []byte) ([]eolCycle, error) {
+	var raw []struct {
+		Cycle  interface{} `json:"cycle"`
+		Latest interface{} `json:"latest"`
+		EOL    interface{} `json:"eol"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parsing cycles: %w", err)
+	}
+
+	cycles := make([]eolCycle, 0, len(raw))
+	for _, r := range raw {
+		c := eolCycle{
+			Cycle:  looseString(r.Cycle),
+			Latest: looseString(r.Latest),
+		}
+		if c.Cycle == "" {
+			continue
+		}
+		switch v := r.EOL.(type) {
+		case string:
+			if t, err := time.Parse("2006-01-02", v); err == nil {
+				c.EOL = t
+			}
+		case bool:
+			c.Ended = v
+		}
+		cycles = append(cycles, c)
+	}
+	return cycles, nil
+}
+
+// looseString renders a JSON string or number as a string.
+func looseString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return ""
+}
+
+// matchEOLCycle returns the cycle that the given version belongs to — the
+// longest cycle that equals the version or is a dotted prefix of it.
+func matchEOLCycle(cycles []eolCycle, version string) (eolCycle, bool) {
+	version = strings.TrimPrefix(version, "v")
+	var best eolCycle
+	found := false
+	for _, c := range cycles {
+		cycle := strings.TrimPrefix(c.Cycle, "v")
+		if version != cycle && !strings.HasPrefix(version, cycle+".") {
+			continue
+		}
+		if !found || len(cycle) > len(strings.TrimPrefix(best.Cycle, "v")) {
+			best = c
+			found = true
+		}
+	}
+	return best, found
+}
+
+// GetEOLStatus returns the end-of-life state of the OS cycle a node runs, as
+// of now. The zero value is returned for distros not resolved via endoflife.date.
+func (nc *NodeChecker) GetEOLStatus(osImage string, now time.Time) EOLStatus {
+	distro, ver := ParseOSImage(osImage)
+	if distro == "" || ver == "" {
+		return EOLStatus{}
+	}
+
+	nc.mu.RLock()
+	cycles := nc.eolCycles[distro]
+	nc.mu.RUnlock()
+
+	c, ok := matchEOLCycle(cycles, ver)
+	if !ok {
+		return EOLStatus{}
+	}
+
+	st := EOLStatus{Cycle: c.Cycle}
+	switch {
+	case !c.EOL.IsZero():
+		st.Date = c.EOL.Format("2006-01-02")
+		st.EOL = !now.Before(c.EOL)
+		st.EOLSoon = !st.EOL && c.EOL.Sub(now) <= nc.eolWarn
+	case c.Ended:
+		st.EOL = true
+	}
+	return st
+}
@@ -0,0 +1,62 @@
+package versions
+
+import "time"
+
+// graceWindow remembers when each latest version was first observed, so a
+// deployment is only flagged outdated once upstream's newer version has been
+// the latest for longer than the window. That keeps a release Renovate is
+// about to bump from showing as outdated the moment upstream publishes.
+// Entries only cover what is deployed now: the checker drops the others
+// after each pass (see retain). First-seen times live in memory only, so
+// after a restart every latest version starts its window again and stays
+// unflagged for up to one more window. The zero value has no window. It is
+// guarded by its checker's mutex.
+type graceWindow struct {
+	window time.Duration
+	now    func() time.Time // time.Now when nil; tests inject a clock
+	seen   map[string]firstSeen
+}
+
+type firstSeen struct {
+	version string
+	at      time.Time
+}
+
+// observe records latest as the latest version for key, keeping the time it
+// was first seen while it stays the same.
+func (g *graceWindow) observe(key, latest string) {
+	if e, ok := g.seen[key]; ok && e.version == latest {
+		return
+	}
+	if g.seen == nil {
+		g.seen = make(map[string]firstSeen)
+	}
+	g.seen[key] = firstSeen{version: latest, at: g.clock()}
+}
+
+// retain drops the first-seen times of keys not in keep, i.e. no longer
+// deployed, so the map doesn't grow with every version ever checked.
+func (g *graceWindow) retain(keep map[string]bool) {
+	for key := range g.seen {
+		if !keep[key] {
+			delete(g.seen, key)
+		}
+	}
+}
+
+// settled reports whether key's latest version has been the latest for at
+// least the window. Keys never observed count as settled.
+func (g *graceWindow) settled(key string) bool {
+	if g.window <= 0 {
+		return true
+	}
+	e, ok := g.seen[key]
+	return !ok || g.clock().Sub(e.at) >= g.window
+}
+
+func (g *graceWindow) clock() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+	return time.Now()
+}
@@ -3,17 +3,23 @@ package versions
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/fredericrous/cluster-vision/internal/imageref"
 	"github.com/fredericrous/cluster-vision/internal/model"
 )
 
@@ -24,13 +30,71 @@ type ImageChecker struct {
 	lastCheck time.Time
 	checking  atomic.Bool
 	client    *http.Client
-	insecure  *http.Client // for HTTP-only registries
+	insecure  *http.Client  // for HTTP-only registries
+	delay     time.Duration // pause between registry requests
+	compare   TagCompare    // see SetTagCompare
+	grace     graceWindow   // see SetGrace
+	maxTags   int           // see SetMaxTags
+	tags      *TagCache     // see SetTagCache
+
+	// scope is swapped whole by SetScope, so a check in flight keeps the
+	// scope it started with.
+	scope atomic.Pointer[imageScope]
+
+	// pending holds image repos ("registry/path") left unresolved by a rate
+	// limit; the next check resumes with them before anything else.
+	pending map[string]bool
+	// backoff holds, per queried registry host, when it may be queried
+	// again after a 429.
+	backoff map[string]time.Time
+	// listStatus holds, per image repo, a TagList* state explaining why
+	// the last check found no newer tag; repos with an ordinary tag list
+	// have no entry.
+	listStatus map[string]string
 }
 
+// Tag list states, reported by TagListStatus, that tell a repo with no newer
+// tag apart from a failed check.
+const (
+	// TagListUnsupported: the registry refuses to list tags (see
+	// ErrTagListUnsupported), so the latest tag is unknown.
+	TagListUnsupported = "unsupported"
+	// TagListEmpty: the registry lists no tags at all, e.g. a brand-new
+	// repo; the deployed tag counts as the latest.
+	TagListEmpty = "no-tags"
+	// TagListOnlyTag: the repo's only tag is the deployed one.
+	TagListOnlyTag = "only-tag"
+)
+
+// imageScope decides which images are checked and where tags come from.
+type imageScope struct {
+	// localRegistry, when set, is the only registry queried for tags
+	// (air-gapped mode): upstream images are looked up under their
+	// upstream host, e.g. "zot:5000/v2/ghcr.io/org/app/tags/list".
+	localRegistry string
+	// namespaces scopes the image inventory to pods in matching namespaces
+	// (exact names or path.Match globs); empty means every namespace.
+	namespaces []string
+}
+
+// ImageCheckInterval is the minimum time between full image checks.
+const ImageCheckInterval = 15 * time.Minute
+
+// rateLimitBackoff is how long a registry is left alone after a 429.
+const rateLimitBackoff = 5 * time.Minute
+
 // NewImageChecker creates a new ImageChecker.
-func NewImageChecker() *ImageChecker {
-	return &ImageChecker{
-		latest: make(map[string]string),
+// localRegistry is the host:port of a registry cache (e.g. Zot) to treat as
+// authoritative for tag listing; empty queries each image's own registry.
+// namespaces, if non-empty, limits checks (and the images table) to pods in
+// those namespaces; entries may be globs such as "team-*".
+func NewImageChecker(localRegistry string, namespaces []string) *ImageChecker {
+	ic := &ImageChecker{
+		latest:     make(map[string]string),
+		pending:    make(map[string]bool),
+		backoff:    make(map[string]time.Time),
+		listStatus: make(map[string]string),
+		delay:      2 * time.Second,
 		client: &http.Client{
 			Timeout: 15 * time.Second,
 		},
@@ -41,6 +105,53 @@ func NewImageChecker() *ImageChecker {
 			},
 		},
 	}
+	ic.SetScope(localRegistry, namespaces)
+	return ic
+}
+
+// TagCompare selects what a pinned image tag (one with a patch version, e.g.
+// "1.2.3") is compared against when looking for the latest tag. Floating tags
+// such as "1" or "1.2" are always compared within their own series.
+type TagCompare string
+
+const (
+	// CompareLatest compares against the highest same-variant tag overall.
+	CompareLatest TagCompare = "latest"
+	// CompareMajor stays within the deployed major version.
+	CompareMajor TagCompare = "major"
+	// CompareMinor stays within the deployed major.minor version.
+	CompareMinor TagCompare = "minor"
+)
+
+// ParseTagCompare parses a TagCompare name; empty means CompareLatest.
+func ParseTagCompare(s string) (TagCompare, error) {
+	switch c := TagCompare(s); c {
+	case "":
+		return CompareLatest, nil
+	case CompareLatest, CompareMajor, CompareMinor:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown tag comparison %q (want latest, major or minor)", s)
+}
+
+// depth is the number of leading version components a candidate must share
+// with the deployed tag: 0 for latest, 1 for major, 2 for minor.
+func (c TagCompare) depth() int {
+	switch c {
+	case CompareMajor:
+		return 1
+	case CompareMinor:
+		return 2
+	}
+	return 0
+}
+
+// SetTagCompare sets what pinned tags are compared against. Call it before
+// the first Check: results already cached are not recomputed.
+func (ic *ImageChecker) SetTagCompare(c TagCompare) {
+	ic.mu.Lock()
+	ic.compare = c
+	ic.mu.Unlock()
 }
 
 // variant represents a tag's decomposed structure: prefix + semver + suffix.
@@ -51,6 +162,10 @@ type variant struct {
 
 var semverInTagRe = regexp.MustCompile(`^(.*?)(\d+\.\d+(?:\.\d+)?)(.*?)$`)
 
+// majorTagRe matches a floating major-version tag such as "1", "v2" or
+// "3-alpine".
+var majorTagRe = regexp.MustCompile(`^(v?)(\d+)(-\D*)?$`)
+
 // extractVariant splits a tag into its variant pattern and semver portion.
 // Returns the variant and the parsed semver. ok=false if the tag has no semver.
 func extractVariant(tag string) (v variant, sv semver, ok bool) {
@@ -76,38 +191,124 @@ func skipRegistry(registry string) bool {
 		strings.HasPrefix(registry, "localhost")
 }
 
+// SetScope replaces the local registry and namespace allowlist (see
+// NewImageChecker). It takes effect from the next check.
+func (ic *ImageChecker) SetScope(localRegistry string, namespaces []string) {
+	ic.scope.Store(&imageScope{localRegistry: localRegistry, namespaces: namespaces})
+}
+
+// tagSource returns the registry host and repository path to list tags from
+// for an image. Normally that is the image's own registry; in local-
+// authoritative mode it is the local registry, with upstream images nested
+// under their registry host.
+func (sc *imageScope) tagSource(registry, repo string) (host, repoPath string) {
+	if sc.localRegistry == "" || registry == sc.localRegistry {
+		return registry, repo
+	}
+	return sc.localRegistry, registry + "/" + repo
+}
+
+func (sc *imageScope) includes(namespace string) bool {
+	if len(sc.namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range sc.namespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// InScope reports whether images of pods in namespace belong in the image
+// inventory. A nil checker includes everything.
+func (ic *ImageChecker) InScope(namespace string) bool {
+	return ic == nil || ic.scope.Load().includes(namespace)
+}
+
+// ScopePods returns the pods whose namespace is in scope (see InScope).
+func (ic *ImageChecker) ScopePods(pods []model.PodImageInfo) []model.PodImageInfo {
+	if ic == nil {
+		return pods
+	}
+	return ic.scope.Load().filter(pods)
+}
+
+func (sc *imageScope) filter(pods []model.PodImageInfo) []model.PodImageInfo {
+	if len(sc.namespaces) == 0 {
+		return pods
+	}
+	var out []model.PodImageInfo
+	for _, p := range pods {
+		if sc.includes(p.Namespace) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 // Check fetches latest tags for all unique image repos used by pods.
 // Single-flight: returns immediately if already checking.
-// Interval gate: skips if last check was less than 15 minutes ago.
+// Interval gate: skips if last check was less than 15 minutes ago, unless
+// repos skipped by an earlier rate limit are due for a retry, in which case
+// only those are checked. Pending repos are always checked first.
+// Pods outside the namespace scope are ignored. Pods annotated with a
+// version source get that source's latest release instead, cached under
+// their annotated version rather than the tag.
 func (ic *ImageChecker) Check(pods []model.PodImageInfo) {
+	ic.check(pods, false)
+}
+
+// ForceCheck is Check without the interval gate, for on-demand checks;
+// registries backing off after a 429 are still left alone. Tags are listed
+// afresh rather than taken from the tag cache. It reports
+// false, without checking, if a check is already running.
+func (ic *ImageChecker) ForceCheck(pods []model.PodImageInfo) (CheckCounts, bool) {
+	return ic.check(pods, true)
+}
+
+// check runs Check; force skips the interval gate. ok is false when another
+// check is running.
+func (ic *ImageChecker) check(pods []model.PodImageInfo, force bool) (counts CheckCounts, ok bool) {
 	if !ic.checking.CompareAndSwap(false, true) {
-		return
+		return CheckCounts{}, false
 	}
 	defer ic.checking.Store(false)
 
-	ic.mu.RLock()
-	tooSoon := time.Since(ic.lastCheck) < 15*time.Minute
-	ic.mu.RUnlock()
-	if tooSoon {
-		return
-	}
+	scope := ic.scope.Load()
+	pods = scope.filter(pods)
 
 	// Dedup: group deployed tags by image repo (registry/path).
 	type repoInfo struct {
-		registry string
+		registry string // host queried for tags (see tagSource)
 		path     string
 		tags     map[string]bool // all deployed tags for this repo
 	}
 	repos := make(map[string]*repoInfo) // key = "registry/path"
 
+	// Images whose pods name a version source are looked up there instead
+	// of in the registry.
+	released := make(map[string]map[string]bool) // version source → "image|version" keys
+
 	for _, p := range pods {
-		registry, repo, tag := parseImageRef(p.Image)
+		registry, repo, tag := imageref.Parse(p.Image)
 		image := registry + "/" + repo
+		if p.Version != "" {
+			tag = p.Version
+		}
+		if p.VersionSource != "" {
+			if released[p.VersionSource] == nil {
+				released[p.VersionSource] = make(map[string]bool)
+			}
+			released[p.VersionSource][image+"|"+tag] = true
+			continue
+		}
 		ri, ok := repos[image]
 		if !ok {
+			host, repoPath := scope.tagSource(registry, repo)
 			ri = &repoInfo{
-				registry: registry,
-				path:     repo,
+				registry: host,
+				path:     repoPath,
 				tags:     make(map[string]bool),
 			}
 			repos[image] = ri
@@ -115,72 +316,296 @@ func (ic *ImageChecker) Check(pods []model.PodImageInfo) {
 		ri.tags[tag] = true
 	}
 
+	now := time.Now()
+	ic.mu.Lock()
+	compare, tagCache, maxTags := ic.compare, ic.tags, ic.maxTags
+	tooSoon := !force && now.Sub(ic.lastCheck) < ImageCheckInterval
+	for image := range ic.pending {
+		if repos[image] == nil { // no longer deployed
+			delete(ic.pending, image)
+		}
+	}
+	deployed := make(map[string]bool)
+	for image, ri := range repos {
+		for tag := range ri.tags {
+			deployed[image+"|"+tag] = true
+		}
+	}
+	for _, keys := range released {
+		maps.Copy(deployed, keys)
+	}
+	ic.grace.retain(deployed)
+	var resume, rest []string
+	for image, ri := range repos {
+		switch {
+		case now.Before(ic.backoff[ri.registry]):
+			// Registry still backing off: repos it has never answered for
+			// wait in the queue; the rest keep their previous results.
+			if !tooSoon && !ic.hasResults(image, ri.tags) {
+				ic.pending[image] = true
+			}
+		case ic.pending[image]:
+			resume = append(resume, image)
+		case !tooSoon:
+			rest = append(rest, image)
+		}
+	}
+	ic.mu.Unlock()
+	checkReleases := !tooSoon && len(released) > 0
+	if len(resume) == 0 && len(rest) == 0 && !checkReleases {
+		return CheckCounts{}, true
+	}
+	if checkReleases {
+		ic.checkReleaseSources(released)
+	}
+	sort.Strings(resume)
+	sort.Strings(rest)
+	order := append(resume, rest...)
+
 	skipRegistries := make(map[string]bool) // registries that returned 429
 	checked := 0
 	resolved := 0
 
-	for image, ri := range repos {
-		if skipRegistry(ri.registry) {
+	for _, image := range order {
+		ri := repos[image]
+		if scope.localRegistry == "" && skipRegistry(ri.registry) {
 			ic.setResults(image, ri.tags, "-")
+			ic.markPending(image, false)
 			checked++
 			continue
 		}
 
 		if skipRegistries[ri.registry] {
 			ic.setResults(image, ri.tags, "-")
+			ic.markPending(image, true)
 			checked++
 			continue
 		}
 
-		allTags, err := ic.listTags(ri.registry, ri.path)
+		// A repo listed within the tag cache's TTL isn't listed again, and
+		// needs no pause before the next request. A forced check, run to
+		// diagnose registry problems, always lists.
+		var allTags []string
+		var cached bool
+		if !force {
+			allTags, cached = tagCache.get(ri.registry, ri.path, maxTags)
+		}
+		var err error
+		if !cached {
+			allTags, err = ic.listTags(ri.registry, ri.path)
+		}
 		if err != nil {
 			if strings.Contains(err.Error(), "429") {
-				slog.Warn("image check: rate limited, skipping registry", "registry", ri.registry)
+				slog.Warn("image check: rate limited, deferring registry", "registry", ri.registry, "retryIn", rateLimitBackoff)
 				skipRegistries[ri.registry] = true
+				ic.mu.Lock()
+				ic.backoff[ri.registry] = time.Now().Add(rateLimitBackoff)
+				ic.mu.Unlock()
+				ic.markPending(image, true)
+			} else if errors.Is(err, ErrTagListUnsupported) {
+				slog.Info("image check: registry does not list tags", "image", image, "error", err)
+				ic.markPending(image, false)
 			} else {
 				slog.Warn("image check: failed to list tags", "image", image, "error", err)
+				ic.markPending(image, false)
 			}
+			ic.mu.Lock()
+			if errors.Is(err, ErrTagListUnsupported) {
+				ic.listStatus[image] = TagListUnsupported
+			} else {
+				delete(ic.listStatus, image)
+			}
+			ic.mu.Unlock()
 			ic.setResults(image, ri.tags, "-")
 			checked++
-			time.Sleep(2 * time.Second)
+			time.Sleep(ic.delay)
 			continue
 		}
 
-		// For each deployed tag, find the highest matching tag with the same variant.
+		// Parse the tag list once, then find each deployed tag's highest
+		// matching tag within its variant. With no tags, or only the
+		// deployed one, there is nothing newer: the deployed tag is the
+		// latest whether or not it parses as a version.
+		ix := newTagIndex(allTags)
+		status := tagListStatus(allTags, ri.tags)
 		results := make(map[string]string)
 		for tag := range ri.tags {
-			latest := highestMatchingTag(tag, allTags)
-			results[tag] = latest
+			if status == TagListEmpty || (status == TagListOnlyTag && tag == allTags[0]) {
+				results[tag] = tag
+				continue
+			}
+			results[tag] = ix.highest(tag, compare)
 		}
 
 		// Write results incrementally so partial data is visible.
 		ic.mu.Lock()
 		for tag, latest := range results {
-			ic.latest[image+"|"+tag] = latest
+			ic.setLatest(image+"|"+tag, latest)
+		}
+		delete(ic.pending, image)
+		if status != "" {
+			ic.listStatus[image] = status
+		} else {
+			delete(ic.listStatus, image)
 		}
 		ic.mu.Unlock()
 
 		checked++
 		resolved++
-		time.Sleep(2 * time.Second)
+		if !cached {
+			tagCache.add(ri.registry, ri.path, maxTags, allTags)
+			time.Sleep(ic.delay)
+		}
+	}
+
+	// A resume-only pass doesn't count as a full check.
+	if len(rest) > 0 || checkReleases {
+		ic.mu.Lock()
+		ic.lastCheck = time.Now()
+		ic.mu.Unlock()
 	}
 
+	ic.mu.RLock()
+	pending := len(ic.pending)
+	ic.mu.RUnlock()
+	slog.Info("image check complete", "repos", checked, "resolved", resolved, "resumed", len(resume), "pending", pending)
+	return CheckCounts{Checked: checked, Resolved: resolved}, true
+}
+
+// checkReleaseSources records, for every "image|version" key of each
+// version source, the source's latest release. Sources that can't be
+// queried record "-" like a registry that can't.
+func (ic *ImageChecker) checkReleaseSources(sources map[string]map[string]bool) {
+	for source, keys := range sources {
+		latest := "-"
+		if repo, ok := githubRepo(source); !ok {
+			slog.Warn("image check: unsupported version source", "source", source)
+		} else if tag, err := fetchLatestGitHubRelease(ic.client, githubAPI, repo); err != nil {
+			slog.Warn("image check: failed to get latest release", "source", source, "error", err)
+		} else {
+			latest = tag
+		}
+		ic.mu.Lock()
+		for key := range keys {
+			ic.setLatest(key, latest)
+		}
+		ic.mu.Unlock()
+	}
+}
+
+// githubRepo extracts "org/name" from a version source such as
+// "github.com/org/name" or "https://github.com/org/name.git".
+func githubRepo(source string) (string, bool) {
+	s := strings.TrimPrefix(strings.TrimPrefix(source, "https://"), "http://")
+	s, ok := strings.CutPrefix(s, "github.com/")
+	if !ok {
+		return "", false
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	if org, name, ok := strings.Cut(s, "/"); !ok || org == "" || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return s, true
+}
+
+// hasResults reports whether every deployed tag of image has a cached result.
+// Caller must hold ic.mu.
+func (ic *ImageChecker) hasResults(image string, tags map[string]bool) bool {
+	for tag := range tags {
+		if _, ok := ic.latest[image+"|"+tag]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// markPending adds or removes an image repo from the resume queue.
+func (ic *ImageChecker) markPending(image string, pending bool) {
 	ic.mu.Lock()
-	ic.lastCheck = time.Now()
+	if pending {
+		ic.pending[image] = true
+	} else {
+		delete(ic.pending, image)
+	}
 	ic.mu.Unlock()
-
-	slog.Info("image check complete", "repos", checked, "resolved", resolved)
 }
 
 // setResults writes "-" for all tags of an image (used for errors/skips).
 func (ic *ImageChecker) setResults(image string, tags map[string]bool, value string) {
 	ic.mu.Lock()
 	for tag := range tags {
-		ic.latest[image+"|"+tag] = value
+		ic.setLatest(image+"|"+tag, value)
 	}
 	ic.mu.Unlock()
 }
 
+// setLatest caches the latest tag for an "image|tag" key. Caller must hold
+// ic.mu.
+func (ic *ImageChecker) setLatest(key, latest string) {
+	ic.latest[key] = latest
+	ic.grace.observe(key, latest)
+}
+
+// SetMaxTags caps the tags fetched per image repo (see Checker.SetMaxTags
+// for the tradeoff). Zero means no cap.
+func (ic *ImageChecker) SetMaxTags(n int) {
+	ic.mu.Lock()
+	ic.maxTags = n
+	ic.mu.Unlock()
+}
+
+// SetTagCache shares a cache of tag listings with the checker (see
+// Checker.SetTagCache), so a repo listed within the cache's TTL isn't listed
+// again. Nil disables caching.
+func (ic *ImageChecker) SetTagCache(tc *TagCache) {
+	ic.mu.Lock()
+	ic.tags = tc
+	ic.mu.Unlock()
+}
+
+// SetGrace sets how long a new latest tag must stay the latest before
+// Settled reports it. Zero disables the grace window.
+func (ic *ImageChecker) SetGrace(d time.Duration) {
+	ic.mu.Lock()
+	ic.grace.window = d
+	ic.mu.Unlock()
+}
+
+// Settled reports whether the latest tag for an image+tag combination has
+// been the latest for at least the grace window.
+func (ic *ImageChecker) Settled(image, tag string) bool {
+	ic.mu.RLock()
+	defer ic.mu.RUnlock()
+	return ic.grace.settled(image + "|" + tag)
+}
+
+// ListingUnsupported reports whether the last check of image found that its
+// registry doesn't list tags, so its latest tag is unknown rather than
+// failed. A version source annotation can stand in for such images.
+func (ic *ImageChecker) ListingUnsupported(image string) bool {
+	return ic.TagListStatus(image) == TagListUnsupported
+}
+
+// TagListStatus returns the TagList* state the last check of image found,
+// or "" when its tag list was ordinary or it failed to list.
+func (ic *ImageChecker) TagListStatus(image string) string {
+	ic.mu.RLock()
+	defer ic.mu.RUnlock()
+	return ic.listStatus[image]
+}
+
+// tagListStatus classifies a repo's tag list: TagListEmpty when it has no
+// tags, TagListOnlyTag when its single tag is a deployed one, else "".
+func tagListStatus(allTags []string, deployed map[string]bool) string {
+	switch {
+	case len(allTags) == 0:
+		return TagListEmpty
+	case len(allTags) == 1 && deployed[allTags[0]]:
+		return TagListOnlyTag
+	}
+	return ""
+}
+
 // GetLatest returns the cached latest tag for a given image+tag combination.
 func (ic *ImageChecker) GetLatest(image, tag string) string {
 	ic.mu.RLock()
@@ -188,32 +613,63 @@ func (ic *ImageChecker) GetLatest(image, tag string) string {
 	return ic.latest[image+"|"+tag]
 }
 
-// highestMatchingTag finds the tag with the highest semver that matches
-// the same variant pattern (prefix + suffix) as the deployed tag.
-func highestMatchingTag(deployedTag string, allTags []string) string {
-	deployedVariant, deployedSV, ok := extractVariant(deployedTag)
-	if !ok {
-		return "-"
-	}
+// tagIndex is a repo's tag list parsed once and grouped by variant, so all
+// of the repo's deployed tags resolve without re-scanning the whole list.
+type tagIndex map[string][]tagCandidate // variant key → stable tags, in list order
 
-	bestTag := deployedTag
-	bestSV := deployedSV
+type tagCandidate struct {
+	tag string
+	sv  semver
+}
 
+// newTagIndex parses allTags, dropping tags without a semver and
+// pre-releases.
+func newTagIndex(allTags []string) tagIndex {
+	ix := make(tagIndex)
 	for _, t := range allTags {
 		v, sv, ok := extractVariant(t)
-		if !ok {
+		if !ok || sv.pre != "" {
 			continue
 		}
-		if v.key() != deployedVariant.key() {
-			continue
+		ix[v.key()] = append(ix[v.key()], tagCandidate{t, sv})
+	}
+	return ix
+}
+
+// highest finds the tag with the highest semver that matches the same
+// variant pattern (prefix + suffix) as the deployed tag. A floating deployed
+// tag ("1", "1.2") only considers tags within its series; a pinned one is
+// compared as compare says.
+func (ix tagIndex) highest(deployedTag string, compare TagCompare) string {
+	deployedVariant, deployedSV, ok := extractVariant(deployedTag)
+	depth := compare.depth()
+	switch {
+	case !ok:
+		m := majorTagRe.FindStringSubmatch(deployedTag)
+		if m == nil {
+			return "-"
 		}
-		// Skip pre-release versions
-		if sv.pre != "" {
+		major, err := strconv.Atoi(m[2])
+		if err != nil {
+			return "-"
+		}
+		deployedVariant = variant{prefix: m[1], suffix: m[3]}
+		deployedSV = semver{major: major, original: deployedTag}
+		depth = 1
+	case !deployedSV.hasPatch:
+		depth = 2
+	}
+
+	bestTag := deployedTag
+	bestSV := deployedSV
+
+	for _, c := range ix[deployedVariant.key()] {
+		if (depth >= 1 && c.sv.major != deployedSV.major) || (depth >= 2 && c.sv.minor != deployedSV.minor) {
 			continue
 		}
-		if bestSV.less(sv) {
-			bestSV = sv
-			bestTag = t
+		if bestSV.less(c.sv) {
+			bestSV = c.sv
+			bestTag = c.tag
 		}
 	}
 
@@ -228,10 +684,14 @@ func (ic *ImageChecker) listTags(registry, imagePath string) ([]string, error) {
 		host = "registry-1.docker.io"
 	}
 
+	ic.mu.RLock()
+	maxTags := ic.maxTags
+	ic.mu.RUnlock()
+
 	var allTags []string
-	tagURL := fmt.Sprintf("https://%s/v2/%s/tags/list?n=1000", host, imagePath)
+	tagURL := fmt.Sprintf("https://%s/v2/%s/tags/list?n=%d", host, imagePath, tagPageSize(maxTags))
 
-	for tagURL != "" {
+	for tagURL != "" && !tagCapReached(allTags, maxTags) {
 		body, nextURL, err := ic.fetchWithAuth(tagURL, host)
 		if err != nil {
 			return nil, err
@@ -248,7 +708,7 @@ func (ic *ImageChecker) listTags(registry, imagePath string) ([]string, error) {
 		tagURL = nextURL
 	}
 
-	return allTags, nil
+	return capTags(allTags, maxTags), nil
 }
 
 // fetchWithAuth performs an HTTP GET, handling 401 Bearer challenge auth.
@@ -297,7 +757,7 @@ func (ic *ImageChecker) fetchWithAuth(reqURL, registryHost string) (body []byte,
 		defer func() { _ = resp2.Body.Close() }()
 
 		if resp2.StatusCode != http.StatusOK {
-			return nil, "", fmt.Errorf("registry returned %d after auth", resp2.StatusCode)
+			return nil, "", registryStatusError(resp2, " after auth")
 		}
 
 		b, readErr := io.ReadAll(io.LimitReader(resp2.Body, 1<<20))
@@ -305,7 +765,7 @@ func (ic *ImageChecker) fetchWithAuth(reqURL, registryHost string) (body []byte,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("registry returned %d", resp.StatusCode)
+		return nil, "", registryStatusError(resp, "")
 	}
 
 	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
@@ -364,37 +824,3 @@ func (ic *ImageChecker) getToken(challenge string) (string, error) {
 	}
 	return tokenResp.AccessToken, nil
 }
-
-// parseImageRef splits a container image reference into registry, repo, and tag.
-// Duplicated from diagram package to avoid circular imports.
-func parseImageRef(ref string) (registry, repo, tag string) {
-	if idx := strings.Index(ref, "@"); idx != -1 {
-		tag = ref[idx+1:]
-		ref = ref[:idx]
-	}
-
-	if tag == "" {
-		if idx := strings.LastIndex(ref, ":"); idx != -1 {
-			slashIdx := strings.LastIndex(ref, "/")
-			if idx > slashIdx {
-				tag = ref[idx+1:]
-				ref = ref[:idx]
-			}
-		}
-		if tag == "" {
-			tag = "latest"
-		}
-	}
-
-	parts := strings.SplitN(ref, "/", 2)
-	if len(parts) == 1 {
-		return "docker.io", "library/" + parts[0], tag
-	}
-
-	first := parts[0]
-	if strings.Contains(first, ".") || strings.Contains(first, ":") || first == "localhost" {
-		return first, parts[1], tag
-	}
-
-	return "docker.io", ref, tag
-}
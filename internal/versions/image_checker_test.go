@@ -0,0 +1,564 @@
+package versions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestImageCheckerResumesRateLimitedRepos(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		requests    []string
+		rateLimited = true
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/"), "/tags/list")
+		mu.Lock()
+		requests = append(requests, repo)
+		limited := rateLimited && repo == "apps/b"
+		mu.Unlock()
+		if limited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tags":["1.0.0","1.1.0"]}`))
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{
+		{Image: registry + "/apps/a:1.0.0"},
+		{Image: registry + "/apps/b:1.0.0"},
+		{Image: registry + "/apps/c:1.0.0"},
+	}
+
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+
+	// First pass: a resolves, b hits a 429 and c is skipped with it.
+	ic.Check(pods)
+	if got := ic.GetLatest(registry+"/apps/a", "1.0.0"); got != "1.1.0" {
+		t.Errorf("a latest = %q, want 1.1.0", got)
+	}
+	for _, repo := range []string{"b", "c"} {
+		if !ic.pending[registry+"/apps/"+repo] {
+			t.Errorf("apps/%s not queued for resume after rate limit", repo)
+		}
+	}
+
+	// Still inside the backoff window: nothing is retried.
+	mu.Lock()
+	requests, rateLimited = nil, false
+	mu.Unlock()
+	ic.Check(pods)
+	if len(requests) != 0 {
+		t.Fatalf("requests during backoff = %v, want none", requests)
+	}
+
+	// A full check due while the registry is still backing off leaves it alone.
+	ic.lastCheck = time.Time{}
+	ic.Check(pods)
+	if len(requests) != 0 {
+		t.Fatalf("requests to backed-off registry = %v, want none", requests)
+	}
+
+	// Backoff elapsed and a full check is due: pending repos go first.
+	ic.backoff[registry] = time.Time{}
+	ic.lastCheck = time.Time{}
+	ic.Check(pods)
+
+	want := []string{"apps/b", "apps/c", "apps/a"}
+	if strings.Join(requests, ",") != strings.Join(want, ",") {
+		t.Errorf("request order = %v, want %v", requests, want)
+	}
+	for _, repo := range []string{"a", "b", "c"} {
+		if got := ic.GetLatest(registry+"/apps/"+repo, "1.0.0"); got != "1.1.0" {
+			t.Errorf("%s latest = %q, want 1.1.0", repo, got)
+		}
+	}
+	if len(ic.pending) != 0 {
+		t.Errorf("pending = %v, want empty", ic.pending)
+	}
+}
+
+func TestImageCheckerResumeOnlyPass(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		_, _ = w.Write([]byte(`{"tags":["2.0.0"]}`))
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{
+		{Image: registry + "/apps/a:1.0.0"},
+		{Image: registry + "/apps/b:1.0.0"},
+	}
+
+	// A full check ran recently but left b unresolved: only b is retried.
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+	checkedAt := time.Now()
+	ic.lastCheck = checkedAt
+	ic.pending[registry+"/apps/b"] = true
+
+	ic.Check(pods)
+
+	if len(requests) != 1 || requests[0] != "/v2/apps/b/tags/list" {
+		t.Errorf("requests = %v, want only apps/b", requests)
+	}
+	if !ic.lastCheck.Equal(checkedAt) {
+		t.Errorf("resume-only pass moved lastCheck to %v", ic.lastCheck)
+	}
+}
+
+func TestImageCheckerLocalAuthoritative(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"tags":["1.0.0","1.2.0"]}`))
+	}))
+	defer local.Close()
+
+	upstreamHits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHits++
+		_, _ = w.Write([]byte(`{"tags":["9.9.9"]}`))
+	}))
+	defer upstream.Close()
+
+	localHost := strings.TrimPrefix(local.URL, "http://")
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+	pods := []model.PodImageInfo{
+		{Image: upstreamHost + "/org/app:1.0.0"},
+		{Image: "nginx:1.0.0"},
+		{Image: "zot.registry.svc.cluster.local/org/internal:1.0.0"},
+		{Image: localHost + "/org/mirrored:1.0.0"},
+	}
+
+	ic := NewImageChecker(localHost, nil)
+	ic.delay = 0
+	ic.Check(pods)
+
+	if upstreamHits != 0 {
+		t.Errorf("upstream registry queried %d times in local-authoritative mode", upstreamHits)
+	}
+	want := map[string]bool{
+		"/v2/" + upstreamHost + "/org/app/tags/list":                true,
+		"/v2/docker.io/library/nginx/tags/list":                     true,
+		"/v2/zot.registry.svc.cluster.local/org/internal/tags/list": true,
+		"/v2/org/mirrored/tags/list":                                true,
+	}
+	if len(requests) != len(want) {
+		t.Errorf("requests = %v, want %d", requests, len(want))
+	}
+	for _, p := range requests {
+		if !want[p] {
+			t.Errorf("unexpected local registry request %s", p)
+		}
+	}
+
+	// Results stay keyed by the image as deployed.
+	if got := ic.GetLatest(upstreamHost+"/org/app", "1.0.0"); got != "1.2.0" {
+		t.Errorf("upstream image latest = %q, want 1.2.0 from the local registry", got)
+	}
+	if got := ic.GetLatest("docker.io/library/nginx", "1.0.0"); got != "1.2.0" {
+		t.Errorf("docker hub image latest = %q, want 1.2.0", got)
+	}
+}
+
+func TestImageCheckerNamespaceScope(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"tags":["1.0.0","1.1.0"]}`))
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{
+		{Namespace: "media", Image: registry + "/apps/jellyfin:1.0.0"},
+		{Namespace: "team-a", Image: registry + "/apps/api:1.0.0"},
+		{Namespace: "kube-system", Image: registry + "/apps/coredns:1.0.0"},
+		{Namespace: "monitoring", Image: registry + "/apps/grafana:1.0.0"},
+	}
+
+	ic := NewImageChecker("", []string{"media", "team-*"})
+	ic.delay = 0
+	ic.Check(pods)
+
+	want := []string{"/v2/apps/api/tags/list", "/v2/apps/jellyfin/tags/list"}
+	if strings.Join(requests, ",") != strings.Join(want, ",") {
+		t.Errorf("requests = %v, want only allowlisted repos %v", requests, want)
+	}
+	if got := ic.GetLatest(registry+"/apps/coredns", "1.0.0"); got != "" {
+		t.Errorf("out-of-scope image was checked: latest = %q", got)
+	}
+	if got := len(ic.ScopePods(pods)); got != 2 {
+		t.Errorf("ScopePods kept %d pods, want 2", got)
+	}
+}
+
+func TestTagIndexHighest(t *testing.T) {
+	tags := []string{"1", "1.2", "1.2.3", "1.2.9", "1.3.0", "1.4.1", "2.0.0", "2.1.0-rc.1", "1.9.0-alpine", "v1.5.0", "latest"}
+	tests := []struct {
+		deployed string
+		compare  TagCompare
+		want     string
+	}{
+		{"1.2", CompareLatest, "1.2.9"},
+		{"1", CompareLatest, "1.4.1"},
+		{"1-alpine", CompareLatest, "1.9.0-alpine"},
+		{"1.2.3", CompareLatest, "2.0.0"},
+		{"1.2.3", CompareMajor, "1.4.1"},
+		{"1.2.3", CompareMinor, "1.2.9"},
+		{"v1", CompareLatest, "v1.5.0"},
+		{"latest", CompareLatest, "-"},
+	}
+	ix := newTagIndex(tags)
+	for _, tt := range tests {
+		if got := ix.highest(tt.deployed, tt.compare); got != tt.want {
+			t.Errorf("highest(%q, %s) = %q, want %q", tt.deployed, tt.compare, got, tt.want)
+		}
+	}
+}
+
+// highestMatchingTagPerTag is the original resolver, which re-parsed the
+// whole tag list for every deployed tag; tagIndex must agree with it.
+func highestMatchingTagPerTag(deployedTag string, allTags []string, compare TagCompare) string {
+	deployedVariant, deployedSV, ok := extractVariant(deployedTag)
+	depth := compare.depth()
+	switch {
+	case !ok:
+		m := majorTagRe.FindStringSubmatch(deployedTag)
+		if m == nil {
+			return "-"
+		}
+		major, err := strconv.Atoi(m[2])
+		if err != nil {
+			return "-"
+		}
+		deployedVariant = variant{prefix: m[1], suffix: m[3]}
+		deployedSV = semver{major: major, original: deployedTag}
+		depth = 1
+	case !deployedSV.hasPatch:
+		depth = 2
+	}
+
+	bestTag := deployedTag
+	bestSV := deployedSV
+	for _, t := range allTags {
+		v, sv, ok := extractVariant(t)
+		if !ok || v.key() != deployedVariant.key() || sv.pre != "" {
+			continue
+		}
+		if (depth >= 1 && sv.major != deployedSV.major) || (depth >= 2 && sv.minor != deployedSV.minor) {
+			continue
+		}
+		if bestSV.less(sv) {
+			bestSV = sv
+			bestTag = t
+		}
+	}
+	return bestTag
+}
+
+// multiTagRepo returns a large tag list with several variants, plus deployed
+// tags spread across it, like a busy repo pinned at many versions.
+func multiTagRepo() (allTags, deployed []string) {
+	for major := 1; major <= 4; major++ {
+		for minor := 0; minor < 12; minor++ {
+			for patch := 0; patch < 8; patch++ {
+				v := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+				allTags = append(allTags, v, "v"+v, v+"-alpine", v+"-rc.1")
+				if patch == 3 && minor%3 == 0 {
+					deployed = append(deployed, v, "v"+v, v+"-alpine")
+				}
+			}
+			allTags = append(allTags, fmt.Sprintf("%d.%d", major, minor))
+		}
+		allTags = append(allTags, fmt.Sprint(major), fmt.Sprintf("%d-alpine", major))
+		deployed = append(deployed, fmt.Sprint(major), fmt.Sprintf("%d.5", major), fmt.Sprintf("v%d", major))
+	}
+	allTags = append(allTags, "latest", "edge", "sha-3f2c1e9")
+	deployed = append(deployed, "latest", "sha-3f2c1e9", "9.9.9")
+	return allTags, deployed
+}
+
+func TestTagIndexMatchesPerTagResolution(t *testing.T) {
+	allTags, deployed := multiTagRepo()
+	ix := newTagIndex(allTags)
+	for _, compare := range []TagCompare{CompareLatest, CompareMajor, CompareMinor} {
+		for _, tag := range deployed {
+			want := highestMatchingTagPerTag(tag, allTags, compare)
+			if got := ix.highest(tag, compare); got != want {
+				t.Errorf("highest(%q, %s) = %q, per-tag resolution gives %q", tag, compare, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkResolveDeployedTags(b *testing.B) {
+	allTags, deployed := multiTagRepo()
+	b.Run("per-tag", func(b *testing.B) {
+		for b.Loop() {
+			for _, tag := range deployed {
+				highestMatchingTagPerTag(tag, allTags, CompareLatest)
+			}
+		}
+	})
+	b.Run("indexed", func(b *testing.B) {
+		for b.Loop() {
+			ix := newTagIndex(allTags)
+			for _, tag := range deployed {
+				ix.highest(tag, CompareLatest)
+			}
+		}
+	})
+}
+
+func TestParseTagCompare(t *testing.T) {
+	if c, err := ParseTagCompare(""); err != nil || c != CompareLatest {
+		t.Errorf(`ParseTagCompare("") = %q, %v; want latest`, c, err)
+	}
+	if c, err := ParseTagCompare("minor"); err != nil || c != CompareMinor {
+		t.Errorf(`ParseTagCompare("minor") = %q, %v; want minor`, c, err)
+	}
+	if _, err := ParseTagCompare("newest"); err == nil {
+		t.Error(`ParseTagCompare("newest") succeeded, want error`)
+	}
+}
+
+func TestImageCheckerTagListingUnsupported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/apps/locked/tags/list":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case "/v2/apps/gar/tags/list":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[{"code":"UNSUPPORTED","message":"tag listing is disabled"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"tags":["1.0.0","1.1.0"]}`))
+		}
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{
+		{Image: registry + "/apps/locked:1.0.0"},
+		{Image: registry + "/apps/gar:1.0.0"},
+		{Image: registry + "/apps/open:1.0.0"},
+	}
+
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+	ic.Check(pods)
+
+	for _, repo := range []string{"locked", "gar"} {
+		image := registry + "/apps/" + repo
+		if !ic.ListingUnsupported(image) {
+			t.Errorf("apps/%s: listing not reported unsupported", repo)
+		}
+		if got := ic.GetLatest(image, "1.0.0"); got != "-" {
+			t.Errorf("apps/%s latest = %q, want -", repo, got)
+		}
+		if ic.pending[image] {
+			t.Errorf("apps/%s queued for retry, want it left alone", repo)
+		}
+	}
+	if ic.ListingUnsupported(registry + "/apps/open") {
+		t.Error("apps/open reported unsupported")
+	}
+	if got := ic.GetLatest(registry+"/apps/open", "1.0.0"); got != "1.1.0" {
+		t.Errorf("apps/open latest = %q, want 1.1.0", got)
+	}
+
+	_, err := ic.listTags(registry, "apps/locked")
+	if !errors.Is(err, ErrTagListUnsupported) {
+		t.Errorf("listTags error = %v, want ErrTagListUnsupported", err)
+	}
+}
+
+func TestImageCheckerEmptyAndSingleTagLists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/apps/new/tags/list":
+			_, _ = w.Write([]byte(`{"name":"apps/new","tags":[]}`))
+		case "/v2/apps/single/tags/list":
+			_, _ = w.Write([]byte(`{"name":"apps/single","tags":["main"]}`))
+		case "/v2/apps/moved/tags/list":
+			_, _ = w.Write([]byte(`{"name":"apps/moved","tags":["2.0.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{
+		{Image: registry + "/apps/new:1.0.0"},
+		{Image: registry + "/apps/single:main"},
+		{Image: registry + "/apps/moved:1.0.0"},
+	}
+
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+	ic.Check(pods)
+
+	tests := []struct {
+		repo, tag, wantLatest, wantStatus string
+	}{
+		{"new", "1.0.0", "1.0.0", TagListEmpty},
+		{"single", "main", "main", TagListOnlyTag},
+		// The only tag isn't the deployed one: an ordinary comparison.
+		{"moved", "1.0.0", "2.0.0", ""},
+	}
+	for _, tt := range tests {
+		image := registry + "/apps/" + tt.repo
+		if got := ic.GetLatest(image, tt.tag); got != tt.wantLatest {
+			t.Errorf("apps/%s latest = %q, want %q", tt.repo, got, tt.wantLatest)
+		}
+		if got := ic.TagListStatus(image); got != tt.wantStatus {
+			t.Errorf("apps/%s status = %q, want %q", tt.repo, got, tt.wantStatus)
+		}
+		if ic.ListingUnsupported(image) {
+			t.Errorf("apps/%s reported unsupported, want a non-error state", tt.repo)
+		}
+	}
+}
+
+func TestImageCheckerMaxTags(t *testing.T) {
+	pages := [][]string{{"1.0.0", "1.1.0"}, {"1.2.0", "1.3.0"}, {"1.4.0", "1.5.0"}}
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page+1 < len(pages) {
+			w.Header().Set("Link", fmt.Sprintf(`</v2/apps/node/tags/list?page=%d>; rel="next"`, page+1))
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]string{"tags": pages[page]})
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+	ic.SetMaxTags(4)
+	ic.Check([]model.PodImageInfo{{Image: registry + "/apps/node:1.0.0"}})
+
+	if len(requests) != 2 {
+		t.Fatalf("requests = %v, want pagination to stop after 2 pages at the cap", requests)
+	}
+	if requests[0] != "n=4" {
+		t.Errorf("first page query = %q, want the page size capped to n=4", requests[0])
+	}
+	if got := ic.GetLatest(registry+"/apps/node", "1.0.0"); got != "1.3.0" {
+		t.Errorf("latest = %q, want 1.3.0, the highest among the fetched tags", got)
+	}
+}
+
+func TestImageCheckerTagCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"tags":["1.0.0","1.1.0"]}`))
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{{Image: registry + "/apps/a:1.0.0"}}
+
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+	ic.SetTagCache(NewTagCache(10, time.Hour))
+
+	ic.Check(pods)
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("first check made %d requests, want 1", got)
+	}
+
+	// A full check due again within the TTL reuses the cached tags.
+	ic.lastCheck = time.Time{}
+	ic.Check(pods)
+	if got := requests.Load(); got != 1 {
+		t.Errorf("second check made %d requests in total, want the cached listing reused", got)
+	}
+	if got := ic.GetLatest(registry+"/apps/a", "1.0.0"); got != "1.1.0" {
+		t.Errorf("latest = %q, want 1.1.0 from the cached tags", got)
+	}
+
+	// Past the TTL the repo is listed again.
+	ic.tags.lru.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	ic.lastCheck = time.Time{}
+	ic.Check(pods)
+	if got := requests.Load(); got != 2 {
+		t.Errorf("check after the TTL made %d requests in total, want 2", got)
+	}
+}
+
+func TestImageCheckerForceCheckBypassesTagCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"tags":["1.0.0","1.1.0"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"tags":["1.0.0","1.1.0","1.2.0"]}`))
+	}))
+	defer srv.Close()
+
+	registry := strings.TrimPrefix(srv.URL, "http://")
+	pods := []model.PodImageInfo{{Image: registry + "/apps/a:1.0.0"}}
+
+	ic := NewImageChecker("", nil)
+	ic.delay = 0
+	ic.SetTagCache(NewTagCache(10, time.Hour))
+
+	ic.Check(pods)
+	// A forced check within the TTL lists the repo again.
+	if _, ok := ic.ForceCheck(pods); !ok {
+		t.Fatal("ForceCheck skipped")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want the forced check to list tags afresh", got)
+	}
+	if got := ic.GetLatest(registry+"/apps/a", "1.0.0"); got != "1.2.0" {
+		t.Errorf("latest = %q, want 1.2.0 from the fresh listing", got)
+	}
+}
+
+func TestImageCheckerGraceForgetsUndeployedTags(t *testing.T) {
+	ic := NewImageChecker("", nil)
+	ic.SetGrace(time.Hour)
+	// An unsupported version source resolves to "-" without any request.
+	ic.Check([]model.PodImageInfo{{Namespace: "apps", Image: "ghcr.io/acme/app:1.0.0", VersionSource: "gitlab.com/acme/app"}})
+	if n := len(ic.grace.seen); n != 1 {
+		t.Fatalf("grace window tracks %d keys, want 1", n)
+	}
+
+	ic.Check(nil)
+	if n := len(ic.grace.seen); n != 0 {
+		t.Errorf("grace window tracks %d keys after the pod went away, want 0", n)
+	}
+}
@@ -0,0 +1,145 @@
+package versions
+
+import (
+	"container/list"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// lruCache is a bounded map whose entries expire after ttl; past size
+// entries, the least recently used is evicted. It is safe for concurrent
+// use.
+type lruCache[V any] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	now   func() time.Time // time.Now when nil; tests inject a clock
+	order *list.List       // of *lruEntry[V], most recently used first
+	items map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newLRUCache[V any](size int, ttl time.Duration) *lruCache[V] {
+	return &lruCache[V]{size: size, ttl: ttl, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *lruCache[V]) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// get returns the value cached for key, unless it is missing or expired.
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*lruEntry[V])
+	if !c.clock().Before(e.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// add caches value for key for ttl, evicting the least recently used entry
+// when the cache is full.
+func (c *lruCache[V]) add(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := c.clock().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry[V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// remove drops key from the cache.
+func (c *lruCache[V]) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// len returns the number of cached entries, expired ones included until
+// they are looked up or evicted.
+func (c *lruCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// TagCache holds registry tag listings keyed by "host/path" and the tag cap
+// they were listed under (see SetMaxTags), so a repo checked again within
+// the TTL, by either checker, isn't listed again. It is bounded in size,
+// evicting the least recently used repo, and safe for concurrent use. A nil
+// *TagCache caches nothing.
+type TagCache struct {
+	lru *lruCache[[]string]
+}
+
+// NewTagCache returns a cache of at most size tag listings, each kept for
+// ttl. It returns nil, which caches nothing, when either is not positive.
+func NewTagCache(size int, ttl time.Duration) *TagCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &TagCache{lru: newLRUCache[[]string](size, ttl)}
+}
+
+// get returns a copy of the tags cached for host/path, listed with at most
+// maxTags tags.
+func (tc *TagCache) get(host, path string, maxTags int) ([]string, bool) {
+	if tc == nil {
+		return nil, false
+	}
+	tags, ok := tc.lru.get(tagCacheKey(host, path, maxTags))
+	return slices.Clone(tags), ok
+}
+
+// add caches a copy of the tags listed for host/path with at most maxTags
+// tags.
+func (tc *TagCache) add(host, path string, maxTags int, tags []string) {
+	if tc != nil {
+		tc.lru.add(tagCacheKey(host, path, maxTags), slices.Clone(tags))
+	}
+}
+
+// tagCacheKey keys a listing by repo and cap, so a listing cut down under
+// one cap isn't served once the cap changes.
+func tagCacheKey(host, path string, maxTags int) string {
+	return host + "/" + path + "#" + strconv.Itoa(maxTags)
+}
+
+// Len returns the number of cached tag listings.
+func (tc *TagCache) Len() int {
+	if tc == nil {
+		return 0
+	}
+	return tc.lru.len()
+}
@@ -0,0 +1,62 @@
+package versions
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTagCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	tc := NewTagCache(2, time.Hour)
+	tc.add("ghcr.io", "org/a", 0, []string{"1.0.0"})
+	tc.add("ghcr.io", "org/b", 0, []string{"2.0.0"})
+	if _, ok := tc.get("ghcr.io", "org/a", 0); !ok { // a is now the most recently used
+		t.Fatal("org/a not cached")
+	}
+	tc.add("ghcr.io", "org/c", 0, []string{"3.0.0"})
+
+	if got := tc.Len(); got != 2 {
+		t.Errorf("Len = %d, want the size bound 2", got)
+	}
+	if _, ok := tc.get("ghcr.io", "org/b", 0); ok {
+		t.Error("org/b still cached, want it evicted as least recently used")
+	}
+	for _, path := range []string{"org/a", "org/c"} {
+		if _, ok := tc.get("ghcr.io", path, 0); !ok {
+			t.Errorf("%s evicted, want it kept", path)
+		}
+	}
+}
+
+func TestTagCacheExpires(t *testing.T) {
+	now := time.Now()
+	tc := NewTagCache(10, time.Minute)
+	tc.lru.now = func() time.Time { return now }
+	tc.add("docker.io", "library/nginx", 0, []string{"1.27.0"})
+
+	now = now.Add(59 * time.Second)
+	tags, ok := tc.get("docker.io", "library/nginx", 0)
+	if !ok || !slices.Equal(tags, []string{"1.27.0"}) {
+		t.Fatalf("get within TTL = %v, %v; want the cached tags", tags, ok)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := tc.get("docker.io", "library/nginx", 0); ok {
+		t.Error("listing still cached after the TTL")
+	}
+	if got := tc.Len(); got != 0 {
+		t.Errorf("Len = %d after expiry, want 0", got)
+	}
+}
+
+func TestNewTagCacheDisabled(t *testing.T) {
+	for _, tc := range []*TagCache{NewTagCache(0, time.Hour), NewTagCache(10, 0)} {
+		if tc != nil {
+			t.Fatalf("NewTagCache = %v, want nil when size or TTL is zero", tc)
+		}
+		tc.add("ghcr.io", "org/a", 0, []string{"1.0.0"}) // a nil cache caches nothing
+		if _, ok := tc.get("ghcr.io", "org/a", 0); ok {
+			t.Error("nil cache returned tags")
+		}
+	}
+}
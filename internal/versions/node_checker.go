@@ -21,25 +21,54 @@ var knownDistros = map[string]string{
 	"k3s":   "k3s-io/k3s",
 }
 
+// knownRuntimes maps container runtime names, as reported in a node's
+// containerRuntimeVersion, to their GitHub repo for release checking.
+var knownRuntimes = map[string]string{
+	"containerd": "containerd/containerd",
+	"cri-o":      "cri-o/cri-o",
+}
+
+// runtimeRe extracts the runtime name and version from a node's container
+// runtime string, e.g. "containerd://1.7.2" → ("containerd", "1.7.2").
+// Distro build suffixes such as "-k3s1" are dropped.
+var runtimeRe = regexp.MustCompile(`^([a-z0-9-]+)://v?([0-9]+\.[0-9]+(?:\.[0-9]+)?)`)
+
 // osImageRe extracts the distro name and version from an OS image string.
 // Examples: "Talos (v1.9.0)" → ("talos", "v1.9.0"), "Ubuntu 22.04" → ("ubuntu", "22.04")
 var osImageRe = regexp.MustCompile(`(?i)^(\S+)\s*\(?v?([0-9]+\.[0-9]+(?:\.[0-9]+)?)\)?`)
 
 // NodeChecker checks for latest OS and kubelet versions for cluster nodes.
 type NodeChecker struct {
-	mu        sync.RWMutex
-	latestOS  map[string]string // "distro" → latest version
-	latestK8s map[string]string // "major.minor" → latest patch version
-	lastCheck time.Time
-	checking  atomic.Bool
-	client    *http.Client
+	mu          sync.RWMutex
+	latestOS    map[string]string     // "distro" → latest version
+	latestK8s   map[string]string     // "major.minor" → latest patch version
+	latestRT    map[string]string     // container runtime name → latest release
+	checkRT     bool                  // see SetRuntimeCheck
+	eolCycles   map[string][]eolCycle // "distro" → release cycles from endoflife.date
+	eolProducts map[string]string     // "distro" → endoflife.date product; nil disables
+	eolWarn     time.Duration         // window before EOL that flags a cycle as EOLSoon
+	eolBaseURL  string
+	githubBase  string
+	lastCheck   time.Time
+	checking    atomic.Bool
+	client      *http.Client
 }
 
 // NewNodeChecker creates a new NodeChecker.
-func NewNodeChecker() *NodeChecker {
+// eolProducts maps distro names (lowercased, as returned by ParseOSImage) to
+// endoflife.date product names; those distros resolve their latest version and
+// EOL date from endoflife.date instead of GitHub releases. eolWarn is how far
+// ahead of the EOL date a cycle is flagged as ending soon.
+func NewNodeChecker(eolProducts map[string]string, eolWarn time.Duration) *NodeChecker {
 	return &NodeChecker{
-		latestOS:  make(map[string]string),
-		latestK8s: make(map[string]string),
+		latestOS:    make(map[string]string),
+		latestK8s:   make(map[string]string),
+		latestRT:    make(map[string]string),
+		eolCycles:   make(map[string][]eolCycle),
+		eolProducts: eolProducts,
+		eolWarn:     eolWarn,
+		eolBaseURL:  "https://endoflife.date",
+		githubBase:  githubAPI,
 		client: &http.Client{
 			Timeout: 15 * time.Second,
 		},
@@ -55,25 +84,60 @@ func ParseOSImage(osImage string) (distro, version string) {
 	return strings.ToLower(m[1]), m[2]
 }
 
-// Check fetches latest OS and kubelet versions for the given nodes.
+// ParseContainerRuntime extracts the runtime name and version from a node's
+// container runtime string.
+func ParseContainerRuntime(runtime string) (name, version string) {
+	m := runtimeRe.FindStringSubmatch(runtime)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+// SetRuntimeCheck turns on comparing containerd and CRI-O versions against
+// their latest GitHub release. It takes effect from the next check.
+func (nc *NodeChecker) SetRuntimeCheck(enabled bool) {
+	nc.mu.Lock()
+	nc.checkRT = enabled
+	nc.mu.Unlock()
+}
+
+// Check fetches latest OS and kubelet versions for the given nodes, the
+// latest patch of each API server's minor version, and container runtime
+// versions when enabled (see SetRuntimeCheck).
 // Single-flight: returns immediately if already checking.
 // Interval gate: skips if last check was less than 15 minutes ago.
-func (nc *NodeChecker) Check(nodes []model.NodeInfo) {
+func (nc *NodeChecker) Check(nodes []model.NodeInfo, apiServers []model.APIServerInfo) {
+	nc.check(nodes, apiServers, false)
+}
+
+// ForceCheck is Check without the interval gate, for on-demand checks. It
+// reports false, without checking, if a check is already running.
+func (nc *NodeChecker) ForceCheck(nodes []model.NodeInfo, apiServers []model.APIServerInfo) (CheckCounts, bool) {
+	return nc.check(nodes, apiServers, true)
+}
+
+// check runs Check; force skips the interval gate. ok is false when another
+// check is running.
+func (nc *NodeChecker) check(nodes []model.NodeInfo, apiServers []model.APIServerInfo, force bool) (counts CheckCounts, ok bool) {
 	if !nc.checking.CompareAndSwap(false, true) {
-		return
+		return CheckCounts{}, false
 	}
 	defer nc.checking.Store(false)
 
 	nc.mu.RLock()
 	tooSoon := time.Since(nc.lastCheck) < 15*time.Minute
+	checkRT := nc.checkRT
 	nc.mu.RUnlock()
-	if tooSoon {
-		return
+	if tooSoon && !force {
+		return CheckCounts{}, true
 	}
 
-	// Collect unique distros and kubelet minor versions
+	// Collect unique distros, kubelet and API server minor versions and
+	// runtimes
 	distros := make(map[string]bool)
 	minorVersions := make(map[string]bool)
+	runtimes := make(map[string]bool)
 
 	for _, n := range nodes {
 		distro, _ := ParseOSImage(n.OSImage)
@@ -86,34 +150,88 @@ func (nc *NodeChecker) Check(nodes []model.NodeInfo) {
 				minorVersions[minor] = true
 			}
 		}
+		if name, _ := ParseContainerRuntime(n.ContainerRuntime); checkRT && knownRuntimes[name] != "" {
+			runtimes[name] = true
+		}
+	}
+	for _, a := range apiServers {
+		if minor := kubeletMinor(a.GitVersion); minor != "" {
+			minorVersions[minor] = true
+		}
 	}
 
 	// Check OS distro versions
 	for distro := range distros {
+		if product, ok := nc.eolProducts[distro]; ok {
+			counts.Checked++
+			cycles, err := nc.fetchEOLCycles(product)
+			if err != nil {
+				slog.Warn("node version check: failed to get endoflife.date cycles", "distro", distro, "product", product, "error", err)
+				continue
+			}
+			counts.Resolved++
+			nc.mu.Lock()
+			nc.eolCycles[distro] = cycles
+			if len(cycles) > 0 && cycles[0].Latest != "" {
+				nc.latestOS[distro] = cycles[0].Latest
+			}
+			nc.mu.Unlock()
+			time.Sleep(time.Second)
+			continue
+		}
+
 		repo, ok := knownDistros[distro]
 		if !ok {
 			continue
 		}
-		latest, err := nc.fetchLatestGitHubRelease(repo)
+		counts.Checked++
+		latest, err := fetchLatestGitHubRelease(nc.client, nc.githubBase, repo)
 		if err != nil {
 			slog.Warn("node version check: failed to get latest OS release", "distro", distro, "error", err)
 			continue
 		}
+		counts.Resolved++
 		nc.mu.Lock()
 		nc.latestOS[distro] = latest
 		nc.mu.Unlock()
 		time.Sleep(time.Second)
 	}
 
-	// Check kubelet versions (latest patch in each minor series)
-	for minor := range minorVersions {
-		latest, err := nc.fetchLatestK8sPatch(minor)
+	// Check kubelet and API server versions (latest patch in each minor
+	// series, across every cluster)
+	if len(minorVersions) > 0 {
+		counts.Checked += len(minorVersions)
+		latest, err := nc.fetchLatestK8sPatches(minorVersions)
+		if err != nil {
+			slog.Warn("node version check: failed to get latest k8s patches", "error", err)
+		}
+		for minor := range minorVersions {
+			if latest[minor] == "" {
+				if err == nil {
+					slog.Warn("node version check: no stable k8s release found", "minor", minor)
+				}
+				continue
+			}
+			counts.Resolved++
+		}
+		nc.mu.Lock()
+		for minor, v := range latest {
+			nc.latestK8s[minor] = v
+		}
+		nc.mu.Unlock()
+	}
+
+	// Check container runtimes (latest release overall)
+	for name := range runtimes {
+		counts.Checked++
+		latest, err := fetchLatestGitHubRelease(nc.client, nc.githubBase, knownRuntimes[name])
 		if err != nil {
-			slog.Warn("node version check: failed to get latest k8s patch", "minor", minor, "error", err)
+			slog.Warn("node version check: failed to get latest runtime release", "runtime", name, "error", err)
 			continue
 		}
+		counts.Resolved++
 		nc.mu.Lock()
-		nc.latestK8s[minor] = latest
+		nc.latestRT[name] = latest
 		nc.mu.Unlock()
 		time.Sleep(time.Second)
 	}
@@ -122,7 +240,8 @@ func (nc *NodeChecker) Check(nodes []model.NodeInfo) {
 	nc.lastCheck = time.Now()
 	nc.mu.Unlock()
 
-	slog.Info("node version check complete", "distros", len(distros), "k8sMinors", len(minorVersions))
+	slog.Info("node version check complete", "distros", len(distros), "k8sMinors", len(minorVersions), "runtimes", len(runtimes))
+	return counts, true
 }
 
 // GetLatestOS returns the latest known version for a given OS distro.
@@ -136,7 +255,8 @@ func (nc *NodeChecker) GetLatestOS(osImage string) string {
 	return nc.latestOS[distro]
 }
 
-// GetLatestKubelet returns the latest known patch version for a given kubelet version's minor series.
+// GetLatestKubelet returns the latest known patch version for a given kubelet
+// (or API server) version's minor series.
 func (nc *NodeChecker) GetLatestKubelet(kubeletVersion string) string {
 	minor := kubeletMinor(kubeletVersion)
 	if minor == "" {
@@ -147,6 +267,18 @@ func (nc *NodeChecker) GetLatestKubelet(kubeletVersion string) string {
 	return nc.latestK8s[minor]
 }
 
+// GetLatestRuntime returns the latest known release for a node's container
+// runtime string, e.g. "v2.1.4" for "containerd://1.7.2".
+func (nc *NodeChecker) GetLatestRuntime(containerRuntime string) string {
+	name, _ := ParseContainerRuntime(containerRuntime)
+	if name == "" {
+		return ""
+	}
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.latestRT[name]
+}
+
 // kubeletMinor extracts the major.minor from a kubelet version string.
 // e.g. "v1.32.0" → "1.32"
 func kubeletMinor(version string) string {
@@ -158,16 +290,20 @@ func kubeletMinor(version string) string {
 	return parts[0] + "." + parts[1]
 }
 
-// fetchLatestGitHubRelease fetches the latest release tag from a GitHub repo.
-func (nc *NodeChecker) fetchLatestGitHubRelease(repo string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
+// githubAPI is the base URL of the GitHub REST API.
+const githubAPI = "https://api.github.com"
+
+// fetchLatestGitHubRelease fetches the latest release tag from a GitHub repo
+// ("org/name") through the API at apiBase.
+func fetchLatestGitHubRelease(client *http.Client, apiBase, repo string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", apiBase, repo)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 
-	resp, err := nc.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("fetching %s: %w", url, err)
 	}
@@ -192,62 +328,76 @@ func (nc *NodeChecker) fetchLatestGitHubRelease(repo string) (string, error) {
 	return release.TagName, nil
 }
 
-// fetchLatestK8sPatch fetches the latest patch release for a given Kubernetes minor version.
-func (nc *NodeChecker) fetchLatestK8sPatch(minor string) (string, error) {
-	url := "https://api.github.com/repos/kubernetes/kubernetes/releases?per_page=100"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Accept", "application/vnd.github+json")
-
-	resp, err := nc.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("fetching k8s releases: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API returned %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
-	if err != nil {
-		return "", err
+// maxK8sReleasePages bounds the kubernetes/kubernetes release pages read to
+// resolve the minors in use; older minors than that stay unresolved.
+const maxK8sReleasePages = 5
+
+// fetchLatestK8sPatches fetches the latest patch release of each Kubernetes
+// minor version in minors ("1.32" → "v1.32.4"). Every minor is resolved from
+// the same release list, read page by page only until each has a match, so
+// clusters on different minors don't each re-download it. Minors without a
+// stable release are left out of the result.
+func (nc *NodeChecker) fetchLatestK8sPatches(minors map[string]bool) (map[string]string, error) {
+	type best struct {
+		sv  semver
+		tag string
 	}
+	found := make(map[string]best)
 
-	var releases []struct {
-		TagName    string `json:"tag_name"`
-		Prerelease bool   `json:"prerelease"`
-		Draft      bool   `json:"draft"`
-	}
-	if err := json.Unmarshal(body, &releases); err != nil {
-		return "", fmt.Errorf("parsing releases: %w", err)
-	}
+	for page := 1; page <= maxK8sReleasePages && len(found) < len(minors); page++ {
+		url := fmt.Sprintf("%s/repos/kubernetes/kubernetes/releases?per_page=100&page=%d", nc.githubBase, page)
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
 
-	prefix := "v" + minor + "."
-	var best semver
-	var bestTag string
+		resp, err := nc.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetching k8s releases: %w", err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GitHub API returned %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	for _, r := range releases {
-		if r.Prerelease || r.Draft {
-			continue
+		var releases []struct {
+			TagName    string `json:"tag_name"`
+			Prerelease bool   `json:"prerelease"`
+			Draft      bool   `json:"draft"`
 		}
-		if !strings.HasPrefix(r.TagName, prefix) {
-			continue
+		if err := json.Unmarshal(body, &releases); err != nil {
+			return nil, fmt.Errorf("parsing releases: %w", err)
 		}
-		sv, ok := parseSemver(r.TagName)
-		if !ok || sv.pre != "" {
-			continue
+
+		for _, r := range releases {
+			if r.Prerelease || r.Draft {
+				continue
+			}
+			minor := kubeletMinor(r.TagName)
+			if !minors[minor] {
+				continue
+			}
+			sv, ok := parseSemver(r.TagName)
+			if !ok || sv.pre != "" {
+				continue
+			}
+			if b, ok := found[minor]; !ok || b.sv.less(sv) {
+				found[minor] = best{sv, r.TagName}
+			}
 		}
-		if bestTag == "" || best.less(sv) {
-			best = sv
-			bestTag = r.TagName
+		if len(releases) < 100 {
+			break // last page
 		}
 	}
 
-	if bestTag == "" {
-		return "", fmt.Errorf("no stable release found for v%s.x", minor)
+	latest := make(map[string]string, len(found))
+	for minor, b := range found {
+		latest[minor] = b.tag
 	}
-	return bestTag, nil
+	return latest, nil
 }
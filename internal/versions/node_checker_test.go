@@ -0,0 +1,217 @@
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestNodeCheckerEndOfLife(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/ubuntu.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"cycle":"24.04","latest":"24.04.1","eol":"2029-04-25"},
+			{"cycle":"22.04","latest":"22.04.5","eol":"2027-04-01"},
+			{"cycle":"20.04","latest":"20.04.6","eol":"2025-04-02"},
+			{"cycle":"18.04","latest":"18.04.6","eol":true}
+		]`))
+	}))
+	defer srv.Close()
+
+	nc := NewNodeChecker(map[string]string{"ubuntu": "ubuntu"}, 90*24*time.Hour)
+	nc.eolBaseURL = srv.URL
+	nc.Check([]model.NodeInfo{{Name: "n1", OSImage: "Ubuntu 22.04.3 LTS"}}, nil)
+
+	if got := nc.GetLatestOS("Ubuntu 22.04.3 LTS"); got != "24.04.1" {
+		t.Errorf("GetLatestOS() = %q, want %q", got, "24.04.1")
+	}
+
+	now := time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		osImage     string
+		wantCycle   string
+		wantDate    string
+		wantEOL     bool
+		wantEOLSoon bool
+	}{
+		{"Ubuntu 24.04.1 LTS", "24.04", "2029-04-25", false, false},
+		{"Ubuntu 22.04.3 LTS", "22.04", "2027-04-01", false, true},
+		{"Ubuntu 20.04.6 LTS", "20.04", "2025-04-02", true, false},
+		{"Ubuntu 18.04.6 LTS", "18.04", "", true, false},
+		{"Ubuntu 16.04.7 LTS", "", "", false, false},
+		{"Talos (v1.9.0)", "", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.osImage, func(t *testing.T) {
+			st := nc.GetEOLStatus(tt.osImage, now)
+			if st.Cycle != tt.wantCycle || st.Date != tt.wantDate || st.EOL != tt.wantEOL || st.EOLSoon != tt.wantEOLSoon {
+				t.Errorf("GetEOLStatus(%q) = %+v, want {Cycle:%s Date:%s EOL:%v EOLSoon:%v}",
+					tt.osImage, st, tt.wantCycle, tt.wantDate, tt.wantEOL, tt.wantEOLSoon)
+			}
+		})
+	}
+}
+
+func TestMatchEOLCycle(t *testing.T) {
+	cycles := []eolCycle{{Cycle: "1.9"}, {Cycle: "1.10"}, {Cycle: "1"}}
+
+	tests := []struct {
+		version string
+		want    string
+		ok      bool
+	}{
+		{"v1.9.0", "1.9", true},
+		{"1.10.2", "1.10", true},
+		{"1.1.0", "1", true},
+		{"2.0.0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			c, ok := matchEOLCycle(cycles, tt.version)
+			if ok != tt.ok || c.Cycle != tt.want {
+				t.Errorf("matchEOLCycle(%q) = (%q, %v), want (%q, %v)", tt.version, c.Cycle, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseContainerRuntime(t *testing.T) {
+	tests := []struct {
+		runtime, wantName, wantVersion string
+	}{
+		{"containerd://1.7.2", "containerd", "1.7.2"},
+		{"containerd://1.7.11-k3s2", "containerd", "1.7.11"},
+		{"cri-o://1.30.1", "cri-o", "1.30.1"},
+		{"docker://v24.0.7", "docker", "24.0.7"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		name, version := ParseContainerRuntime(tt.runtime)
+		if name != tt.wantName || version != tt.wantVersion {
+			t.Errorf("ParseContainerRuntime(%q) = %q, %q; want %q, %q", tt.runtime, name, version, tt.wantName, tt.wantVersion)
+		}
+	}
+}
+
+func TestNodeCheckerRuntimeOutdated(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/repos/containerd/containerd/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tag_name":"v2.1.4"}`))
+	}))
+	defer srv.Close()
+
+	nodes := []model.NodeInfo{{Name: "n1", ContainerRuntime: "containerd://1.7.2"}}
+
+	nc := NewNodeChecker(nil, 0)
+	nc.githubBase = srv.URL
+	nc.Check(nodes, nil)
+	if len(requests) != 0 || nc.GetLatestRuntime("containerd://1.7.2") != "" {
+		t.Fatalf("runtime checked while disabled: requests = %v", requests)
+	}
+
+	nc = NewNodeChecker(nil, 0)
+	nc.githubBase = srv.URL
+	nc.SetRuntimeCheck(true)
+	nc.Check(nodes, nil)
+	latest := nc.GetLatestRuntime("containerd://1.7.2")
+	if latest != "v2.1.4" {
+		t.Fatalf("GetLatestRuntime() = %q, want v2.1.4", latest)
+	}
+	if _, current := ParseContainerRuntime("containerd://1.7.2"); !IsOutdated(current, latest) {
+		t.Errorf("containerd 1.7.2 not outdated against %s", latest)
+	}
+}
+
+func TestNodeCheckerKubeletAcrossClusters(t *testing.T) {
+	// Page 1 is a full page of newer releases; the older minor only shows
+	// up on page 2.
+	page1 := []map[string]any{
+		{"tag_name": "v1.34.0-rc.0", "prerelease": true},
+		{"tag_name": "v1.33.9", "draft": true},
+		{"tag_name": "v1.33.2"},
+		{"tag_name": "v1.33.3"},
+	}
+	for i := len(page1); i < 100; i++ {
+		page1 = append(page1, map[string]any{"tag_name": fmt.Sprintf("v1.32.%d", i)})
+	}
+	page2 := []map[string]any{{"tag_name": "v1.31.14"}, {"tag_name": "v1.31.13"}}
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/kubernetes/kubernetes/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		page := page1
+		if r.URL.Query().Get("page") == "2" {
+			page = page2
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	nodes := []model.NodeInfo{
+		{Name: "cp-1", Cluster: "Homelab", KubeletVersion: "v1.33.1"},
+		{Name: "worker-1", Cluster: "Homelab", KubeletVersion: "v1.33.1"},
+		{Name: "nas-1", Cluster: "NAS", KubeletVersion: "v1.31.4+k3s1"},
+	}
+	nc := NewNodeChecker(nil, 0)
+	nc.githubBase = srv.URL
+	counts, _ := nc.ForceCheck(nodes, nil)
+
+	if got := nc.GetLatestKubelet("v1.33.1"); got != "v1.33.3" {
+		t.Errorf("Homelab latest kubelet = %q, want v1.33.3", got)
+	}
+	if got := nc.GetLatestKubelet("v1.31.4+k3s1"); got != "v1.31.14" {
+		t.Errorf("NAS latest kubelet = %q, want v1.31.14", got)
+	}
+	if counts.Checked != 2 || counts.Resolved != 2 {
+		t.Errorf("counts = %+v, want 2 minors checked and resolved", counts)
+	}
+	if requests != 2 {
+		t.Errorf("release list requests = %d, want the 2 pages read once for both minors", requests)
+	}
+}
+
+func TestNodeCheckerAPIServerVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/kubernetes/kubernetes/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"tag_name":"v1.32.7"},{"tag_name":"v1.31.14"},{"tag_name":"v1.31.13"}]`))
+	}))
+	defer srv.Close()
+
+	// The control plane runs a minor no node does; it is checked all the same.
+	nodes := []model.NodeInfo{{Name: "n1", Cluster: "NAS", KubeletVersion: "v1.30.2"}}
+	apiServers := []model.APIServerInfo{{Cluster: "NAS", GitVersion: "v1.31.4+k3s1"}}
+	nc := NewNodeChecker(nil, 0)
+	nc.githubBase = srv.URL
+	nc.ForceCheck(nodes, apiServers)
+
+	latest := nc.GetLatestKubelet("v1.31.4+k3s1")
+	if latest != "v1.31.14" {
+		t.Fatalf("latest API server patch = %q, want v1.31.14", latest)
+	}
+	if !IsOutdated("v1.31.4+k3s1", latest) {
+		t.Errorf("API server v1.31.4+k3s1 not outdated against %s", latest)
+	}
+}
@@ -0,0 +1,83 @@
+package versions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Policy holds minimum required versions, keyed by chart name and by image.
+// An image key matches the full image ("docker.io/library/nginx") or any
+// trailing path of it ("library/nginx", "nginx"); the longest matching key
+// wins. Minimums are written "1.25" or ">=1.25".
+type Policy struct {
+	Charts map[string]string `json:"charts"`
+	Images map[string]string `json:"images"`
+}
+
+// LoadPolicy reads a JSON policy file. Unknown keys and minimums that are
+// not versions are rejected so a typo doesn't silently exempt something.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file: %w", err)
+	}
+	var p Policy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parsing policy file %s: %w", path, err)
+	}
+	for _, m := range []map[string]string{p.Charts, p.Images} {
+		for name, raw := range m {
+			v := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(raw), ">="))
+			if _, ok := parseSemver(v); !ok {
+				return nil, fmt.Errorf("policy file %s: %s: invalid minimum version %q", path, name, raw)
+			}
+			m[name] = v
+		}
+	}
+	return &p, nil
+}
+
+// ChartMinimum returns the minimum version required for chart, or "" when
+// the policy doesn't cover it. A nil policy covers nothing.
+func (p *Policy) ChartMinimum(chart string) string {
+	if p == nil {
+		return ""
+	}
+	return p.Charts[chart]
+}
+
+// ImageMinimum returns the minimum version required for image (registry and
+// repository, without tag), or "" when the policy doesn't cover it.
+func (p *Policy) ImageMinimum(image string) string {
+	if p == nil {
+		return ""
+	}
+	best, minimum := "", ""
+	for key, v := range p.Images {
+		if (image == key || strings.HasSuffix(image, "/"+key)) && len(key) > len(best) {
+			best, minimum = key, v
+		}
+	}
+	return minimum
+}
+
+// BelowMinimum reports whether version is lower than minimum. Suffixes such
+// as "-alpine" or "-rc.1" are ignored, so only the numeric versions are
+// compared; a version that isn't one (a digest, "latest") is never below.
+func BelowMinimum(version, minimum string) bool {
+	cur, ok := parseSemver(version)
+	if !ok || minimum == "" {
+		return false
+	}
+	floor, ok := parseSemver(minimum)
+	if !ok {
+		return false
+	}
+	cur.pre, floor.pre = "", ""
+	return cur.less(floor)
+}
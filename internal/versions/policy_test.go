@@ -0,0 +1,72 @@
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte(`{
+		"charts": {"ingress-nginx": ">= 4.10.0"},
+		"images": {"nginx": ">=1.25", "bitnami/nginx": "1.26"}
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	if got := p.ChartMinimum("ingress-nginx"); got != "4.10.0" {
+		t.Errorf("ChartMinimum(ingress-nginx) = %q, want 4.10.0", got)
+	}
+	for image, want := range map[string]string{
+		"docker.io/library/nginx":     "1.25",
+		"docker.io/bitnami/nginx":     "1.26", // the longer key wins
+		"ghcr.io/acme/nginx-exporter": "",
+		"ghcr.io/acme/not-nginx":      "",
+	} {
+		if got := p.ImageMinimum(image); got != want {
+			t.Errorf("ImageMinimum(%s) = %q, want %q", image, got, want)
+		}
+	}
+
+	var nilPolicy *Policy
+	if nilPolicy.ChartMinimum("ingress-nginx") != "" || nilPolicy.ImageMinimum("nginx") != "" {
+		t.Error("a nil policy should require nothing")
+	}
+
+	for _, bad := range []string{`{"images": {"nginx": "latest"}}`, `{"image": {"nginx": "1.25"}}`} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("LoadPolicy accepted %s", bad)
+		}
+	}
+}
+
+func TestBelowMinimum(t *testing.T) {
+	tests := []struct {
+		version, minimum string
+		want             bool
+	}{
+		{"1.24", "1.25", true},
+		{"1.24.9", "1.25", true},
+		{"1.25.0", "1.25", false},
+		{"1.26", "1.25", false},
+		{"v1.24.0", "1.25", true},
+		{"1.25.0-alpine", "1.25", false}, // variant suffix ignored
+		{"1.24.3-alpine", "1.25", true},
+		{"latest", "1.25", false}, // not a version: can't judge
+		{"sha256:abc", "1.25", false},
+		{"1.24", "", false},
+	}
+	for _, tt := range tests {
+		if got := BelowMinimum(tt.version, tt.minimum); got != tt.want {
+			t.Errorf("BelowMinimum(%q, %q) = %v, want %v", tt.version, tt.minimum, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ProxyHealth is the outcome of the last probe of the registry proxy.
+type ProxyHealth struct {
+	Proxy     string    `json:"proxy"`
+	Reachable bool      `json:"reachable"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ProbeRegistryProxy checks that the registry proxy answers on /v2/, the
+// OCI distribution base endpoint, and records the outcome for
+// RegistryProxyHealth. 200 and 401 both count as reachable: a proxy that
+// requires auth still serves the endpoint. HTTPS is tried first, then plain
+// HTTP, as for tag listings against internal registries. With no proxy
+// configured it clears the recorded outcome and returns the zero
+// ProxyHealth.
+func (c *Checker) ProbeRegistryProxy(ctx context.Context) ProxyHealth {
+	proxy := c.RegistryProxy()
+	if proxy == "" {
+		c.mu.Lock()
+		c.proxyHealth = ProxyHealth{}
+		c.mu.Unlock()
+		return ProxyHealth{}
+	}
+
+	h := ProxyHealth{Proxy: proxy, Reachable: true}
+	if err := c.probeV2(ctx, "https://"+proxy+"/v2/"); err != nil {
+		if httpErr := c.probeV2(ctx, "http://"+proxy+"/v2/"); httpErr != nil {
+			h.Reachable = false
+			h.Error = err.Error()
+		}
+	}
+	h.CheckedAt = time.Now()
+
+	c.mu.Lock()
+	c.proxyHealth = h
+	c.mu.Unlock()
+	return h
+}
+
+// RegistryProxyHealth returns the outcome of the last ProbeRegistryProxy,
+// or the zero ProxyHealth when the proxy has not been probed.
+func (c *Checker) RegistryProxyHealth() ProxyHealth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.proxyHealth
+}
+
+func (c *Checker) probeV2(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return nil
+}
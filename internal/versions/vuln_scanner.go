@@ -0,0 +1,320 @@
+package versions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fredericrous/cluster-vision/internal/imageref"
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+// vulnScanTTL is how long a scan result for an image digest is reused.
+const vulnScanTTL = 24 * time.Hour
+
+// VulnCounts holds the number of distinct critical and high vulnerabilities
+// found in one image.
+type VulnCounts struct {
+	Critical int
+	High     int
+}
+
+type vulnScan struct {
+	counts    VulnCounts
+	scannedAt time.Time
+}
+
+// VulnScanner summarizes image vulnerabilities from an external scanner:
+// a Trivy server wrapper answering POST /scan, and/or a directory of
+// precomputed Trivy or Grype JSON reports. Results are cached per image
+// digest, so pods sharing an image cost one scan, and a tag that moved
+// mid-rollout gets each of its digests scanned.
+type VulnScanner struct {
+	mu        sync.RWMutex
+	scans     map[string]vulnScan // digest (or image ref if unresolved) → result
+	keys      map[string][]string // image ref "registry/repo:tag" → scans keys of its digests
+	checking  atomic.Bool
+	serverURL string // e.g. http://trivy.security:8080; POST {serverURL}/scan
+	reportDir string // *.json Trivy (`trivy image -f json`) or Grype (`grype -o json`) reports
+	client    *http.Client
+}
+
+// NewVulnScanner creates a VulnScanner. Either source may be empty.
+func NewVulnScanner(serverURL, reportDir string) *VulnScanner {
+	return &VulnScanner{
+		scans:     make(map[string]vulnScan),
+		keys:      make(map[string][]string),
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		reportDir: reportDir,
+		client: &http.Client{
+			Timeout: 5 * time.Minute, // a cold scan pulls the image
+		},
+	}
+}
+
+// Get returns the vulnerability counts for an image ref ("registry/repo:tag").
+// When pods run several digests under the ref, it returns the worst of
+// those scanned.
+func (vs *VulnScanner) Get(image string) (VulnCounts, bool) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+	var worst VulnCounts
+	found := false
+	for _, key := range vs.keys[image] {
+		s, ok := vs.scans[key]
+		if !ok {
+			continue
+		}
+		if !found || s.counts.Critical > worst.Critical ||
+			(s.counts.Critical == worst.Critical && s.counts.High > worst.High) {
+			worst = s.counts
+		}
+		found = true
+	}
+	return worst, found
+}
+
+// Check resolves vulnerability counts for every distinct image in pods,
+// dropping those cached for images none of pods runs.
+// Report files are re-read on each call; server scans only run for digests
+// without a fresh cached result. If the server is unreachable the rest of
+// the images are left for the next check.
+// Single-flight: returns immediately if already checking.
+func (vs *VulnScanner) Check(pods []model.PodImageInfo) {
+	if !vs.checking.CompareAndSwap(false, true) {
+		return
+	}
+	defer vs.checking.Store(false)
+
+	type target struct {
+		image  string // what the scanner server is asked to scan
+		ref    string // normalized image ref
+		digest string
+	}
+	// Pods are deduplicated on digest when the runtime reports one, so
+	// every digest behind a moved tag is scanned, and on ref otherwise.
+	targets := make(map[string]target) // scans key → target
+	keys := make(map[string][]string)  // normalized ref → scans keys
+	for _, p := range pods {
+		ref := normalizeImageRef(p.Image)
+		digest := imageref.Digest(p.ImageID)
+		if digest == "" {
+			digest = imageref.Digest(p.Image)
+		}
+		key, image := ref, p.Image
+		if digest != "" {
+			registry, repo, _ := imageref.Parse(p.Image)
+			key, image = digest, registry+"/"+repo+"@"+digest
+		}
+		if _, ok := targets[key]; ok {
+			continue
+		}
+		targets[key] = target{image: image, ref: ref, digest: digest}
+		keys[ref] = append(keys[ref], key)
+	}
+	// Forget images no pod runs any more, so the maps track the current
+	// pod set instead of every digest the cluster ever ran.
+	vs.mu.Lock()
+	vs.keys = keys
+	maps.DeleteFunc(vs.scans, func(key string, _ vulnScan) bool {
+		_, ok := targets[key]
+		return !ok
+	})
+	vs.mu.Unlock()
+
+	var reports map[string]VulnCounts
+	if vs.reportDir != "" {
+		reports = vs.loadReports()
+	}
+
+	now := time.Now()
+	serverDown := false
+	scanned := 0
+	for key, t := range targets {
+		vs.mu.RLock()
+		cached, ok := vs.scans[key]
+		vs.mu.RUnlock()
+
+		if counts, ok := reports[t.digest]; ok && t.digest != "" {
+			vs.store(key, counts, now)
+			continue
+		}
+		if counts, ok := reports[t.ref]; ok {
+			vs.store(key, counts, now)
+			continue
+		}
+
+		if vs.serverURL == "" || serverDown || (ok && now.Sub(cached.scannedAt) < vulnScanTTL) {
+			continue
+		}
+		counts, err := vs.scan(t.image)
+		if err != nil {
+			slog.Warn("vulnerability scan failed — skipping remaining images this round", "image", t.image, "error", err)
+			serverDown = true
+			continue
+		}
+		vs.store(key, counts, now)
+		scanned++
+	}
+
+	slog.Info("vulnerability check complete", "images", len(targets), "scanned", scanned, "reports", len(reports))
+}
+
+func (vs *VulnScanner) store(key string, counts VulnCounts, at time.Time) {
+	vs.mu.Lock()
+	vs.scans[key] = vulnScan{counts: counts, scannedAt: at}
+	vs.mu.Unlock()
+}
+
+// scan asks the scanner server for a report on one image.
+func (vs *VulnScanner) scan(image string) (VulnCounts, error) {
+	body, _ := json.Marshal(map[string]string{"image": image})
+	resp, err := vs.client.Post(vs.serverURL+"/scan", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return VulnCounts{}, fmt.Errorf("posting to scanner: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return VulnCounts{}, fmt.Errorf("scanner returned %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return VulnCounts{}, err
+	}
+	r, err := parseVulnReport(data)
+	if err != nil {
+		return VulnCounts{}, err
+	}
+	return r.counts, nil
+}
+
+// loadReports reads every *.json report in reportDir, keyed by normalized
+// image ref and by digest.
+func (vs *VulnScanner) loadReports() map[string]VulnCounts {
+	files, err := filepath.Glob(filepath.Join(vs.reportDir, "*.json"))
+	if err != nil {
+		slog.Warn("listing vulnerability reports", "dir", vs.reportDir, "error", err)
+		return nil
+	}
+
+	out := make(map[string]VulnCounts)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			slog.Warn("reading vulnerability report", "file", f, "error", err)
+			continue
+		}
+		r, err := parseVulnReport(data)
+		if err != nil {
+			slog.Warn("parsing vulnerability report", "file", f, "error", err)
+			continue
+		}
+		if r.image != "" {
+			out[normalizeImageRef(r.image)] = r.counts
+		}
+		for _, d := range r.digests {
+			out[d] = r.counts
+		}
+	}
+	return out
+}
+
+type vulnReport struct {
+	image   string
+	digests []string
+	counts  VulnCounts
+}
+
+// parseVulnReport reads a Trivy or Grype JSON report.
+func parseVulnReport(data []byte) (vulnReport, error) {
+	var raw struct {
+		// Trivy
+		ArtifactName string `json:"ArtifactName"`
+		Metadata     struct {
+			ImageID     string   `json:"ImageID"`
+			RepoDigests []string `json:"RepoDigests"`
+		} `json:"Metadata"`
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID string `json:"VulnerabilityID"`
+				Severity        string `json:"Severity"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+		// Grype
+		Matches []struct {
+			Vulnerability struct {
+				ID       string `json:"id"`
+				Severity string `json:"severity"`
+			} `json:"vulnerability"`
+		} `json:"matches"`
+		Source struct {
+			Target struct {
+				UserInput   string   `json:"userInput"`
+				RepoDigests []string `json:"repoDigests"`
+			} `json:"target"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return vulnReport{}, fmt.Errorf("parsing report: %w", err)
+	}
+
+	var r vulnReport
+	seen := make(map[string]bool)
+	count := func(id, severity string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		switch strings.ToUpper(severity) {
+		case "CRITICAL":
+			r.counts.Critical++
+		case "HIGH":
+			r.counts.High++
+		}
+	}
+
+	if raw.ArtifactName != "" {
+		r.image = raw.ArtifactName
+		for _, d := range raw.Metadata.RepoDigests {
+			if dg := imageref.Digest(d); dg != "" {
+				r.digests = append(r.digests, dg)
+			}
+		}
+		for _, res := range raw.Results {
+			for _, v := range res.Vulnerabilities {
+				count(v.VulnerabilityID, v.Severity)
+			}
+		}
+		return r, nil
+	}
+
+	r.image = raw.Source.Target.UserInput
+	for _, d := range raw.Source.Target.RepoDigests {
+		if dg := imageref.Digest(d); dg != "" {
+			r.digests = append(r.digests, dg)
+		}
+	}
+	for _, m := range raw.Matches {
+		count(m.Vulnerability.ID, m.Vulnerability.Severity)
+	}
+	return r, nil
+}
+
+// normalizeImageRef rewrites an image reference as "registry/repo:tag", the
+// form the images table is keyed by.
+func normalizeImageRef(ref string) string {
+	registry, repo, tag := imageref.Parse(ref)
+	return registry + "/" + repo + ":" + tag
+}
@@ -0,0 +1,125 @@
+package versions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fredericrous/cluster-vision/internal/model"
+)
+
+func TestVulnScannerReportDir(t *testing.T) {
+	dir := t.TempDir()
+	reports := map[string]string{
+		// Trivy report matched by image ref.
+		"redis.json": `{
+			"ArtifactName": "redis:7.2",
+			"Results": [{"Vulnerabilities": [
+				{"VulnerabilityID": "CVE-1", "Severity": "HIGH"}
+			]}]
+		}`,
+		// Grype report matched by digest, whatever tag the pod uses.
+		"app.json": `{
+			"source": {"target": {"userInput": "ghcr.io/acme/app:main", "repoDigests": ["ghcr.io/acme/app@sha256:feed"]}},
+			"matches": [
+				{"vulnerability": {"id": "CVE-2", "severity": "Critical"}},
+				{"vulnerability": {"id": "CVE-3", "severity": "Critical"}},
+				{"vulnerability": {"id": "CVE-3", "severity": "Critical"}},
+				{"vulnerability": {"id": "CVE-4", "severity": "Medium"}}
+			]
+		}`,
+		"notes.txt": "ignored",
+	}
+	for name, content := range reports {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vs := NewVulnScanner("", dir)
+	vs.Check([]model.PodImageInfo{
+		{Image: "redis:7.2"},
+		{Image: "ghcr.io/acme/app:v2", ImageID: "ghcr.io/acme/app@sha256:feed"},
+		{Image: "busybox:1.36"},
+	})
+
+	tests := []struct {
+		image string
+		want  VulnCounts
+		ok    bool
+	}{
+		{"docker.io/library/redis:7.2", VulnCounts{High: 1}, true},
+		{"ghcr.io/acme/app:v2", VulnCounts{Critical: 2}, true},
+		{"docker.io/library/busybox:1.36", VulnCounts{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := vs.Get(tt.image)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Get(%q) = %+v, %v; want %+v, %v", tt.image, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestVulnScannerScansEachDigestOfMovedTag(t *testing.T) {
+	var scanned []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Image string `json:"image"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		scanned = append(scanned, req.Image)
+		vulns := `[]`
+		if req.Image == "ghcr.io/acme/app@sha256:new" {
+			vulns = `[{"VulnerabilityID": "CVE-1", "Severity": "CRITICAL"}]`
+		}
+		_, _ = w.Write([]byte(`{"ArtifactName":"` + req.Image + `","Results":[{"Vulnerabilities":` + vulns + `}]}`))
+	}))
+	defer srv.Close()
+
+	vs := NewVulnScanner(srv.URL, "")
+	vs.Check([]model.PodImageInfo{
+		{Image: "ghcr.io/acme/app:main", ImageID: "ghcr.io/acme/app@sha256:old"},
+		{Image: "ghcr.io/acme/app:main", ImageID: "ghcr.io/acme/app@sha256:new"},
+		{Image: "ghcr.io/acme/app:main", ImageID: "ghcr.io/acme/app@sha256:new"},
+	})
+
+	slices.Sort(scanned)
+	if want := []string{"ghcr.io/acme/app@sha256:new", "ghcr.io/acme/app@sha256:old"}; !slices.Equal(scanned, want) {
+		t.Errorf("scanned %v, want each digest once: %v", scanned, want)
+	}
+	if got, ok := vs.Get("ghcr.io/acme/app:main"); !ok || got != (VulnCounts{Critical: 1}) {
+		t.Errorf("Get() = %+v, %v; want the worst digest's counts", got, ok)
+	}
+}
+
+func TestVulnScannerForgetsRemovedImages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ArtifactName":"x","Results":[]}`))
+	}))
+	defer srv.Close()
+
+	vs := NewVulnScanner(srv.URL, "")
+	vs.Check([]model.PodImageInfo{
+		{Image: "ghcr.io/acme/app:v1", ImageID: "ghcr.io/acme/app@sha256:one"},
+		{Image: "redis:7.2"},
+	})
+	vs.Check([]model.PodImageInfo{
+		{Image: "ghcr.io/acme/app:v2", ImageID: "ghcr.io/acme/app@sha256:two"},
+	})
+
+	if _, ok := vs.Get("ghcr.io/acme/app:v1"); ok {
+		t.Error("app:v1 still cached after its pods went away")
+	}
+	if _, ok := vs.Get("ghcr.io/acme/app:v2"); !ok {
+		t.Error("app:v2 not cached")
+	}
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+	if len(vs.keys) != 1 || len(vs.scans) != 1 {
+		t.Errorf("cached %d refs and %d scans, want 1 each: %v", len(vs.keys), len(vs.scans), vs.keys)
+	}
+}
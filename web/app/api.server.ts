@@ -5,11 +5,22 @@ export interface DiagramResult {
   title: string;
   type: "mermaid" | "markdown" | "table" | "flow";
   content: string;
+  /** Embedded JSON for table/flow diagrams; same payload as `content`. */
+  data?: unknown;
+  /** Placeholder with nothing to show yet; `content` explains why. */
+  empty?: boolean;
+  /** Generator run time in milliseconds. */
+  genMillis?: number;
+  /** Content hash; export URLs carry it as `?v=`. */
+  hash?: string;
 }
 
 interface DiagramsResponse {
   diagrams: DiagramResult[];
   generated_at: string;
+  /** Set once the last successful refresh is older than STALE_AFTER. */
+  stale?: boolean;
+  ageSeconds?: number;
 }
 
 export async function fetchDiagrams(): Promise<DiagramsResponse> {
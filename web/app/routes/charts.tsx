@@ -4,6 +4,7 @@ import { fetchDiagram } from "../api.server";
 import { DiagramPage } from "../components/diagram-page";
 import { DataTable, OutdatedBadge, SecurityBadge } from "../components/data-table";
 import type { ColumnDef } from "@tanstack/react-table";
+import { Badge, Tooltip } from "@duro-app/ui";
 
 interface VersionRow {
   cluster: string;
@@ -11,8 +12,17 @@ interface VersionRow {
   namespace: string;
   chart: string;
   version: string;
-  latest: string;
+  constraint?: string;      // version range the release resolves from; "*" is latest
+  latest: string;           // within the constraint, when there is one
+  latestOverall?: string;   // newer version outside the constraint
   outdated: boolean;
+  checkError?: string;      // the latest-version lookup failed
+  failures?: number;        // consecutive reconcile failures
+  installFailures?: number;
+  upgradeFailures?: number;
+  thrashing?: boolean;      // stuck in a reconcile retry loop
+  policyMinimum?: string;   // minimum version the policy file requires
+  policyViolation?: boolean;
   repoType: string;
   repoUrl: string;
   securityRisk: string;
@@ -29,19 +39,85 @@ export async function loader() {
 
 const columns: ColumnDef<VersionRow, string>[] = [
   { accessorKey: "cluster", header: "Cluster" },
-  { accessorKey: "release", header: "Release" },
+  {
+    accessorKey: "release",
+    header: "Release",
+    cell: ({ row }) => {
+      const r = row.original;
+      if (!r.thrashing) return r.release;
+      const detail = `${r.failures ?? 0} failures, ${r.installFailures ?? 0} install, ${r.upgradeFailures ?? 0} upgrade`;
+      return (
+        <span>
+          {r.release}{" "}
+          <Tooltip.Root content={detail}>
+            <Tooltip.Trigger>
+              <Badge variant="error" size="sm">thrashing</Badge>
+            </Tooltip.Trigger>
+          </Tooltip.Root>
+        </span>
+      );
+    },
+  },
   { accessorKey: "namespace", header: "Namespace" },
   { accessorKey: "chart", header: "Chart" },
-  { accessorKey: "version", header: "Version" },
+  {
+    accessorKey: "version",
+    header: "Version",
+    cell: ({ row }) => {
+      const r = row.original;
+      if (!r.policyViolation && !r.constraint) return r.version;
+      return (
+        <span>
+          {r.version}{" "}
+          {r.constraint && (
+            <Tooltip.Root content="Version constraint in the HelmRelease">
+              <Tooltip.Trigger>
+                <Badge variant="default" size="sm">{r.constraint === "*" ? "latest" : r.constraint}</Badge>
+              </Tooltip.Trigger>
+            </Tooltip.Root>
+          )}{" "}
+          {r.policyViolation && (
+            <Tooltip.Root content={`Policy requires >= ${r.policyMinimum}`}>
+              <Tooltip.Trigger>
+                <Badge variant="error" size="sm">below policy</Badge>
+              </Tooltip.Trigger>
+            </Tooltip.Root>
+          )}
+        </span>
+      );
+    },
+  },
   {
     accessorKey: "latest",
     header: "Latest",
-    cell: ({ row }) => (
-      <OutdatedBadge
-        value={row.original.latest}
-        outdated={row.original.outdated}
-      />
-    ),
+    cell: ({ row }) => {
+      if (row.original.checkError && row.original.latest === "-") {
+        return (
+          <Tooltip.Root content={row.original.checkError}>
+            <Tooltip.Trigger>
+              <Badge variant="warning" size="sm">check failed</Badge>
+            </Tooltip.Trigger>
+          </Tooltip.Root>
+        );
+      }
+      const badge = (
+        <OutdatedBadge
+          value={row.original.latest}
+          outdated={row.original.outdated}
+        />
+      );
+      if (!row.original.latestOverall) return badge;
+      return (
+        <span>
+          {badge}{" "}
+          <Tooltip.Root content="Newest version, outside the release's constraint">
+            <Tooltip.Trigger>
+              <Badge variant="warning" size="sm">{row.original.latestOverall} outside range</Badge>
+            </Tooltip.Trigger>
+          </Tooltip.Root>
+        </span>
+      );
+    },
   },
   {
     accessorKey: "securityRisk",
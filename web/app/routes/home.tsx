@@ -21,6 +21,8 @@ export async function loader() {
     diagramCount: data.diagrams.length,
     diagrams: data.diagrams.map((d) => ({ id: d.id, title: d.title })),
     generatedAt: data.generated_at,
+    stale: data.stale ?? false,
+    ageSeconds: data.ageSeconds ?? 0,
   };
 }
 
@@ -148,7 +150,7 @@ const cards = [
 ];
 
 export default function Home({ loaderData }: Route.ComponentProps) {
-  const { generatedAt } = loaderData;
+  const { generatedAt, stale, ageSeconds } = loaderData;
   const formattedTime = new Date(generatedAt).toLocaleString();
 
   return (
@@ -158,6 +160,12 @@ export default function Home({ loaderData }: Route.ComponentProps) {
         Auto-generated infrastructure diagrams from live Kubernetes state
       </p>
       <span className={styles.generatedAt}>Last refresh: {formattedTime}</span>
+      {stale && (
+        <p className={styles.staleBanner}>
+          Data is {Math.round(ageSeconds / 60)} minutes old — recent refreshes
+          have not succeeded.
+        </p>
+      )}
       <Separator />
       <div className={styles.grid}>
         {cards.map((card) => (
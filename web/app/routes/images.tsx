@@ -4,18 +4,24 @@ import { fetchDiagram } from "../api.server";
 import { DiagramPage } from "../components/diagram-page";
 import { DataTable, ExploitBadge, OutdatedBadge, SecurityBadge } from "../components/data-table";
 import type { ColumnDef } from "@tanstack/react-table";
-import { Tooltip } from "@duro-app/ui";
+import { Badge, Tooltip } from "@duro-app/ui";
 import tableStyles from "../components/data-table.module.css";
 
 interface ImageRow {
   image: string;
   tag: string;
+  version?: string;         // from the cluster-vision.io/version pod annotation
   type: string;
   namespaces: string;
+  owners: string;           // top-level workloads, e.g. "Deployment/web"
   pods: number;
   registry: string;
+  registryName: string;
   latest: string;
   outdated: boolean;
+  latestStatus?: string;    // "unsupported" | "no-tags" | "only-tag"
+  policyMinimum?: string;   // minimum version the policy file requires
+  policyViolation?: boolean;
   securityRisk: string;
   vulnSummary: string;
   exploitRisk: string;     // "kev" | "high-epss" | "low-epss" | "none" | ""
@@ -36,8 +42,29 @@ const columns: ColumnDef<ImageRow, string>[] = [
   {
     accessorKey: "tag",
     header: "Tag",
-    cell: ({ getValue }) => {
+    cell: ({ getValue, row }) => {
       const tag = getValue();
+      if (row.original.policyViolation) {
+        return (
+          <span>
+            {row.original.version || tag}{" "}
+            <Tooltip.Root content={`Policy requires >= ${row.original.policyMinimum}`}>
+              <Tooltip.Trigger>
+                <Badge variant="error" size="sm">below policy</Badge>
+              </Tooltip.Trigger>
+            </Tooltip.Root>
+          </span>
+        );
+      }
+      if (row.original.version) {
+        return (
+          <Tooltip.Root content={tag}>
+            <Tooltip.Trigger>
+              <span>{row.original.version}</span>
+            </Tooltip.Trigger>
+          </Tooltip.Root>
+        );
+      }
       const isSha = /^sha256:/.test(tag) || /^[0-9a-f]{40,}$/.test(tag);
       if (isSha) {
         const short = tag.replace(/^sha256:/, "").slice(0, 7);
@@ -55,12 +82,35 @@ const columns: ColumnDef<ImageRow, string>[] = [
   {
     accessorKey: "latest",
     header: "Latest",
-    cell: ({ row }) => (
-      <OutdatedBadge
-        value={row.original.latest}
-        outdated={row.original.outdated}
-      />
-    ),
+    cell: ({ row }) => {
+      if (row.original.latestStatus === "unsupported") {
+        return (
+          <Tooltip.Root content="The registry doesn't allow listing tags. Annotate the pods with cluster-vision.io/version-source to track releases instead.">
+            <Tooltip.Trigger>
+              <Badge variant="default" size="sm">listing unsupported</Badge>
+            </Tooltip.Trigger>
+          </Tooltip.Root>
+        );
+      }
+      if (row.original.latestStatus === "no-tags" || row.original.latestStatus === "only-tag") {
+        const why = row.original.latestStatus === "no-tags"
+          ? "The registry lists no tags for this image yet."
+          : "The deployed tag is the only tag in the registry.";
+        return (
+          <Tooltip.Root content={why}>
+            <Tooltip.Trigger>
+              <Badge variant="success" size="sm">up to date</Badge>
+            </Tooltip.Trigger>
+          </Tooltip.Root>
+        );
+      }
+      return (
+        <OutdatedBadge
+          value={row.original.latest}
+          outdated={row.original.outdated}
+        />
+      );
+    },
   },
   {
     accessorKey: "securityRisk",
@@ -83,8 +133,15 @@ const columns: ColumnDef<ImageRow, string>[] = [
     ),
   },
   { accessorKey: "type", header: "Type" },
-  { accessorKey: "registry", header: "Registry" },
+  {
+    accessorKey: "registry",
+    header: "Registry",
+    cell: ({ row }) => (
+      <span title={row.original.registry}>{row.original.registryName}</span>
+    ),
+  },
   { accessorKey: "namespaces", header: "Namespaces", meta: { className: tableStyles.wideCell } },
+  { accessorKey: "owners", header: "Owners", meta: { className: tableStyles.wideCell } },
   { accessorKey: "pods", header: "Pods" },
 ];
 
@@ -101,7 +158,7 @@ export default function Images({ loaderData }: Route.ComponentProps) {
       <DataTable
         data={rows}
         columns={columns}
-        filterColumns={["registry", "namespaces"]}
+        filterColumns={["registry", "namespaces", "owners"]}
       />
     </DiagramPage>
   );
@@ -19,11 +19,14 @@ interface NodeRow {
   latestKubelet: string;
   kubeletOutdated: boolean;
   containerRuntime: string;
+  latestRuntime: string;
+  runtimeOutdated: boolean;
   kernel: string;
   cpu: string;
   memory: string;
   arch: string;
   provider: string;
+  host: string;
   distro: string;
   gpu: string;
   osDisk: string;
@@ -47,6 +50,7 @@ const columns: ColumnDef<NodeRow, string>[] = [
   { accessorKey: "roles", header: "Roles" },
   { accessorKey: "ip", header: "IP" },
   { accessorKey: "provider", header: "Provider" },
+  { accessorKey: "host", header: "Host" },
   { accessorKey: "distro", header: "Distro" },
   {
     id: "osVersion",
@@ -86,6 +90,16 @@ const columns: ColumnDef<NodeRow, string>[] = [
     ),
   },
   { accessorKey: "containerRuntime", header: "Runtime" },
+  {
+    accessorKey: "latestRuntime",
+    header: "Latest Runtime",
+    cell: ({ row }) => (
+      <OutdatedBadge
+        value={row.original.latestRuntime || "-"}
+        outdated={row.original.runtimeOutdated}
+      />
+    ),
+  },
   { accessorKey: "kernel", header: "Kernel" },
   { accessorKey: "cpu", header: "CPU" },
   { accessorKey: "memory", header: "Memory" },
@@ -108,7 +122,7 @@ export default function Nodes({ loaderData }: Route.ComponentProps) {
       <DataTable
         data={rows}
         columns={columns}
-        filterColumns={["cluster", "type", "roles", "arch"]}
+        filterColumns={["cluster", "type", "roles", "arch", "host"]}
       />
     </DiagramPage>
   );
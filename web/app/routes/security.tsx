@@ -7,6 +7,7 @@ import {
   BooleanBadge,
 } from "../components/data-table";
 import type { ColumnDef } from "@tanstack/react-table";
+import { Badge, Tooltip } from "@duro-app/ui";
 
 interface SecurityRow {
   cluster: string;
@@ -16,8 +17,10 @@ interface SecurityRow {
   mtls: string;
   mtlsClient: string;
   extAuth: string;
-  backup: string;
+  backup: string;        // "yes" | "stale" | "no"
+  lastBackup?: string;   // age of the last completed Velero backup
   podSecurity: string;
+  limited: string;
 }
 
 export function meta({}: Route.MetaArgs) {
@@ -65,9 +68,30 @@ const columns: ColumnDef<SecurityRow, string>[] = [
   {
     accessorKey: "backup",
     header: "Backup",
-    cell: ({ getValue }) => <BooleanBadge value={getValue()} />,
+    cell: ({ row }) => {
+      const { backup, lastBackup } = row.original;
+      const badge =
+        backup === "stale" ? (
+          <Badge variant="warning" size="sm">stale</Badge>
+        ) : (
+          <BooleanBadge value={backup} />
+        );
+      if (!lastBackup) return badge;
+      return (
+        <Tooltip.Root content={`Last completed Velero backup ${lastBackup} ago`}>
+          <Tooltip.Trigger>
+            <span>{badge} {lastBackup}</span>
+          </Tooltip.Trigger>
+        </Tooltip.Root>
+      );
+    },
   },
   { accessorKey: "podSecurity", header: "Pod Security" },
+  {
+    accessorKey: "limited",
+    header: "Limited",
+    cell: ({ getValue }) => <BooleanBadge value={getValue()} />,
+  },
 ];
 
 export default function Security({ loaderData }: Route.ComponentProps) {
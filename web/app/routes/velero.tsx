@@ -14,6 +14,7 @@ interface VeleroRow {
   excludedNS: string;
   ttl: string;
   phase: string;
+  lastBackup: string; // age of the last backup it started, e.g. "5h"
 }
 
 export function meta({}: Route.MetaArgs) {
@@ -33,6 +34,7 @@ const columns: ColumnDef<VeleroRow, string>[] = [
   { accessorKey: "excludedNS", header: "Excluded NS" },
   { accessorKey: "ttl", header: "TTL" },
   { accessorKey: "phase", header: "Phase" },
+  { accessorKey: "lastBackup", header: "Last Backup" },
 ];
 
 export default function Velero({ loaderData }: Route.ComponentProps) {
@@ -15,6 +15,8 @@ interface WorkloadRow {
   updateStrategy: string;
   images: string;
   age: string;
+  autoscaler?: string;
+  scaleMetrics?: string;
 }
 
 export function meta({}: Route.MetaArgs) {
@@ -31,6 +33,16 @@ const columns: ColumnDef<WorkloadRow, string>[] = [
   { accessorKey: "cluster", header: "Cluster" },
   { accessorKey: "kind", header: "Kind" },
   { accessorKey: "replicas", header: "Replicas" },
+  {
+    accessorKey: "autoscaler",
+    header: "Autoscaling",
+    cell: ({ row }) =>
+      row.original.autoscaler ? (
+        <span title={row.original.scaleMetrics}>{row.original.autoscaler}</span>
+      ) : (
+        "-"
+      ),
+  },
   { accessorKey: "updateStrategy", header: "Strategy" },
   { accessorKey: "images", header: "Images", meta: { className: tableStyles.truncateCell } },
   { accessorKey: "age", header: "Created" },